@@ -83,6 +83,11 @@ type PruneRequest struct {
 	ReservedSpace int64                  `protobuf:"varint,4,opt,name=reservedSpace,proto3" json:"reservedSpace,omitempty"`
 	MaxUsedSpace  int64                  `protobuf:"varint,5,opt,name=maxUsedSpace,proto3" json:"maxUsedSpace,omitempty"`
 	MinFreeSpace  int64                  `protobuf:"varint,6,opt,name=minFreeSpace,proto3" json:"minFreeSpace,omitempty"`
+	// dryRun returns the records that would be removed without removing them.
+	DryRun bool `protobuf:"varint,7,opt,name=dryRun,proto3" json:"dryRun,omitempty"`
+	// historyAge limits the prune to records that are only referenced by
+	// build history records older than historyAge nanoseconds.
+	HistoryAge    int64 `protobuf:"varint,8,opt,name=historyAge,proto3" json:"historyAge,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -159,6 +164,20 @@ func (x *PruneRequest) GetMinFreeSpace() int64 {
 	return 0
 }
 
+func (x *PruneRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PruneRequest) GetHistoryAge() int64 {
+	if x != nil {
+		return x.HistoryAge
+	}
+	return 0
+}
+
 type DiskUsageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Filter        []string               `protobuf:"bytes,1,rep,name=filter,proto3" json:"filter,omitempty"`
@@ -408,8 +427,28 @@ type SolveRequest struct {
 	SourcePolicy            *pb1.Policy               `protobuf:"bytes,12,opt,name=SourcePolicy,proto3" json:"SourcePolicy,omitempty"`
 	Exporters               []*Exporter               `protobuf:"bytes,13,rep,name=Exporters,proto3" json:"Exporters,omitempty"`
 	EnableSessionExporter   bool                      `protobuf:"varint,14,opt,name=EnableSessionExporter,proto3" json:"EnableSessionExporter,omitempty"`
-	unknownFields           protoimpl.UnknownFields
-	sizeCache               protoimpl.SizeCache
+	// Plan resolves the cache keys of the build without executing any
+	// operations or running the exporters. The status of the vertexes is
+	// returned in SolveResponse.Plan.
+	Plan bool `protobuf:"varint,15,opt,name=Plan,proto3" json:"Plan,omitempty"`
+	// CacheOnly fails the build instead of executing operations or accessing
+	// the network for resolving sources. Vertexes that could not be loaded
+	// from the local or imported cache are returned in an errdefs.CacheOnly
+	// error.
+	CacheOnly bool `protobuf:"varint,16,opt,name=CacheOnly,proto3" json:"CacheOnly,omitempty"`
+	// KeepGoing continues building the parts of the build that do not depend
+	// on a failed vertex. If more than one vertex fails, the errors are
+	// returned in an errdefs.Failures error.
+	KeepGoing bool `protobuf:"varint,17,opt,name=KeepGoing,proto3" json:"KeepGoing,omitempty"`
+	// Weight is the share of the worker parallelism the build gets when it
+	// runs alongside other builds, and its priority in the admission queue.
+	// Zero uses the default of the daemon.
+	Weight int64 `protobuf:"varint,18,opt,name=Weight,proto3" json:"Weight,omitempty"`
+	// SchedulerLabels are matched against the scheduler configuration of the
+	// daemon to choose the default weight of the build.
+	SchedulerLabels map[string]string `protobuf:"bytes,19,rep,name=SchedulerLabels,proto3" json:"SchedulerLabels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *SolveRequest) Reset() {
@@ -540,6 +579,41 @@ func (x *SolveRequest) GetEnableSessionExporter() bool {
 	return false
 }
 
+func (x *SolveRequest) GetPlan() bool {
+	if x != nil {
+		return x.Plan
+	}
+	return false
+}
+
+func (x *SolveRequest) GetCacheOnly() bool {
+	if x != nil {
+		return x.CacheOnly
+	}
+	return false
+}
+
+func (x *SolveRequest) GetKeepGoing() bool {
+	if x != nil {
+		return x.KeepGoing
+	}
+	return false
+}
+
+func (x *SolveRequest) GetWeight() int64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *SolveRequest) GetSchedulerLabels() map[string]string {
+	if x != nil {
+		return x.SchedulerLabels
+	}
+	return nil
+}
+
 type CacheOptions struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// ExportRefDeprecated is deprecated in favor or the new Exports since BuildKit v0.4.0.
@@ -686,6 +760,7 @@ func (x *CacheOptionsEntry) GetAttrs() map[string]string {
 type SolveResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	ExporterResponse map[string]string      `protobuf:"bytes,1,rep,name=ExporterResponse,proto3" json:"ExporterResponse,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Plan             []*VertexPlan          `protobuf:"bytes,2,rep,name=Plan,proto3" json:"Plan,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
@@ -727,6 +802,75 @@ func (x *SolveResponse) GetExporterResponse() map[string]string {
 	return nil
 }
 
+func (x *SolveResponse) GetPlan() []*VertexPlan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+// VertexPlan is the status of a vertex in a plan-only build.
+type VertexPlan struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Digest string                 `protobuf:"bytes,1,opt,name=Digest,proto3" json:"Digest,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	// Status is one of "cached", "would-run" or "unknown-until-input".
+	Status        string `protobuf:"bytes,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VertexPlan) Reset() {
+	*x = VertexPlan{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VertexPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VertexPlan) ProtoMessage() {}
+
+func (x *VertexPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VertexPlan.ProtoReflect.Descriptor instead.
+func (*VertexPlan) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VertexPlan) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *VertexPlan) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VertexPlan) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 type StatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ref           string                 `protobuf:"bytes,1,opt,name=Ref,proto3" json:"Ref,omitempty"`
@@ -736,7 +880,7 @@ type StatusRequest struct {
 
 func (x *StatusRequest) Reset() {
 	*x = StatusRequest{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -748,7 +892,7 @@ func (x *StatusRequest) String() string {
 func (*StatusRequest) ProtoMessage() {}
 
 func (x *StatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -761,7 +905,7 @@ func (x *StatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{8}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *StatusRequest) GetRef() string {
@@ -777,13 +921,14 @@ type StatusResponse struct {
 	Statuses      []*VertexStatus        `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`
 	Logs          []*VertexLog           `protobuf:"bytes,3,rep,name=logs,proto3" json:"logs,omitempty"`
 	Warnings      []*VertexWarning       `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Events        []*VertexEvent         `protobuf:"bytes,5,rep,name=events,proto3" json:"events,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -795,7 +940,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -808,7 +953,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{9}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *StatusResponse) GetVertexes() []*Vertex {
@@ -839,6 +984,13 @@ func (x *StatusResponse) GetWarnings() []*VertexWarning {
 	return nil
 }
 
+func (x *StatusResponse) GetEvents() []*VertexEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 type Vertex struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
@@ -855,7 +1007,7 @@ type Vertex struct {
 
 func (x *Vertex) Reset() {
 	*x = Vertex{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -867,7 +1019,7 @@ func (x *Vertex) String() string {
 func (*Vertex) ProtoMessage() {}
 
 func (x *Vertex) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -880,7 +1032,7 @@ func (x *Vertex) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vertex.ProtoReflect.Descriptor instead.
 func (*Vertex) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{10}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Vertex) GetDigest() string {
@@ -955,7 +1107,7 @@ type VertexStatus struct {
 
 func (x *VertexStatus) Reset() {
 	*x = VertexStatus{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -967,7 +1119,7 @@ func (x *VertexStatus) String() string {
 func (*VertexStatus) ProtoMessage() {}
 
 func (x *VertexStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -980,7 +1132,7 @@ func (x *VertexStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VertexStatus.ProtoReflect.Descriptor instead.
 func (*VertexStatus) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{11}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *VertexStatus) GetID() string {
@@ -1051,7 +1203,7 @@ type VertexLog struct {
 
 func (x *VertexLog) Reset() {
 	*x = VertexLog{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1063,7 +1215,7 @@ func (x *VertexLog) String() string {
 func (*VertexLog) ProtoMessage() {}
 
 func (x *VertexLog) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1076,7 +1228,7 @@ func (x *VertexLog) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VertexLog.ProtoReflect.Descriptor instead.
 func (*VertexLog) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{12}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *VertexLog) GetVertex() string {
@@ -1122,7 +1274,7 @@ type VertexWarning struct {
 
 func (x *VertexWarning) Reset() {
 	*x = VertexWarning{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1134,7 +1286,7 @@ func (x *VertexWarning) String() string {
 func (*VertexWarning) ProtoMessage() {}
 
 func (x *VertexWarning) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1147,7 +1299,7 @@ func (x *VertexWarning) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VertexWarning.ProtoReflect.Descriptor instead.
 func (*VertexWarning) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{13}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *VertexWarning) GetVertex() string {
@@ -1199,6 +1351,93 @@ func (x *VertexWarning) GetRanges() []*pb.Range {
 	return nil
 }
 
+// VertexEvent reports a change in the execution of a vertex, eg. a retry of a
+// failed process.
+type VertexEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Vertex    string                 `protobuf:"bytes,1,opt,name=vertex,proto3" json:"vertex,omitempty"`
+	Type      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Message   string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Attempt   int64                  `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	Timestamp *timestamp.Timestamp   `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// position of the build in the admission queue for "queued" events
+	Position      int64 `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VertexEvent) Reset() {
+	*x = VertexEvent{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VertexEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VertexEvent) ProtoMessage() {}
+
+func (x *VertexEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VertexEvent.ProtoReflect.Descriptor instead.
+func (*VertexEvent) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VertexEvent) GetVertex() string {
+	if x != nil {
+		return x.Vertex
+	}
+	return ""
+}
+
+func (x *VertexEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VertexEvent) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *VertexEvent) GetAttempt() int64 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *VertexEvent) GetTimestamp() *timestamp.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *VertexEvent) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
 type BytesMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
@@ -1208,7 +1447,7 @@ type BytesMessage struct {
 
 func (x *BytesMessage) Reset() {
 	*x = BytesMessage{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1220,7 +1459,7 @@ func (x *BytesMessage) String() string {
 func (*BytesMessage) ProtoMessage() {}
 
 func (x *BytesMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1233,7 +1472,7 @@ func (x *BytesMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BytesMessage.ProtoReflect.Descriptor instead.
 func (*BytesMessage) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{14}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BytesMessage) GetData() []byte {
@@ -1252,7 +1491,7 @@ type ListWorkersRequest struct {
 
 func (x *ListWorkersRequest) Reset() {
 	*x = ListWorkersRequest{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1264,7 +1503,7 @@ func (x *ListWorkersRequest) String() string {
 func (*ListWorkersRequest) ProtoMessage() {}
 
 func (x *ListWorkersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1277,7 +1516,7 @@ func (x *ListWorkersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersRequest.ProtoReflect.Descriptor instead.
 func (*ListWorkersRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{15}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ListWorkersRequest) GetFilter() []string {
@@ -1296,7 +1535,7 @@ type ListWorkersResponse struct {
 
 func (x *ListWorkersResponse) Reset() {
 	*x = ListWorkersResponse{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1308,7 +1547,7 @@ func (x *ListWorkersResponse) String() string {
 func (*ListWorkersResponse) ProtoMessage() {}
 
 func (x *ListWorkersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1321,7 +1560,7 @@ func (x *ListWorkersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListWorkersResponse.ProtoReflect.Descriptor instead.
 func (*ListWorkersResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{16}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ListWorkersResponse) GetRecord() []*types.WorkerRecord {
@@ -1339,7 +1578,7 @@ type InfoRequest struct {
 
 func (x *InfoRequest) Reset() {
 	*x = InfoRequest{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1351,7 +1590,7 @@ func (x *InfoRequest) String() string {
 func (*InfoRequest) ProtoMessage() {}
 
 func (x *InfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1364,7 +1603,7 @@ func (x *InfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
 func (*InfoRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{17}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{19}
 }
 
 type InfoResponse struct {
@@ -1376,7 +1615,7 @@ type InfoResponse struct {
 
 func (x *InfoResponse) Reset() {
 	*x = InfoResponse{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1388,7 +1627,7 @@ func (x *InfoResponse) String() string {
 func (*InfoResponse) ProtoMessage() {}
 
 func (x *InfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1401,7 +1640,7 @@ func (x *InfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
 func (*InfoResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{18}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *InfoResponse) GetBuildkitVersion() *types.BuildkitVersion {
@@ -1424,7 +1663,7 @@ type BuildHistoryRequest struct {
 
 func (x *BuildHistoryRequest) Reset() {
 	*x = BuildHistoryRequest{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1436,7 +1675,7 @@ func (x *BuildHistoryRequest) String() string {
 func (*BuildHistoryRequest) ProtoMessage() {}
 
 func (x *BuildHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1449,7 +1688,7 @@ func (x *BuildHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildHistoryRequest.ProtoReflect.Descriptor instead.
 func (*BuildHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{19}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *BuildHistoryRequest) GetActiveOnly() bool {
@@ -1497,7 +1736,7 @@ type BuildHistoryEvent struct {
 
 func (x *BuildHistoryEvent) Reset() {
 	*x = BuildHistoryEvent{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1509,7 +1748,7 @@ func (x *BuildHistoryEvent) String() string {
 func (*BuildHistoryEvent) ProtoMessage() {}
 
 func (x *BuildHistoryEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1522,7 +1761,7 @@ func (x *BuildHistoryEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildHistoryEvent.ProtoReflect.Descriptor instead.
 func (*BuildHistoryEvent) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{20}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *BuildHistoryEvent) GetType() BuildHistoryEventType {
@@ -1560,13 +1799,14 @@ type BuildHistoryRecord struct {
 	NumCompletedSteps int32                       `protobuf:"varint,17,opt,name=numCompletedSteps,proto3" json:"numCompletedSteps,omitempty"`
 	ExternalError     *Descriptor                 `protobuf:"bytes,18,opt,name=externalError,proto3" json:"externalError,omitempty"`
 	NumWarnings       int32                       `protobuf:"varint,19,opt,name=numWarnings,proto3" json:"numWarnings,omitempty"`
+	CacheKeys         *Descriptor                 `protobuf:"bytes,20,opt,name=cacheKeys,proto3" json:"cacheKeys,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *BuildHistoryRecord) Reset() {
 	*x = BuildHistoryRecord{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1578,7 +1818,7 @@ func (x *BuildHistoryRecord) String() string {
 func (*BuildHistoryRecord) ProtoMessage() {}
 
 func (x *BuildHistoryRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1591,7 +1831,7 @@ func (x *BuildHistoryRecord) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildHistoryRecord.ProtoReflect.Descriptor instead.
 func (*BuildHistoryRecord) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{21}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *BuildHistoryRecord) GetRef() string {
@@ -1727,6 +1967,13 @@ func (x *BuildHistoryRecord) GetNumWarnings() int32 {
 	return 0
 }
 
+func (x *BuildHistoryRecord) GetCacheKeys() *Descriptor {
+	if x != nil {
+		return x.CacheKeys
+	}
+	return nil
+}
+
 type UpdateBuildHistoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ref           string                 `protobuf:"bytes,1,opt,name=Ref,proto3" json:"Ref,omitempty"`
@@ -1739,7 +1986,7 @@ type UpdateBuildHistoryRequest struct {
 
 func (x *UpdateBuildHistoryRequest) Reset() {
 	*x = UpdateBuildHistoryRequest{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1751,7 +1998,7 @@ func (x *UpdateBuildHistoryRequest) String() string {
 func (*UpdateBuildHistoryRequest) ProtoMessage() {}
 
 func (x *UpdateBuildHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1764,7 +2011,7 @@ func (x *UpdateBuildHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateBuildHistoryRequest.ProtoReflect.Descriptor instead.
 func (*UpdateBuildHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{22}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *UpdateBuildHistoryRequest) GetRef() string {
@@ -1803,7 +2050,7 @@ type UpdateBuildHistoryResponse struct {
 
 func (x *UpdateBuildHistoryResponse) Reset() {
 	*x = UpdateBuildHistoryResponse{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1815,7 +2062,7 @@ func (x *UpdateBuildHistoryResponse) String() string {
 func (*UpdateBuildHistoryResponse) ProtoMessage() {}
 
 func (x *UpdateBuildHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1828,7 +2075,245 @@ func (x *UpdateBuildHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateBuildHistoryResponse.ProtoReflect.Descriptor instead.
 func (*UpdateBuildHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{23}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{25}
+}
+
+type ExplainCacheMissRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ref is the build record that is explained.
+	Ref string `protobuf:"bytes,1,opt,name=Ref,proto3" json:"Ref,omitempty"`
+	// BaseRef is the build record it is compared against.
+	BaseRef       string `protobuf:"bytes,2,opt,name=BaseRef,proto3" json:"BaseRef,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainCacheMissRequest) Reset() {
+	*x = ExplainCacheMissRequest{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainCacheMissRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainCacheMissRequest) ProtoMessage() {}
+
+func (x *ExplainCacheMissRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainCacheMissRequest.ProtoReflect.Descriptor instead.
+func (*ExplainCacheMissRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ExplainCacheMissRequest) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+func (x *ExplainCacheMissRequest) GetBaseRef() string {
+	if x != nil {
+		return x.BaseRef
+	}
+	return ""
+}
+
+type ExplainCacheMissResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vertexes      []*CacheMissVertex     `protobuf:"bytes,1,rep,name=Vertexes,proto3" json:"Vertexes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainCacheMissResponse) Reset() {
+	*x = ExplainCacheMissResponse{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainCacheMissResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainCacheMissResponse) ProtoMessage() {}
+
+func (x *ExplainCacheMissResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainCacheMissResponse.ProtoReflect.Descriptor instead.
+func (*ExplainCacheMissResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExplainCacheMissResponse) GetVertexes() []*CacheMissVertex {
+	if x != nil {
+		return x.Vertexes
+	}
+	return nil
+}
+
+// CacheMissVertex is a vertex that was executed in the explained build.
+type CacheMissVertex struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Digest string                 `protobuf:"bytes,1,opt,name=Digest,proto3" json:"Digest,omitempty"`
+	Name   string                 `protobuf:"bytes,2,opt,name=Name,proto3" json:"Name,omitempty"`
+	// BaseDigest is the digest of the matching vertex in the base build.
+	// Empty if no matching vertex was found.
+	BaseDigest    string             `protobuf:"bytes,3,opt,name=BaseDigest,proto3" json:"BaseDigest,omitempty"`
+	Reasons       []*CacheMissReason `protobuf:"bytes,4,rep,name=Reasons,proto3" json:"Reasons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheMissVertex) Reset() {
+	*x = CacheMissVertex{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheMissVertex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheMissVertex) ProtoMessage() {}
+
+func (x *CacheMissVertex) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheMissVertex.ProtoReflect.Descriptor instead.
+func (*CacheMissVertex) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CacheMissVertex) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *CacheMissVertex) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CacheMissVertex) GetBaseDigest() string {
+	if x != nil {
+		return x.BaseDigest
+	}
+	return ""
+}
+
+func (x *CacheMissVertex) GetReasons() []*CacheMissReason {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+type CacheMissReason struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Type is like "op", "input", "content", "source", "build-arg", "secret"
+	Type          string `protobuf:"bytes,1,opt,name=Type,proto3" json:"Type,omitempty"`
+	Key           string `protobuf:"bytes,2,opt,name=Key,proto3" json:"Key,omitempty"`
+	Old           string `protobuf:"bytes,3,opt,name=Old,proto3" json:"Old,omitempty"`
+	New           string `protobuf:"bytes,4,opt,name=New,proto3" json:"New,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheMissReason) Reset() {
+	*x = CacheMissReason{}
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheMissReason) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheMissReason) ProtoMessage() {}
+
+func (x *CacheMissReason) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheMissReason.ProtoReflect.Descriptor instead.
+func (*CacheMissReason) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CacheMissReason) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CacheMissReason) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CacheMissReason) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *CacheMissReason) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
 }
 
 type Descriptor struct {
@@ -1843,7 +2328,7 @@ type Descriptor struct {
 
 func (x *Descriptor) Reset() {
 	*x = Descriptor{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1855,7 +2340,7 @@ func (x *Descriptor) String() string {
 func (*Descriptor) ProtoMessage() {}
 
 func (x *Descriptor) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1868,7 +2353,7 @@ func (x *Descriptor) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Descriptor.ProtoReflect.Descriptor instead.
 func (*Descriptor) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{24}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *Descriptor) GetMediaType() string {
@@ -1910,7 +2395,7 @@ type BuildResultInfo struct {
 
 func (x *BuildResultInfo) Reset() {
 	*x = BuildResultInfo{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1922,7 +2407,7 @@ func (x *BuildResultInfo) String() string {
 func (*BuildResultInfo) ProtoMessage() {}
 
 func (x *BuildResultInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1935,7 +2420,7 @@ func (x *BuildResultInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildResultInfo.ProtoReflect.Descriptor instead.
 func (*BuildResultInfo) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{25}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *BuildResultInfo) GetResultDeprecated() *Descriptor {
@@ -1972,7 +2457,7 @@ type Exporter struct {
 
 func (x *Exporter) Reset() {
 	*x = Exporter{}
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1984,7 +2469,7 @@ func (x *Exporter) String() string {
 func (*Exporter) ProtoMessage() {}
 
 func (x *Exporter) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1997,7 +2482,7 @@ func (x *Exporter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Exporter.ProtoReflect.Descriptor instead.
 func (*Exporter) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{26}
+	return file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *Exporter) GetType() string {
@@ -2018,14 +2503,18 @@ var File_github_com_moby_buildkit_api_services_control_control_proto protoreflec
 
 const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc = "" +
 	"\n" +
-	";github.com/moby/buildkit/api/services/control/control.proto\x12\x10moby.buildkit.v1\x1a/github.com/moby/buildkit/api/types/worker.proto\x1a,github.com/moby/buildkit/solver/pb/ops.proto\x1a5github.com/moby/buildkit/sourcepolicy/pb/policy.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x17google/rpc/status.proto\"\xca\x01\n" +
+	";github.com/moby/buildkit/api/services/control/control.proto\x12\x10moby.buildkit.v1\x1a/github.com/moby/buildkit/api/types/worker.proto\x1a,github.com/moby/buildkit/solver/pb/ops.proto\x1a5github.com/moby/buildkit/sourcepolicy/pb/policy.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x17google/rpc/status.proto\"\x82\x02\n" +
 	"\fPruneRequest\x12\x16\n" +
 	"\x06filter\x18\x01 \x03(\tR\x06filter\x12\x10\n" +
 	"\x03all\x18\x02 \x01(\bR\x03all\x12\"\n" +
 	"\fkeepDuration\x18\x03 \x01(\x03R\fkeepDuration\x12$\n" +
 	"\rreservedSpace\x18\x04 \x01(\x03R\rreservedSpace\x12\"\n" +
 	"\fmaxUsedSpace\x18\x05 \x01(\x03R\fmaxUsedSpace\x12\"\n" +
-	"\fminFreeSpace\x18\x06 \x01(\x03R\fminFreeSpace\"F\n" +
+	"\fminFreeSpace\x18\x06 \x01(\x03R\fminFreeSpace\x12\x16\n" +
+	"\x06dryRun\x18\a \x01(\bR\x06dryRun\x12\x1e\n" +
+	"\n" +
+	"historyAge\x18\b \x01(\x03R\n" +
+	"historyAge\"F\n" +
 	"\x10DiskUsageRequest\x12\x16\n" +
 	"\x06filter\x18\x01 \x03(\tR\x06filter\x12\x1a\n" +
 	"\bageLimit\x18\x02 \x01(\x03R\bageLimit\"J\n" +
@@ -2050,7 +2539,7 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	" \x01(\tR\n" +
 	"RecordType\x12\x16\n" +
 	"\x06Shared\x18\v \x01(\bR\x06Shared\x12\x18\n" +
-	"\aParents\x18\f \x03(\tR\aParents\"\xf4\a\n" +
+	"\aParents\x18\f \x03(\tR\aParents\"\xff\t\n" +
 	"\fSolveRequest\x12\x10\n" +
 	"\x03Ref\x18\x01 \x01(\tR\x03Ref\x12.\n" +
 	"\n" +
@@ -2068,7 +2557,12 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\bInternal\x18\v \x01(\bR\bInternal\x12I\n" +
 	"\fSourcePolicy\x18\f \x01(\v2%.moby.buildkit.v1.sourcepolicy.PolicyR\fSourcePolicy\x128\n" +
 	"\tExporters\x18\r \x03(\v2\x1a.moby.buildkit.v1.ExporterR\tExporters\x124\n" +
-	"\x15EnableSessionExporter\x18\x0e \x01(\bR\x15EnableSessionExporter\x1aJ\n" +
+	"\x15EnableSessionExporter\x18\x0e \x01(\bR\x15EnableSessionExporter\x12\x12\n" +
+	"\x04Plan\x18\x0f \x01(\bR\x04Plan\x12\x1c\n" +
+	"\tCacheOnly\x18\x10 \x01(\bR\tCacheOnly\x12\x1c\n" +
+	"\tKeepGoing\x18\x11 \x01(\bR\tKeepGoing\x12\x16\n" +
+	"\x06Weight\x18\x12 \x01(\x03R\x06Weight\x12]\n" +
+	"\x0fSchedulerLabels\x18\x13 \x03(\v23.moby.buildkit.v1.SolveRequest.SchedulerLabelsEntryR\x0fSchedulerLabels\x1aJ\n" +
 	"\x1cExporterAttrsDeprecatedEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a@\n" +
@@ -2077,7 +2571,10 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aQ\n" +
 	"\x13FrontendInputsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12$\n" +
-	"\x05value\x18\x02 \x01(\v2\x0e.pb.DefinitionR\x05value:\x028\x01\"\xad\x03\n" +
+	"\x05value\x18\x02 \x01(\v2\x0e.pb.DefinitionR\x05value:\x028\x01\x1aB\n" +
+	"\x14SchedulerLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xad\x03\n" +
 	"\fCacheOptions\x120\n" +
 	"\x13ExportRefDeprecated\x18\x01 \x01(\tR\x13ExportRefDeprecated\x122\n" +
 	"\x14ImportRefsDeprecated\x18\x02 \x03(\tR\x14ImportRefsDeprecated\x12o\n" +
@@ -2093,19 +2590,26 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\n" +
 	"AttrsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe9\x01\n" +
 	"\rSolveResponse\x12a\n" +
-	"\x10ExporterResponse\x18\x01 \x03(\v25.moby.buildkit.v1.SolveResponse.ExporterResponseEntryR\x10ExporterResponse\x1aC\n" +
+	"\x10ExporterResponse\x18\x01 \x03(\v25.moby.buildkit.v1.SolveResponse.ExporterResponseEntryR\x10ExporterResponse\x120\n" +
+	"\x04Plan\x18\x02 \x03(\v2\x1c.moby.buildkit.v1.VertexPlanR\x04Plan\x1aC\n" +
 	"\x15ExporterResponseEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"!\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"P\n" +
+	"\n" +
+	"VertexPlan\x12\x16\n" +
+	"\x06Digest\x18\x01 \x01(\tR\x06Digest\x12\x12\n" +
+	"\x04Name\x18\x02 \x01(\tR\x04Name\x12\x16\n" +
+	"\x06Status\x18\x03 \x01(\tR\x06Status\"!\n" +
 	"\rStatusRequest\x12\x10\n" +
-	"\x03Ref\x18\x01 \x01(\tR\x03Ref\"\xf0\x01\n" +
+	"\x03Ref\x18\x01 \x01(\tR\x03Ref\"\xa7\x02\n" +
 	"\x0eStatusResponse\x124\n" +
 	"\bvertexes\x18\x01 \x03(\v2\x18.moby.buildkit.v1.VertexR\bvertexes\x12:\n" +
 	"\bstatuses\x18\x02 \x03(\v2\x1e.moby.buildkit.v1.VertexStatusR\bstatuses\x12/\n" +
 	"\x04logs\x18\x03 \x03(\v2\x1b.moby.buildkit.v1.VertexLogR\x04logs\x12;\n" +
-	"\bwarnings\x18\x04 \x03(\v2\x1f.moby.buildkit.v1.VertexWarningR\bwarnings\"\xa3\x02\n" +
+	"\bwarnings\x18\x04 \x03(\v2\x1f.moby.buildkit.v1.VertexWarningR\bwarnings\x125\n" +
+	"\x06events\x18\x05 \x03(\v2\x1d.moby.buildkit.v1.VertexEventR\x06events\"\xa3\x02\n" +
 	"\x06Vertex\x12\x16\n" +
 	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x16\n" +
 	"\x06inputs\x18\x02 \x03(\tR\x06inputs\x12\x12\n" +
@@ -2136,7 +2640,14 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\x06detail\x18\x04 \x03(\fR\x06detail\x12\x10\n" +
 	"\x03url\x18\x05 \x01(\tR\x03url\x12\"\n" +
 	"\x04info\x18\x06 \x01(\v2\x0e.pb.SourceInfoR\x04info\x12!\n" +
-	"\x06ranges\x18\a \x03(\v2\t.pb.RangeR\x06ranges\"\"\n" +
+	"\x06ranges\x18\a \x03(\v2\t.pb.RangeR\x06ranges\"\xc3\x01\n" +
+	"\vVertexEvent\x12\x16\n" +
+	"\x06vertex\x18\x01 \x01(\tR\x06vertex\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\x03R\aattempt\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x03R\bposition\"\"\n" +
 	"\fBytesMessage\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\",\n" +
 	"\x12ListWorkersRequest\x12\x16\n" +
@@ -2156,7 +2667,8 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\x05Limit\x18\x05 \x01(\x05R\x05Limit\"\x8e\x01\n" +
 	"\x11BuildHistoryEvent\x12;\n" +
 	"\x04type\x18\x01 \x01(\x0e2'.moby.buildkit.v1.BuildHistoryEventTypeR\x04type\x12<\n" +
-	"\x06record\x18\x02 \x01(\v2$.moby.buildkit.v1.BuildHistoryRecordR\x06record\"\xd3\t\n" +
+	"\x06record\x18\x02 \x01(\v2$.moby.buildkit.v1.BuildHistoryRecordR\x06record\"\x8f\n" +
+	"\n" +
 	"\x12BuildHistoryRecord\x12\x10\n" +
 	"\x03Ref\x18\x01 \x01(\tR\x03Ref\x12\x1a\n" +
 	"\bFrontend\x18\x02 \x01(\tR\bFrontend\x12]\n" +
@@ -2179,7 +2691,8 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\rnumTotalSteps\x18\x10 \x01(\x05R\rnumTotalSteps\x12,\n" +
 	"\x11numCompletedSteps\x18\x11 \x01(\x05R\x11numCompletedSteps\x12B\n" +
 	"\rexternalError\x18\x12 \x01(\v2\x1c.moby.buildkit.v1.DescriptorR\rexternalError\x12 \n" +
-	"\vnumWarnings\x18\x13 \x01(\x05R\vnumWarnings\x1a@\n" +
+	"\vnumWarnings\x18\x13 \x01(\x05R\vnumWarnings\x12:\n" +
+	"\tcacheKeys\x18\x14 \x01(\v2\x1c.moby.buildkit.v1.DescriptorR\tcacheKeys\x1a@\n" +
 	"\x12FrontendAttrsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aC\n" +
@@ -2194,7 +2707,24 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\x06Pinned\x18\x02 \x01(\bR\x06Pinned\x12\x16\n" +
 	"\x06Delete\x18\x03 \x01(\bR\x06Delete\x12\x1a\n" +
 	"\bFinalize\x18\x04 \x01(\bR\bFinalize\"\x1c\n" +
-	"\x1aUpdateBuildHistoryResponse\"\xe8\x01\n" +
+	"\x1aUpdateBuildHistoryResponse\"E\n" +
+	"\x17ExplainCacheMissRequest\x12\x10\n" +
+	"\x03Ref\x18\x01 \x01(\tR\x03Ref\x12\x18\n" +
+	"\aBaseRef\x18\x02 \x01(\tR\aBaseRef\"Y\n" +
+	"\x18ExplainCacheMissResponse\x12=\n" +
+	"\bVertexes\x18\x01 \x03(\v2!.moby.buildkit.v1.CacheMissVertexR\bVertexes\"\x9a\x01\n" +
+	"\x0fCacheMissVertex\x12\x16\n" +
+	"\x06Digest\x18\x01 \x01(\tR\x06Digest\x12\x12\n" +
+	"\x04Name\x18\x02 \x01(\tR\x04Name\x12\x1e\n" +
+	"\n" +
+	"BaseDigest\x18\x03 \x01(\tR\n" +
+	"BaseDigest\x12;\n" +
+	"\aReasons\x18\x04 \x03(\v2!.moby.buildkit.v1.CacheMissReasonR\aReasons\"[\n" +
+	"\x0fCacheMissReason\x12\x12\n" +
+	"\x04Type\x18\x01 \x01(\tR\x04Type\x12\x10\n" +
+	"\x03Key\x18\x02 \x01(\tR\x03Key\x12\x10\n" +
+	"\x03Old\x18\x03 \x01(\tR\x03Old\x12\x10\n" +
+	"\x03New\x18\x04 \x01(\tR\x03New\"\xe8\x01\n" +
 	"\n" +
 	"Descriptor\x12\x1d\n" +
 	"\n" +
@@ -2222,7 +2752,7 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\x15BuildHistoryEventType\x12\v\n" +
 	"\aSTARTED\x10\x00\x12\f\n" +
 	"\bCOMPLETE\x10\x01\x12\v\n" +
-	"\aDELETED\x10\x022\x89\x06\n" +
+	"\aDELETED\x10\x022\xf4\x06\n" +
 	"\aControl\x12T\n" +
 	"\tDiskUsage\x12\".moby.buildkit.v1.DiskUsageRequest\x1a#.moby.buildkit.v1.DiskUsageResponse\x12H\n" +
 	"\x05Prune\x12\x1e.moby.buildkit.v1.PruneRequest\x1a\x1d.moby.buildkit.v1.UsageRecord0\x01\x12H\n" +
@@ -2232,7 +2762,8 @@ const file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc =
 	"\vListWorkers\x12$.moby.buildkit.v1.ListWorkersRequest\x1a%.moby.buildkit.v1.ListWorkersResponse\x12E\n" +
 	"\x04Info\x12\x1d.moby.buildkit.v1.InfoRequest\x1a\x1e.moby.buildkit.v1.InfoResponse\x12b\n" +
 	"\x12ListenBuildHistory\x12%.moby.buildkit.v1.BuildHistoryRequest\x1a#.moby.buildkit.v1.BuildHistoryEvent0\x01\x12o\n" +
-	"\x12UpdateBuildHistory\x12+.moby.buildkit.v1.UpdateBuildHistoryRequest\x1a,.moby.buildkit.v1.UpdateBuildHistoryResponseB@Z>github.com/moby/buildkit/api/services/control;moby_buildkit_v1b\x06proto3"
+	"\x12UpdateBuildHistory\x12+.moby.buildkit.v1.UpdateBuildHistoryRequest\x1a,.moby.buildkit.v1.UpdateBuildHistoryResponse\x12i\n" +
+	"\x10ExplainCacheMiss\x12).moby.buildkit.v1.ExplainCacheMissRequest\x1a*.moby.buildkit.v1.ExplainCacheMissResponseB@Z>github.com/moby/buildkit/api/services/control;moby_buildkit_v1b\x06proto3"
 
 var (
 	file_github_com_moby_buildkit_api_services_control_control_proto_rawDescOnce sync.Once
@@ -2247,7 +2778,7 @@ func file_github_com_moby_buildkit_api_services_control_control_proto_rawDescGZI
 }
 
 var file_github_com_moby_buildkit_api_services_control_control_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes = make([]protoimpl.MessageInfo, 39)
+var file_github_com_moby_buildkit_api_services_control_control_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
 var file_github_com_moby_buildkit_api_services_control_control_proto_goTypes = []any{
 	(BuildHistoryEventType)(0),         // 0: moby.buildkit.v1.BuildHistoryEventType
 	(*PruneRequest)(nil),               // 1: moby.buildkit.v1.PruneRequest
@@ -2258,122 +2789,138 @@ var file_github_com_moby_buildkit_api_services_control_control_proto_goTypes = [
 	(*CacheOptions)(nil),               // 6: moby.buildkit.v1.CacheOptions
 	(*CacheOptionsEntry)(nil),          // 7: moby.buildkit.v1.CacheOptionsEntry
 	(*SolveResponse)(nil),              // 8: moby.buildkit.v1.SolveResponse
-	(*StatusRequest)(nil),              // 9: moby.buildkit.v1.StatusRequest
-	(*StatusResponse)(nil),             // 10: moby.buildkit.v1.StatusResponse
-	(*Vertex)(nil),                     // 11: moby.buildkit.v1.Vertex
-	(*VertexStatus)(nil),               // 12: moby.buildkit.v1.VertexStatus
-	(*VertexLog)(nil),                  // 13: moby.buildkit.v1.VertexLog
-	(*VertexWarning)(nil),              // 14: moby.buildkit.v1.VertexWarning
-	(*BytesMessage)(nil),               // 15: moby.buildkit.v1.BytesMessage
-	(*ListWorkersRequest)(nil),         // 16: moby.buildkit.v1.ListWorkersRequest
-	(*ListWorkersResponse)(nil),        // 17: moby.buildkit.v1.ListWorkersResponse
-	(*InfoRequest)(nil),                // 18: moby.buildkit.v1.InfoRequest
-	(*InfoResponse)(nil),               // 19: moby.buildkit.v1.InfoResponse
-	(*BuildHistoryRequest)(nil),        // 20: moby.buildkit.v1.BuildHistoryRequest
-	(*BuildHistoryEvent)(nil),          // 21: moby.buildkit.v1.BuildHistoryEvent
-	(*BuildHistoryRecord)(nil),         // 22: moby.buildkit.v1.BuildHistoryRecord
-	(*UpdateBuildHistoryRequest)(nil),  // 23: moby.buildkit.v1.UpdateBuildHistoryRequest
-	(*UpdateBuildHistoryResponse)(nil), // 24: moby.buildkit.v1.UpdateBuildHistoryResponse
-	(*Descriptor)(nil),                 // 25: moby.buildkit.v1.Descriptor
-	(*BuildResultInfo)(nil),            // 26: moby.buildkit.v1.BuildResultInfo
-	(*Exporter)(nil),                   // 27: moby.buildkit.v1.Exporter
-	nil,                                // 28: moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecatedEntry
-	nil,                                // 29: moby.buildkit.v1.SolveRequest.FrontendAttrsEntry
-	nil,                                // 30: moby.buildkit.v1.SolveRequest.FrontendInputsEntry
-	nil,                                // 31: moby.buildkit.v1.CacheOptions.ExportAttrsDeprecatedEntry
-	nil,                                // 32: moby.buildkit.v1.CacheOptionsEntry.AttrsEntry
-	nil,                                // 33: moby.buildkit.v1.SolveResponse.ExporterResponseEntry
-	nil,                                // 34: moby.buildkit.v1.BuildHistoryRecord.FrontendAttrsEntry
-	nil,                                // 35: moby.buildkit.v1.BuildHistoryRecord.ExporterResponseEntry
-	nil,                                // 36: moby.buildkit.v1.BuildHistoryRecord.ResultsEntry
-	nil,                                // 37: moby.buildkit.v1.Descriptor.AnnotationsEntry
-	nil,                                // 38: moby.buildkit.v1.BuildResultInfo.ResultsEntry
-	nil,                                // 39: moby.buildkit.v1.Exporter.AttrsEntry
-	(*timestamp.Timestamp)(nil),        // 40: google.protobuf.Timestamp
-	(*pb.Definition)(nil),              // 41: pb.Definition
-	(*pb1.Policy)(nil),                 // 42: moby.buildkit.v1.sourcepolicy.Policy
-	(*pb.ProgressGroup)(nil),           // 43: pb.ProgressGroup
-	(*pb.SourceInfo)(nil),              // 44: pb.SourceInfo
-	(*pb.Range)(nil),                   // 45: pb.Range
-	(*types.WorkerRecord)(nil),         // 46: moby.buildkit.v1.types.WorkerRecord
-	(*types.BuildkitVersion)(nil),      // 47: moby.buildkit.v1.types.BuildkitVersion
-	(*status.Status)(nil),              // 48: google.rpc.Status
+	(*VertexPlan)(nil),                 // 9: moby.buildkit.v1.VertexPlan
+	(*StatusRequest)(nil),              // 10: moby.buildkit.v1.StatusRequest
+	(*StatusResponse)(nil),             // 11: moby.buildkit.v1.StatusResponse
+	(*Vertex)(nil),                     // 12: moby.buildkit.v1.Vertex
+	(*VertexStatus)(nil),               // 13: moby.buildkit.v1.VertexStatus
+	(*VertexLog)(nil),                  // 14: moby.buildkit.v1.VertexLog
+	(*VertexWarning)(nil),              // 15: moby.buildkit.v1.VertexWarning
+	(*VertexEvent)(nil),                // 16: moby.buildkit.v1.VertexEvent
+	(*BytesMessage)(nil),               // 17: moby.buildkit.v1.BytesMessage
+	(*ListWorkersRequest)(nil),         // 18: moby.buildkit.v1.ListWorkersRequest
+	(*ListWorkersResponse)(nil),        // 19: moby.buildkit.v1.ListWorkersResponse
+	(*InfoRequest)(nil),                // 20: moby.buildkit.v1.InfoRequest
+	(*InfoResponse)(nil),               // 21: moby.buildkit.v1.InfoResponse
+	(*BuildHistoryRequest)(nil),        // 22: moby.buildkit.v1.BuildHistoryRequest
+	(*BuildHistoryEvent)(nil),          // 23: moby.buildkit.v1.BuildHistoryEvent
+	(*BuildHistoryRecord)(nil),         // 24: moby.buildkit.v1.BuildHistoryRecord
+	(*UpdateBuildHistoryRequest)(nil),  // 25: moby.buildkit.v1.UpdateBuildHistoryRequest
+	(*UpdateBuildHistoryResponse)(nil), // 26: moby.buildkit.v1.UpdateBuildHistoryResponse
+	(*ExplainCacheMissRequest)(nil),    // 27: moby.buildkit.v1.ExplainCacheMissRequest
+	(*ExplainCacheMissResponse)(nil),   // 28: moby.buildkit.v1.ExplainCacheMissResponse
+	(*CacheMissVertex)(nil),            // 29: moby.buildkit.v1.CacheMissVertex
+	(*CacheMissReason)(nil),            // 30: moby.buildkit.v1.CacheMissReason
+	(*Descriptor)(nil),                 // 31: moby.buildkit.v1.Descriptor
+	(*BuildResultInfo)(nil),            // 32: moby.buildkit.v1.BuildResultInfo
+	(*Exporter)(nil),                   // 33: moby.buildkit.v1.Exporter
+	nil,                                // 34: moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecatedEntry
+	nil,                                // 35: moby.buildkit.v1.SolveRequest.FrontendAttrsEntry
+	nil,                                // 36: moby.buildkit.v1.SolveRequest.FrontendInputsEntry
+	nil,                                // 37: moby.buildkit.v1.SolveRequest.SchedulerLabelsEntry
+	nil,                                // 38: moby.buildkit.v1.CacheOptions.ExportAttrsDeprecatedEntry
+	nil,                                // 39: moby.buildkit.v1.CacheOptionsEntry.AttrsEntry
+	nil,                                // 40: moby.buildkit.v1.SolveResponse.ExporterResponseEntry
+	nil,                                // 41: moby.buildkit.v1.BuildHistoryRecord.FrontendAttrsEntry
+	nil,                                // 42: moby.buildkit.v1.BuildHistoryRecord.ExporterResponseEntry
+	nil,                                // 43: moby.buildkit.v1.BuildHistoryRecord.ResultsEntry
+	nil,                                // 44: moby.buildkit.v1.Descriptor.AnnotationsEntry
+	nil,                                // 45: moby.buildkit.v1.BuildResultInfo.ResultsEntry
+	nil,                                // 46: moby.buildkit.v1.Exporter.AttrsEntry
+	(*timestamp.Timestamp)(nil),        // 47: google.protobuf.Timestamp
+	(*pb.Definition)(nil),              // 48: pb.Definition
+	(*pb1.Policy)(nil),                 // 49: moby.buildkit.v1.sourcepolicy.Policy
+	(*pb.ProgressGroup)(nil),           // 50: pb.ProgressGroup
+	(*pb.SourceInfo)(nil),              // 51: pb.SourceInfo
+	(*pb.Range)(nil),                   // 52: pb.Range
+	(*types.WorkerRecord)(nil),         // 53: moby.buildkit.v1.types.WorkerRecord
+	(*types.BuildkitVersion)(nil),      // 54: moby.buildkit.v1.types.BuildkitVersion
+	(*status.Status)(nil),              // 55: google.rpc.Status
 }
 var file_github_com_moby_buildkit_api_services_control_control_proto_depIdxs = []int32{
 	4,  // 0: moby.buildkit.v1.DiskUsageResponse.record:type_name -> moby.buildkit.v1.UsageRecord
-	40, // 1: moby.buildkit.v1.UsageRecord.CreatedAt:type_name -> google.protobuf.Timestamp
-	40, // 2: moby.buildkit.v1.UsageRecord.LastUsedAt:type_name -> google.protobuf.Timestamp
-	41, // 3: moby.buildkit.v1.SolveRequest.Definition:type_name -> pb.Definition
-	28, // 4: moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecated:type_name -> moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecatedEntry
-	29, // 5: moby.buildkit.v1.SolveRequest.FrontendAttrs:type_name -> moby.buildkit.v1.SolveRequest.FrontendAttrsEntry
+	47, // 1: moby.buildkit.v1.UsageRecord.CreatedAt:type_name -> google.protobuf.Timestamp
+	47, // 2: moby.buildkit.v1.UsageRecord.LastUsedAt:type_name -> google.protobuf.Timestamp
+	48, // 3: moby.buildkit.v1.SolveRequest.Definition:type_name -> pb.Definition
+	34, // 4: moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecated:type_name -> moby.buildkit.v1.SolveRequest.ExporterAttrsDeprecatedEntry
+	35, // 5: moby.buildkit.v1.SolveRequest.FrontendAttrs:type_name -> moby.buildkit.v1.SolveRequest.FrontendAttrsEntry
 	6,  // 6: moby.buildkit.v1.SolveRequest.Cache:type_name -> moby.buildkit.v1.CacheOptions
-	30, // 7: moby.buildkit.v1.SolveRequest.FrontendInputs:type_name -> moby.buildkit.v1.SolveRequest.FrontendInputsEntry
-	42, // 8: moby.buildkit.v1.SolveRequest.SourcePolicy:type_name -> moby.buildkit.v1.sourcepolicy.Policy
-	27, // 9: moby.buildkit.v1.SolveRequest.Exporters:type_name -> moby.buildkit.v1.Exporter
-	31, // 10: moby.buildkit.v1.CacheOptions.ExportAttrsDeprecated:type_name -> moby.buildkit.v1.CacheOptions.ExportAttrsDeprecatedEntry
-	7,  // 11: moby.buildkit.v1.CacheOptions.Exports:type_name -> moby.buildkit.v1.CacheOptionsEntry
-	7,  // 12: moby.buildkit.v1.CacheOptions.Imports:type_name -> moby.buildkit.v1.CacheOptionsEntry
-	32, // 13: moby.buildkit.v1.CacheOptionsEntry.Attrs:type_name -> moby.buildkit.v1.CacheOptionsEntry.AttrsEntry
-	33, // 14: moby.buildkit.v1.SolveResponse.ExporterResponse:type_name -> moby.buildkit.v1.SolveResponse.ExporterResponseEntry
-	11, // 15: moby.buildkit.v1.StatusResponse.vertexes:type_name -> moby.buildkit.v1.Vertex
-	12, // 16: moby.buildkit.v1.StatusResponse.statuses:type_name -> moby.buildkit.v1.VertexStatus
-	13, // 17: moby.buildkit.v1.StatusResponse.logs:type_name -> moby.buildkit.v1.VertexLog
-	14, // 18: moby.buildkit.v1.StatusResponse.warnings:type_name -> moby.buildkit.v1.VertexWarning
-	40, // 19: moby.buildkit.v1.Vertex.started:type_name -> google.protobuf.Timestamp
-	40, // 20: moby.buildkit.v1.Vertex.completed:type_name -> google.protobuf.Timestamp
-	43, // 21: moby.buildkit.v1.Vertex.progressGroup:type_name -> pb.ProgressGroup
-	40, // 22: moby.buildkit.v1.VertexStatus.timestamp:type_name -> google.protobuf.Timestamp
-	40, // 23: moby.buildkit.v1.VertexStatus.started:type_name -> google.protobuf.Timestamp
-	40, // 24: moby.buildkit.v1.VertexStatus.completed:type_name -> google.protobuf.Timestamp
-	40, // 25: moby.buildkit.v1.VertexLog.timestamp:type_name -> google.protobuf.Timestamp
-	44, // 26: moby.buildkit.v1.VertexWarning.info:type_name -> pb.SourceInfo
-	45, // 27: moby.buildkit.v1.VertexWarning.ranges:type_name -> pb.Range
-	46, // 28: moby.buildkit.v1.ListWorkersResponse.record:type_name -> moby.buildkit.v1.types.WorkerRecord
-	47, // 29: moby.buildkit.v1.InfoResponse.buildkitVersion:type_name -> moby.buildkit.v1.types.BuildkitVersion
-	0,  // 30: moby.buildkit.v1.BuildHistoryEvent.type:type_name -> moby.buildkit.v1.BuildHistoryEventType
-	22, // 31: moby.buildkit.v1.BuildHistoryEvent.record:type_name -> moby.buildkit.v1.BuildHistoryRecord
-	34, // 32: moby.buildkit.v1.BuildHistoryRecord.FrontendAttrs:type_name -> moby.buildkit.v1.BuildHistoryRecord.FrontendAttrsEntry
-	27, // 33: moby.buildkit.v1.BuildHistoryRecord.Exporters:type_name -> moby.buildkit.v1.Exporter
-	48, // 34: moby.buildkit.v1.BuildHistoryRecord.error:type_name -> google.rpc.Status
-	40, // 35: moby.buildkit.v1.BuildHistoryRecord.CreatedAt:type_name -> google.protobuf.Timestamp
-	40, // 36: moby.buildkit.v1.BuildHistoryRecord.CompletedAt:type_name -> google.protobuf.Timestamp
-	25, // 37: moby.buildkit.v1.BuildHistoryRecord.logs:type_name -> moby.buildkit.v1.Descriptor
-	35, // 38: moby.buildkit.v1.BuildHistoryRecord.ExporterResponse:type_name -> moby.buildkit.v1.BuildHistoryRecord.ExporterResponseEntry
-	26, // 39: moby.buildkit.v1.BuildHistoryRecord.Result:type_name -> moby.buildkit.v1.BuildResultInfo
-	36, // 40: moby.buildkit.v1.BuildHistoryRecord.Results:type_name -> moby.buildkit.v1.BuildHistoryRecord.ResultsEntry
-	25, // 41: moby.buildkit.v1.BuildHistoryRecord.trace:type_name -> moby.buildkit.v1.Descriptor
-	25, // 42: moby.buildkit.v1.BuildHistoryRecord.externalError:type_name -> moby.buildkit.v1.Descriptor
-	37, // 43: moby.buildkit.v1.Descriptor.annotations:type_name -> moby.buildkit.v1.Descriptor.AnnotationsEntry
-	25, // 44: moby.buildkit.v1.BuildResultInfo.ResultDeprecated:type_name -> moby.buildkit.v1.Descriptor
-	25, // 45: moby.buildkit.v1.BuildResultInfo.Attestations:type_name -> moby.buildkit.v1.Descriptor
-	38, // 46: moby.buildkit.v1.BuildResultInfo.Results:type_name -> moby.buildkit.v1.BuildResultInfo.ResultsEntry
-	39, // 47: moby.buildkit.v1.Exporter.Attrs:type_name -> moby.buildkit.v1.Exporter.AttrsEntry
-	41, // 48: moby.buildkit.v1.SolveRequest.FrontendInputsEntry.value:type_name -> pb.Definition
-	26, // 49: moby.buildkit.v1.BuildHistoryRecord.ResultsEntry.value:type_name -> moby.buildkit.v1.BuildResultInfo
-	25, // 50: moby.buildkit.v1.BuildResultInfo.ResultsEntry.value:type_name -> moby.buildkit.v1.Descriptor
-	2,  // 51: moby.buildkit.v1.Control.DiskUsage:input_type -> moby.buildkit.v1.DiskUsageRequest
-	1,  // 52: moby.buildkit.v1.Control.Prune:input_type -> moby.buildkit.v1.PruneRequest
-	5,  // 53: moby.buildkit.v1.Control.Solve:input_type -> moby.buildkit.v1.SolveRequest
-	9,  // 54: moby.buildkit.v1.Control.Status:input_type -> moby.buildkit.v1.StatusRequest
-	15, // 55: moby.buildkit.v1.Control.Session:input_type -> moby.buildkit.v1.BytesMessage
-	16, // 56: moby.buildkit.v1.Control.ListWorkers:input_type -> moby.buildkit.v1.ListWorkersRequest
-	18, // 57: moby.buildkit.v1.Control.Info:input_type -> moby.buildkit.v1.InfoRequest
-	20, // 58: moby.buildkit.v1.Control.ListenBuildHistory:input_type -> moby.buildkit.v1.BuildHistoryRequest
-	23, // 59: moby.buildkit.v1.Control.UpdateBuildHistory:input_type -> moby.buildkit.v1.UpdateBuildHistoryRequest
-	3,  // 60: moby.buildkit.v1.Control.DiskUsage:output_type -> moby.buildkit.v1.DiskUsageResponse
-	4,  // 61: moby.buildkit.v1.Control.Prune:output_type -> moby.buildkit.v1.UsageRecord
-	8,  // 62: moby.buildkit.v1.Control.Solve:output_type -> moby.buildkit.v1.SolveResponse
-	10, // 63: moby.buildkit.v1.Control.Status:output_type -> moby.buildkit.v1.StatusResponse
-	15, // 64: moby.buildkit.v1.Control.Session:output_type -> moby.buildkit.v1.BytesMessage
-	17, // 65: moby.buildkit.v1.Control.ListWorkers:output_type -> moby.buildkit.v1.ListWorkersResponse
-	19, // 66: moby.buildkit.v1.Control.Info:output_type -> moby.buildkit.v1.InfoResponse
-	21, // 67: moby.buildkit.v1.Control.ListenBuildHistory:output_type -> moby.buildkit.v1.BuildHistoryEvent
-	24, // 68: moby.buildkit.v1.Control.UpdateBuildHistory:output_type -> moby.buildkit.v1.UpdateBuildHistoryResponse
-	60, // [60:69] is the sub-list for method output_type
-	51, // [51:60] is the sub-list for method input_type
-	51, // [51:51] is the sub-list for extension type_name
-	51, // [51:51] is the sub-list for extension extendee
-	0,  // [0:51] is the sub-list for field type_name
+	36, // 7: moby.buildkit.v1.SolveRequest.FrontendInputs:type_name -> moby.buildkit.v1.SolveRequest.FrontendInputsEntry
+	49, // 8: moby.buildkit.v1.SolveRequest.SourcePolicy:type_name -> moby.buildkit.v1.sourcepolicy.Policy
+	33, // 9: moby.buildkit.v1.SolveRequest.Exporters:type_name -> moby.buildkit.v1.Exporter
+	37, // 10: moby.buildkit.v1.SolveRequest.SchedulerLabels:type_name -> moby.buildkit.v1.SolveRequest.SchedulerLabelsEntry
+	38, // 11: moby.buildkit.v1.CacheOptions.ExportAttrsDeprecated:type_name -> moby.buildkit.v1.CacheOptions.ExportAttrsDeprecatedEntry
+	7,  // 12: moby.buildkit.v1.CacheOptions.Exports:type_name -> moby.buildkit.v1.CacheOptionsEntry
+	7,  // 13: moby.buildkit.v1.CacheOptions.Imports:type_name -> moby.buildkit.v1.CacheOptionsEntry
+	39, // 14: moby.buildkit.v1.CacheOptionsEntry.Attrs:type_name -> moby.buildkit.v1.CacheOptionsEntry.AttrsEntry
+	40, // 15: moby.buildkit.v1.SolveResponse.ExporterResponse:type_name -> moby.buildkit.v1.SolveResponse.ExporterResponseEntry
+	9,  // 16: moby.buildkit.v1.SolveResponse.Plan:type_name -> moby.buildkit.v1.VertexPlan
+	12, // 17: moby.buildkit.v1.StatusResponse.vertexes:type_name -> moby.buildkit.v1.Vertex
+	13, // 18: moby.buildkit.v1.StatusResponse.statuses:type_name -> moby.buildkit.v1.VertexStatus
+	14, // 19: moby.buildkit.v1.StatusResponse.logs:type_name -> moby.buildkit.v1.VertexLog
+	15, // 20: moby.buildkit.v1.StatusResponse.warnings:type_name -> moby.buildkit.v1.VertexWarning
+	16, // 21: moby.buildkit.v1.StatusResponse.events:type_name -> moby.buildkit.v1.VertexEvent
+	47, // 22: moby.buildkit.v1.Vertex.started:type_name -> google.protobuf.Timestamp
+	47, // 23: moby.buildkit.v1.Vertex.completed:type_name -> google.protobuf.Timestamp
+	50, // 24: moby.buildkit.v1.Vertex.progressGroup:type_name -> pb.ProgressGroup
+	47, // 25: moby.buildkit.v1.VertexStatus.timestamp:type_name -> google.protobuf.Timestamp
+	47, // 26: moby.buildkit.v1.VertexStatus.started:type_name -> google.protobuf.Timestamp
+	47, // 27: moby.buildkit.v1.VertexStatus.completed:type_name -> google.protobuf.Timestamp
+	47, // 28: moby.buildkit.v1.VertexLog.timestamp:type_name -> google.protobuf.Timestamp
+	51, // 29: moby.buildkit.v1.VertexWarning.info:type_name -> pb.SourceInfo
+	52, // 30: moby.buildkit.v1.VertexWarning.ranges:type_name -> pb.Range
+	47, // 31: moby.buildkit.v1.VertexEvent.timestamp:type_name -> google.protobuf.Timestamp
+	53, // 32: moby.buildkit.v1.ListWorkersResponse.record:type_name -> moby.buildkit.v1.types.WorkerRecord
+	54, // 33: moby.buildkit.v1.InfoResponse.buildkitVersion:type_name -> moby.buildkit.v1.types.BuildkitVersion
+	0,  // 34: moby.buildkit.v1.BuildHistoryEvent.type:type_name -> moby.buildkit.v1.BuildHistoryEventType
+	24, // 35: moby.buildkit.v1.BuildHistoryEvent.record:type_name -> moby.buildkit.v1.BuildHistoryRecord
+	41, // 36: moby.buildkit.v1.BuildHistoryRecord.FrontendAttrs:type_name -> moby.buildkit.v1.BuildHistoryRecord.FrontendAttrsEntry
+	33, // 37: moby.buildkit.v1.BuildHistoryRecord.Exporters:type_name -> moby.buildkit.v1.Exporter
+	55, // 38: moby.buildkit.v1.BuildHistoryRecord.error:type_name -> google.rpc.Status
+	47, // 39: moby.buildkit.v1.BuildHistoryRecord.CreatedAt:type_name -> google.protobuf.Timestamp
+	47, // 40: moby.buildkit.v1.BuildHistoryRecord.CompletedAt:type_name -> google.protobuf.Timestamp
+	31, // 41: moby.buildkit.v1.BuildHistoryRecord.logs:type_name -> moby.buildkit.v1.Descriptor
+	42, // 42: moby.buildkit.v1.BuildHistoryRecord.ExporterResponse:type_name -> moby.buildkit.v1.BuildHistoryRecord.ExporterResponseEntry
+	32, // 43: moby.buildkit.v1.BuildHistoryRecord.Result:type_name -> moby.buildkit.v1.BuildResultInfo
+	43, // 44: moby.buildkit.v1.BuildHistoryRecord.Results:type_name -> moby.buildkit.v1.BuildHistoryRecord.ResultsEntry
+	31, // 45: moby.buildkit.v1.BuildHistoryRecord.trace:type_name -> moby.buildkit.v1.Descriptor
+	31, // 46: moby.buildkit.v1.BuildHistoryRecord.externalError:type_name -> moby.buildkit.v1.Descriptor
+	31, // 47: moby.buildkit.v1.BuildHistoryRecord.cacheKeys:type_name -> moby.buildkit.v1.Descriptor
+	29, // 48: moby.buildkit.v1.ExplainCacheMissResponse.Vertexes:type_name -> moby.buildkit.v1.CacheMissVertex
+	30, // 49: moby.buildkit.v1.CacheMissVertex.Reasons:type_name -> moby.buildkit.v1.CacheMissReason
+	44, // 50: moby.buildkit.v1.Descriptor.annotations:type_name -> moby.buildkit.v1.Descriptor.AnnotationsEntry
+	31, // 51: moby.buildkit.v1.BuildResultInfo.ResultDeprecated:type_name -> moby.buildkit.v1.Descriptor
+	31, // 52: moby.buildkit.v1.BuildResultInfo.Attestations:type_name -> moby.buildkit.v1.Descriptor
+	45, // 53: moby.buildkit.v1.BuildResultInfo.Results:type_name -> moby.buildkit.v1.BuildResultInfo.ResultsEntry
+	46, // 54: moby.buildkit.v1.Exporter.Attrs:type_name -> moby.buildkit.v1.Exporter.AttrsEntry
+	48, // 55: moby.buildkit.v1.SolveRequest.FrontendInputsEntry.value:type_name -> pb.Definition
+	32, // 56: moby.buildkit.v1.BuildHistoryRecord.ResultsEntry.value:type_name -> moby.buildkit.v1.BuildResultInfo
+	31, // 57: moby.buildkit.v1.BuildResultInfo.ResultsEntry.value:type_name -> moby.buildkit.v1.Descriptor
+	2,  // 58: moby.buildkit.v1.Control.DiskUsage:input_type -> moby.buildkit.v1.DiskUsageRequest
+	1,  // 59: moby.buildkit.v1.Control.Prune:input_type -> moby.buildkit.v1.PruneRequest
+	5,  // 60: moby.buildkit.v1.Control.Solve:input_type -> moby.buildkit.v1.SolveRequest
+	10, // 61: moby.buildkit.v1.Control.Status:input_type -> moby.buildkit.v1.StatusRequest
+	17, // 62: moby.buildkit.v1.Control.Session:input_type -> moby.buildkit.v1.BytesMessage
+	18, // 63: moby.buildkit.v1.Control.ListWorkers:input_type -> moby.buildkit.v1.ListWorkersRequest
+	20, // 64: moby.buildkit.v1.Control.Info:input_type -> moby.buildkit.v1.InfoRequest
+	22, // 65: moby.buildkit.v1.Control.ListenBuildHistory:input_type -> moby.buildkit.v1.BuildHistoryRequest
+	25, // 66: moby.buildkit.v1.Control.UpdateBuildHistory:input_type -> moby.buildkit.v1.UpdateBuildHistoryRequest
+	27, // 67: moby.buildkit.v1.Control.ExplainCacheMiss:input_type -> moby.buildkit.v1.ExplainCacheMissRequest
+	3,  // 68: moby.buildkit.v1.Control.DiskUsage:output_type -> moby.buildkit.v1.DiskUsageResponse
+	4,  // 69: moby.buildkit.v1.Control.Prune:output_type -> moby.buildkit.v1.UsageRecord
+	8,  // 70: moby.buildkit.v1.Control.Solve:output_type -> moby.buildkit.v1.SolveResponse
+	11, // 71: moby.buildkit.v1.Control.Status:output_type -> moby.buildkit.v1.StatusResponse
+	17, // 72: moby.buildkit.v1.Control.Session:output_type -> moby.buildkit.v1.BytesMessage
+	19, // 73: moby.buildkit.v1.Control.ListWorkers:output_type -> moby.buildkit.v1.ListWorkersResponse
+	21, // 74: moby.buildkit.v1.Control.Info:output_type -> moby.buildkit.v1.InfoResponse
+	23, // 75: moby.buildkit.v1.Control.ListenBuildHistory:output_type -> moby.buildkit.v1.BuildHistoryEvent
+	26, // 76: moby.buildkit.v1.Control.UpdateBuildHistory:output_type -> moby.buildkit.v1.UpdateBuildHistoryResponse
+	28, // 77: moby.buildkit.v1.Control.ExplainCacheMiss:output_type -> moby.buildkit.v1.ExplainCacheMissResponse
+	68, // [68:78] is the sub-list for method output_type
+	58, // [58:68] is the sub-list for method input_type
+	58, // [58:58] is the sub-list for extension type_name
+	58, // [58:58] is the sub-list for extension extendee
+	0,  // [0:58] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_api_services_control_control_proto_init() }
@@ -2387,7 +2934,7 @@ func file_github_com_moby_buildkit_api_services_control_control_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc), len(file_github_com_moby_buildkit_api_services_control_control_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   39,
+			NumMessages:   46,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
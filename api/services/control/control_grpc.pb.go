@@ -28,6 +28,7 @@ const (
 	Control_Info_FullMethodName               = "/moby.buildkit.v1.Control/Info"
 	Control_ListenBuildHistory_FullMethodName = "/moby.buildkit.v1.Control/ListenBuildHistory"
 	Control_UpdateBuildHistory_FullMethodName = "/moby.buildkit.v1.Control/UpdateBuildHistory"
+	Control_ExplainCacheMiss_FullMethodName   = "/moby.buildkit.v1.Control/ExplainCacheMiss"
 )
 
 // ControlClient is the client API for Control service.
@@ -43,6 +44,7 @@ type ControlClient interface {
 	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
 	ListenBuildHistory(ctx context.Context, in *BuildHistoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BuildHistoryEvent], error)
 	UpdateBuildHistory(ctx context.Context, in *UpdateBuildHistoryRequest, opts ...grpc.CallOption) (*UpdateBuildHistoryResponse, error)
+	ExplainCacheMiss(ctx context.Context, in *ExplainCacheMissRequest, opts ...grpc.CallOption) (*ExplainCacheMissResponse, error)
 }
 
 type controlClient struct {
@@ -173,6 +175,16 @@ func (c *controlClient) UpdateBuildHistory(ctx context.Context, in *UpdateBuildH
 	return out, nil
 }
 
+func (c *controlClient) ExplainCacheMiss(ctx context.Context, in *ExplainCacheMissRequest, opts ...grpc.CallOption) (*ExplainCacheMissResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainCacheMissResponse)
+	err := c.cc.Invoke(ctx, Control_ExplainCacheMiss_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlServer is the server API for Control service.
 // All implementations should embed UnimplementedControlServer
 // for forward compatibility.
@@ -186,6 +198,7 @@ type ControlServer interface {
 	Info(context.Context, *InfoRequest) (*InfoResponse, error)
 	ListenBuildHistory(*BuildHistoryRequest, grpc.ServerStreamingServer[BuildHistoryEvent]) error
 	UpdateBuildHistory(context.Context, *UpdateBuildHistoryRequest) (*UpdateBuildHistoryResponse, error)
+	ExplainCacheMiss(context.Context, *ExplainCacheMissRequest) (*ExplainCacheMissResponse, error)
 }
 
 // UnimplementedControlServer should be embedded to have
@@ -222,6 +235,9 @@ func (UnimplementedControlServer) ListenBuildHistory(*BuildHistoryRequest, grpc.
 func (UnimplementedControlServer) UpdateBuildHistory(context.Context, *UpdateBuildHistoryRequest) (*UpdateBuildHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateBuildHistory not implemented")
 }
+func (UnimplementedControlServer) ExplainCacheMiss(context.Context, *ExplainCacheMissRequest) (*ExplainCacheMissResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainCacheMiss not implemented")
+}
 func (UnimplementedControlServer) testEmbeddedByValue() {}
 
 // UnsafeControlServer may be embedded to opt out of forward compatibility for this service.
@@ -372,6 +388,24 @@ func _Control_UpdateBuildHistory_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Control_ExplainCacheMiss_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainCacheMissRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).ExplainCacheMiss(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_ExplainCacheMiss_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).ExplainCacheMiss(ctx, req.(*ExplainCacheMissRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Control_ServiceDesc is the grpc.ServiceDesc for Control service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -399,6 +433,10 @@ var Control_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateBuildHistory",
 			Handler:    _Control_UpdateBuildHistory_Handler,
 		},
+		{
+			MethodName: "ExplainCacheMiss",
+			Handler:    _Control_ExplainCacheMiss_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
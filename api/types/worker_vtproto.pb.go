@@ -81,6 +81,11 @@ func (m *GCPolicy) CloneVT() *GCPolicy {
 		copy(tmpContainer, rhs)
 		r.Filters = tmpContainer
 	}
+	if rhs := m.CacheMountIDs; rhs != nil {
+		tmpContainer := make([]string, len(rhs))
+		copy(tmpContainer, rhs)
+		r.CacheMountIDs = tmpContainer
+	}
 	if len(m.unknownFields) > 0 {
 		r.unknownFields = make([]byte, len(m.unknownFields))
 		copy(r.unknownFields, m.unknownFields)
@@ -252,6 +257,15 @@ func (this *GCPolicy) EqualVT(that *GCPolicy) bool {
 	if this.MinFreeSpace != that.MinFreeSpace {
 		return false
 	}
+	if len(this.CacheMountIDs) != len(that.CacheMountIDs) {
+		return false
+	}
+	for i, vx := range this.CacheMountIDs {
+		vy := that.CacheMountIDs[i]
+		if vx != vy {
+			return false
+		}
+	}
 	return string(this.unknownFields) == string(that.unknownFields)
 }
 
@@ -459,6 +473,15 @@ func (m *GCPolicy) MarshalToSizedBufferVT(dAtA []byte) (int, error) {
 		i -= len(m.unknownFields)
 		copy(dAtA[i:], m.unknownFields)
 	}
+	if len(m.CacheMountIDs) > 0 {
+		for iNdEx := len(m.CacheMountIDs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.CacheMountIDs[iNdEx])
+			copy(dAtA[i:], m.CacheMountIDs[iNdEx])
+			i = protohelpers.EncodeVarint(dAtA, i, uint64(len(m.CacheMountIDs[iNdEx])))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
 	if m.MinFreeSpace != 0 {
 		i = protohelpers.EncodeVarint(dAtA, i, uint64(m.MinFreeSpace))
 		i--
@@ -705,6 +728,12 @@ func (m *GCPolicy) SizeVT() (n int) {
 	if m.MinFreeSpace != 0 {
 		n += 1 + protohelpers.SizeOfVarint(uint64(m.MinFreeSpace))
 	}
+	if len(m.CacheMountIDs) > 0 {
+		for _, s := range m.CacheMountIDs {
+			l = len(s)
+			n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
+		}
+	}
 	n += len(m.unknownFields)
 	return n
 }
@@ -1264,6 +1293,38 @@ func (m *GCPolicy) UnmarshalVT(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CacheMountIDs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return protohelpers.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CacheMountIDs = append(m.CacheMountIDs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := protohelpers.Skip(dAtA[iNdEx:])
@@ -1015,16 +1015,23 @@ func (cm *cacheManager) createDiffRef(ctx context.Context, parents parentRefs, d
 func (cm *cacheManager) Prune(ctx context.Context, ch chan client.UsageInfo, opts ...client.PruneInfo) error {
 	cm.muPrune.Lock()
 
+	// records removed by a dry run stay removed for the next policies
+	dryRun := &pruneDryRun{
+		removed:  map[*cacheRecord]struct{}{},
+		released: map[ref]struct{}{},
+	}
+	removed := false
 	for _, opt := range opts {
-		if err := cm.prune(ctx, ch, opt); err != nil {
+		if err := cm.prune(ctx, ch, opt, dryRun); err != nil {
 			cm.muPrune.Unlock()
 			return err
 		}
+		removed = removed || !opt.DryRun
 	}
 
 	cm.muPrune.Unlock()
 
-	if cm.GarbageCollect != nil {
+	if cm.GarbageCollect != nil && removed {
 		if _, err := cm.GarbageCollect(ctx); err != nil {
 			return err
 		}
@@ -1033,7 +1040,7 @@ func (cm *cacheManager) Prune(ctx context.Context, ch chan client.UsageInfo, opt
 	return nil
 }
 
-func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt client.PruneInfo) error {
+func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt client.PruneInfo, dryRun *pruneDryRun) error {
 	filter, err := filters.ParseAll(opt.Filter...)
 	if err != nil {
 		return errors.Wrapf(err, "failed to parse prune filters %v", opt.Filter)
@@ -1058,6 +1065,10 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 			if ui.Shared {
 				continue
 			}
+			if len(opt.CacheMountIDs) > 0 && !cm.matchCacheMountIDs(ui.ID, opt.CacheMountIDs) {
+				// policies for cache mounts only count the size of the cache mounts
+				continue
+			}
 			totalSize += ui.Size
 		}
 	}
@@ -1077,6 +1088,12 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 		keepDuration: opt.KeepDuration,
 		keepBytes:    calculateKeepBytes(totalSize, dstat, opt),
 		totalSize:    totalSize,
+
+		cacheMountIDs: opt.CacheMountIDs,
+		historyRefs:   opt.HistoryRefs,
+	}
+	if opt.DryRun {
+		popt.dryRun = dryRun
 	}
 	for {
 		releasedSize, releasedCount, err := cm.pruneOnce(ctx, ch, popt)
@@ -1087,6 +1104,13 @@ func (cm *cacheManager) prune(ctx context.Context, ch chan client.UsageInfo, opt
 	}
 }
 
+func (cm *cacheManager) matchCacheMountIDs(id string, ids []string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	md, ok := cm.getMetadata(id)
+	return ok && slices.Contains(ids, md.GetCacheMountID())
+}
+
 func calculateKeepBytes(totalSize int64, dstat disk.DiskStat, opt client.PruneInfo) int64 {
 	// 0 values are special, and means we have no keep cap
 	if opt.MaxUsedSpace == 0 && opt.ReservedSpace == 0 && opt.MinFreeSpace == 0 {
@@ -1135,17 +1159,17 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 		cr.mu.Lock()
 
 		// ignore duplicates that share data
-		if cr.equalImmutable != nil && len(cr.equalImmutable.refs) > 0 || cr.equalMutable != nil && len(cr.refs) == 0 {
+		if cr.equalImmutable != nil && !opt.dryRun.unreferenced(cr.equalImmutable.cacheRecord) || cr.equalMutable != nil && opt.dryRun.unreferenced(cr) {
 			cr.mu.Unlock()
 			continue
 		}
 
-		if cr.isDead() {
+		if cr.isDead() || opt.dryRun.isRemoved(cr) {
 			cr.mu.Unlock()
 			continue
 		}
 
-		if len(cr.refs) == 0 {
+		if opt.dryRun.unreferenced(cr) {
 			recordType := cr.GetRecordType()
 			if recordType == "" {
 				recordType = client.UsageRecordTypeRegular
@@ -1182,6 +1206,16 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 				}
 			}
 
+			if len(opt.cacheMountIDs) > 0 && !slices.Contains(opt.cacheMountIDs, cr.GetCacheMountID()) {
+				cr.mu.Unlock()
+				continue
+			}
+
+			if opt.historyRefs != nil && !opt.historyRefs(cr.layerDigestChain()) {
+				cr.mu.Unlock()
+				continue
+			}
+
 			if opt.filter.Match(adaptUsageInfo(c)) {
 				toDelete = append(toDelete, &deleteRecord{
 					cacheRecord: cr,
@@ -1215,7 +1249,7 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 
 	for i, cr := range toDelete {
 		// only remove single record at a time
-		if i < batchSize {
+		if i < batchSize && opt.dryRun == nil {
 			cr.dead = true
 			// mark metadata as deleted in case we crash before cleanup finished
 			if err := cr.queueDeleted(); err != nil {
@@ -1262,7 +1296,7 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 		c := client.UsageInfo{
 			ID:          cr.ID(),
 			Mutable:     cr.mutable,
-			InUse:       len(cr.refs) > 0,
+			InUse:       !opt.dryRun.unreferenced(cr.cacheRecord),
 			Size:        cr.getSize(),
 			CreatedAt:   cr.GetCreatedAt(),
 			Description: cr.GetDescription(),
@@ -1293,6 +1327,16 @@ func (cm *cacheManager) pruneOnce(ctx context.Context, ch chan client.UsageInfo,
 
 		releasedSize += c.Size
 
+		if opt.dryRun != nil {
+			opt.dryRun.remove(cr.cacheRecord)
+			releasedCount++
+			if ch != nil {
+				ch <- c
+			}
+			cr.mu.Unlock()
+			continue
+		}
+
 		if cr.equalImmutable != nil {
 			if err1 := cr.equalImmutable.remove(ctx, false); err == nil {
 				err = err1
@@ -1549,6 +1593,14 @@ func WithRecordType(t client.UsageRecordType) RefOption {
 	}
 }
 
+// WithCacheMountID marks the ref as the contents of the cache mount id so
+// that GC policies can target it.
+func WithCacheMountID(id string) RefOption {
+	return func(m *cacheMetadata) error {
+		return m.queueCacheMountID(id)
+	}
+}
+
 func WithCreationTime(tm time.Time) RefOption {
 	return func(m *cacheMetadata) error {
 		return m.queueCreatedAt(tm)
@@ -1672,6 +1724,65 @@ type pruneOpt struct {
 
 	keepBytes int64
 	totalSize int64
+
+	cacheMountIDs []string
+	historyRefs   func([]digest.Digest) bool
+
+	// dryRun is set if the records should only be reported
+	dryRun *pruneDryRun
+}
+
+// pruneDryRun tracks the records that a dry run would have removed so that
+// the parents only referenced by them can be reported as well.
+type pruneDryRun struct {
+	removed  map[*cacheRecord]struct{}
+	released map[ref]struct{}
+}
+
+func (d *pruneDryRun) isRemoved(cr *cacheRecord) bool {
+	if d == nil {
+		return false
+	}
+	_, ok := d.removed[cr]
+	return ok
+}
+
+// unreferenced returns true if the record has no refs other than the ones
+// held by removed records. Needs to be called with cr.mu held.
+func (d *pruneDryRun) unreferenced(cr *cacheRecord) bool {
+	if d == nil {
+		return len(cr.refs) == 0
+	}
+	for r := range cr.refs {
+		if _, ok := d.released[r]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// remove marks the record as removed and releases its refs to the parents.
+// Needs to be called with cr.mu held.
+func (d *pruneDryRun) remove(cr *cacheRecord) {
+	if cr.equalImmutable != nil {
+		d.remove(cr.equalImmutable.cacheRecord)
+	}
+	d.removed[cr] = struct{}{}
+	switch cr.kind() {
+	case Layer:
+		d.released[cr.layerParent] = struct{}{}
+	case Merge:
+		for _, p := range cr.mergeParents {
+			d.released[p] = struct{}{}
+		}
+	case Diff:
+		if cr.diffParents.lower != nil {
+			d.released[cr.diffParents.lower] = struct{}{}
+		}
+		if cr.diffParents.upper != nil {
+			d.released[cr.diffParents.upper] = struct{}{}
+		}
+	}
 }
 
 type deleteRecord struct {
@@ -968,6 +968,141 @@ func TestPrune(t *testing.T) {
 	require.Equal(t, 0, len(dirs))
 }
 
+func TestPruneCacheMountIDs(t *testing.T) {
+	t.Parallel()
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	co, cleanup, err := newCacheManager(ctx, t, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	cm := co.manager
+
+	foo, err := cm.New(ctx, nil, nil, WithRecordType(client.UsageRecordTypeCacheMount), WithCacheMountID("foo"), CachePolicyRetain)
+	require.NoError(t, err)
+	fooID := foo.ID()
+	require.Equal(t, "foo", foo.GetCacheMountID())
+	require.NoError(t, foo.Release(ctx))
+
+	bar, err := cm.New(ctx, nil, nil, WithRecordType(client.UsageRecordTypeCacheMount), WithCacheMountID("bar"), CachePolicyRetain)
+	require.NoError(t, err)
+	require.NoError(t, bar.Release(ctx))
+
+	// created before the cache mount ID was recorded
+	baz, err := cm.New(ctx, nil, nil, WithRecordType(client.UsageRecordTypeCacheMount), WithDescription(`cached mount /cache from exec true with id "baz"`), CachePolicyRetain)
+	require.NoError(t, err)
+	bazID := baz.ID()
+	require.Equal(t, "baz", baz.GetCacheMountID())
+	require.NoError(t, baz.Release(ctx))
+
+	checkDiskUsage(ctx, t, cm, 0, 3)
+
+	buf := pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{CacheMountIDs: []string{"foo", "baz"}})
+	buf.close()
+	require.NoError(t, err)
+
+	checkDiskUsage(ctx, t, cm, 0, 1)
+	require.Equal(t, 2, len(buf.all))
+	require.ElementsMatch(t, []string{fooID, bazID}, []string{buf.all[0].ID, buf.all[1].ID})
+}
+
+func TestPruneDryRun(t *testing.T) {
+	t.Parallel()
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	co, cleanup, err := newCacheManager(ctx, t, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	cm := co.manager
+
+	active, err := cm.New(ctx, nil, nil)
+	require.NoError(t, err)
+	snap, err := active.Commit(ctx)
+	require.NoError(t, err)
+
+	active, err = cm.New(ctx, snap, nil, CachePolicyRetain)
+	require.NoError(t, err)
+	snap2, err := active.Commit(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, snap.Release(ctx))
+	require.NoError(t, snap2.Release(ctx))
+
+	checkDiskUsage(ctx, t, cm, 0, 2)
+
+	// dry run reports the parent that is only referenced by the removed child
+	buf := pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{DryRun: true})
+	buf.close()
+	require.NoError(t, err)
+
+	checkDiskUsage(ctx, t, cm, 0, 2)
+	require.Equal(t, 2, len(buf.all))
+	require.False(t, buf.all[1].InUse)
+	dryRun := buf.all
+
+	dirs, err := os.ReadDir(filepath.Join(tmpdir, "snapshots/snapshots"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(dirs))
+
+	// records not referenced by old build history are kept
+	buf = pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{
+		HistoryAge: time.Hour,
+		HistoryRefs: func([]digest.Digest) bool {
+			return false
+		},
+	})
+	buf.close()
+	require.NoError(t, err)
+
+	checkDiskUsage(ctx, t, cm, 0, 2)
+	require.Equal(t, 0, len(buf.all))
+
+	buf = pruneResultBuffer()
+	err = cm.Prune(ctx, buf.C, client.PruneInfo{})
+	buf.close()
+	require.NoError(t, err)
+
+	checkDiskUsage(ctx, t, cm, 0, 0)
+	require.Equal(t, 2, len(buf.all))
+	for i, ui := range buf.all {
+		require.Equal(t, ui.ID, dryRun[i].ID)
+		require.Equal(t, ui.Size, dryRun[i].Size)
+	}
+}
+
+func TestCacheMountIDFromDescription(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "/root/.cache", cacheMountIDFromDescription(`cached mount /root/.cache from exec /bin/sh -c go build`))
+	require.Equal(t, "gocache", cacheMountIDFromDescription(`cached mount /root/.cache from exec /bin/sh -c go build with id "gocache"`))
+	require.Equal(t, "", cacheMountIDFromDescription("mount / from exec /bin/sh"))
+}
+
 func TestLazyCommit(t *testing.T) {
 	t.Parallel()
 
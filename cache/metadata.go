@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/moby/buildkit/cache/metadata"
@@ -22,6 +24,7 @@ const keyLastUsedAt = "cache.lastUsedAt"
 const keyUsageCount = "cache.usageCount"
 const keyLayerType = "cache.layerType"
 const keyRecordType = "cache.recordType"
+const keyCacheMountID = "cache.cacheMountID"
 const keyCommitted = "snapshot.committed"
 const keyParent = "cache.parent"
 const keyMergeParents = "cache.mergeParents"
@@ -67,6 +70,10 @@ type RefMetadata interface {
 	GetRecordType() client.UsageRecordType
 	SetRecordType(client.UsageRecordType) error
 
+	// GetCacheMountID returns the ID of the cache mount the ref was created
+	// for, if any.
+	GetCacheMountID() string
+
 	GetEqualMutable() (RefMetadata, bool)
 
 	// generic getters/setters for external packages
@@ -180,6 +187,43 @@ func (md *cacheMetadata) queueRecordType(value client.UsageRecordType) error {
 	return md.queueValue(keyRecordType, value, "")
 }
 
+func (md *cacheMetadata) GetCacheMountID() string {
+	if id := md.GetString(keyCacheMountID); id != "" {
+		return id
+	}
+	// cache mounts created before the ID was recorded only have it in their
+	// description
+	if md.GetRecordType() != client.UsageRecordTypeCacheMount {
+		return ""
+	}
+	return cacheMountIDFromDescription(md.GetDescription())
+}
+
+// cacheMountIDFromDescription returns the cache mount ID from a description
+// of the form `cached mount <target> from <name>[ with id "<id>"]`. The ID
+// defaults to the target of the mount.
+func cacheMountIDFromDescription(desc string) string {
+	rest, ok := strings.CutPrefix(desc, "cached mount ")
+	if !ok {
+		return ""
+	}
+	const withID = " with id "
+	if i := strings.LastIndex(rest, withID); i >= 0 {
+		if id, err := strconv.Unquote(rest[i+len(withID):]); err == nil {
+			return id
+		}
+	}
+	target, _, ok := strings.Cut(rest, " from ")
+	if !ok {
+		return ""
+	}
+	return target
+}
+
+func (md *cacheMetadata) queueCacheMountID(id string) error {
+	return md.queueValue(keyCacheMountID, id, "")
+}
+
 func (md *cacheMetadata) SetCreatedAt(tm time.Time) error {
 	return md.setTime(keyCreatedAt, tm, "")
 }
@@ -12,8 +12,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	aws_config "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
@@ -25,6 +23,7 @@ import (
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/s3util"
 	"github.com/moby/buildkit/worker"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -403,19 +402,17 @@ type s3Client struct {
 }
 
 func newS3Client(ctx context.Context, config Config) (*s3Client, error) {
-	cfg, err := aws_config.LoadDefaultConfig(ctx, aws_config.WithRegion(config.Region))
+	client, err := s3util.NewClient(ctx, s3util.Config{
+		Region:          config.Region,
+		EndpointURL:     config.EndpointURL,
+		AccessKeyID:     config.AccessKeyID,
+		SecretAccessKey: config.SecretAccessKey,
+		SessionToken:    config.SessionToken,
+		UsePathStyle:    config.UsePathStyle,
+	})
 	if err != nil {
-		return nil, errors.Errorf("Unable to load AWS SDK config, %v", err)
+		return nil, err
 	}
-	client := s3.NewFromConfig(cfg, func(options *s3.Options) {
-		if config.AccessKeyID != "" && config.SecretAccessKey != "" {
-			options.Credentials = credentials.NewStaticCredentialsProvider(config.AccessKeyID, config.SecretAccessKey, config.SessionToken)
-		}
-		if config.EndpointURL != "" {
-			options.UsePathStyle = config.UsePathStyle
-			options.BaseEndpoint = aws.String(config.EndpointURL)
-		}
-	})
 
 	return &s3Client{
 		Client:          client,
@@ -5,8 +5,12 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
@@ -28,6 +32,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	ctd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/content/proxy"
@@ -58,10 +64,13 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/solver/result"
 	"github.com/moby/buildkit/sourcepolicy"
+	"github.com/moby/buildkit/sourcepolicy/lockfile"
 	sourcepolicypb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/entitlements"
+	"github.com/moby/buildkit/util/imagesig"
+	"github.com/moby/buildkit/util/s3util"
 	"github.com/moby/buildkit/util/testutil"
 	containerdutil "github.com/moby/buildkit/util/testutil/containerd"
 	"github.com/moby/buildkit/util/testutil/echoserver"
@@ -70,6 +79,7 @@ import (
 	"github.com/moby/buildkit/util/testutil/integration"
 	"github.com/moby/buildkit/util/testutil/workers"
 	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/spdx/tools-golang/spdx"
@@ -130,7 +140,10 @@ var allTests = []func(t *testing.T, sb integration.Sandbox){
 	testReadonlyRootFS,
 	testBasicRegistryCacheImportExport,
 	testBasicLocalCacheImportExport,
+	testCacheMountsLocalCacheImportExport,
 	testBasicS3CacheImportExport,
+	testBuildS3Source,
+	testBuildOCIArtifactSource,
 	testBasicAzblobCacheImportExport,
 	testCachedMounts,
 	testCopyFromEmptyImage,
@@ -219,6 +232,10 @@ var allTests = []func(t *testing.T, sb integration.Sandbox){
 	testMountStubsDirectory,
 	testMountStubsTimestamp,
 	testSourcePolicy,
+	testSourcePolicyImageSignature,
+	testImageExporterSign,
+	testExportAttestationReferrers,
+	testSourceLockfile,
 	testImageManifestRegistryCacheImportExport,
 	testLLBMountPerformance,
 	testClientCustomGRPCOpts,
@@ -290,6 +307,15 @@ func testIntegration(t *testing.T, funcs ...func(t *testing.T, sb integration.Sa
 	)
 
 	integration.Run(t, integration.TestFuncs(cdiTests...), mirrors)
+
+	integration.Run(t, integration.TestFuncs(
+		testDaemonSourcePolicy,
+	),
+		mirrors,
+		integration.WithMatrix("sourcepolicy", map[string]any{
+			"deny": daemonDenyPolicy,
+		}),
+	)
 }
 
 func newContainerd(cdAddress string) (*ctd.Client, error) {
@@ -6318,6 +6344,67 @@ func testBasicLocalCacheImportExport(t *testing.T, sb integration.Sandbox) {
 	testBasicCacheImportExport(t, sb, []CacheOptionsEntry{im}, []CacheOptionsEntry{ex})
 }
 
+func testCacheMountsLocalCacheImportExport(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	workers.CheckFeatureCompat(t, sb,
+		workers.FeatureCacheExport,
+		workers.FeatureCacheImport,
+		workers.FeatureCacheBackendLocal,
+	)
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	dir := t.TempDir()
+	busybox := llb.Image("busybox:latest")
+
+	solve := func(cmd string, cacheOpt SolveOpt) string {
+		st := busybox.Run(llb.Shlex(cmd), llb.Dir("/wd"))
+		st.AddMount("/cache", llb.Scratch(), llb.AsPersistentCacheDir("mycache", llb.CacheMountShared))
+		out := st.AddMount("/wd", llb.Scratch())
+
+		def, err := out.Marshal(sb.Context())
+		require.NoError(t, err)
+
+		destDir := t.TempDir()
+		cacheOpt.Exports = []ExportEntry{
+			{
+				Type:      ExporterLocal,
+				OutputDir: destDir,
+			},
+		}
+		_, err = c.Solve(sb.Context(), def, cacheOpt, nil)
+		require.NoError(t, err)
+
+		dt, err := os.ReadFile(filepath.Join(destDir, "unique"))
+		require.NoError(t, err)
+		return string(dt)
+	}
+
+	dt := solve(`sh -c "cat /dev/urandom | head -c 100 | sha256sum > /cache/unique && cp /cache/unique unique"`, SolveOpt{
+		CacheExports: []CacheOptionsEntry{{
+			Type: "local",
+			Attrs: map[string]string{
+				"dest":         dir,
+				"cache-mounts": "mycache",
+			},
+		}},
+	})
+
+	ensurePruneAll(t, c, sb)
+
+	// a different command that is not cached reads the imported cache mount
+	dt2 := solve(`sh -c "cp /cache/unique unique"`, SolveOpt{
+		CacheImports: []CacheOptionsEntry{{
+			Type: "local",
+			Attrs: map[string]string{
+				"src": dir,
+			},
+		}},
+	})
+	require.Equal(t, dt, dt2)
+}
+
 func testBasicS3CacheImportExport(t *testing.T, sb integration.Sandbox) {
 	integration.SkipOnPlatform(t, "windows")
 	workers.CheckFeatureCompat(t, sb,
@@ -6361,6 +6448,171 @@ func testBasicS3CacheImportExport(t *testing.T, sb integration.Sandbox) {
 	testBasicCacheImportExport(t, sb, []CacheOptionsEntry{im}, []CacheOptionsEntry{ex})
 }
 
+func testBuildS3Source(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	workers.CheckFeatureCompat(t, sb, workers.FeatureSourceS3)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	opts := helpers.MinioOpts{
+		Region:          "us-east-1",
+		AccessKeyID:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+	}
+
+	s3Addr, s3Bucket, cleanup, err := helpers.NewMinioServer(t, sb, opts)
+	require.NoError(t, err)
+	defer cleanup()
+
+	s3Client, err := s3util.NewClient(sb.Context(), s3util.Config{
+		Region:          opts.Region,
+		EndpointURL:     s3Addr,
+		AccessKeyID:     opts.AccessKeyID,
+		SecretAccessKey: opts.SecretAccessKey,
+		UsePathStyle:    true,
+	})
+	require.NoError(t, err)
+
+	_, err = s3Client.PutObject(sb.Context(), &s3.PutObjectInput{
+		Bucket: aws.String(s3Bucket),
+		Key:    aws.String("dir/foo"),
+		Body:   bytes.NewReader([]byte("content1")),
+	})
+	require.NoError(t, err)
+
+	secrets := secretsprovider.FromMap(map[string][]byte{
+		"AWS_ACCESS_KEY_ID":     []byte(opts.AccessKeyID),
+		"AWS_SECRET_ACCESS_KEY": []byte(opts.SecretAccessKey),
+	})
+
+	st := llb.S3(s3Bucket, "dir/foo", llb.S3Region(opts.Region), llb.S3Endpoint(s3Addr, true))
+	def, err := st.Marshal(sb.Context())
+	require.NoError(t, err)
+
+	tmpdir := t.TempDir()
+	_, err = c.Solve(sb.Context(), def, SolveOpt{
+		Session: []session.Attachable{secrets},
+		Exports: []ExportEntry{
+			{
+				Type:      ExporterLocal,
+				OutputDir: tmpdir,
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	dt, err := os.ReadFile(filepath.Join(tmpdir, "foo"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("content1"), dt)
+
+	// missing object
+	st = llb.S3(s3Bucket, "dir/bar", llb.S3Region(opts.Region), llb.S3Endpoint(s3Addr, true))
+	def, err = st.Marshal(sb.Context())
+	require.NoError(t, err)
+
+	_, err = c.Solve(sb.Context(), def, SolveOpt{
+		Session: []session.Attachable{secrets},
+	}, nil)
+	require.Error(t, err)
+}
+
+func testBuildOCIArtifactSource(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	workers.CheckFeatureCompat(t, sb, workers.FeatureSourceOCIArtifact)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+
+	target := registry + "/buildkit/testartifact:latest"
+	ctx := sb.Context()
+	pusher, err := docker.NewResolver(docker.ResolverOptions{PlainHTTP: true}).Pusher(ctx, target)
+	require.NoError(t, err)
+	ingester := contentutil.FromPusher(pusher)
+
+	blobs := []struct {
+		name string
+		dt   []byte
+	}{
+		{"model.bin", []byte("model-data")},
+		{"config/params.json", []byte(`{"a":1}`)},
+	}
+	mfst := ocispecs.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.buildkit.test.artifact",
+		Config:       ocispecs.DescriptorEmptyJSON,
+	}
+	require.NoError(t, content.WriteBlob(ctx, ingester, mfst.Config.Digest.String(), bytes.NewReader(mfst.Config.Data), mfst.Config))
+	for _, b := range blobs {
+		desc := ocispecs.Descriptor{
+			MediaType:   "application/octet-stream",
+			Digest:      digest.FromBytes(b.dt),
+			Size:        int64(len(b.dt)),
+			Annotations: map[string]string{ocispecs.AnnotationTitle: b.name},
+		}
+		require.NoError(t, content.WriteBlob(ctx, ingester, desc.Digest.String(), bytes.NewReader(b.dt), desc))
+		mfst.Layers = append(mfst.Layers, desc)
+	}
+	dt, err := json.Marshal(mfst)
+	require.NoError(t, err)
+	mfstDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, ingester, mfstDesc.Digest.String(), bytes.NewReader(dt), mfstDesc))
+
+	solve := func(st llb.State) (string, error) {
+		def, err := st.Marshal(sb.Context())
+		if err != nil {
+			return "", err
+		}
+		destDir := t.TempDir()
+		_, err = c.Solve(sb.Context(), def, SolveOpt{
+			Exports: []ExportEntry{
+				{
+					Type:      ExporterLocal,
+					OutputDir: destDir,
+				},
+			},
+		}, nil)
+		return destDir, err
+	}
+
+	destDir, err := solve(llb.OCIArtifact(target))
+	require.NoError(t, err)
+	for _, b := range blobs {
+		dt, err := os.ReadFile(filepath.Join(destDir, b.name))
+		require.NoError(t, err)
+		require.Equal(t, b.dt, dt)
+	}
+
+	destDir, err = solve(llb.OCIArtifact(registry+"/buildkit/testartifact@"+mfstDesc.Digest.String(), llb.OCIArtifactFiles("model.bin"), llb.OCIArtifactType(mfst.ArtifactType)))
+	require.NoError(t, err)
+	dt, err = os.ReadFile(filepath.Join(destDir, "model.bin"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("model-data"), dt)
+	_, err = os.Stat(filepath.Join(destDir, "config"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = solve(llb.OCIArtifact(target, llb.OCIArtifactType("application/vnd.buildkit.test.other")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected application/vnd.buildkit.test.other")
+
+	_, err = solve(llb.OCIArtifact(target, llb.OCIArtifactFiles("missing")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "file missing not found")
+}
+
 func testBasicAzblobCacheImportExport(t *testing.T, sb integration.Sandbox) {
 	integration.SkipOnPlatform(t, "windows")
 	workers.CheckFeatureCompat(t, sb,
@@ -11370,12 +11622,76 @@ var (
 	bridgeDNSNetwork integration.ConfigUpdater = &netModeBridgeDNS{}
 )
 
+// daemonDenyPolicyConfig configures buildkitd with a source policy that
+// denies busybox images.
+type daemonDenyPolicyConfig struct{}
+
+func (*daemonDenyPolicyConfig) UpdateConfigFile(in string) string {
+	dt, err := json.Marshal(&sourcepolicypb.Policy{
+		Rules: []*sourcepolicypb.Rule{
+			{
+				Action: sourcepolicypb.PolicyAction_DENY,
+				Selector: &sourcepolicypb.Selector{
+					Identifier: "docker-image://docker.io/library/busybox:*",
+				},
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	dir, err := os.MkdirTemp("", "buildkit-source-policy")
+	if err != nil {
+		panic(err)
+	}
+	fp := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(fp, dt, 0600); err != nil {
+		panic(err)
+	}
+	return in + fmt.Sprintf("\n[sourcePolicy]\nfiles = [%q]\n", fp)
+}
+
+var daemonDenyPolicy integration.ConfigUpdater = &daemonDenyPolicyConfig{}
+
 func fixedWriteCloser(wc io.WriteCloser) filesync.FileOutputFunc {
 	return func(map[string]string) (io.WriteCloser, error) {
 		return wc, nil
 	}
 }
 
+// testDaemonSourcePolicy checks that the source policy of the daemon applies
+// to builds that don't send a source policy of their own.
+func testDaemonSourcePolicy(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	def, err := llb.Image("busybox:latest").Marshal(sb.Context())
+	require.NoError(t, err)
+	_, err = c.Solve(sb.Context(), def, SolveOpt{}, nil)
+	require.ErrorContains(t, err, "source denied by policy")
+	require.ErrorContains(t, err, "daemon source policy")
+
+	frontend := func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		def, err := llb.Image("busybox:latest").Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.Solve(ctx, gateway.SolveRequest{
+			Definition: def.ToPB(),
+		})
+	}
+	_, err = c.Build(sb.Context(), SolveOpt{}, "", frontend, nil)
+	require.ErrorContains(t, err, "daemon source policy")
+
+	// sources that the daemon policy doesn't deny are allowed
+	def, err = llb.Scratch().File(llb.Mkfile("foo", 0600, []byte("foo"))).Marshal(sb.Context())
+	require.NoError(t, err)
+	_, err = c.Solve(sb.Context(), def, SolveOpt{}, nil)
+	require.NoError(t, err)
+}
+
 func testSourcePolicy(t *testing.T, sb integration.Sandbox) {
 	requiresLinux(t)
 	c, err := New(sb.Context(), sb.Address())
@@ -11560,6 +11876,586 @@ func testSourcePolicy(t *testing.T, sb integration.Sandbox) {
 	})
 }
 
+func testSourceLockfile(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	workers.CheckFeatureCompat(t, sb, workers.FeatureDirectPush)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+	ctx := sb.Context()
+
+	target := registry + "/buildkit/testlockfile:latest"
+	push := func(data string) digest.Digest {
+		def, err := llb.Scratch().File(llb.Mkfile("foo", 0600, []byte(data))).Marshal(ctx)
+		require.NoError(t, err)
+		resp, err := c.Solve(ctx, def, SolveOpt{
+			Exports: []ExportEntry{
+				{
+					Type: ExporterImage,
+					Attrs: map[string]string{
+						"name": target,
+						"push": "true",
+					},
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+		return digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+	}
+	build := func(pol *sourcepolicypb.Policy) (string, map[string]string) {
+		def, err := llb.Image(target).Marshal(ctx)
+		require.NoError(t, err)
+		destDir := t.TempDir()
+		resp, err := c.Solve(ctx, def, SolveOpt{
+			SourcePolicy: pol,
+			Exports: []ExportEntry{
+				{
+					Type:      ExporterLocal,
+					OutputDir: destDir,
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+		dt, err := os.ReadFile(filepath.Join(destDir, "foo"))
+		require.NoError(t, err)
+		return string(dt), resp.ExporterResponse
+	}
+
+	dgst := push("v1")
+	dt, resp := build(nil)
+	require.Equal(t, "v1", dt)
+
+	v, ok := resp[lockfile.ExporterResponseKey]
+	require.True(t, ok)
+	lt, err := base64.StdEncoding.DecodeString(v)
+	require.NoError(t, err)
+	l, err := lockfile.Parse(lt)
+	require.NoError(t, err)
+	require.Equal(t, []lockfile.Source{
+		{
+			Type: lockfile.SourceTypeImage,
+			Ref:  target,
+			Pin:  dgst.String(),
+		},
+	}, l.Sources)
+
+	push("v2")
+	dt, _ = build(nil)
+	require.Equal(t, "v2", dt)
+
+	// replaying the lockfile builds the image that was pinned
+	pol, err := l.Policy()
+	require.NoError(t, err)
+	dt, _ = build(pol)
+	require.Equal(t, "v1", dt)
+}
+
+func testSourcePolicyImageSignature(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	workers.CheckFeatureCompat(t, sb, workers.FeatureDirectPush, workers.FeatureSourceImageSignature)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+	ctx := sb.Context()
+
+	push := func(name, data string) digest.Digest {
+		def, err := llb.Scratch().File(llb.Mkfile("foo", 0600, []byte(data))).Marshal(ctx)
+		require.NoError(t, err)
+		resp, err := c.Solve(ctx, def, SolveOpt{
+			Exports: []ExportEntry{
+				{
+					Type: ExporterImage,
+					Attrs: map[string]string{
+						"name": name,
+						"push": "true",
+					},
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+		return digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+	}
+	signed := registry + "/buildkit/testsigned:latest"
+	dgst := push(signed, "signed")
+	unsigned := registry + "/buildkit/testunsigned:latest"
+	push(unsigned, "unsigned")
+
+	newKey := func() (*rsa.PrivateKey, string) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		dt, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+	}
+	key, pubKey := newKey()
+	_, otherPubKey := newKey()
+
+	// sign the image the way cosign does, with a manifest in the signature tag
+	sigRef := registry + "/buildkit/testsigned:" + imagesig.CosignTag(dgst)
+	pusher, err := docker.NewResolver(docker.ResolverOptions{PlainHTTP: true}).Pusher(ctx, sigRef)
+	require.NoError(t, err)
+	ingester := contentutil.FromPusher(pusher)
+
+	var ss imagesig.SimpleSigning
+	ss.Critical.Identity.DockerReference = registry + "/buildkit/testsigned"
+	ss.Critical.Image.DockerManifestDigest = dgst
+	ss.Critical.Type = imagesig.CosignSignatureType
+	payload, err := json.Marshal(ss)
+	require.NoError(t, err)
+	h := sha256.Sum256(payload)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	require.NoError(t, err)
+
+	layer := ocispecs.Descriptor{
+		MediaType: imagesig.CosignSimpleSigningMediaType,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+		Annotations: map[string]string{
+			imagesig.CosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	}
+	mfst := ocispecs.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    ocispecs.DescriptorEmptyJSON,
+		Layers:    []ocispecs.Descriptor{layer},
+	}
+	require.NoError(t, content.WriteBlob(ctx, ingester, mfst.Config.Digest.String(), bytes.NewReader(mfst.Config.Data), mfst.Config))
+	require.NoError(t, content.WriteBlob(ctx, ingester, layer.Digest.String(), bytes.NewReader(payload), layer))
+	dt, err := json.Marshal(mfst)
+	require.NoError(t, err)
+	mfstDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, ingester, mfstDesc.Digest.String(), bytes.NewReader(dt), mfstDesc))
+
+	policy := func(pubKeys ...string) *sourcepolicypb.Policy {
+		return &sourcepolicypb.Policy{
+			Rules: []*sourcepolicypb.Rule{
+				{
+					Action: sourcepolicypb.PolicyAction_VERIFY,
+					Selector: &sourcepolicypb.Selector{
+						Identifier: "docker-image://" + registry + "/buildkit/*",
+						MatchType:  sourcepolicypb.MatchType_WILDCARD,
+					},
+					Signature: &sourcepolicypb.SignatureRequirement{
+						PublicKeys: pubKeys,
+					},
+				},
+			},
+		}
+	}
+	solve := func(st llb.State, pol *sourcepolicypb.Policy) error {
+		def, err := st.Marshal(ctx)
+		require.NoError(t, err)
+		_, err = c.Solve(ctx, def, SolveOpt{SourcePolicy: pol}, nil)
+		return err
+	}
+
+	require.NoError(t, solve(llb.Image(signed), policy(otherPubKey, pubKey)))
+	require.NoError(t, solve(llb.Image(signed, llb.VerifyImageSignature(pubKey)), nil))
+
+	err = solve(llb.Image(signed), policy(otherPubKey))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to verify cosign signature")
+
+	err = solve(llb.Image(unsigned), policy(pubKey))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no signatures found")
+
+	err = solve(llb.Image(signed), policy("invalid"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid signature requirement")
+}
+
+func testImageExporterSign(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	workers.CheckFeatureCompat(t, sb, workers.FeatureDirectPush, workers.FeatureImageSigning, workers.FeatureSourceImageSignature)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+	ctx := sb.Context()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	privKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: dt})
+	dt, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pubKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+
+	p := platforms.MustParse("linux/amd64")
+	pk := platforms.Format(p)
+	frontend := func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		res := gateway.NewResult()
+
+		def, err := llb.Scratch().File(llb.Mkfile("/greeting", 0600, []byte("hello"))).Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err := c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+		if err != nil {
+			return nil, err
+		}
+		ref, err := r.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		res.AddRef(pk, ref)
+
+		def, err = llb.Scratch().File(llb.Mkfile("/attestation.json", 0600, []byte(`{"success": true}`))).Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err = c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+		if err != nil {
+			return nil, err
+		}
+		refAttest, err := r.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		res.AddAttestation(pk, gateway.Attestation{
+			Kind: gatewaypb.AttestationKind_InToto,
+			Ref:  refAttest,
+			Path: "/attestation.json",
+			InToto: result.InTotoAttestation{
+				PredicateType: "https://example.com/attestations/v1.0",
+				Subjects: []result.InTotoSubject{{
+					Kind: gatewaypb.InTotoSubjectKind_Self,
+				}},
+			},
+		})
+
+		dt, err := json.Marshal(&exptypes.Platforms{Platforms: []exptypes.Platform{{ID: pk, Platform: p}}})
+		if err != nil {
+			return nil, err
+		}
+		res.AddMeta(exptypes.ExporterPlatformsKey, dt)
+		return res, nil
+	}
+
+	target := registry + "/buildkit/testimagesign:latest"
+	resp, err := c.Build(ctx, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":     target,
+					"push":     "true",
+					"sign-key": "signkey",
+				},
+			},
+		},
+		Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{
+			"signkey": privKey,
+		})},
+	}, "", frontend, nil)
+	require.NoError(t, err)
+	dgst := digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+
+	// the pushed digest is signed
+	def, err := llb.Image(target+"@"+dgst.String(), llb.VerifyImageSignature(pubKey)).Marshal(ctx)
+	require.NoError(t, err)
+	_, err = c.Solve(ctx, def, SolveOpt{}, nil)
+	require.NoError(t, err)
+
+	// the attestations are wrapped in signed DSSE envelopes
+	desc, provider, err := contentutil.ProviderFromRef(target)
+	require.NoError(t, err)
+	require.Equal(t, dgst, desc.Digest)
+	imgs, err := testutil.ReadImages(ctx, provider, desc)
+	require.NoError(t, err)
+	atts := imgs.Filter("unknown/unknown")
+	require.Len(t, atts.Images, 1)
+	att := atts.Images[0]
+	require.Len(t, att.Manifest.Layers, 1)
+	require.Equal(t, attestation.MediaTypeDSSE, att.Manifest.Layers[0].MediaType)
+	require.Equal(t, "https://example.com/attestations/v1.0", att.Manifest.Layers[0].Annotations["in-toto.io/predicate-type"])
+
+	var env imagesig.DSSEEnvelope
+	require.NoError(t, json.Unmarshal(att.LayersRaw[0], &env))
+	require.Equal(t, intoto.PayloadType, env.PayloadType)
+	keys, err := imagesig.ParseKeys(pubKey)
+	require.NoError(t, err)
+	payload, _, err := imagesig.VerifyDSSE(&env, keys)
+	require.NoError(t, err)
+	var stmt intoto.Statement
+	require.NoError(t, json.Unmarshal(payload, &stmt))
+	require.Equal(t, "https://example.com/attestations/v1.0", stmt.PredicateType)
+
+	// signing the same image with another key appends to the existing cosign
+	// signature manifest instead of replacing it
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err = x509.MarshalPKCS8PrivateKey(key2)
+	require.NoError(t, err)
+	privKey2 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: dt})
+	dt, err = x509.MarshalPKIXPublicKey(&key2.PublicKey)
+	require.NoError(t, err)
+	pubKey2 := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+
+	plainFrontend := func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		def, err := llb.Scratch().File(llb.Mkfile("/greeting", 0600, []byte("hello"))).Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+	}
+	plainTarget := registry + "/buildkit/testimagesign:plain"
+	var plainDgst digest.Digest
+	for _, signKey := range [][]byte{privKey, privKey2, privKey} {
+		resp, err := c.Build(ctx, SolveOpt{
+			FrontendAttrs: map[string]string{
+				"build-arg:SOURCE_DATE_EPOCH": "1234",
+			},
+			Exports: []ExportEntry{
+				{
+					Type: ExporterImage,
+					Attrs: map[string]string{
+						"name":     plainTarget,
+						"push":     "true",
+						"sign-key": "signkey",
+					},
+				},
+			},
+			Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{
+				"signkey": signKey,
+			})},
+		}, "", plainFrontend, nil)
+		require.NoError(t, err)
+		dgst := digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+		if plainDgst != "" {
+			require.Equal(t, plainDgst, dgst)
+		}
+		plainDgst = dgst
+	}
+	for _, pub := range []string{pubKey, pubKey2} {
+		def, err := llb.Image(plainTarget+"@"+plainDgst.String(), llb.VerifyImageSignature(pub)).Marshal(ctx)
+		require.NoError(t, err)
+		_, err = c.Solve(ctx, def, SolveOpt{}, nil)
+		require.NoError(t, err)
+	}
+	// signing again with the first key replaces its signature
+	desc, provider, err = contentutil.ProviderFromRef(registry + "/buildkit/testimagesign:" + imagesig.CosignTag(plainDgst))
+	require.NoError(t, err)
+	dt, err = content.ReadBlob(ctx, provider, desc)
+	require.NoError(t, err)
+	var sigMfst ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(dt, &sigMfst))
+	require.Len(t, sigMfst.Layers, 2)
+
+	// signatures are only stored in the registry
+	_, err = c.Build(ctx, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":     target,
+					"sign-key": "signkey",
+				},
+			},
+		},
+		Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{
+			"signkey": privKey,
+		})},
+	}, "", frontend, nil)
+	require.ErrorContains(t, err, "sign-key requires push")
+
+	// a missing key fails the export
+	_, err = c.Build(ctx, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":     target,
+					"push":     "true",
+					"sign-key": "nokey",
+				},
+			},
+		},
+		Session: []session.Attachable{secretsprovider.FromMap(map[string][]byte{})},
+	}, "", frontend, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get signing key from secret nokey")
+}
+
+func testExportAttestationReferrers(t *testing.T, sb integration.Sandbox) {
+	requiresLinux(t)
+	workers.CheckFeatureCompat(t, sb, workers.FeatureDirectPush, workers.FeatureAttestationReferrers)
+
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+	ctx := sb.Context()
+
+	p := platforms.MustParse("linux/amd64")
+	pk := platforms.Format(p)
+	frontend := func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		res := gateway.NewResult()
+
+		def, err := llb.Scratch().File(llb.Mkfile("/greeting", 0600, []byte("hello"))).Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err := c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+		if err != nil {
+			return nil, err
+		}
+		ref, err := r.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		res.AddRef(pk, ref)
+
+		def, err = llb.Scratch().File(llb.Mkfile("/attestation.json", 0600, []byte(`{"success": true}`))).Marshal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err = c.Solve(ctx, gateway.SolveRequest{Definition: def.ToPB()})
+		if err != nil {
+			return nil, err
+		}
+		refAttest, err := r.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		res.AddAttestation(pk, gateway.Attestation{
+			Kind: gatewaypb.AttestationKind_InToto,
+			Ref:  refAttest,
+			Path: "/attestation.json",
+			InToto: result.InTotoAttestation{
+				PredicateType: "https://example.com/attestations/v1.0",
+			},
+		})
+
+		dt, err := json.Marshal(&exptypes.Platforms{Platforms: []exptypes.Platform{{ID: pk, Platform: p}}})
+		if err != nil {
+			return nil, err
+		}
+		res.AddMeta(exptypes.ExporterPlatformsKey, dt)
+		return res, nil
+	}
+
+	target := registry + "/buildkit/testattestationreferrers:latest"
+	resp, err := c.Build(ctx, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":                  target,
+					"push":                  "true",
+					"attestation-referrers": "true",
+				},
+			},
+		},
+	}, "", frontend, nil)
+	require.NoError(t, err)
+	dgst := digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+
+	// the image is not wrapped in an index for the attestations
+	desc, _, err := contentutil.ProviderFromRef(target)
+	require.NoError(t, err)
+	require.Equal(t, dgst, desc.Digest)
+	require.Equal(t, ocispecs.MediaTypeImageManifest, desc.MediaType)
+
+	// the attestations are discovered from the referrers of the manifest
+	var atts []sourceresolver.ImageAttestation
+	_, err = c.Build(ctx, SolveOpt{}, "", func(ctx context.Context, c gateway.Client) (*gateway.Result, error) {
+		res, err := c.ResolveSourceMetadata(ctx, &pb.SourceOp{
+			Identifier: "docker-image://" + target,
+		}, sourceresolver.Opt{
+			Platform: &p,
+			ImageOpt: &sourceresolver.ResolveImageOpt{
+				ResolveMode:         llb.ResolveModeForcePull.String(),
+				ResolveAttestations: true,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		atts = res.Image.Attestations
+		return gateway.NewResult(), nil
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, atts, 1)
+	require.Equal(t, "https://example.com/attestations/v1.0", atts[0].PredicateType)
+
+	var stmt intoto.Statement
+	require.NoError(t, json.Unmarshal(atts[0].Data, &stmt))
+	require.Len(t, stmt.Subject, 1)
+	require.Equal(t, dgst.Encoded(), stmt.Subject[0].Digest["sha256"])
+
+	// an image that is only stored keeps its referrers
+	cdAddress := sb.ContainerdAddress()
+	if cdAddress == "" {
+		return
+	}
+	name := "buildkit/testattestationreferrersstore:latest"
+	resp, err = c.Build(ctx, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":                  name,
+					"attestation-referrers": "true",
+				},
+			},
+		},
+	}, "", frontend, nil)
+	require.NoError(t, err)
+	dgst = digest.Digest(resp.ExporterResponse[exptypes.ExporterImageDigestKey])
+
+	client, err := newContainerd(cdAddress)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx = namespaces.WithNamespace(ctx, "buildkit")
+
+	img, err := client.GetImage(ctx, name)
+	require.NoError(t, err)
+	require.Equal(t, dgst, img.Target().Digest)
+	info, err := client.ContentStore().Info(ctx, dgst)
+	require.NoError(t, err)
+	referrer, ok := info.Labels["containerd.io/gc.ref.content.referrer.0"]
+	require.True(t, ok)
+	dt, err := content.ReadBlob(ctx, client.ContentStore(), ocispecs.Descriptor{Digest: digest.Digest(referrer)})
+	require.NoError(t, err)
+	var mfst ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.NotNil(t, mfst.Subject)
+	require.Equal(t, dgst, mfst.Subject.Digest)
+}
+
 func testLLBMountPerformance(t *testing.T, sb integration.Sandbox) {
 	c, err := New(sb.Context(), sb.Address())
 	require.NoError(t, err)
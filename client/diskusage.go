@@ -83,6 +83,7 @@ const (
 	UsageRecordTypeFrontend    UsageRecordType = "frontend"
 	UsageRecordTypeLocalSource UsageRecordType = "source.local"
 	UsageRecordTypeGitCheckout UsageRecordType = "source.git.checkout"
+	UsageRecordTypeGitObjects  UsageRecordType = "source.git.objects"
 	UsageRecordTypeCacheMount  UsageRecordType = "exec.cachemount"
 	UsageRecordTypeRegular     UsageRecordType = "regular"
 )
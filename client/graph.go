@@ -47,14 +47,63 @@ type VertexWarning struct {
 	Range      []*pb.Range    `json:"range,omitempty"`
 }
 
+// Types of VertexEvent.
+const (
+	// VertexEventRetry is sent when a failed process is retried.
+	VertexEventRetry = "retry"
+	// VertexEventTimeout is sent when a process is killed because it
+	// exceeded its timeout.
+	VertexEventTimeout = "timeout"
+	// VertexEventQueued is sent when the position of a build waiting in the
+	// build queue of the daemon changes.
+	VertexEventQueued = "queued"
+	// VertexEventSourcePolicy is sent for every source policy rule that
+	// matched a source of the build.
+	VertexEventSourcePolicy = "source-policy"
+)
+
+// VertexEvent reports a change in the execution of a vertex, eg. a retry of
+// a failed process.
+type VertexEvent struct {
+	Vertex    digest.Digest `json:"vertex,omitempty"`
+	Type      string        `json:"type"`
+	Message   string        `json:"message,omitempty"`
+	Attempt   int           `json:"attempt,omitempty"`
+	Position  int           `json:"position,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
 type SolveStatus struct {
 	Vertexes []*Vertex        `json:"vertexes,omitempty"`
 	Statuses []*VertexStatus  `json:"statuses,omitempty"`
 	Logs     []*VertexLog     `json:"logs,omitempty"`
 	Warnings []*VertexWarning `json:"warnings,omitempty"`
+	Events   []*VertexEvent   `json:"events,omitempty"`
 }
 
 type SolveResponse struct {
 	// ExporterResponse is also used for CacheExporter
 	ExporterResponse map[string]string
+	// Plan is set for builds with SolveOpt.Plan and lists the vertexes
+	// that the build would load, inputs first.
+	Plan []*VertexPlan
+}
+
+// PlanStatus is the status of a vertex in a plan-only build.
+type PlanStatus string
+
+const (
+	// PlanCached means that the result of the vertex is loaded from cache.
+	PlanCached PlanStatus = "cached"
+	// PlanWouldRun means that the vertex would be executed.
+	PlanWouldRun PlanStatus = "would-run"
+	// PlanUnknownUntilInput means that the cache key of the vertex depends on
+	// the contents of its inputs and can't be known before they are built.
+	PlanUnknownUntilInput PlanStatus = "unknown-until-input"
+)
+
+type VertexPlan struct {
+	Digest digest.Digest `json:"digest"`
+	Name   string        `json:"name,omitempty"`
+	Status PlanStatus    `json:"status"`
 }
@@ -7,6 +7,7 @@ import (
 	"net"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/system"
@@ -46,6 +47,7 @@ type mount struct {
 	tmpfs        bool
 	tmpfsOpt     TmpfsInfo
 	cacheSharing CacheMountSharingMode
+	cacheMaxSize int64
 	noOutput     bool
 	contentCache MountContentCache
 }
@@ -215,6 +217,25 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		addCap(&e.constraints, pb.CapExecValidExitCode)
 	}
 
+	execTimeout, err := getTimeout(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	if execTimeout != 0 {
+		addCap(&e.constraints, pb.CapExecMetaTimeout)
+	}
+
+	var execRetry *pb.Retry
+	if r, err := getRetry(e.base)(ctx, c); err != nil {
+		return "", nil, nil, nil, err
+	} else if r != nil && r.attempts > 0 {
+		execRetry = &pb.Retry{
+			Attempts: int64(r.attempts),
+			Backoff:  int64(r.backoff),
+		}
+		addCap(&e.constraints, pb.CapExecMetaRetry)
+	}
+
 	meta := &pb.Meta{
 		Args:                      args,
 		Env:                       env.ToArray(),
@@ -224,6 +245,8 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		CgroupParent:              cgrpParent,
 		RemoveMountStubsRecursive: true,
 		ValidExitCodes:            validExitCodes,
+		Timeout:                   int64(execTimeout),
+		Retry:                     execRetry,
 	}
 
 	extraHosts, err := getExtraHosts(e.base)(ctx, c)
@@ -299,6 +322,9 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		if m.cacheID != "" {
 			addCap(&e.constraints, pb.CapExecMountCache)
 			addCap(&e.constraints, pb.CapExecMountCacheSharing)
+			if m.cacheMaxSize > 0 {
+				addCap(&e.constraints, pb.CapExecMountCacheMaxSize)
+			}
 		} else if m.tmpfs {
 			addCap(&e.constraints, pb.CapExecMountTmpfs)
 			if m.tmpfsOpt.Size > 0 {
@@ -396,7 +422,8 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		if m.cacheID != "" {
 			pm.MountType = pb.MountType_CACHE
 			pm.CacheOpt = &pb.CacheOpt{
-				ID: m.cacheID,
+				ID:      m.cacheID,
+				MaxSize: m.cacheMaxSize,
 			}
 			switch m.cacheSharing {
 			case CacheMountShared:
@@ -563,6 +590,16 @@ func AsPersistentCacheDir(id string, sharing CacheMountSharingMode) MountOption
 	}
 }
 
+// CacheMountMaxSize limits the size of the contents of a persistent cache
+// directory. Contents over the limit are discarded before the cache directory
+// is used again. The size is measured when a mount with a max size releases
+// the cache directory.
+func CacheMountMaxSize(size int64) MountOption {
+	return func(m *mount) {
+		m.cacheMaxSize = size
+	}
+}
+
 func Tmpfs(opts ...TmpfsOption) MountOption {
 	return func(m *mount) {
 		t := &TmpfsInfo{}
@@ -681,6 +718,25 @@ func ValidExitCodes(codes ...int) RunOption {
 	})
 }
 
+// WithTimeout returns a [RunOption] that kills the process if it runs longer
+// than the duration. Together with [WithRetry] the timeout applies to every
+// attempt separately.
+func WithTimeout(d time.Duration) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = timeout(d)(ei.State)
+	})
+}
+
+// WithRetry returns a [RunOption] that retries the process up to attempts
+// times if it exits with a non-zero exit code or exceeds its timeout. The
+// delay between the retries starts from backoff and is doubled for every
+// retry. If backoff is zero, the delay starts from 1 second.
+func WithRetry(attempts int, backoff time.Duration) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = retry(attempts, backoff)(ei.State)
+	})
+}
+
 func WithCgroupParent(cp string) RunOption {
 	return runOptionFunc(func(ei *ExecInfo) {
 		ei.State = ei.State.WithCgroupParent(cp)
@@ -3,8 +3,10 @@ package llb
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -70,3 +72,48 @@ func TestExecOpMarshalConsistency(t *testing.T) {
 		prevDef = def.Def
 	}
 }
+
+func TestExecTimeoutRetry(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args"), WithTimeout(10*time.Minute), WithRetry(3, 0)).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+
+	exec := m[dgst].Op.(*pb.Op_Exec).Exec
+	require.Equal(t, int64(10*time.Minute), exec.Meta.Timeout)
+	require.Equal(t, int64(3), exec.Meta.Retry.Attempts)
+	require.Equal(t, int64(0), exec.Meta.Retry.Backoff)
+
+	st = Image("foo").Run(Shlex("args")).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	exec = m[dgst].Op.(*pb.Op_Exec).Exec
+	require.Equal(t, int64(0), exec.Meta.Timeout)
+	require.Nil(t, exec.Meta.Retry)
+}
+
+func TestExecCacheMountMaxSize(t *testing.T) {
+	t.Parallel()
+
+	es := Image("foo").Run(Shlex("args"))
+	es.AddMount("/cache", Scratch(), AsPersistentCacheDir("mycache", CacheMountShared), CacheMountMaxSize(1<<30))
+	def, err := es.Root().Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	exec := m[dgst].Op.(*pb.Op_Exec).Exec
+	require.Equal(t, 2, len(exec.Mounts))
+	require.Equal(t, pb.MountType_CACHE, exec.Mounts[1].MountType)
+	require.Equal(t, "mycache", exec.Mounts[1].CacheOpt.ID)
+	require.Equal(t, int64(1<<30), exec.Mounts[1].CacheOpt.MaxSize)
+	require.True(t, def.Metadata[digest.Digest(dgst)].Caps[pb.CapExecMountCacheMaxSize])
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/containerd/platforms"
 	"github.com/google/shlex"
@@ -27,6 +28,8 @@ var (
 	keyCgroupParent   = contextKeyT("llb.exec.cgroup.parent")
 	keyUser           = contextKeyT("llb.exec.user")
 	keyValidExitCodes = contextKeyT("llb.exec.validexitcodes")
+	keyTimeout        = contextKeyT("llb.exec.timeout")
+	keyRetry          = contextKeyT("llb.exec.retry")
 
 	keyPlatform = contextKeyT("llb.platform")
 	keyNetwork  = contextKeyT("llb.network")
@@ -185,6 +188,50 @@ func getValidExitCodes(s State) func(context.Context, *Constraints) ([]int, erro
 	}
 }
 
+func timeout(d time.Duration) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyTimeout, d)
+	}
+}
+
+func getTimeout(s State) func(context.Context, *Constraints) (time.Duration, error) {
+	return func(ctx context.Context, c *Constraints) (time.Duration, error) {
+		v, err := s.getValue(keyTimeout)(ctx, c)
+		if err != nil {
+			return 0, err
+		}
+		if v != nil {
+			return v.(time.Duration), nil
+		}
+		return 0, nil
+	}
+}
+
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+func retry(attempts int, backoff time.Duration) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyRetry, retryPolicy{attempts: attempts, backoff: backoff})
+	}
+}
+
+func getRetry(s State) func(context.Context, *Constraints) (*retryPolicy, error) {
+	return func(ctx context.Context, c *Constraints) (*retryPolicy, error) {
+		v, err := s.getValue(keyRetry)(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			r := v.(retryPolicy)
+			return &r, nil
+		}
+		return nil, nil
+	}
+}
+
 // Hostname returns a [StateOption] which sets the hostname used for containers created by [State.Run].
 // This is the equivalent of [State.Hostname]
 // See [State.With] for where to use this.
@@ -130,6 +130,12 @@ func Image(ref string, opts ...ImageOption) State {
 		addCap(&info.Constraints, pb.CapSourceImageLayerLimit)
 	}
 
+	if len(info.SignatureKeys) > 0 {
+		dt, _ := json.Marshal(info.SignatureKeys) // empty on error
+		attrs[pb.AttrImageSignatureKeys] = string(dt)
+		addCap(&info.Constraints, pb.CapSourceImageSignature)
+	}
+
 	src := NewSource("docker-image://"+ref, attrs, info.Constraints) // controversial
 	if err != nil {
 		src.err = err
@@ -196,6 +202,17 @@ var MarkImageInternal = imageOptionFunc(func(ii *ImageInfo) {
 	ii.RecordType = "internal"
 })
 
+// VerifyImageSignature requires the image manifest to have a cosign or
+// notation signature that can be verified with one of pubKeys before the
+// image is pulled. pubKeys are PEM encoded public keys for cosign signatures
+// or root certificates for notation signatures. Every call adds a separate
+// requirement that must be met.
+func VerifyImageSignature(pubKeys ...string) ImageOption {
+	return imageOptionFunc(func(ii *ImageInfo) {
+		ii.SignatureKeys = append(ii.SignatureKeys, pubKeys)
+	})
+}
+
 type ResolveMode int
 
 const (
@@ -228,6 +245,7 @@ type ImageInfo struct {
 	resolveMode   ResolveMode
 	layerLimit    *int
 	RecordType    string
+	SignatureKeys [][]string
 }
 
 const (
@@ -328,6 +346,31 @@ func Git(url, ref string, opts ...GitOption) State {
 		addCap(&gi.Constraints, pb.CapSourceGitChecksum)
 	}
 
+	if gi.Filter != "" {
+		attrs[pb.AttrGitFilter] = gi.Filter
+		addCap(&gi.Constraints, pb.CapSourceGitPartialClone)
+	}
+
+	if len(gi.SparsePaths) > 0 {
+		dt, _ := json.Marshal(gi.SparsePaths) // empty on error
+		attrs[pb.AttrGitSparsePaths] = string(dt)
+		addCap(&gi.Constraints, pb.CapSourceGitSparsePaths)
+	}
+
+	if gi.SignatureKeys != "" {
+		attrs[pb.AttrGitSignatureKeys] = gi.SignatureKeys
+		addCap(&gi.Constraints, pb.CapSourceGitSignature)
+	}
+	if gi.SignatureKeySecret != "" {
+		attrs[pb.AttrGitSignatureKeySecret] = gi.SignatureKeySecret
+		addCap(&gi.Constraints, pb.CapSourceGitSignature)
+	}
+
+	if gi.LFS {
+		attrs[pb.AttrGitLFS] = "true"
+		addCap(&gi.Constraints, pb.CapSourceGitLFS)
+	}
+
 	addCap(&gi.Constraints, pb.CapSourceGit)
 
 	source := NewSource("git://"+id, attrs, gi.Constraints)
@@ -352,6 +395,12 @@ type GitInfo struct {
 	KnownSSHHosts    string
 	MountSSHSock     string
 	Checksum         string
+	Filter           string
+	SparsePaths      []string
+	LFS              bool
+
+	SignatureKeys      string
+	SignatureKeySecret string
 }
 
 func KeepGitDir() GitOption {
@@ -386,6 +435,50 @@ func GitChecksum(v string) GitOption {
 	})
 }
 
+// GitFilter sets a partial clone filter, eg. "blob:none" or "tree:0", so
+// that only the objects needed for the checkout are fetched from the remote.
+// The filter does not change the contents of the checkout.
+func GitFilter(filter string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.Filter = filter
+	})
+}
+
+// GitSparsePaths limits the checkout to the given paths, relative to the
+// root of the repository. The paths are part of the cache key.
+func GitSparsePaths(paths ...string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SparsePaths = append(gi.SparsePaths, paths...)
+	})
+}
+
+// GitVerifySignature requires the commit, or the annotated tag, that the ref
+// resolves to to be signed by one of the public keys. pubKeys can contain
+// armored GPG public key blocks and SSH public keys in authorized_keys format.
+func GitVerifySignature(pubKeys string) GitOption {
+	pubKeys = strings.TrimSuffix(pubKeys, "\n")
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SignatureKeys = gi.SignatureKeys + pubKeys + "\n"
+	})
+}
+
+// GitVerifySignatureSecret is like [GitVerifySignature] but reads the public
+// keys from the secret with the given name.
+func GitVerifySignatureSecret(secretName string) GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.SignatureKeySecret = secretName
+	})
+}
+
+// GitLFS replaces the Git LFS pointer files in the checkout with the contents
+// of the LFS objects. The objects are downloaded with the same credentials as
+// the repository.
+func GitLFS() GitOption {
+	return gitOptionFunc(func(gi *GitInfo) {
+		gi.LFS = true
+	})
+}
+
 // AuthOption can be used with either HTTP or Git sources.
 type AuthOption interface {
 	GitOption
@@ -591,6 +684,72 @@ type OCILayoutInfo struct {
 	layerLimit *int
 }
 
+// OCIArtifact returns a state with the blobs of an OCI artifact in a registry.
+// Each blob is written to a file named after its title annotation, blobs
+// without a title are named after their digest. If the reference points to an
+// index, the manifest for the platform of the state is used.
+// Example:
+//
+//	st := llb.OCIArtifact("ghcr.io/example/models:v1", llb.OCIArtifactFiles("model.bin"))
+func OCIArtifact(ref string, opts ...OCIArtifactOption) State {
+	r, err := reference.ParseNormalizedNamed(ref)
+	if err == nil {
+		r = reference.TagNameOnly(r)
+		ref = r.String()
+	}
+	ai := &OCIArtifactInfo{}
+	for _, o := range opts {
+		o.SetOCIArtifactOption(ai)
+	}
+	attrs := map[string]string{}
+	if ai.ArtifactType != "" {
+		attrs[pb.AttrOCIArtifactType] = ai.ArtifactType
+	}
+	if len(ai.Files) > 0 {
+		dt, _ := json.Marshal(ai.Files) // empty on error
+		attrs[pb.AttrOCIArtifactFiles] = string(dt)
+	}
+
+	addCap(&ai.Constraints, pb.CapSourceOCIArtifact)
+
+	source := NewSource("oci-artifact://"+ref, attrs, ai.Constraints)
+	if err != nil {
+		source.err = err
+	}
+	return NewState(source.Output())
+}
+
+type OCIArtifactInfo struct {
+	constraintsWrapper
+	ArtifactType string
+	Files        []string
+}
+
+type OCIArtifactOption interface {
+	SetOCIArtifactOption(*OCIArtifactInfo)
+}
+
+type ociArtifactOptionFunc func(*OCIArtifactInfo)
+
+func (fn ociArtifactOptionFunc) SetOCIArtifactOption(ai *OCIArtifactInfo) {
+	fn(ai)
+}
+
+// OCIArtifactType requires the artifact to have the given artifact type.
+func OCIArtifactType(artifactType string) OCIArtifactOption {
+	return ociArtifactOptionFunc(func(ai *OCIArtifactInfo) {
+		ai.ArtifactType = artifactType
+	})
+}
+
+// OCIArtifactFiles selects the blobs of the artifact by file name. All blobs
+// are fetched by default.
+func OCIArtifactFiles(names ...string) OCIArtifactOption {
+	return ociArtifactOptionFunc(func(ai *OCIArtifactInfo) {
+		ai.Files = append(ai.Files, names...)
+	})
+}
+
 type DiffType string
 
 const (
@@ -629,6 +788,9 @@ func HTTP(url string, opts ...HTTPOption) State {
 	if hi.Checksum != "" {
 		attrs[pb.AttrHTTPChecksum] = hi.Checksum.String()
 		addCap(&hi.Constraints, pb.CapSourceHTTPChecksum)
+		if hi.Checksum.Algorithm() != digest.SHA256 {
+			addCap(&hi.Constraints, pb.CapSourceHTTPChecksumAlgorithms)
+		}
 	}
 	if hi.Filename != "" {
 		attrs[pb.AttrHTTPFilename] = hi.Filename
@@ -653,6 +815,23 @@ func HTTP(url string, opts ...HTTPOption) State {
 		hi.Header.setAttrs(attrs)
 		addCap(&hi.Constraints, pb.CapSourceHTTPHeader)
 	}
+	if len(hi.Mirrors) > 0 {
+		dt, _ := json.Marshal(hi.Mirrors) // empty on error
+		attrs[pb.AttrHTTPMirrors] = string(dt)
+		addCap(&hi.Constraints, pb.CapSourceHTTPMirrors)
+	}
+	if hi.SignatureURL != "" {
+		attrs[pb.AttrHTTPSignatureURL] = hi.SignatureURL
+		addCap(&hi.Constraints, pb.CapSourceHTTPSignature)
+	}
+	if hi.SignatureKeys != "" {
+		attrs[pb.AttrHTTPSignatureKeys] = hi.SignatureKeys
+		addCap(&hi.Constraints, pb.CapSourceHTTPSignature)
+	}
+	if hi.SignatureKeySecret != "" {
+		attrs[pb.AttrHTTPSignatureKeySecret] = hi.SignatureKeySecret
+		addCap(&hi.Constraints, pb.CapSourceHTTPSignature)
+	}
 
 	addCap(&hi.Constraints, pb.CapSourceHTTP)
 	source := NewSource(url, attrs, hi.Constraints)
@@ -668,6 +847,11 @@ type HTTPInfo struct {
 	GID              int
 	AuthHeaderSecret string
 	Header           *HTTPHeader
+	Mirrors          []string
+
+	SignatureURL       string
+	SignatureKeys      string
+	SignatureKeySecret string
 }
 
 type HTTPOption interface {
@@ -686,6 +870,44 @@ func Checksum(dgst digest.Digest) HTTPOption {
 	})
 }
 
+// Mirrors sets URLs that are tried in order if the download from the URL of
+// the source fails. Mirrors are expected to serve the same content, so they
+// are not part of the cache key. Mirrors require a Checksum.
+func Mirrors(urls ...string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.Mirrors = append(hi.Mirrors, urls...)
+	})
+}
+
+// SignatureURL sets the URL of a detached signature of the downloaded file.
+// The signature can be an armored or binary GPG signature, a minisign
+// signature or a base64 encoded signature created by `cosign sign-blob`. It is
+// verified against the keys set with [VerifySignature] and
+// [VerifySignatureSecret] before the file is stored.
+func SignatureURL(url string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.SignatureURL = url
+	})
+}
+
+// VerifySignature adds public keys that the signature set with [SignatureURL]
+// is verified against. pubKeys can contain armored GPG public key blocks, PEM
+// encoded public keys and minisign public keys.
+func VerifySignature(pubKeys string) HTTPOption {
+	pubKeys = strings.TrimSuffix(pubKeys, "\n")
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.SignatureKeys = hi.SignatureKeys + pubKeys + "\n"
+	})
+}
+
+// VerifySignatureSecret is like [VerifySignature] but reads the public keys
+// from the secret with the given name.
+func VerifySignatureSecret(secretName string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.SignatureKeySecret = secretName
+	})
+}
+
 func Chmod(perm os.FileMode) HTTPOption {
 	return httpOptionFunc(func(hi *HTTPInfo) {
 		hi.Perm = int(perm) & 0777
@@ -732,6 +954,87 @@ func (hh *HTTPHeader) attr(name string) string {
 	return pb.AttrHTTPHeaderPrefix + name
 }
 
+// S3 returns a state with a single file downloaded from an object in S3
+// compatible storage. The file is named after the base name of the key unless
+// [S3Filename] is set. Credentials are read from the AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN build secrets. The credentials
+// of the daemon are only used if the daemon is configured to allow it.
+func S3(bucket, key string, opts ...S3Option) State {
+	si := &S3Info{}
+	for _, o := range opts {
+		o.SetS3Option(si)
+	}
+	attrs := map[string]string{}
+	if si.Region != "" {
+		attrs[pb.AttrS3Region] = si.Region
+	}
+	if si.EndpointURL != "" {
+		attrs[pb.AttrS3EndpointURL] = si.EndpointURL
+	}
+	if si.UsePathStyle {
+		attrs[pb.AttrS3UsePathStyle] = "true"
+	}
+	if si.VersionID != "" {
+		attrs[pb.AttrS3VersionID] = si.VersionID
+	}
+	if si.Filename != "" {
+		attrs[pb.AttrS3Filename] = si.Filename
+	}
+
+	addCap(&si.Constraints, pb.CapSourceS3)
+	source := NewSource("s3://"+bucket+"/"+strings.TrimPrefix(key, "/"), attrs, si.Constraints)
+	return NewState(source.Output())
+}
+
+type S3Info struct {
+	constraintsWrapper
+	Region       string
+	EndpointURL  string
+	UsePathStyle bool
+	VersionID    string
+	Filename     string
+}
+
+type S3Option interface {
+	SetS3Option(*S3Info)
+}
+
+type s3OptionFunc func(*S3Info)
+
+func (fn s3OptionFunc) SetS3Option(si *S3Info) {
+	fn(si)
+}
+
+// S3Region sets the region of the bucket. The region of the daemon
+// environment is used by default.
+func S3Region(region string) S3Option {
+	return s3OptionFunc(func(si *S3Info) {
+		si.Region = region
+	})
+}
+
+// S3Endpoint sets the URL of an S3 compatible storage, eg. a MinIO server.
+// Buckets are addressed in the path of the URL if usePathStyle is set.
+func S3Endpoint(url string, usePathStyle bool) S3Option {
+	return s3OptionFunc(func(si *S3Info) {
+		si.EndpointURL = url
+		si.UsePathStyle = usePathStyle
+	})
+}
+
+// S3VersionID pins the version of the object in a versioned bucket.
+func S3VersionID(versionID string) S3Option {
+	return s3OptionFunc(func(si *S3Info) {
+		si.VersionID = versionID
+	})
+}
+
+func S3Filename(name string) S3Option {
+	return s3OptionFunc(func(si *S3Info) {
+		si.Filename = name
+	})
+}
+
 func platformSpecificSource(id string) bool {
 	return strings.HasPrefix(id, "docker-image://") || strings.HasPrefix(id, "oci-layout://")
 }
@@ -37,11 +37,24 @@ type MetaResponse struct {
 
 type ResolveImageOpt struct {
 	ResolveMode string
+	// ResolveAttestations also resolves the attestations of the image, from
+	// the image index or the referrers of the platform manifest.
+	ResolveAttestations bool
 }
 
 type ResolveImageResponse struct {
-	Digest digest.Digest
-	Config []byte
+	Digest       digest.Digest
+	Config       []byte
+	Attestations []ImageAttestation
+}
+
+// ImageAttestation is an attestation layer of an attestation manifest of the
+// resolved platform manifest.
+type ImageAttestation struct {
+	Manifest      digest.Digest
+	PredicateType string
+	MediaType     string
+	Data          []byte
 }
 
 type ResolveOCILayoutOpt struct {
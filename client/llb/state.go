@@ -530,6 +530,8 @@ type ConstraintsOpt interface {
 	ImageOption
 	GitOption
 	OCILayoutOption
+	OCIArtifactOption
+	S3Option
 }
 
 type constraintsOptFunc func(m *Constraints)
@@ -554,6 +556,14 @@ func (fn constraintsOptFunc) SetHTTPOption(hi *HTTPInfo) {
 	hi.applyConstraints(fn)
 }
 
+func (fn constraintsOptFunc) SetS3Option(si *S3Info) {
+	si.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetOCIArtifactOption(ai *OCIArtifactInfo) {
+	ai.applyConstraints(fn)
+}
+
 func (fn constraintsOptFunc) SetImageOption(ii *ImageInfo) {
 	ii.applyConstraints(fn)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	controlapi "github.com/moby/buildkit/api/services/control"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
 
@@ -21,6 +22,8 @@ func (c *Client) Prune(ctx context.Context, ch chan UsageInfo, opts ...PruneOpti
 		ReservedSpace: info.ReservedSpace,
 		MaxUsedSpace:  info.MaxUsedSpace,
 		MinFreeSpace:  info.MinFreeSpace,
+		DryRun:        info.DryRun,
+		HistoryAge:    int64(info.HistoryAge),
 	}
 	if info.All {
 		req.All = true
@@ -74,6 +77,23 @@ type PruneInfo struct {
 	ReservedSpace int64 `json:"reservedSpace"`
 	MaxUsedSpace  int64 `json:"maxUsedSpace"`
 	MinFreeSpace  int64 `json:"minFreeSpace"`
+
+	// CacheMountIDs limits the policy to the contents of the cache mounts
+	// with the IDs. The space limits then apply to the size of these cache
+	// mounts instead of the whole cache.
+	CacheMountIDs []string `json:"cacheMountIDs,omitempty"`
+
+	// DryRun reports the records that would be removed without removing
+	// them.
+	DryRun bool `json:"-"`
+
+	// HistoryAge limits the prune to records whose data is only referenced by
+	// build history records older than HistoryAge.
+	HistoryAge time.Duration `json:"-"`
+	// HistoryRefs is set by the daemon when HistoryAge is set. It reports if
+	// the layer chain of a record is only referenced by old build history
+	// records.
+	HistoryRefs func(layers []digest.Digest) bool `json:"-"`
 }
 
 type pruneOptionFunc func(*PruneInfo)
@@ -86,6 +106,20 @@ var PruneAll = pruneOptionFunc(func(pi *PruneInfo) {
 	pi.All = true
 })
 
+// PruneDryRun makes the prune report the records that would be removed
+// without removing them.
+var PruneDryRun = pruneOptionFunc(func(pi *PruneInfo) {
+	pi.DryRun = true
+})
+
+// WithHistoryAge limits the prune to records that are only referenced by
+// build history records older than age.
+func WithHistoryAge(age time.Duration) PruneOption {
+	return pruneOptionFunc(func(pi *PruneInfo) {
+		pi.HistoryAge = age
+	})
+}
+
 func WithKeepOpt(duration time.Duration, reserved int64, max int64, free int64) PruneOption {
 	return pruneOptionFunc(func(pi *PruneInfo) {
 		pi.KeepDuration = duration
@@ -25,6 +25,7 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/bklog"
+	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 	"github.com/tonistiigi/fsutil"
@@ -52,6 +53,22 @@ type SolveOpt struct {
 	Internal              bool
 	SourcePolicy          *spb.Policy
 	Ref                   string
+	// Plan resolves the cache keys of the build without executing it. The
+	// planned status of the vertexes is returned in SolveResponse.Plan.
+	Plan bool
+	// CacheOnly fails the build if any operation would need to be executed
+	// or a source would need to be resolved from the network.
+	CacheOnly bool
+	// KeepGoing continues building the steps that do not depend on a failed
+	// step and returns the errors of all the failed steps.
+	KeepGoing bool
+	// Weight is the share of the worker parallelism the build gets when it
+	// runs alongside other builds. Zero uses the default of the daemon. The
+	// daemon limits it to the highest weight in its scheduler configuration.
+	Weight int
+	// SchedulerLabels choose the default weight of the build from the
+	// scheduler configuration of the daemon.
+	SchedulerLabels map[string]string
 }
 
 type ExportEntry struct {
@@ -290,6 +307,11 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			Entitlements:            slices.Clone(opt.AllowedEntitlements),
 			Internal:                opt.Internal,
 			SourcePolicy:            opt.SourcePolicy,
+			Plan:                    opt.Plan,
+			CacheOnly:               opt.CacheOnly,
+			KeepGoing:               opt.KeepGoing,
+			Weight:                  int64(opt.Weight),
+			SchedulerLabels:         opt.SchedulerLabels,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to solve")
@@ -297,6 +319,13 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 		res = &SolveResponse{
 			ExporterResponse: resp.ExporterResponse,
 		}
+		for _, p := range resp.Plan {
+			res.Plan = append(res.Plan, &VertexPlan{
+				Digest: digest.Digest(p.Digest),
+				Name:   p.Name,
+				Status: PlanStatus(p.Status),
+			})
+		}
 		return nil
 	})
 
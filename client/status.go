@@ -60,6 +60,16 @@ func NewSolveStatus(resp *controlapi.StatusResponse) *SolveStatus {
 			Range:      v.Ranges,
 		})
 	}
+	for _, v := range resp.Events {
+		s.Events = append(s.Events, &VertexEvent{
+			Vertex:    digest.Digest(v.Vertex),
+			Type:      v.Type,
+			Message:   v.Message,
+			Attempt:   int(v.Attempt),
+			Position:  int(v.Position),
+			Timestamp: v.Timestamp.AsTime(),
+		})
+	}
 	return s
 }
 
@@ -120,6 +130,16 @@ func (ss *SolveStatus) Marshal() (out []*controlapi.StatusResponse) {
 				Url:    v.URL,
 			})
 		}
+		for _, v := range ss.Events {
+			sr.Events = append(sr.Events, &controlapi.VertexEvent{
+				Vertex:    string(v.Vertex),
+				Type:      v.Type,
+				Message:   v.Message,
+				Attempt:   int64(v.Attempt),
+				Position:  int64(v.Position),
+				Timestamp: timestamppb.New(v.Timestamp),
+			})
+		}
 		out = append(out, &sr)
 		if !retry {
 			break
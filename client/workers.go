@@ -70,6 +70,7 @@ func fromAPIGCPolicy(in []*apitypes.GCPolicy) []PruneInfo {
 			ReservedSpace: p.ReservedSpace,
 			MaxUsedSpace:  p.MaxUsedSpace,
 			MinFreeSpace:  p.MinFreeSpace,
+			CacheMountIDs: p.CacheMountIDs,
 		})
 	}
 	return out
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/containerd/continuity"
@@ -23,6 +24,7 @@ import (
 	"github.com/moby/buildkit/session/auth/authprovider"
 	"github.com/moby/buildkit/session/sshforward/sshprovider"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy/lockfile"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/progress/progresswriter"
@@ -103,6 +105,14 @@ var buildCommand = cli.Command{
 			Name:  "source-policy-file",
 			Usage: "Read source policy file from a JSON file",
 		},
+		cli.StringFlag{
+			Name:  "source-lockfile",
+			Usage: "Pin the sources of the build to the versions in a lockfile written by --source-lockfile-output",
+		},
+		cli.StringFlag{
+			Name:  "source-lockfile-output",
+			Usage: "Write the versions of the sources resolved by the build to a lockfile",
+		},
 		cli.StringFlag{
 			Name:  "ref-file",
 			Usage: "Write build ref to a file",
@@ -115,6 +125,26 @@ var buildCommand = cli.Command{
 			Name:  "debug-json-cache-metrics",
 			Usage: "Where to output json cache metrics, use 'stdout' or 'stderr' for standard (error) output.",
 		},
+		cli.BoolFlag{
+			Name:  "plan",
+			Usage: "Resolve the build cache without building and print the steps that would run",
+		},
+		cli.BoolFlag{
+			Name:  "cache-only",
+			Usage: "Fail instead of running steps that can not be loaded from the cache or accessing the network",
+		},
+		cli.BoolFlag{
+			Name:  "keep-going",
+			Usage: "Continue building the steps that do not depend on a failed step",
+		},
+		cli.IntFlag{
+			Name:  "weight",
+			Usage: "Share of the builder parallelism relative to other builds, and priority in the build queue",
+		},
+		cli.StringSliceFlag{
+			Name:  "scheduler-label",
+			Usage: "Label matched against the scheduler configuration of the daemon, e.g. team=infra",
+		},
 	},
 }
 
@@ -243,6 +273,19 @@ func buildAction(clicontext *cli.Context) error {
 		}
 		srcPol = &srcPolStruct
 	}
+	if lockFile := clicontext.String("source-lockfile"); lockFile != "" {
+		pol, err := readSourceLockfile(lockFile)
+		if err != nil {
+			return err
+		}
+		if srcPol == nil {
+			srcPol = pol
+		} else {
+			// rules of the lockfile are evaluated after the rules of the
+			// policy file so that they pin the converted sources
+			srcPol.Rules = append(srcPol.Rules, pol.Rules...)
+		}
+	}
 
 	eg, ctx := errgroup.WithContext(bccommon.CommandContext(clicontext))
 
@@ -260,6 +303,15 @@ func buildAction(clicontext *cli.Context) error {
 		AllowedEntitlements: clicontext.StringSlice("allow"),
 		SourcePolicy:        srcPol,
 		Ref:                 ref,
+		Plan:                clicontext.Bool("plan"),
+		CacheOnly:           clicontext.Bool("cache-only"),
+		KeepGoing:           clicontext.Bool("keep-going"),
+		Weight:              clicontext.Int("weight"),
+	}
+
+	solveOpt.SchedulerLabels, err = build.ParseSchedulerLabels(clicontext.StringSlice("scheduler-label"))
+	if err != nil {
+		return errors.Wrap(err, "invalid scheduler-label")
 	}
 
 	solveOpt.FrontendAttrs, err = build.ParseOpt(clicontext.StringSlice("opt"))
@@ -351,6 +403,7 @@ func buildAction(clicontext *cli.Context) error {
 	}
 
 	var subMetadata map[string][]byte
+	var plan []*client.VertexPlan
 
 	eg.Go(func() error {
 		defer func() {
@@ -397,6 +450,7 @@ func buildAction(clicontext *cli.Context) error {
 		for k, v := range resp.ExporterResponse {
 			bklog.G(ctx).Debugf("exporter response: %s=%s", k, v)
 		}
+		plan = resp.Plan
 
 		metadataFile := clicontext.String("metadata-file")
 		if metadataFile != "" && resp.ExporterResponse != nil {
@@ -405,6 +459,12 @@ func buildAction(clicontext *cli.Context) error {
 			}
 		}
 
+		if lockFile := clicontext.String("source-lockfile-output"); lockFile != "" {
+			if err := writeSourceLockfile(lockFile, resp.ExporterResponse); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 
@@ -427,11 +487,32 @@ func buildAction(clicontext *cli.Context) error {
 		}
 	}
 
+	if solveOpt.Plan {
+		if err := printPlan(os.Stdout, plan); err != nil {
+			return err
+		}
+	}
+
 	meg.Wait()
 
 	return nil
 }
 
+func printPlan(w io.Writer, plan []*client.VertexPlan) error {
+	counts := map[client.PlanStatus]int{}
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "STATUS\tDIGEST\tNAME")
+	for _, p := range plan {
+		counts[p.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Status, p.Digest, p.Name)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d cached, %d would run, %d unknown until input\n", counts[client.PlanCached], counts[client.PlanWouldRun], counts[client.PlanUnknownUntilInput])
+	return err
+}
+
 func writeMetadataFile(filename string, exporterResponse map[string]string) error {
 	out := make(map[string]any)
 	for k, v := range exporterResponse {
@@ -453,3 +534,33 @@ func writeMetadataFile(filename string, exporterResponse map[string]string) erro
 	}
 	return continuity.AtomicWriteFile(filename, b, 0666)
 }
+
+func readSourceLockfile(filename string) (*spb.Policy, error) {
+	dt, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	l, err := lockfile.Parse(dt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read source lockfile %q", filename)
+	}
+	return l.Policy()
+}
+
+func writeSourceLockfile(filename string, exporterResponse map[string]string) error {
+	l := lockfile.New()
+	if v, ok := exporterResponse[lockfile.ExporterResponseKey]; ok {
+		dt, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return errors.Wrap(err, "invalid source lockfile in build response")
+		}
+		if l, err = lockfile.Parse(dt); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return continuity.AtomicWriteFile(filename, b, 0666)
+}
@@ -0,0 +1,10 @@
+package build
+
+// ParseSchedulerLabels parses the key=value labels matched against the
+// scheduler configuration of the daemon.
+func ParseSchedulerLabels(labels []string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return attrMap(labels)
+}
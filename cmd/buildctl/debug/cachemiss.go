@@ -0,0 +1,76 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var CacheMissCommand = cli.Command{
+	Name:      "cache-miss",
+	Usage:     "explain why steps of a build did not reuse the cache of a previous build",
+	ArgsUsage: "<base-ref> <ref>",
+	Action:    cacheMiss,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func cacheMiss(clicontext *cli.Context) error {
+	args := clicontext.Args()
+	if len(args) != 2 {
+		return errors.Errorf("base build ref and build ref must be specified")
+	}
+
+	c, err := bccommon.ResolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	ctx := appcontext.Context()
+	resp, err := c.ControlClient().ExplainCacheMiss(ctx, &controlapi.ExplainCacheMissRequest{
+		BaseRef: args[0],
+		Ref:     args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := bccommon.ParseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.App.Writer, resp); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.App.Writer, "\n")
+		return err
+	}
+	return printCacheMissTable(clicontext.App.Writer, resp.Vertexes)
+}
+
+func printCacheMissTable(w io.Writer, vtxs []*controlapi.CacheMissVertex) error {
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "STEP\tREASON\tKEY\tOLD\tNEW")
+	for _, v := range vtxs {
+		name := v.Name
+		if name == "" {
+			name = v.Digest
+		}
+		for _, r := range v.Reasons {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, r.Type, r.Key, r.Old, r.New)
+			name = ""
+		}
+	}
+	return tw.Flush()
+}
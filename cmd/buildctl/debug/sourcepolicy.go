@@ -0,0 +1,136 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/containerd/platforms"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var SourcePolicyCommand = cli.Command{
+	Name:  "source-policy",
+	Usage: "source policy utilities",
+	Subcommands: []cli.Command{
+		sourcePolicyEvalCommand,
+	},
+}
+
+var sourcePolicyEvalCommand = cli.Command{
+	Name:      "eval",
+	Usage:     "evaluate a source policy file against a source identifier without building",
+	ArgsUsage: "<policy.json> <identifier>",
+	Action:    sourcePolicyEval,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "attr",
+			Usage: "Attribute of the source, e.g. http.checksum=sha256:...",
+		},
+		cli.StringFlag{
+			Name:  "platform",
+			Usage: "Platform that the source is requested for, e.g. linux/arm64",
+		},
+		cli.StringSliceFlag{
+			Name:  "opt",
+			Usage: "Frontend attr of the build, e.g. target=release",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Format the output using the given Go template, e.g, '{{json .}}'",
+		},
+	},
+}
+
+func sourcePolicyEval(clicontext *cli.Context) error {
+	args := clicontext.Args()
+	if len(args) != 2 {
+		return errors.Errorf("policy file and source identifier must be specified")
+	}
+
+	dt, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var pol spb.Policy
+	if err := json.Unmarshal(dt, &pol); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal source policy file %q", args[0])
+	}
+
+	op := &pb.SourceOp{Identifier: args[1]}
+	if attrs := clicontext.StringSlice("attr"); len(attrs) > 0 {
+		op.Attrs, err = parseKeyValues("attr", attrs)
+		if err != nil {
+			return err
+		}
+	}
+	frontendOpt, err := parseKeyValues("opt", clicontext.StringSlice("opt"))
+	if err != nil {
+		return err
+	}
+
+	ctx := sourcepolicy.WithFrontendAttrs(appcontext.Context(), frontendOpt)
+	if v := clicontext.String("platform"); v != "" {
+		p, err := platforms.Parse(v)
+		if err != nil {
+			return err
+		}
+		ctx = sourcepolicy.WithPlatform(ctx, &pb.Platform{OS: p.OS, Architecture: p.Architecture, Variant: p.Variant})
+	}
+
+	var tr *sourcepolicy.Trace
+	e := sourcepolicy.NewEngine([]*spb.Policy{&pol})
+	e.SetTracer(func(t *sourcepolicy.Trace) {
+		tr = t
+	})
+	_, evalErr := e.Evaluate(ctx, op)
+	if tr == nil {
+		return evalErr
+	}
+
+	if format := clicontext.String("format"); format != "" {
+		tmpl, err := bccommon.ParseTemplate(format)
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(clicontext.App.Writer, tr); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(clicontext.App.Writer, "\n")
+		return err
+	}
+
+	w := clicontext.App.Writer
+	for _, s := range tr.Steps {
+		fmt.Fprintln(w, s.String())
+	}
+	if evalErr != nil {
+		return evalErr
+	}
+	fmt.Fprintln(w, tr.Result)
+	for _, k := range slices.Sorted(maps.Keys(op.Attrs)) {
+		fmt.Fprintf(w, "%s=%s\n", k, op.Attrs[k])
+	}
+	return nil
+}
+
+func parseKeyValues(name string, values []string) (map[string]string, error) {
+	m := make(map[string]string, len(values))
+	for _, kv := range values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid %s %q, expected key=value", name, kv)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
@@ -106,6 +106,9 @@ func printWorkersVerbose(tw *tabwriter.Writer, winfo []*client.WorkerInfo) {
 			if len(rule.Filter) > 0 {
 				fmt.Fprintf(tw, "\tFilters:\t%s\n", strings.Join(rule.Filter, " "))
 			}
+			if len(rule.CacheMountIDs) > 0 {
+				fmt.Fprintf(tw, "\tCache mount IDs:\t%s\n", strings.Join(rule.CacheMountIDs, " "))
+			}
 			if rule.KeepDuration > 0 {
 				fmt.Fprintf(tw, "\tKeep duration:\t%v\n", rule.KeepDuration.String())
 			}
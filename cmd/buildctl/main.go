@@ -146,6 +146,14 @@ func handleErr(debug bool, err error) {
 	for _, s := range errdefs.Sources(err) {
 		s.Print(os.Stderr)
 	}
+	var fe *errdefs.FailuresError
+	if errors.As(err, &fe) {
+		for _, f := range fe.Items {
+			for _, s := range f.Sources {
+				s.Print(os.Stderr)
+			}
+		}
+	}
 	if debug {
 		fmt.Fprintf(os.Stderr, "error: %+v", stack.Formatter(err))
 	} else {
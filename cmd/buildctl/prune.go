@@ -41,6 +41,14 @@ var pruneCommand = cli.Command{
 			Name:  "all",
 			Usage: "Include internal/frontend references",
 		},
+		cli.DurationFlag{
+			Name:  "history-age",
+			Usage: "Only prune data referenced by build history records older than this limit",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show the data that would be pruned without removing it",
+		},
 		cli.BoolFlag{
 			Name:  "verbose, v",
 			Usage: "Verbose output",
@@ -75,6 +83,13 @@ func prune(clicontext *cli.Context) error {
 	if clicontext.Bool("all") {
 		opts = append(opts, client.PruneAll)
 	}
+	if age := clicontext.Duration("history-age"); age > 0 {
+		opts = append(opts, client.WithHistoryAge(age))
+	}
+	dryRun := clicontext.Bool("dry-run")
+	if dryRun {
+		opts = append(opts, client.PruneDryRun)
+	}
 
 	if format := clicontext.String("format"); format != "" {
 		if clicontext.Bool("verbose") {
@@ -118,7 +133,11 @@ func prune(clicontext *cli.Context) error {
 		}()
 		summarizer = func() {
 			tw = tabwriter.NewWriter(os.Stdout, 1, 8, 1, '\t', 0)
-			fmt.Fprintf(tw, "Total:\t%.2f\n", units.Bytes(total))
+			if dryRun {
+				fmt.Fprintf(tw, "Total reclaimable:\t%.2f\n", units.Bytes(total))
+			} else {
+				fmt.Fprintf(tw, "Total:\t%.2f\n", units.Bytes(total))
+			}
 			tw.Flush()
 		}
 	}
@@ -36,6 +36,14 @@ type Config struct {
 
 	History *HistoryConfig `toml:"history"`
 
+	Scheduler *SchedulerConfig `toml:"scheduler"`
+
+	Git *GitConfig `toml:"git"`
+
+	S3 *S3Config `toml:"s3"`
+
+	SourcePolicy *SourcePolicyConfig `toml:"sourcePolicy"`
+
 	Frontends struct {
 		Dockerfile DockerfileFrontendConfig `toml:"dockerfile.v0"`
 		Gateway    GatewayFrontendConfig    `toml:"gateway.v0"`
@@ -189,6 +197,11 @@ type GCPolicy struct {
 	// MinFreeSpace is the target amount of free disk space the garbage collector will attempt to leave.
 	// However, it will never let the available space fall below ReservedSpace.
 	MinFreeSpace DiskSpace `toml:"minFreeSpace"`
+
+	// CacheMountIDs limits the policy to the contents of the cache mounts
+	// with the IDs. ReservedSpace, MaxUsedSpace and MinFreeSpace then apply
+	// to the size of these cache mounts only.
+	CacheMountIDs []string `toml:"cacheMountIDs"`
 }
 
 type DNSConfig struct {
@@ -202,6 +215,52 @@ type HistoryConfig struct {
 	MaxEntries int64    `toml:"maxEntries"`
 }
 
+type SchedulerConfig struct {
+	// MaxConcurrentBuilds is the maximum number of builds that run at the
+	// same time. Other builds wait in the queue. Zero means no limit.
+	MaxConcurrentBuilds int `toml:"maxConcurrentBuilds"`
+	// MaxQueuedBuilds is the maximum number of builds waiting in the queue.
+	// Builds over the limit fail immediately. Zero means no limit.
+	MaxQueuedBuilds int `toml:"maxQueuedBuilds"`
+	// DefaultWeight is the weight of builds that don't set a weight and
+	// don't match any of the Weights. Defaults to 1.
+	DefaultWeight int               `toml:"defaultWeight"`
+	Weights       []SchedulerWeight `toml:"weights"`
+}
+
+// SchedulerWeight sets the default weight of builds whose scheduler labels
+// contain all the Labels.
+type SchedulerWeight struct {
+	Labels map[string]string `toml:"labels"`
+	Weight int               `toml:"weight"`
+}
+
+type GitConfig struct {
+	// ObjectStoreAliases maps aliases to patterns of remote URLs. Remotes
+	// with the same alias share a git object store. Other remotes share an
+	// object store by their root commit if their full history is fetched
+	// first, or by their URL if they are cloned shallow first.
+	ObjectStoreAliases map[string][]string `toml:"objectStoreAliases"`
+}
+
+type S3Config struct {
+	// AllowDaemonCredentials makes S3 sources that don't get credentials from
+	// the build secrets of the client use the credentials of the daemon
+	// environment.
+	AllowDaemonCredentials bool `toml:"allowDaemonCredentials"`
+	// DaemonCredentialsEndpoints are the custom endpoint URLs that sources
+	// may use together with the credentials of the daemon environment.
+	DaemonCredentialsEndpoints []string `toml:"daemonCredentialsEndpoints"`
+}
+
+type SourcePolicyConfig struct {
+	// Files are paths to source policies that are evaluated for every build
+	// before the source policy of the client. A source that is denied by one
+	// of them can't be allowed by the client. Files are reloaded when they
+	// change.
+	Files []string `toml:"files"`
+}
+
 type DockerfileFrontendConfig struct {
 	Enabled *bool `toml:"enabled"`
 }
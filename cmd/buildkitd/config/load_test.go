@@ -61,6 +61,10 @@ keepDuration="24h"
 reservedSpace="10GB"
 maxUsedSpace="80%"
 minFreeSpace="10%"
+[[worker.containerd.gcpolicy]]
+cacheMountIDs=["go-build", "npm"]
+maxUsedSpace="1GB"
+keepDuration="24h"
 
 [registry."docker.io"]
 mirrors=["hub.docker.io"]
@@ -76,6 +80,23 @@ cert="cert.pem"
 nameservers=["1.1.1.1","8.8.8.8"]
 options=["edns0"]
 searchDomains=["example.com"]
+
+[scheduler]
+maxConcurrentBuilds=4
+maxQueuedBuilds=16
+defaultWeight=2
+[[scheduler.weights]]
+labels={ team="release" }
+weight=8
+
+[git.objectStoreAliases]
+buildkit=["https://github.com/*/buildkit.git"]
+
+[s3]
+allowDaemonCredentials=true
+
+[sourcePolicy]
+files=["/etc/buildkit/policy.json"]
 `
 
 	cfg, err := Load(bytes.NewBuffer([]byte(testConfig)))
@@ -114,7 +135,7 @@ searchDomains=["example.com"]
 	require.Equal(t, "exotic", cfg.Workers.Containerd.Runtime.Name)
 	require.Equal(t, "/usr/bin/exotic", cfg.Workers.Containerd.Runtime.Path)
 	require.Equal(t, "bar", cfg.Workers.Containerd.Runtime.Options["foo"])
-	require.Equal(t, 4, len(cfg.Workers.Containerd.GCPolicy))
+	require.Equal(t, 5, len(cfg.Workers.Containerd.GCPolicy))
 
 	require.Nil(t, cfg.Workers.Containerd.GC)
 	require.Equal(t, true, cfg.Workers.Containerd.GCPolicy[0].All)
@@ -136,6 +157,10 @@ searchDomains=["example.com"]
 	require.Equal(t, int64(80), cfg.Workers.Containerd.GCPolicy[3].MaxUsedSpace.Percentage)
 	require.Equal(t, int64(10), cfg.Workers.Containerd.GCPolicy[3].MinFreeSpace.Percentage)
 
+	require.Equal(t, []string{"go-build", "npm"}, cfg.Workers.Containerd.GCPolicy[4].CacheMountIDs)
+	require.Equal(t, int64(1024*1024*1024), cfg.Workers.Containerd.GCPolicy[4].MaxUsedSpace.Bytes)
+	require.Equal(t, time.Duration(86400), cfg.Workers.Containerd.GCPolicy[4].KeepDuration.Duration/time.Second)
+
 	require.Equal(t, true, *cfg.Registries["docker.io"].PlainHTTP)
 	require.Equal(t, true, *cfg.Registries["docker.io"].Insecure)
 	require.Equal(t, "hub.docker.io", cfg.Registries["docker.io"].Mirrors[0])
@@ -148,4 +173,19 @@ searchDomains=["example.com"]
 	require.Equal(t, []string{"1.1.1.1", "8.8.8.8"}, cfg.DNS.Nameservers)
 	require.Equal(t, []string{"example.com"}, cfg.DNS.SearchDomains)
 	require.Equal(t, []string{"edns0"}, cfg.DNS.Options)
+
+	require.NotNil(t, cfg.Scheduler)
+	require.Equal(t, 4, cfg.Scheduler.MaxConcurrentBuilds)
+	require.Equal(t, 16, cfg.Scheduler.MaxQueuedBuilds)
+	require.Equal(t, 2, cfg.Scheduler.DefaultWeight)
+	require.Equal(t, []SchedulerWeight{{Labels: map[string]string{"team": "release"}, Weight: 8}}, cfg.Scheduler.Weights)
+
+	require.NotNil(t, cfg.Git)
+	require.Equal(t, map[string][]string{"buildkit": {"https://github.com/*/buildkit.git"}}, cfg.Git.ObjectStoreAliases)
+
+	require.NotNil(t, cfg.S3)
+	require.True(t, cfg.S3.AllowDaemonCredentials)
+
+	require.NotNil(t, cfg.SourcePolicy)
+	require.Equal(t, []string{"/etc/buildkit/policy.json"}, cfg.SourcePolicy.Files)
 }
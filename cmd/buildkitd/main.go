@@ -856,8 +856,11 @@ func newController(ctx context.Context, c *cli.Context, cfg *config.Config) (*co
 		LeaseManager:              w.LeaseManager(),
 		ContentStore:              w.ContentStore(),
 		HistoryConfig:             cfg.History,
+		SchedulerConfig:           cfg.Scheduler,
+		SourcePolicyConfig:        cfg.SourcePolicy,
 		GarbageCollect:            w.GarbageCollect,
 		GracefulStop:              ctx.Done(),
+		Root:                      cfg.Root,
 	})
 }
 
@@ -948,6 +951,7 @@ func getGCPolicy(cfg config.GCConfig, root string) []client.PruneInfo {
 			ReservedSpace: rule.ReservedSpace.AsBytes(dstat),
 			MaxUsedSpace:  rule.MaxUsedSpace.AsBytes(dstat),
 			MinFreeSpace:  rule.MinFreeSpace.AsBytes(dstat),
+			CacheMountIDs: rule.CacheMountIDs,
 		})
 	}
 	return out
@@ -14,6 +14,7 @@ import (
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/disk"
+	"github.com/moby/buildkit/util/fairsem"
 	"github.com/moby/buildkit/util/network/cniprovider"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/worker"
@@ -299,9 +300,13 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 		},
 	}
 
-	var parallelismSem *semaphore.Weighted
+	var (
+		parallelismSem  *semaphore.Weighted
+		fairParallelism *fairsem.Semaphore
+	)
 	if cfg.MaxParallelism > 0 {
 		parallelismSem = semaphore.NewWeighted(int64(cfg.MaxParallelism))
+		fairParallelism = fairsem.New(int64(cfg.MaxParallelism))
 	}
 
 	snapshotter := defaults.DefaultSnapshotter
@@ -342,6 +347,7 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 		ApparmorProfile: common.config.Workers.Containerd.ApparmorProfile,
 		Selinux:         common.config.Workers.Containerd.SELinux,
 		ParallelismSem:  parallelismSem,
+		FairParallelism: fairParallelism,
 		TraceSocket:     common.traceSocket,
 		Runtime:         runtime,
 		CDIManager:      cdiManager,
@@ -354,6 +360,13 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
 	opt.BuildkitVersion = getBuildkitVersion()
 	opt.RegistryHosts = resolverFunc(common.config)
+	if cfg := common.config.Git; cfg != nil {
+		opt.GitObjectStoreAliases = cfg.ObjectStoreAliases
+	}
+	if cfg := common.config.S3; cfg != nil {
+		opt.S3AllowDaemonCredentials = cfg.AllowDaemonCredentials
+		opt.S3DaemonCredentialsEndpoints = cfg.DaemonCredentialsEndpoints
+	}
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
 		platforms, err := parsePlatforms(platformsStr)
@@ -34,6 +34,7 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/disk"
+	"github.com/moby/buildkit/util/fairsem"
 	"github.com/moby/buildkit/util/network/cniprovider"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/resolver"
@@ -314,18 +315,30 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 		},
 	}
 
-	var parallelismSem *semaphore.Weighted
+	var (
+		parallelismSem  *semaphore.Weighted
+		fairParallelism *fairsem.Semaphore
+	)
 	if cfg.MaxParallelism > 0 {
 		parallelismSem = semaphore.NewWeighted(int64(cfg.MaxParallelism))
+		fairParallelism = fairsem.New(int64(cfg.MaxParallelism))
 	}
 
 	opt, err := runc.NewWorkerOpt(common.config.Root, snFactory, cfg.Rootless, processMode, cfg.Labels, idmapping, nc, dns, cfg.Binary, cfg.ApparmorProfile, cfg.SELinux, parallelismSem, common.traceSocket, cfg.DefaultCgroupParent, cdiManager)
 	if err != nil {
 		return nil, err
 	}
+	opt.FairParallelism = fairParallelism
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
 	opt.BuildkitVersion = getBuildkitVersion()
 	opt.RegistryHosts = hosts
+	if cfg := common.config.Git; cfg != nil {
+		opt.GitObjectStoreAliases = cfg.ObjectStoreAliases
+	}
+	if cfg := common.config.S3; cfg != nil {
+		opt.S3AllowDaemonCredentials = cfg.AllowDaemonCredentials
+		opt.S3DaemonCredentialsEndpoints = cfg.DaemonCredentialsEndpoints
+	}
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
 		platforms, err := parsePlatforms(platformsStr)
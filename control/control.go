@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime/trace"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/plugins/services/content/contentserver"
 	"github.com/distribution/reference"
+	"github.com/docker/go-units"
 	"github.com/hashicorp/go-multierror"
 	"github.com/mitchellh/hashstructure/v2"
 	controlapi "github.com/moby/buildkit/api/services/control"
@@ -33,12 +35,14 @@ import (
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/bboltcachestorage"
 	"github.com/moby/buildkit/solver/llbsolver"
+	"github.com/moby/buildkit/solver/llbsolver/cachemiss"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
 	"github.com/moby/buildkit/solver/llbsolver/proc"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/db"
+	"github.com/moby/buildkit/util/disk"
 	"github.com/moby/buildkit/util/entitlements"
 	"github.com/moby/buildkit/util/imageutil"
 	"github.com/moby/buildkit/util/leaseutil"
@@ -72,8 +76,13 @@ type Opt struct {
 	LeaseManager              *leaseutil.Manager
 	ContentStore              *containerdsnapshot.Store
 	HistoryConfig             *config.HistoryConfig
+	SchedulerConfig           *config.SchedulerConfig
+	SourcePolicyConfig        *config.SourcePolicyConfig
 	GarbageCollect            func(context.Context) error
 	GracefulStop              <-chan struct{}
+	// Root is the state directory of the daemon. GC runs when the free space
+	// of its filesystem drops below the minFreeSpace of the GC policies.
+	Root string
 }
 
 type Controller struct { // TODO: ControlService
@@ -87,6 +96,10 @@ type Controller struct { // TODO: ControlService
 	throttledGC                  func()
 	throttledReleaseUnreferenced func()
 	gcmu                         sync.Mutex
+	stopDiskWatcher              context.CancelFunc
+	// nextPressureGC delays GC under disk pressure after a run that could
+	// not reclaim any space
+	nextPressureGC time.Time
 	tracev1.UnimplementedTraceServiceServer
 }
 
@@ -106,14 +119,16 @@ func NewController(opt Opt) (*Controller, error) {
 	}
 
 	s, err := llbsolver.New(llbsolver.Opt{
-		WorkerController: opt.WorkerController,
-		Frontends:        opt.Frontends,
-		CacheManager:     opt.CacheManager,
-		CacheResolvers:   opt.ResolveCacheImporterFuncs,
-		GatewayForwarder: gatewayForwarder,
-		SessionManager:   opt.SessionManager,
-		Entitlements:     opt.Entitlements,
-		HistoryQueue:     hq,
+		WorkerController:   opt.WorkerController,
+		Frontends:          opt.Frontends,
+		CacheManager:       opt.CacheManager,
+		CacheResolvers:     opt.ResolveCacheImporterFuncs,
+		GatewayForwarder:   gatewayForwarder,
+		SessionManager:     opt.SessionManager,
+		Entitlements:       opt.Entitlements,
+		HistoryQueue:       hq,
+		SchedulerConfig:    opt.SchedulerConfig,
+		SourcePolicyConfig: opt.SourcePolicyConfig,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create solver")
@@ -126,7 +141,7 @@ func NewController(opt Opt) (*Controller, error) {
 		cache:            opt.CacheManager,
 		gatewayForwarder: gatewayForwarder,
 	}
-	c.throttledGC = throttle.After(time.Minute, c.gc)
+	c.throttledGC = throttle.After(time.Minute, func() { c.gc() })
 	// use longer interval for releaseUnreferencedCache deleting links quickly is less important
 	c.throttledReleaseUnreferenced = throttle.After(5*time.Minute, func() { c.releaseUnreferencedCache(context.TODO()) })
 
@@ -134,10 +149,19 @@ func NewController(opt Opt) (*Controller, error) {
 		time.AfterFunc(time.Second, c.throttledGC)
 	}()
 
+	if opt.Root != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.stopDiskWatcher = cancel
+		go disk.NewWatcher(opt.Root, c.minFreeSpace, diskPressureInterval, c.gcUnderDiskPressure).Run(ctx)
+	}
+
 	return c, nil
 }
 
 func (c *Controller) Close() error {
+	if c.stopDiskWatcher != nil {
+		c.stopDiskWatcher()
+	}
 	rerr := c.opt.HistoryDB.Close()
 	if err := c.opt.WorkerController.Close(); err != nil {
 		rerr = multierror.Append(rerr, err)
@@ -205,10 +229,19 @@ func (c *Controller) releaseUnreferencedCache(ctx context.Context) error {
 }
 
 func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Control_PruneServer) error {
-	if atomic.LoadInt64(&c.buildCount) == 0 {
+	if atomic.LoadInt64(&c.buildCount) == 0 && !req.DryRun {
 		imageutil.CancelCacheLeases()
 	}
 
+	var historyRefs func([]digest.Digest) bool
+	if req.HistoryAge > 0 {
+		var err error
+		historyRefs, err = c.history.HistoryRefs(stream.Context(), time.Now().Add(-time.Duration(req.HistoryAge)))
+		if err != nil {
+			return errors.Wrap(err, "failed to read build history for prune")
+		}
+	}
+
 	ch := make(chan client.UsageInfo, 32)
 
 	eg, ctx := errgroup.WithContext(stream.Context())
@@ -219,7 +252,7 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 
 	didPrune := false
 	defer func() {
-		if didPrune {
+		if didPrune && !req.DryRun {
 			if c, ok := c.cache.(interface {
 				ReleaseUnreferenced(context.Context) error
 			}); ok {
@@ -240,6 +273,9 @@ func (c *Controller) Prune(req *controlapi.PruneRequest, stream controlapi.Contr
 					ReservedSpace: req.ReservedSpace,
 					MaxUsedSpace:  req.MaxUsedSpace,
 					MinFreeSpace:  req.MinFreeSpace,
+					DryRun:        req.DryRun,
+					HistoryAge:    time.Duration(req.HistoryAge),
+					HistoryRefs:   historyRefs,
 				})
 			})
 		}(w)
@@ -333,6 +369,39 @@ func (c *Controller) UpdateBuildHistory(ctx context.Context, req *controlapi.Upd
 	return &controlapi.UpdateBuildHistoryResponse{}, err
 }
 
+func (c *Controller) ExplainCacheMiss(ctx context.Context, req *controlapi.ExplainCacheMissRequest) (*controlapi.ExplainCacheMissResponse, error) {
+	if req.Ref == "" || req.BaseRef == "" {
+		return nil, status.Error(codes.InvalidArgument, "both ref and base ref are required")
+	}
+	base, err := c.history.CacheKeys(ctx, req.BaseRef)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.history.CacheKeys(ctx, req.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &controlapi.ExplainCacheMissResponse{}
+	for _, m := range cachemiss.Diff(base, target) {
+		v := &controlapi.CacheMissVertex{
+			Digest:     string(m.Digest),
+			Name:       m.Name,
+			BaseDigest: string(m.BaseDigest),
+		}
+		for _, r := range m.Reasons {
+			v.Reasons = append(v.Reasons, &controlapi.CacheMissReason{
+				Type: r.Type,
+				Key:  r.Key,
+				Old:  r.Old,
+				New:  r.New,
+			})
+		}
+		resp.Vertexes = append(resp.Vertexes, v)
+	}
+	return resp, nil
+}
+
 func translateLegacySolveRequest(req *controlapi.SolveRequest) {
 	// translates ExportRef and ExportAttrs to new Exports (v0.4.0)
 	if legacyExportRef := req.Cache.ExportRefDeprecated; legacyExportRef != "" {
@@ -456,6 +525,10 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 				exp.IgnoreError = ignoreError
 			}
 		}
+		exp.CacheMounts, exp.CacheMountsMaxSize, err = parseCacheExportCacheMounts(e.Attrs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to configure %v cache exporter", e.Type)
+		}
 		cacheExporters = append(cacheExporters, exp)
 	}
 
@@ -534,12 +607,26 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 		Exporters:             expis,
 		CacheExporters:        cacheExporters,
 		EnableSessionExporter: req.EnableSessionExporter,
+		Plan:                  req.Plan,
+		CacheOnly:             req.CacheOnly,
+		KeepGoing:             req.KeepGoing,
+		Weight:                int(req.Weight),
+		SchedulerLabels:       req.SchedulerLabels,
 	}, entitlementsFromPB(req.Entitlements), procs, req.Internal, req.SourcePolicy)
 	if err != nil {
 		return nil, err
 	}
+	var plan []*controlapi.VertexPlan
+	for _, p := range resp.Plan {
+		plan = append(plan, &controlapi.VertexPlan{
+			Digest: string(p.Digest),
+			Name:   p.Name,
+			Status: string(p.Status),
+		})
+	}
 	return &controlapi.SolveResponse{
 		ExporterResponse: resp.ExporterResponse,
+		Plan:             plan,
 	}, nil
 }
 
@@ -622,13 +709,23 @@ func (c *Controller) Info(ctx context.Context, r *controlapi.InfoRequest) (*cont
 	}, nil
 }
 
-func (c *Controller) gc() {
+// gc runs the GC policies of the workers and returns the number of bytes
+// that were released.
+func (c *Controller) gc() int64 {
+	return c.prune(func(w worker.Worker) []client.PruneInfo {
+		return w.GCPolicy()
+	})
+}
+
+// prune runs the policies returned by policy for each worker and returns the
+// number of bytes that were released.
+func (c *Controller) prune(policy func(worker.Worker) []client.PruneInfo) int64 {
 	c.gcmu.Lock()
 	defer c.gcmu.Unlock()
 
 	workers, err := c.opt.WorkerController.List()
 	if err != nil {
-		return
+		return 0
 	}
 
 	eg, ctx := errgroup.WithContext(context.TODO())
@@ -645,7 +742,7 @@ func (c *Controller) gc() {
 
 	for _, w := range workers {
 		eg.Go(func() error {
-			if policy := w.GCPolicy(); len(policy) > 0 {
+			if policy := policy(w); len(policy) > 0 {
 				return w.Prune(ctx, ch, policy...)
 			}
 			return nil
@@ -662,6 +759,60 @@ func (c *Controller) gc() {
 		bklog.G(ctx).Debugf("gc cleaned up %d bytes", size)
 		go c.throttledReleaseUnreferenced()
 	}
+	return size
+}
+
+// minFreeSpace returns the largest minFreeSpace of the current GC policies of
+// the workers.
+func (c *Controller) minFreeSpace() int64 {
+	workers, err := c.opt.WorkerController.List()
+	if err != nil {
+		return 0
+	}
+	var minFree int64
+	for _, w := range workers {
+		for _, p := range w.GCPolicy() {
+			minFree = max(minFree, p.MinFreeSpace)
+		}
+	}
+	return minFree
+}
+
+// minFreeSpacePolicy returns the GC policies of w that have a minFreeSpace
+// without their other size limits.
+func minFreeSpacePolicy(w worker.Worker) []client.PruneInfo {
+	var out []client.PruneInfo
+	for _, p := range w.GCPolicy() {
+		if p.MinFreeSpace <= 0 {
+			continue
+		}
+		p.MaxUsedSpace = 0
+		out = append(out, p)
+	}
+	return out
+}
+
+// diskPressureInterval is how often the free disk space is checked.
+const diskPressureInterval = 10 * time.Second
+
+// gcUnderDiskPressure frees disk space when it is low, also while builds are
+// running, and warns the running builds if space had to be reclaimed. Only
+// the GC policies with a minFreeSpace are run, and only for that target, so
+// the oldest unreferenced records are removed one at a time until the free
+// space is reached instead of running the full GC.
+func (c *Controller) gcUnderDiskPressure(ctx context.Context, dstat disk.DiskStat) {
+	if time.Now().Before(c.nextPressureGC) {
+		return
+	}
+	size := c.prune(minFreeSpacePolicy)
+	if size == 0 {
+		c.nextPressureGC = time.Now().Add(time.Minute)
+		return
+	}
+	bklog.G(ctx).Infof("low disk space: %d bytes free, gc cleaned up %d bytes", dstat.Free, size)
+	if atomic.LoadInt64(&c.buildCount) > 0 {
+		c.solver.WarnRunningBuilds(ctx, "[internal] low disk space", fmt.Sprintf("low disk space (%s free): removed %s of build cache during the build", units.HumanSize(float64(dstat.Free)), units.HumanSize(float64(size))))
+	}
 }
 
 func parseCacheExportMode(mode string) (solver.CacheExportMode, bool) {
@@ -682,6 +833,26 @@ func parseCacheExportIgnoreError(ignoreErrorStr string) (bool, bool) {
 	return ignoreError, true
 }
 
+// parseCacheExportCacheMounts returns the IDs of the cache mounts to export
+// from the ";" separated cache-mounts attribute and the size limit for them.
+func parseCacheExportCacheMounts(attrs map[string]string) ([]string, int64, error) {
+	var ids []string
+	for _, id := range strings.Split(attrs["cache-mounts"], ";") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	var maxSize int64
+	if v, ok := attrs["cache-mounts-max-size"]; ok {
+		var err error
+		maxSize, err = units.RAMInBytes(v)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "invalid cache-mounts-max-size %q", v)
+		}
+	}
+	return ids, maxSize, nil
+}
+
 func toPBGCPolicy(in []client.PruneInfo) []*apitypes.GCPolicy {
 	policy := make([]*apitypes.GCPolicy, 0, len(in))
 	for _, p := range in {
@@ -692,6 +863,7 @@ func toPBGCPolicy(in []client.PruneInfo) []*apitypes.GCPolicy {
 			ReservedSpace: p.ReservedSpace,
 			MaxUsedSpace:  p.MaxUsedSpace,
 			MinFreeSpace:  p.MinFreeSpace,
+			CacheMountIDs: p.CacheMountIDs,
 		})
 	}
 	return policy
@@ -2,8 +2,11 @@ package control
 
 import (
 	"testing"
+	"time"
 
 	controlapi "github.com/moby/buildkit/api/services/control"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/worker"
 	"github.com/stretchr/testify/require"
 )
 
@@ -146,3 +149,63 @@ func TestParseCacheExportIgnoreError(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCacheExportCacheMounts(t *testing.T) {
+	tests := map[string]struct {
+		attrs           map[string]string
+		expectedIDs     []string
+		expectedMaxSize int64
+		expectedErr     string
+	}{
+		"none": {
+			attrs: map[string]string{"mode": "max"},
+		},
+		"ids": {
+			attrs:       map[string]string{"cache-mounts": "go-mod; go-build;;"},
+			expectedIDs: []string{"go-mod", "go-build"},
+		},
+		"max-size": {
+			attrs:           map[string]string{"cache-mounts": "npm", "cache-mounts-max-size": "512MB"},
+			expectedIDs:     []string{"npm"},
+			expectedMaxSize: 512 * 1024 * 1024,
+		},
+		"invalid-max-size": {
+			attrs:       map[string]string{"cache-mounts": "npm", "cache-mounts-max-size": "lots"},
+			expectedErr: "invalid cache-mounts-max-size",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ids, maxSize, err := parseCacheExportCacheMounts(test.attrs)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedIDs, ids)
+			require.Equal(t, test.expectedMaxSize, maxSize)
+		})
+	}
+}
+
+type gcPolicyWorker struct {
+	worker.Worker
+	policy []client.PruneInfo
+}
+
+func (w *gcPolicyWorker) GCPolicy() []client.PruneInfo {
+	return w.policy
+}
+
+func TestMinFreeSpacePolicy(t *testing.T) {
+	w := &gcPolicyWorker{policy: []client.PruneInfo{
+		{Filter: []string{"type==source.local"}, KeepDuration: time.Hour, MaxUsedSpace: 512},
+		{KeepDuration: time.Hour, MaxUsedSpace: 1024, MinFreeSpace: 2048},
+		{All: true, ReservedSpace: 256, MaxUsedSpace: 4096, MinFreeSpace: 8192},
+	}}
+	require.Equal(t, []client.PruneInfo{
+		{KeepDuration: time.Hour, MinFreeSpace: 2048},
+		{All: true, ReservedSpace: 256, MinFreeSpace: 8192},
+	}, minFreeSpacePolicy(w))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/containerd/containerd/v2/pkg/rootfs"
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/platforms"
+	"github.com/distribution/reference"
 	"github.com/moby/buildkit/cache"
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/client"
@@ -27,8 +28,10 @@ import (
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/push"
@@ -177,6 +180,9 @@ func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 			i.meta[k] = []byte(v)
 		}
 	}
+	if i.opts.SignKeyID != "" && !i.push {
+		return nil, errors.Errorf("%s requires %s", exptypes.OptKeySignKey, exptypes.OptKeyPush)
+	}
 	return i, nil
 }
 
@@ -232,6 +238,10 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	}
 	opts.Annotations = opts.Annotations.Merge(as)
 
+	if err := opts.LoadSigningKey(ctx, e.opt.SessionManager, session.NewGroup(sessionID)); err != nil {
+		return nil, nil, err
+	}
+
 	ctx, done, err := leaseutil.WithLease(ctx, e.opt.LeaseManager, leaseutil.MakeTemporary)
 	if err != nil {
 		return nil, nil, err
@@ -242,7 +252,7 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		}
 	}()
 
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
+	desc, referrers, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -355,6 +365,12 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 			}
 			if e.push {
 				err = e.pushImage(ctx, src, sessionID, targetName, desc.Digest)
+				if err == nil && len(referrers) > 0 {
+					err = e.pushReferrers(ctx, sessionID, referrers, targetName)
+				}
+				if err == nil && opts.SigningKey != nil {
+					err = e.pushSignature(ctx, sessionID, &opts, *desc, targetName)
+				}
 				if err != nil {
 					var statusErr remoteserrors.ErrUnexpectedStatus
 					if errors.As(err, &statusErr) {
@@ -413,6 +429,64 @@ func (e *imageExporterInstance) pushImage(ctx context.Context, src *exporter.Sou
 	return push.Push(ctx, e.opt.SessionManager, sessionID, mprovider, e.opt.ImageWriter.ContentStore(), dgst, targetName, e.insecure, e.opt.RegistryHosts, e.pushByDigest, annotations)
 }
 
+// pushSignature signs the pushed image target and pushes the signature to the
+// repository of targetName. In cosign tag mode the signatures already in the
+// tag are kept.
+func (e *imageExporterInstance) pushSignature(ctx context.Context, sessionID string, opts *ImageCommitOpts, target ocispecs.Descriptor, targetName string) error {
+	parsed, err := reference.ParseNormalizedNamed(targetName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", targetName)
+	}
+	repo := parsed.Name()
+	cs := e.opt.ImageWriter.ContentStore()
+	if opts.SignMode == SignModeReferrers {
+		desc, err := e.opt.ImageWriter.CommitSignature(ctx, opts, target, repo, nil)
+		if err != nil {
+			return err
+		}
+		return push.Referrers(ctx, e.opt.SessionManager, sessionID, cs, cs, target.Digest, []ocispecs.Descriptor{*desc}, repo, e.insecure, e.opt.RegistryHosts)
+	}
+
+	sigRef := repo + ":" + imagesig.CosignTag(target.Digest)
+	var existing []ocispecs.Descriptor
+	mfst, err := push.FetchManifest(ctx, e.opt.SessionManager, sessionID, cs, sigRef, e.insecure, e.opt.RegistryHosts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch existing signatures from %s", sigRef)
+	}
+	if mfst != nil {
+		existing = mfst.Layers
+	}
+	desc, err := e.opt.ImageWriter.CommitSignature(ctx, opts, target, repo, existing)
+	if err != nil {
+		return err
+	}
+	return push.Push(ctx, e.opt.SessionManager, sessionID, cs, cs, desc.Digest, sigRef, e.insecure, e.opt.RegistryHosts, false, nil)
+}
+
+// pushReferrers pushes the attestation manifests that refer to the platform
+// manifests of the image, grouped by the manifest that they refer to.
+func (e *imageExporterInstance) pushReferrers(ctx context.Context, sessionID string, referrers []ocispecs.Descriptor, targetName string) error {
+	var subjects []digest.Digest
+	bySubject := map[digest.Digest][]ocispecs.Descriptor{}
+	for _, desc := range referrers {
+		subject, err := digest.Parse(desc.Annotations[attestationTypes.DockerAnnotationReferenceDigest])
+		if err != nil {
+			return errors.Wrapf(err, "invalid subject of referrer %s", desc.Digest)
+		}
+		if _, ok := bySubject[subject]; !ok {
+			subjects = append(subjects, subject)
+		}
+		bySubject[subject] = append(bySubject[subject], desc)
+	}
+	cs := e.opt.ImageWriter.ContentStore()
+	for _, subject := range subjects {
+		if err := push.Referrers(ctx, e.opt.SessionManager, sessionID, cs, cs, subject, bySubject[subject], targetName, e.insecure, e.opt.RegistryHosts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (e *imageExporterInstance) unpackImage(ctx context.Context, img images.Image, src *exporter.Source, s session.Group) (err0 error) {
 	matcher := platforms.Only(platforms.Normalize(platforms.DefaultSpec()))
 
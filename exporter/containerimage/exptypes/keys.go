@@ -57,6 +57,11 @@ var (
 	// Value: bool <true|false>
 	OptKeyForceInlineAttestations ImageExporterOptKey = "attestation-inline"
 
+	// Attach attestation manifests as OCI referrers of the platform manifests
+	// instead of adding them to the image index.
+	// Value: bool <true|false>
+	OptKeyAttestationReferrers ImageExporterOptKey = "attestation-referrers"
+
 	// Mark layers as non-distributable if they are found to use a
 	// non-distributable media type. When this option is not set, the exporter
 	// will change the media type of the layer to a distributable one.
@@ -85,4 +90,19 @@ var (
 	// Rewrite timestamps in layers to match SOURCE_DATE_EPOCH
 	// Value: bool <true|false>
 	OptKeyRewriteTimestamp ImageExporterOptKey = "rewrite-timestamp"
+
+	// Sign the exported image with the PEM encoded private key in the session
+	// secret with this ID.
+	// Value: string
+	OptKeySignKey ImageExporterOptKey = "sign-key"
+
+	// Where the cosign signature of the image is stored: in the tag
+	// sha256-<digest>.sig or as an OCI referrer of the image.
+	// Value: string <tag|referrers>
+	OptKeySignMode ImageExporterOptKey = "sign-mode"
+
+	// Wrap attestations in DSSE envelopes signed with the sign-key. Defaults
+	// to true if sign-key is set.
+	// Value: bool <true|false>
+	OptKeySignAttestations ImageExporterOptKey = "sign-attestations"
 )
@@ -8,8 +8,11 @@ import (
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/exporter/util/epoch"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/pkg/errors"
 )
 
@@ -22,9 +25,25 @@ type ImageCommitOpts struct {
 	Epoch       *time.Time
 
 	ForceInlineAttestations bool // force inline attestations to be attached
+	AttestationReferrers    bool // attach attestations as referrers instead of in the index
 	RewriteTimestamp        bool // rewrite timestamps in layers to match the epoch
+
+	SignKeyID        string               // ID of the session secret with the signing key
+	SignMode         SignMode             // where the image signature is stored
+	SignAttestations bool                 // sign attestations in DSSE envelopes
+	SigningKey       *imagesig.SigningKey // set by LoadSigningKey
 }
 
+// SignMode is where the cosign signature of an image is stored.
+type SignMode string
+
+const (
+	// SignModeTag stores the signature in the sha256-<digest>.sig tag.
+	SignModeTag SignMode = "tag"
+	// SignModeReferrers stores the signature as an OCI referrer of the image.
+	SignModeReferrers SignMode = "referrers"
+)
+
 func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[string]string, error) {
 	rest := make(map[string]string)
 
@@ -43,6 +62,7 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 		return nil, err
 	}
 
+	c.SignAttestations = true
 	for k, v := range opt {
 		var err error
 		switch exptypes.ImageExporterOptKey(k) {
@@ -54,10 +74,23 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 			err = parseBool(&c.OCIArtifact, k, v)
 		case exptypes.OptKeyForceInlineAttestations:
 			err = parseBool(&c.ForceInlineAttestations, k, v)
+		case exptypes.OptKeyAttestationReferrers:
+			err = parseBool(&c.AttestationReferrers, k, v)
 		case exptypes.OptKeyPreferNondistLayers:
 			err = parseBool(&c.RefCfg.PreferNonDistributable, k, v)
 		case exptypes.OptKeyRewriteTimestamp:
 			err = parseBool(&c.RewriteTimestamp, k, v)
+		case exptypes.OptKeySignKey:
+			c.SignKeyID = v
+		case exptypes.OptKeySignMode:
+			switch SignMode(v) {
+			case SignModeTag, SignModeReferrers:
+				c.SignMode = SignMode(v)
+			default:
+				err = errors.Errorf("invalid value %q for %s, expected %q or %q", v, k, SignModeTag, SignModeReferrers)
+			}
+		case exptypes.OptKeySignAttestations:
+			err = parseBoolWithDefault(&c.SignAttestations, k, v, true)
 		default:
 			rest[k] = v
 		}
@@ -74,11 +107,41 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 		c.EnableOCITypes(ctx, "oci-artifact")
 	}
 
+	if c.SignKeyID == "" && c.SignMode != "" {
+		return nil, errors.Errorf("%s requires %s", exptypes.OptKeySignMode, exptypes.OptKeySignKey)
+	}
+	if c.SignMode == "" {
+		c.SignMode = SignModeTag
+	}
+
 	c.Annotations = c.Annotations.Merge(as)
 
 	return rest, nil
 }
 
+// LoadSigningKey reads the signing key from the session secret SignKeyID. It
+// does nothing if signing is not enabled.
+func (c *ImageCommitOpts) LoadSigningKey(ctx context.Context, sm *session.Manager, g session.Group) error {
+	if c.SignKeyID == "" {
+		return nil
+	}
+	var dt []byte
+	err := sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+		var err error
+		dt, err = secrets.GetSecret(ctx, caller, c.SignKeyID)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get signing key from secret %s", c.SignKeyID)
+	}
+	key, err := imagesig.ParseSigningKey(dt)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse signing key from secret %s", c.SignKeyID)
+	}
+	c.SigningKey = key
+	return nil
+}
+
 func (c *ImageCommitOpts) EnableOCITypes(ctx context.Context, reason string) {
 	if !c.OCITypes {
 		message := "forcibly turning on oci-mediatype mode"
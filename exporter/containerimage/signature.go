@@ -0,0 +1,122 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/moby/buildkit/util/imagesig"
+	"github.com/moby/buildkit/util/progress"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// CommitSignature writes a cosign signature manifest for target to the
+// content store. ref is the repository that the signature is made for. In
+// SignModeReferrers the manifest refers to target with its subject field,
+// otherwise it is meant to be pushed to the cosign tag of target and the new
+// signature is appended to the existing layers of the signature manifest in
+// that tag. The existing layers must be in the content store. Signatures made
+// with the same key are replaced.
+func (ic *ImageWriter) CommitSignature(ctx context.Context, opts *ImageCommitOpts, target ocispecs.Descriptor, ref string, existing []ocispecs.Descriptor) (*ocispecs.Descriptor, error) {
+	if opts.SigningKey == nil {
+		return nil, errors.New("no signing key loaded")
+	}
+	payload, sig, err := opts.SigningKey.SignCosign(ref, target.Digest)
+	if err != nil {
+		return nil, err
+	}
+	layer := ocispecs.Descriptor{
+		MediaType: imagesig.CosignSimpleSigningMediaType,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+		Annotations: map[string]string{
+			imagesig.CosignSignatureAnnotation: sig,
+		},
+	}
+
+	mfst := ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+	}
+
+	var config []byte
+	if opts.SignMode == SignModeReferrers {
+		mfst.Layers = []ocispecs.Descriptor{layer}
+		mfst.ArtifactType = imagesig.CosignArtifactType
+		mfst.Config = ocispecs.DescriptorEmptyJSON
+		mfst.Subject = &ocispecs.Descriptor{
+			MediaType: target.MediaType,
+			Digest:    target.Digest,
+			Size:      target.Size,
+		}
+		config = ocispecs.DescriptorEmptyJSON.Data
+	} else {
+		for _, l := range existing {
+			if l.MediaType == imagesig.CosignSimpleSigningMediaType && l.Digest == layer.Digest {
+				dt, err := content.ReadBlob(ctx, ic.opt.ContentStore, l)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to read signature payload %s", l.Digest)
+				}
+				if opts.SigningKey.SignedCosign(dt, l.Annotations[imagesig.CosignSignatureAnnotation]) {
+					continue
+				}
+			}
+			mfst.Layers = append(mfst.Layers, l)
+		}
+		mfst.Layers = append(mfst.Layers, layer)
+
+		img := ocispecs.Image{}
+		img.RootFS.Type = "layers"
+		for _, l := range mfst.Layers {
+			img.RootFS.DiffIDs = append(img.RootFS.DiffIDs, l.Digest)
+		}
+		config, err = json.Marshal(img)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create signature image config")
+		}
+		mfst.Config = ocispecs.Descriptor{
+			MediaType: ocispecs.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		}
+	}
+
+	mfstJSON, err := json.MarshalIndent(mfst, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal manifest")
+	}
+	mfstDesc := ocispecs.Descriptor{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: mfst.ArtifactType,
+		Digest:       digest.FromBytes(mfstJSON),
+		Size:         int64(len(mfstJSON)),
+	}
+
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.0": mfst.Config.Digest.String(),
+	}
+	for i, l := range mfst.Layers {
+		labels[fmt.Sprintf("containerd.io/gc.ref.content.%d", i+1)] = l.Digest.String()
+	}
+
+	done := progress.OneOff(ctx, "exporting signature manifest "+mfstDesc.Digest.String())
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, layer.Digest.String(), bytes.NewReader(payload), layer); err != nil {
+		return nil, done(errors.Wrapf(err, "error writing signature payload %s", layer.Digest))
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, mfst.Config.Digest.String(), bytes.NewReader(config), mfst.Config); err != nil {
+		return nil, done(errors.Wrap(err, "error writing config blob"))
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, mfstDesc.Digest.String(), bytes.NewReader(mfstJSON), mfstDesc, content.WithLabels(labels)); err != nil {
+		return nil, done(errors.Wrapf(err, "error writing manifest blob %s", mfstDesc.Digest))
+	}
+	done(nil)
+
+	return &mfstDesc, nil
+}
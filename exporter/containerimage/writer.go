@@ -46,8 +46,6 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const attestationManifestArtifactType = "application/vnd.docker.attestation.manifest.v1+json"
-
 type WriterOpt struct {
 	Snapshotter  snapshot.Snapshotter
 	ContentStore content.Store
@@ -63,39 +61,37 @@ type ImageWriter struct {
 	opt WriterOpt
 }
 
-func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, sessionID string, inlineCache exptypes.InlineCache, opts *ImageCommitOpts) (*ocispecs.Descriptor, error) {
+// Commit writes the image of inp to the content store and returns the
+// descriptor of its manifest or index. If opts.AttestationReferrers is set,
+// the attestation manifests are returned separately as referrers of the
+// platform manifests instead of being added to the index.
+func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, sessionID string, inlineCache exptypes.InlineCache, opts *ImageCommitOpts) (*ocispecs.Descriptor, []ocispecs.Descriptor, error) {
 	if _, ok := inp.Metadata[exptypes.ExporterPlatformsKey]; len(inp.Refs) > 0 && !ok {
-		return nil, errors.Errorf("unable to export multiple refs, missing platforms mapping")
+		return nil, nil, errors.Errorf("unable to export multiple refs, missing platforms mapping")
 	}
 
 	isMap := len(inp.Refs) > 0
 
 	ps, err := exptypes.ParsePlatforms(inp.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if !isMap {
+	if !isMap && !opts.AttestationReferrers {
 		// enable index if we need to include attestations
 		for _, p := range ps.Platforms {
-			if atts, ok := inp.Attestations[p.ID]; ok {
-				if !opts.ForceInlineAttestations {
-					// if we don't need force inline attestations (for oci
-					// exporter), filter them out
-					atts = attestation.Filter(atts, nil, map[string][]byte{
-						result.AttestationInlineOnlyKey: []byte(strconv.FormatBool(true)),
-					})
-				}
-				if len(atts) > 0 {
-					isMap = true
-					break
-				}
+			if atts := opts.filterAttestations(inp.Attestations[p.ID]); len(atts) > 0 {
+				isMap = true
+				break
 			}
 		}
 	}
+	if opts.AttestationReferrers && len(inp.Attestations) > 0 {
+		opts.EnableOCITypes(ctx, "attestation-referrers")
+	}
 	if opts.Epoch == nil {
 		if tm, ok, err := epoch.ParseSource(inp); err != nil {
-			return nil, err
+			return nil, nil, err
 		} else if ok {
 			opts.Epoch = tm
 		}
@@ -104,7 +100,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	for pk, a := range opts.Annotations {
 		if pk != "" {
 			if _, ok := inp.FindRef(pk); !ok {
-				return nil, errors.Errorf("invalid annotation: no platform %s found in source", pk)
+				return nil, nil, errors.Errorf("invalid annotation: no platform %s found in source", pk)
 			}
 		}
 		if len(a.Index)+len(a.IndexDescriptor)+len(a.ManifestDescriptor) > 0 {
@@ -114,7 +110,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	if !isMap {
 		if len(ps.Platforms) > 1 {
-			return nil, errors.Errorf("cannot export multiple platforms without multi-platform enabled")
+			return nil, nil, errors.Errorf("cannot export multiple platforms without multi-platform enabled")
 		}
 
 		var ref cache.ImmutableRef
@@ -133,33 +129,33 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if len(baseImgConfig) > 0 {
 			var baseImgX dockerspec.DockerOCIImage
 			if err := json.Unmarshal(baseImgConfig, &baseImgX); err != nil {
-				return nil, errors.Wrap(err, "failed to unmarshal base image config")
+				return nil, nil, errors.Wrap(err, "failed to unmarshal base image config")
 			}
 			baseImg = &baseImgX
 		}
 
 		remotes, err := ic.exportLayers(ctx, opts.RefCfg, session.NewGroup(sessionID), ref)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		remote := &remotes[0]
 		if opts.RewriteTimestamp {
 			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
 		annotations := opts.Annotations.Platform(nil)
 		if len(annotations.Index) > 0 || len(annotations.IndexDescriptor) > 0 {
-			return nil, errors.Errorf("index annotations not supported for single platform export")
+			return nil, nil, errors.Errorf("index annotations not supported for single platform export")
 		}
 
 		var inlineCacheEntry *exptypes.InlineCacheEntry
 		if inlineCache != nil {
 			inlineCacheResult, err := inlineCache(ctx)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if inlineCacheResult != nil {
 				if p != nil {
@@ -172,8 +168,20 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 		mfstDesc, configDesc, err := ic.commitDistributionManifest(ctx, opts, ref, config, remote, annotations, inlineCacheEntry, opts.Epoch, session.NewGroup(sessionID), baseImg)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		var referrers []ocispecs.Descriptor
+		if p != nil && opts.AttestationReferrers {
+			if atts := opts.filterAttestations(inp.Attestations[p.ID]); len(atts) > 0 {
+				desc, err := ic.commitAttestations(ctx, opts, sessionID, *p, ref, remote, *mfstDesc, atts)
+				if err != nil {
+					return nil, nil, err
+				}
+				referrers = append(referrers, *desc)
+			}
 		}
+
 		if mfstDesc.Annotations == nil {
 			mfstDesc.Annotations = make(map[string]string)
 		}
@@ -182,7 +190,10 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		}
 		mfstDesc.Annotations[exptypes.ExporterConfigDigestKey] = configDesc.Digest.String()
 
-		return mfstDesc, nil
+		if err := ic.labelReferrers(ctx, mfstDesc.Digest, referrers); err != nil {
+			return nil, nil, err
+		}
+		return mfstDesc, referrers, nil
 	}
 
 	if len(inp.Attestations) > 0 {
@@ -194,7 +205,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	for _, p := range ps.Platforms {
 		r, ok := inp.FindRef(p.ID)
 		if !ok {
-			return nil, errors.Errorf("failed to find ref for ID %s", p.ID)
+			return nil, nil, errors.Errorf("failed to find ref for ID %s", p.ID)
 		}
 		remotesMap[p.ID] = len(refs)
 		refs = append(refs, r)
@@ -202,14 +213,14 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	remotes, err := ic.exportLayers(ctx, opts.RefCfg, session.NewGroup(sessionID), refs...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var inlineCacheResult *result.Result[*exptypes.InlineCacheEntry]
 	if inlineCache != nil {
 		inlineCacheResult, err = inlineCache(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -227,12 +238,12 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	labels := map[string]string{}
 
-	var attestationManifests []ocispecs.Descriptor
+	var attestationManifests, referrers []ocispecs.Descriptor
 
 	for i, p := range ps.Platforms {
 		r, ok := inp.FindRef(p.ID)
 		if !ok {
-			return nil, errors.Errorf("failed to find ref for ID %s", p.ID)
+			return nil, nil, errors.Errorf("failed to find ref for ID %s", p.ID)
 		}
 		config := exptypes.ParseKey(inp.Metadata, exptypes.ExporterImageConfigKey, &p)
 		baseImgConfig := exptypes.ParseKey(inp.Metadata, exptypes.ExporterImageBaseConfigKey, &p)
@@ -240,7 +251,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if len(baseImgConfig) > 0 {
 			var baseImgX dockerspec.DockerOCIImage
 			if err := json.Unmarshal(baseImgConfig, &baseImgX); err != nil {
-				return nil, errors.Wrap(err, "failed to unmarshal base image config")
+				return nil, nil, errors.Wrap(err, "failed to unmarshal base image config")
 			}
 			baseImg = &baseImgX
 		}
@@ -254,7 +265,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if opts.RewriteTimestamp {
 			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
@@ -265,7 +276,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 		desc, _, err := ic.commitDistributionManifest(ctx, opts, r, config, remote, opts.Annotations.Platform(&p.Platform), inlineCacheEntry, opts.Epoch, session.NewGroup(sessionID), baseImg)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		dp := p.Platform
 		desc.Platform = &dp
@@ -274,49 +285,13 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		labels[fmt.Sprintf("containerd.io/gc.ref.content.%d", i)] = desc.Digest.String()
 
 		if attestations, ok := inp.Attestations[p.ID]; ok {
-			attestations, err := attestation.Unbundle(ctx, session.NewGroup(sessionID), attestations)
-			if err != nil {
-				return nil, err
-			}
-
-			eg, ctx2 := errgroup.WithContext(ctx)
-			for i, att := range attestations {
-				i, att := i, att
-				eg.Go(func() error {
-					att, err := supplementSBOM(ctx2, session.NewGroup(sessionID), r, remote, att)
-					if err != nil {
-						return err
-					}
-					attestations[i] = att
-					return nil
-				})
-			}
-			if err := eg.Wait(); err != nil {
-				return nil, err
-			}
-
-			var defaultSubjects []intoto.Subject
-			for _, name := range strings.Split(opts.ImageName, ",") {
-				if name == "" {
-					continue
-				}
-				pl, err := purl.RefToPURL(packageurl.TypeDocker, name, &p.Platform)
-				if err != nil {
-					return nil, err
-				}
-				defaultSubjects = append(defaultSubjects, intoto.Subject{
-					Name:   pl,
-					Digest: result.ToDigestMap(desc.Digest),
-				})
-			}
-			stmts, err := attestation.MakeInTotoStatements(ctx, session.NewGroup(sessionID), attestations, defaultSubjects)
+			desc, err := ic.commitAttestations(ctx, opts, sessionID, p, r, remote, *desc, attestations)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
-
-			desc, err := ic.commitAttestationsManifest(ctx, opts, *desc, stmts, opts.OCIArtifact)
-			if err != nil {
-				return nil, err
+			if opts.AttestationReferrers {
+				referrers = append(referrers, *desc)
+				continue
 			}
 			desc.Platform = &intotoPlatform
 			attestationManifests = append(attestationManifests, *desc)
@@ -330,7 +305,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	idxBytes, err := json.MarshalIndent(idx, "", "  ")
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal index")
+		return nil, nil, errors.Wrap(err, "failed to marshal index")
 	}
 
 	idxDigest := digest.FromBytes(idxBytes)
@@ -343,11 +318,103 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	idxDone := progress.OneOff(ctx, "exporting manifest list "+idxDigest.String())
 
 	if err := content.WriteBlob(ctx, ic.opt.ContentStore, idxDigest.String(), bytes.NewReader(idxBytes), idxDesc, content.WithLabels(labels)); err != nil {
-		return nil, idxDone(errors.Wrapf(err, "error writing manifest list blob %s", idxDigest))
+		return nil, nil, idxDone(errors.Wrapf(err, "error writing manifest list blob %s", idxDigest))
 	}
 	idxDone(nil)
 
-	return &idxDesc, nil
+	if err := ic.labelReferrers(ctx, idxDigest, referrers); err != nil {
+		return nil, nil, err
+	}
+	return &idxDesc, referrers, nil
+}
+
+// labelReferrers adds references from the manifest or index dgst to its
+// referrers, so that the content store keeps the referrers as long as the
+// image even if they are not pushed. The labels are updated instead of being
+// set when the blob is written because the blob may already exist.
+func (ic *ImageWriter) labelReferrers(ctx context.Context, dgst digest.Digest, referrers []ocispecs.Descriptor) error {
+	if len(referrers) == 0 {
+		return nil
+	}
+	info := content.Info{
+		Digest: dgst,
+		Labels: make(map[string]string, len(referrers)),
+	}
+	fields := make([]string, 0, len(referrers))
+	for i, desc := range referrers {
+		k := fmt.Sprintf("containerd.io/gc.ref.content.referrer.%d", i)
+		info.Labels[k] = desc.Digest.String()
+		fields = append(fields, "labels."+k)
+	}
+	if _, err := ic.opt.ContentStore.Update(ctx, info, fields...); err != nil {
+		return errors.Wrapf(err, "failed to add referrer labels to %s", dgst)
+	}
+	return nil
+}
+
+// commitAttestations writes the attestation manifest for the image manifest
+// desc of platform p.
+func (ic *ImageWriter) commitAttestations(ctx context.Context, opts *ImageCommitOpts, sessionID string, p exptypes.Platform, r cache.ImmutableRef, remote *solver.Remote, desc ocispecs.Descriptor, attestations []exporter.Attestation) (*ocispecs.Descriptor, error) {
+	attestations, err := attestation.Unbundle(ctx, session.NewGroup(sessionID), attestations)
+	if err != nil {
+		return nil, err
+	}
+
+	eg, ctx2 := errgroup.WithContext(ctx)
+	for i, att := range attestations {
+		i, att := i, att
+		eg.Go(func() error {
+			att, err := supplementSBOM(ctx2, session.NewGroup(sessionID), r, remote, att)
+			if err != nil {
+				return err
+			}
+			attestations[i] = att
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var defaultSubjects []intoto.Subject
+	for _, name := range strings.Split(opts.ImageName, ",") {
+		if name == "" {
+			continue
+		}
+		pl, err := purl.RefToPURL(packageurl.TypeDocker, name, &p.Platform)
+		if err != nil {
+			return nil, err
+		}
+		defaultSubjects = append(defaultSubjects, intoto.Subject{
+			Name:   pl,
+			Digest: result.ToDigestMap(desc.Digest),
+		})
+	}
+	stmts, err := attestation.MakeInTotoStatements(ctx, session.NewGroup(sessionID), attestations, defaultSubjects)
+	if err != nil {
+		return nil, err
+	}
+
+	mfstDesc, err := ic.commitAttestationsManifest(ctx, opts, desc, stmts, opts.OCIArtifact || opts.AttestationReferrers)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AttestationReferrers {
+		mfstDesc.ArtifactType = attestationTypes.ArtifactTypeAttestationManifest
+	}
+	return mfstDesc, nil
+}
+
+// filterAttestations returns the attestations that are attached to the image.
+// Attestations that are only meant to be inlined are filtered out unless
+// ForceInlineAttestations is set (for the oci exporter).
+func (c *ImageCommitOpts) filterAttestations(atts []exporter.Attestation) []exporter.Attestation {
+	if c.ForceInlineAttestations {
+		return atts
+	}
+	return attestation.Filter(atts, nil, map[string][]byte{
+		result.AttestationInlineOnlyKey: []byte(strconv.FormatBool(true)),
+	})
 }
 
 func (ic *ImageWriter) exportLayers(ctx context.Context, refCfg cacheconfig.RefConfig, s session.Group, refs ...cache.ImmutableRef) ([]solver.Remote, error) {
@@ -573,9 +640,21 @@ func (ic *ImageWriter) commitAttestationsManifest(ctx context.Context, opts *Ima
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to marshal attestation")
 		}
+		mediaType := intoto.PayloadType
+		if opts.SigningKey != nil && opts.SignAttestations {
+			env, err := opts.SigningKey.SignDSSE(intoto.PayloadType, data)
+			if err != nil {
+				return nil, err
+			}
+			data, err = json.Marshal(env)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to marshal attestation envelope")
+			}
+			mediaType = attestationTypes.MediaTypeDSSE
+		}
 		digest := digest.FromBytes(data)
 		desc := ocispecs.Descriptor{
-			MediaType: intoto.PayloadType,
+			MediaType: mediaType,
 			Digest:    digest,
 			Size:      int64(len(data)),
 			Annotations: map[string]string{
@@ -616,7 +695,7 @@ func (ic *ImageWriter) commitAttestationsManifest(ctx context.Context, opts *Ima
 	}
 
 	if ociArtifact {
-		mfst.ArtifactType = attestationManifestArtifactType
+		mfst.ArtifactType = attestationTypes.ArtifactTypeAttestationManifest
 		mfst.Subject = &target
 	}
 
@@ -25,6 +25,7 @@ import (
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/progress"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -78,6 +79,12 @@ func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 	if err != nil {
 		return nil, err
 	}
+	if e.opt.Variant == VariantDocker && i.opts.SignKeyID != "" {
+		return nil, errors.Errorf("docker exporter does not support signing")
+	}
+	if e.opt.Variant == VariantDocker && i.opts.AttestationReferrers {
+		return nil, errors.Errorf("docker exporter does not support attestation referrers")
+	}
 
 	for k, v := range opt {
 		switch k {
@@ -149,6 +156,10 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	}
 	opts.Annotations = opts.Annotations.Merge(as)
 
+	if err := opts.LoadSigningKey(ctx, e.opt.SessionManager, session.NewGroup(sessionID)); err != nil {
+		return nil, nil, err
+	}
+
 	ctx, done, err := leaseutil.WithLease(ctx, e.opt.LeaseManager, leaseutil.MakeTemporary)
 	if err != nil {
 		return nil, nil, err
@@ -159,7 +170,7 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		}
 	}()
 
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
+	desc, referrers, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -208,6 +219,18 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	}
 
 	expOpts := []archiveexporter.ExportOpt{archiveexporter.WithManifest(*desc, names...)}
+	for _, r := range referrers {
+		expOpts = append(expOpts, archiveexporter.WithManifest(r))
+	}
+	var sigDesc *ocispecs.Descriptor
+	if opts.SigningKey != nil {
+		var sigNames []string
+		sigDesc, sigNames, err = e.commitSignature(ctx, &opts, *desc, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		expOpts = append(expOpts, archiveexporter.WithManifest(*sigDesc, sigNames...))
+	}
 	switch e.opt.Variant {
 	case VariantOCI:
 		expOpts = append(expOpts, archiveexporter.WithAllPlatforms(), archiveexporter.WithSkipDockerManifest())
@@ -290,11 +313,51 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		if err != nil {
 			return nil, nil, err
 		}
+		for _, r := range referrers {
+			if err := contentutil.CopyChain(ctx, store, mprovider, r); err != nil {
+				return nil, nil, err
+			}
+		}
+		if sigDesc != nil {
+			if err := contentutil.CopyChain(ctx, store, mprovider, *sigDesc); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	return resp, nil, nil
 }
 
+// commitSignature signs the exported image target for the repository of the
+// first of names. In the tag sign mode, the signature is named with the cosign
+// tag of target in the repositories of all names.
+func (e *imageExporterInstance) commitSignature(ctx context.Context, opts *containerimage.ImageCommitOpts, target ocispecs.Descriptor, names []string) (*ocispecs.Descriptor, []string, error) {
+	repos := make([]string, len(names))
+	for i, name := range names {
+		parsed, err := reference.ParseNormalizedNamed(name)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+		repos[i] = parsed.Name()
+	}
+	var ref string
+	if len(repos) > 0 {
+		ref = repos[0]
+	}
+	desc, err := e.opt.ImageWriter.CommitSignature(ctx, opts, target, ref, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.SignMode == containerimage.SignModeReferrers {
+		return desc, nil, nil
+	}
+	sigNames := make([]string, len(repos))
+	for i, repo := range repos {
+		sigNames[i] = repo + ":" + imagesig.CosignTag(target.Digest)
+	}
+	return desc, sigNames, nil
+}
+
 func normalizedNames(name string) ([]string, error) {
 	if name == "" {
 		return nil, nil
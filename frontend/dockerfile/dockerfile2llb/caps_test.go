@@ -0,0 +1,20 @@
+//go:build dfadds3 || dfaddociartifact
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/apicaps"
+	apicapspb "github.com/moby/buildkit/util/apicaps/pb"
+)
+
+// capsWithout returns the LLB caps of the daemon without id.
+func capsWithout(id apicaps.CapID) apicaps.CapSet {
+	var caps []*apicapspb.APICap
+	for _, c := range pb.Caps.All() {
+		if apicaps.CapID(c.ID) != id {
+			caps = append(caps, c)
+		}
+	}
+	return pb.Caps.CapSet(caps)
+}
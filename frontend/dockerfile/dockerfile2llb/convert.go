@@ -949,6 +949,11 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			keepGitDir:      c.KeepGitDir,
 			checksum:        c.Checksum,
 			unpack:          c.Unpack,
+			sparsePaths:     c.SparsePaths,
+			verifyKeys:      c.VerifyKeys,
+			verifyKeySecret: c.VerifyKeySecret,
+			mirrors:         c.Mirrors,
+			signature:       c.Signature,
 			location:        c.Location(),
 			ignoreMatcher:   opt.dockerIgnoreMatcher,
 			opt:             opt,
@@ -1305,6 +1310,12 @@ func dispatchRun(d *dispatchState, c *instructions.RunCommand, proxy *llb.ProxyE
 		opt = append(opt, networkOpt)
 	}
 
+	retryOpts, err := dispatchRunRetry(c, dopt)
+	if err != nil {
+		return err
+	}
+	opt = append(opt, retryOpts...)
+
 	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaUlimit) == nil {
 		for _, u := range dopt.ulimit {
 			opt = append(opt, llb.AddUlimit(llb.UlimitName(u.Name), u.Soft, u.Hard))
@@ -1470,6 +1481,51 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 		}
 	}
 
+	if len(cfg.sparsePaths) > 0 {
+		for _, src := range cfg.params.SourcePaths {
+			if !isGitSource(src) {
+				return errors.New("sparse paths require Git sources")
+			}
+		}
+		// cfg.opt.llbCaps can be nil in unit tests
+		if caps := cfg.opt.llbCaps; caps != nil && (caps.Supports(pb.CapSourceGitSparsePaths) != nil || caps.Supports(pb.CapSourceGitPartialClone) != nil) {
+			return errors.New("ADD --sparse is not supported by the BuildKit daemon")
+		}
+	}
+
+	if cfg.signature != "" {
+		if len(cfg.params.SourcePaths) != 1 {
+			return errors.New("signature can't be specified for multiple sources")
+		}
+		if !isHTTPSource(cfg.params.SourcePaths[0]) {
+			return errors.New("signature requires HTTP(S) sources")
+		}
+		if len(cfg.verifyKeys) == 0 && cfg.verifyKeySecret == "" {
+			return errors.New("signature requires --verify-key or --verify-key-secret")
+		}
+	} else if len(cfg.verifyKeys) > 0 || cfg.verifyKeySecret != "" {
+		for _, src := range cfg.params.SourcePaths {
+			if !isGitSource(src) {
+				return errors.New("signature verification requires Git sources or --signature")
+			}
+		}
+	}
+
+	if len(cfg.mirrors) > 0 {
+		if len(cfg.params.SourcePaths) != 1 {
+			return errors.New("mirrors can't be specified for multiple sources")
+		}
+		if !isHTTPSource(cfg.params.SourcePaths[0]) {
+			return errors.New("mirrors require HTTP(S) sources")
+		}
+		if cfg.checksum == "" {
+			return errors.New("mirrors require --checksum")
+		}
+		if cfg.opt.llbCaps != nil && cfg.opt.llbCaps.Supports(pb.CapSourceHTTPMirrors) != nil {
+			return errors.New("ADD --mirror is not supported by the BuildKit daemon")
+		}
+	}
+
 	commitMessage := bytes.NewBufferString("")
 	if cfg.isAddCommand {
 		commitMessage.WriteString("ADD")
@@ -1517,6 +1573,15 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 			if cfg.checksum != "" {
 				gitOptions = append(gitOptions, llb.GitChecksum(cfg.checksum))
 			}
+			if len(cfg.sparsePaths) > 0 {
+				gitOptions = append(gitOptions, llb.GitSparsePaths(cfg.sparsePaths...), llb.GitFilter("blob:none"))
+			}
+			for _, k := range cfg.verifyKeys {
+				gitOptions = append(gitOptions, llb.GitVerifySignature(k))
+			}
+			if cfg.verifyKeySecret != "" {
+				gitOptions = append(gitOptions, llb.GitVerifySignatureSecret(cfg.verifyKeySecret))
+			}
 			st := llb.Git(gitRef.Remote, commit, gitOptions...)
 			opts := append([]llb.CopyOption{&llb.CopyInfo{
 				Mode:           chopt,
@@ -1553,7 +1618,20 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 				}
 			}
 
-			st := llb.HTTP(src, llb.Filename(f), llb.WithCustomName(pgName), llb.Checksum(checksum), dfCmd(cfg.params))
+			httpOpts := []llb.HTTPOption{llb.Filename(f), llb.WithCustomName(pgName), llb.Checksum(checksum), dfCmd(cfg.params)}
+			if len(cfg.mirrors) > 0 {
+				httpOpts = append(httpOpts, llb.Mirrors(cfg.mirrors...))
+			}
+			if cfg.signature != "" {
+				httpOpts = append(httpOpts, llb.SignatureURL(cfg.signature))
+				for _, k := range cfg.verifyKeys {
+					httpOpts = append(httpOpts, llb.VerifySignature(k))
+				}
+				if cfg.verifyKeySecret != "" {
+					httpOpts = append(httpOpts, llb.VerifySignatureSecret(cfg.verifyKeySecret))
+				}
+			}
+			st := llb.HTTP(src, httpOpts...)
 
 			var unpack bool
 			if cfg.unpack != nil {
@@ -1571,6 +1649,61 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 			} else {
 				a = a.Copy(st, f, dest, opts...)
 			}
+		} else if isS3Source(src) {
+			if !cfg.isAddCommand {
+				return errors.New("source can't be a URL for COPY")
+			}
+
+			if cfg.opt.llbCaps != nil && cfg.opt.llbCaps.Supports(pb.CapSourceS3) != nil {
+				return errors.New("ADD from S3 is not supported by the BuildKit daemon")
+			}
+
+			st, f, err := s3Source(src, llb.WithCustomName(pgName))
+			if err != nil {
+				return err
+			}
+
+			// like remote URLs, objects are not decompressed by default
+			var unpack bool
+			if cfg.unpack != nil {
+				unpack = *cfg.unpack
+			}
+
+			opts := append([]llb.CopyOption{&llb.CopyInfo{
+				Mode:           chopt,
+				CreateDestPath: true,
+				AttemptUnpack:  unpack,
+			}}, copyOpt...)
+
+			if a == nil {
+				a = llb.Copy(st, f, dest, opts...)
+			} else {
+				a = a.Copy(st, f, dest, opts...)
+			}
+		} else if isOCIArtifactSource(src) {
+			if !cfg.isAddCommand {
+				return errors.New("source can't be a URL for COPY")
+			}
+
+			if cfg.opt.llbCaps != nil && cfg.opt.llbCaps.Supports(pb.CapSourceOCIArtifact) != nil {
+				return errors.New("ADD from an OCI artifact is not supported by the BuildKit daemon")
+			}
+
+			st, err := ociArtifactSource(src, llb.WithCustomName(pgName), llb.Platform(platform))
+			if err != nil {
+				return err
+			}
+
+			opts := append([]llb.CopyOption{&llb.CopyInfo{
+				Mode:           chopt,
+				CreateDestPath: true,
+			}}, copyOpt...)
+
+			if a == nil {
+				a = llb.Copy(st, "/", dest, opts...)
+			} else {
+				a = a.Copy(st, "/", dest, opts...)
+			}
 		} else {
 			validateCopySourcePath(src, &cfg)
 			var patterns []string
@@ -1692,6 +1825,11 @@ type copyConfig struct {
 	link            bool
 	keepGitDir      bool
 	checksum        string
+	sparsePaths     []string
+	verifyKeys      []string
+	verifyKeySecret string
+	mirrors         []string
+	signature       string
 	parents         bool
 	location        []parser.Range
 	ignoreMatcher   *patternmatcher.PatternMatcher
@@ -2286,6 +2424,14 @@ func isHTTPSource(src string) bool {
 	return !isGitSource(src)
 }
 
+func isS3Source(src string) bool {
+	return strings.HasPrefix(src, "s3://")
+}
+
+func isOCIArtifactSource(src string) bool {
+	return strings.HasPrefix(src, "oci-artifact://")
+}
+
 func isGitSource(src string) bool {
 	// https://github.com/ORG/REPO.git is a git source, not an http source
 	if gitRef, gitErr := gitutil.ParseGitRef(src); gitRef != nil && gitErr == nil {
@@ -0,0 +1,25 @@
+//go:build dfaddociartifact
+
+package dockerfile2llb
+
+import (
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/pkg/errors"
+)
+
+// ociArtifactSource returns the state for an oci-artifact://<ref> URL. The
+// files of the artifact can be selected with a comma separated list in the
+// URL fragment.
+func ociArtifactSource(src string, opts ...llb.OCIArtifactOption) (llb.State, error) {
+	ref, files, _ := strings.Cut(strings.TrimPrefix(src, "oci-artifact://"), "#")
+	if _, err := reference.ParseNormalizedNamed(ref); err != nil {
+		return llb.State{}, errors.Wrapf(err, "invalid OCI artifact reference %s", ref)
+	}
+	if files != "" {
+		opts = append(opts, llb.OCIArtifactFiles(strings.Split(files, ",")...))
+	}
+	return llb.OCIArtifact(ref, opts...), nil
+}
@@ -0,0 +1,32 @@
+//go:build dfaddociartifact
+
+package dockerfile2llb
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddOCIArtifact(t *testing.T) {
+	df := `FROM scratch
+	ADD oci-artifact://ghcr.io/example/models:v1#model.bin,config.json /models/
+		`
+	_, _, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+
+	df = `FROM scratch
+	ADD oci-artifact://ghcr.io/Example/models:v1 /models/
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.ErrorContains(t, err, "invalid OCI artifact reference")
+
+	caps := capsWithout(pb.CapSourceOCIArtifact)
+	df = `FROM scratch
+	ADD oci-artifact://ghcr.io/example/models:v1 /models/
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.EqualError(t, err, "ADD from an OCI artifact is not supported by the BuildKit daemon")
+}
@@ -0,0 +1,45 @@
+//go:build dfadds3
+
+package dockerfile2llb
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/pkg/errors"
+)
+
+// s3Source returns the state for an s3://<bucket>/<key> URL and the name of
+// the downloaded file. The versionId, region and endpoint query parameters
+// set the version of the object, the region of the bucket and the URL of an
+// S3 compatible storage that is accessed with path-style requests.
+func s3Source(src string, opts ...llb.S3Option) (llb.State, string, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return llb.State{}, "", errors.Wrapf(err, "invalid s3 URL %s", src)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return llb.State{}, "", errors.Errorf("invalid s3 URL %s, expected s3://<bucket>/<key>", src)
+	}
+	f := path.Base(key)
+	if f == "." || f == "/" {
+		f = "__unnamed__"
+	}
+	opts = append(opts, llb.S3Filename(f))
+	for k, v := range u.Query() {
+		switch k {
+		case "versionId":
+			opts = append(opts, llb.S3VersionID(v[0]))
+		case "region":
+			opts = append(opts, llb.S3Region(v[0]))
+		case "endpoint":
+			opts = append(opts, llb.S3Endpoint(v[0], true))
+		default:
+			return llb.State{}, "", errors.Errorf("invalid s3 URL %s, unknown query parameter %s", src, k)
+		}
+	}
+	return llb.S3(u.Host, key, opts...), f, nil
+}
@@ -0,0 +1,32 @@
+//go:build dfadds3
+
+package dockerfile2llb
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddS3(t *testing.T) {
+	df := `FROM scratch
+	ADD s3://bucket/dir/file.tar?region=eu-west-1 /
+		`
+	_, _, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+
+	df = `FROM scratch
+	ADD s3://bucket /
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.ErrorContains(t, err, "expected s3://<bucket>/<key>")
+
+	caps := capsWithout(pb.CapSourceS3)
+	df = `FROM scratch
+	ADD s3://bucket/dir/file.tar /
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{LLBCaps: &caps})
+	require.EqualError(t, err, "ADD from S3 is not supported by the BuildKit daemon")
+}
@@ -0,0 +1,12 @@
+//go:build !dfaddociartifact
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/pkg/errors"
+)
+
+func ociArtifactSource(src string, _ ...llb.OCIArtifactOption) (llb.State, error) {
+	return llb.State{}, errors.Errorf("oci-artifact sources are only supported in Dockerfile frontend 1.20.0-labs or later")
+}
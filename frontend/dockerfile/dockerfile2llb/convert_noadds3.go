@@ -0,0 +1,12 @@
+//go:build !dfadds3
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/pkg/errors"
+)
+
+func s3Source(src string, _ ...llb.S3Option) (llb.State, string, error) {
+	return llb.State{}, "", errors.Errorf("s3 sources are only supported in Dockerfile frontend 1.20.0-labs or later")
+}
@@ -0,0 +1,12 @@
+//go:build !dfrunretry
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+)
+
+func dispatchRunRetry(_ *instructions.RunCommand, _ dispatchOpt) ([]llb.RunOption, error) {
+	return nil, nil
+}
@@ -117,6 +117,9 @@ func dispatchRunMounts(d *dispatchState, c *instructions.RunCommand, sources []*
 				mount.CacheID = path.Clean(mount.Target)
 			}
 			mountOpts = append(mountOpts, llb.AsPersistentCacheDir(opt.cacheIDNamespace+"/"+mount.CacheID, sharing))
+			if mount.SizeLimit > 0 {
+				mountOpts = append(mountOpts, llb.CacheMountMaxSize(mount.SizeLimit))
+			}
 		}
 		target := mount.Target
 		if !system.IsAbsolutePath(filepath.Clean(mount.Target)) {
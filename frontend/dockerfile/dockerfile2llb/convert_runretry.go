@@ -0,0 +1,27 @@
+//go:build dfrunretry
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+func dispatchRunRetry(c *instructions.RunCommand, dopt dispatchOpt) ([]llb.RunOption, error) {
+	var out []llb.RunOption
+	if d := instructions.GetTimeout(c); d > 0 {
+		if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaTimeout) != nil {
+			return nil, errors.New("RUN --timeout is not supported by the BuildKit daemon")
+		}
+		out = append(out, llb.WithTimeout(d))
+	}
+	if n := instructions.GetRetry(c); n > 0 {
+		if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaRetry) != nil {
+			return nil, errors.New("RUN --retry is not supported by the BuildKit daemon")
+		}
+		out = append(out, llb.WithRetry(n, 0))
+	}
+	return out, nil
+}
@@ -84,6 +84,18 @@ COPY --from=0 f2 /
 	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
 	require.EqualError(t, err, "source can't be a URL for COPY")
 
+	df = `FROM scratch
+	COPY s3://bucket/dir/file.tar /
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.EqualError(t, err, "source can't be a URL for COPY")
+
+	df = `FROM scratch
+	COPY oci-artifact://ghcr.io/example/models:v1 /models/
+		`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.EqualError(t, err, "source can't be a URL for COPY")
+
 	df = `FROM "" AS foo`
 	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
 	require.Error(t, err)
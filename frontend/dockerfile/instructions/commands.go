@@ -247,6 +247,11 @@ type AddCommand struct {
 	KeepGitDir      bool // whether to keep .git dir, only meaningful for git sources
 	Checksum        string
 	Unpack          *bool
+	SparsePaths     []string // paths to check out, only meaningful for git sources
+	VerifyKeys      []string // public keys that must have signed git sources or the signature
+	VerifyKeySecret string   // secret with public keys that must have signed git sources or the signature
+	Mirrors         []string // fallback URLs, only meaningful for HTTP sources
+	Signature       string   // URL of a detached signature, only meaningful for HTTP sources
 }
 
 func (c *AddCommand) Expand(expander SingleWordExpander) error {
@@ -268,6 +273,42 @@ func (c *AddCommand) Expand(expander SingleWordExpander) error {
 	}
 	c.Checksum = expandedChecksum
 
+	for i, p := range c.SparsePaths {
+		expanded, err := expander(p)
+		if err != nil {
+			return err
+		}
+		c.SparsePaths[i] = expanded
+	}
+
+	for i, k := range c.VerifyKeys {
+		expanded, err := expander(k)
+		if err != nil {
+			return err
+		}
+		c.VerifyKeys[i] = expanded
+	}
+
+	expandedVerifyKeySecret, err := expander(c.VerifyKeySecret)
+	if err != nil {
+		return err
+	}
+	c.VerifyKeySecret = expandedVerifyKeySecret
+
+	for i, m := range c.Mirrors {
+		expanded, err := expander(m)
+		if err != nil {
+			return err
+		}
+		c.Mirrors[i] = expanded
+	}
+
+	expandedSignature, err := expander(c.Signature)
+	if err != nil {
+		return err
+	}
+	c.Signature = expandedSignature
+
 	return c.SourcesAndDest.Expand(expander)
 }
 
@@ -221,7 +221,7 @@ func parseMount(val string, expander SingleWordExpander) (*Mount, error) {
 				return nil, errors.Errorf("unexpected key '%s' for mount type '%s'", key, m.Type)
 			}
 		case "size":
-			if m.Type == MountTypeTmpfs {
+			if m.Type == MountTypeTmpfs || m.Type == MountTypeCache {
 				m.SizeLimit, err = units.RAMInBytes(value)
 				if err != nil {
 					return nil, errors.Errorf("invalid value for %s: %s", key, value)
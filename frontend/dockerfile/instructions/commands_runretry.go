@@ -0,0 +1,74 @@
+//go:build dfrunretry
+
+package instructions
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var retryKey = "dockerfile/run/retry"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runRetryPreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runRetryPostHook)
+}
+
+func runRetryPreHook(cmd *RunCommand, req parseRequest) error {
+	st := &retryState{}
+	st.timeoutFlag = req.flags.AddString("timeout", "")
+	st.retryFlag = req.flags.AddString("retry", "")
+	cmd.setExternalValue(retryKey, st)
+	return nil
+}
+
+func runRetryPostHook(cmd *RunCommand, req parseRequest) error {
+	st := cmd.getExternalValue(retryKey).(*retryState)
+	if st == nil {
+		return errors.Errorf("no retry state")
+	}
+
+	if v := st.timeoutFlag.Value; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid timeout %q", v)
+		}
+		if d <= 0 {
+			return errors.Errorf("invalid timeout %q: must be positive", v)
+		}
+		st.timeout = d
+	}
+
+	if v := st.retryFlag.Value; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid retry %q", v)
+		}
+		if n < 0 {
+			return errors.Errorf("invalid retry %q: must not be negative", v)
+		}
+		st.retries = n
+	}
+
+	return nil
+}
+
+// GetTimeout returns the maximum duration of a single attempt of the RUN
+// command, or 0 if it is not limited.
+func GetTimeout(cmd *RunCommand) time.Duration {
+	return cmd.getExternalValue(retryKey).(*retryState).timeout
+}
+
+// GetRetry returns how many times the RUN command is retried if it fails.
+func GetRetry(cmd *RunCommand) int {
+	return cmd.getExternalValue(retryKey).(*retryState).retries
+}
+
+type retryState struct {
+	timeoutFlag *Flag
+	retryFlag   *Flag
+	timeout     time.Duration
+	retries     int
+}
@@ -0,0 +1,58 @@
+//go:build dfrunretry
+
+package instructions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRetry(t *testing.T) {
+	cases := []struct {
+		dockerfile  string
+		timeout     time.Duration
+		retry       int
+		expectedErr string
+	}{
+		{
+			dockerfile: "RUN echo hello",
+		},
+		{
+			dockerfile: "RUN --timeout=10m --retry=3 echo hello",
+			timeout:    10 * time.Minute,
+			retry:      3,
+		},
+		{
+			dockerfile:  "RUN --timeout=10 echo hello",
+			expectedErr: `invalid timeout "10"`,
+		},
+		{
+			dockerfile:  "RUN --timeout=-1s echo hello",
+			expectedErr: `invalid timeout "-1s": must be positive`,
+		},
+		{
+			dockerfile:  "RUN --retry=-1 echo hello",
+			expectedErr: `invalid retry "-1": must not be negative`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.dockerfile, func(t *testing.T) {
+			ast, err := parser.Parse(strings.NewReader(tc.dockerfile))
+			require.NoError(t, err)
+
+			c, err := ParseInstruction(ast.AST.Children[0])
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &RunCommand{}, c)
+			require.Equal(t, tc.timeout, GetTimeout(c.(*RunCommand)))
+			require.Equal(t, tc.retry, GetRetry(c.(*RunCommand)))
+		})
+	}
+}
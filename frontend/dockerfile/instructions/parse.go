@@ -38,6 +38,12 @@ var parseRunPostHooks []func(*RunCommand, parseRequest) error
 
 var parentsEnabled = false
 
+var addVerifyEnabled = false
+
+var addSparseEnabled = false
+
+var addMirrorEnabled = false
+
 func nodeArgs(node *parser.Node) []string {
 	result := []string{}
 	for ; node.Next != nil; node = node.Next {
@@ -321,6 +327,14 @@ func stringValuesFromFlagIfPossible(f *Flag) []string {
 	return f.StringValues
 }
 
+func stringValueFromFlagIfPossible(f *Flag) string {
+	if f == nil {
+		return ""
+	}
+
+	return f.Value
+}
+
 func parseAdd(req parseRequest) (*AddCommand, error) {
 	if len(req.args) < 2 {
 		return nil, errNoDestinationArgument("ADD")
@@ -339,6 +353,23 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 	flKeepGitDir := req.flags.AddBool("keep-git-dir", false)
 	flChecksum := req.flags.AddString("checksum", "")
 	flUnpack := req.flags.AddBool("unpack", false)
+
+	var flSparse *Flag
+	if addSparseEnabled {
+		flSparse = req.flags.AddStrings("sparse")
+	}
+
+	var flMirror *Flag
+	if addMirrorEnabled {
+		flMirror = req.flags.AddStrings("mirror")
+	}
+
+	var flVerifyKey, flVerifyKeySecret, flSignature *Flag
+	if addVerifyEnabled {
+		flVerifyKey = req.flags.AddStrings("verify-key")
+		flVerifyKeySecret = req.flags.AddString("verify-key-secret", "")
+		flSignature = req.flags.AddString("signature", "")
+	}
 	if err := req.flags.Parse(); err != nil {
 		return nil, err
 	}
@@ -364,6 +395,11 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 		Checksum:        flChecksum.Value,
 		ExcludePatterns: stringValuesFromFlagIfPossible(flExcludes),
 		Unpack:          unpack,
+		SparsePaths:     stringValuesFromFlagIfPossible(flSparse),
+		VerifyKeys:      stringValuesFromFlagIfPossible(flVerifyKey),
+		VerifyKeySecret: stringValueFromFlagIfPossible(flVerifyKeySecret),
+		Mirrors:         stringValuesFromFlagIfPossible(flMirror),
+		Signature:       stringValueFromFlagIfPossible(flSignature),
 	}, nil
 }
 
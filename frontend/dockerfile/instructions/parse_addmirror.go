@@ -0,0 +1,7 @@
+//go:build dfaddmirror
+
+package instructions
+
+func init() {
+	addMirrorEnabled = true
+}
@@ -0,0 +1,25 @@
+//go:build dfaddmirror
+
+package instructions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddMirrors(t *testing.T) {
+	dockerfile := `ADD --mirror=https://mirror1.example.com/foo.tar --mirror=${MIRROR} https://example.com/foo.tar /src`
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	cmd := c.(*AddCommand)
+	require.NoError(t, cmd.Expand(func(word string) (string, error) {
+		return strings.ReplaceAll(word, "${MIRROR}", "https://mirror2.example.com/foo.tar"), nil
+	}))
+	require.Equal(t, []string{"https://mirror1.example.com/foo.tar", "https://mirror2.example.com/foo.tar"}, cmd.Mirrors)
+}
@@ -0,0 +1,7 @@
+//go:build dfaddsparse
+
+package instructions
+
+func init() {
+	addSparseEnabled = true
+}
@@ -0,0 +1,25 @@
+//go:build dfaddsparse
+
+package instructions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSparsePaths(t *testing.T) {
+	dockerfile := "ADD --sparse=docs --sparse=$DIR https://github.com/moby/buildkit.git /src"
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	cmd := c.(*AddCommand)
+	require.NoError(t, cmd.Expand(func(word string) (string, error) {
+		return strings.ReplaceAll(word, "$DIR", "client"), nil
+	}))
+	require.Equal(t, []string{"docs", "client"}, cmd.SparsePaths)
+}
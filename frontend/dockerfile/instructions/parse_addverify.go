@@ -0,0 +1,7 @@
+//go:build dfaddverify
+
+package instructions
+
+func init() {
+	addVerifyEnabled = true
+}
@@ -0,0 +1,41 @@
+//go:build dfaddverify
+
+package instructions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddVerifyKeys(t *testing.T) {
+	dockerfile := `ADD --verify-key="ssh-ed25519 AAAA" --verify-key-secret=keys https://github.com/moby/buildkit.git /src`
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	cmd := c.(*AddCommand)
+	require.NoError(t, cmd.Expand(func(word string) (string, error) {
+		return word, nil
+	}))
+	require.Equal(t, []string{"ssh-ed25519 AAAA"}, cmd.VerifyKeys)
+	require.Equal(t, "keys", cmd.VerifyKeySecret)
+}
+
+func TestAddSignature(t *testing.T) {
+	dockerfile := `ADD --signature=https://example.com/foo.tar.minisig --verify-key-secret=keys https://example.com/foo.tar /src`
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	cmd := c.(*AddCommand)
+	require.NoError(t, cmd.Expand(func(word string) (string, error) {
+		return word, nil
+	}))
+	require.Equal(t, "https://example.com/foo.tar.minisig", cmd.Signature)
+	require.Equal(t, "keys", cmd.VerifyKeySecret)
+}
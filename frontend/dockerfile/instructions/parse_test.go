@@ -272,6 +272,34 @@ func TestRunCmdFlagsUsed(t *testing.T) {
 	require.Equal(t, []string{"mount"}, c.(*RunCommand).FlagsUsed)
 }
 
+func TestRunCacheMountSize(t *testing.T) {
+	expander := func(word string) (string, error) {
+		return word, nil
+	}
+
+	dockerfile := "RUN --mount=type=cache,target=/root/.cache,size=1G echo hello"
+	ast, err := parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	cmd := c.(*RunCommand)
+	require.NoError(t, setMountState(cmd, expander))
+	mounts := GetMounts(cmd)
+	require.Len(t, mounts, 1)
+	require.Equal(t, MountTypeCache, mounts[0].Type)
+	require.Equal(t, int64(1<<30), mounts[0].SizeLimit)
+
+	dockerfile = "RUN --mount=type=bind,target=/src,size=1G echo hello"
+	ast, err = parser.Parse(strings.NewReader(dockerfile))
+	require.NoError(t, err)
+
+	c, err = ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	err = setMountState(c.(*RunCommand), expander)
+	require.ErrorContains(t, err, "unexpected key 'size' for mount type 'bind'")
+}
+
 func BenchmarkParseBuildStageName(b *testing.B) {
 	b.ReportAllocs()
 	stageNames := []string{"STAGE_NAME", "StageName", "St4g3N4m3"}
@@ -633,7 +633,8 @@ func (lbf *llbBridgeForwarder) ResolveSourceMeta(ctx context.Context, req *pb.Re
 		Platform:       platform,
 	}
 	resolveopt.ImageOpt = &sourceresolver.ResolveImageOpt{
-		ResolveMode: req.ResolveMode,
+		ResolveMode:         req.ResolveMode,
+		ResolveAttestations: req.ResolveAttestations,
 	}
 	resp, err := lbf.llbBridge.ResolveSourceMetadata(ctx, req.Source, resolveopt)
 	if err != nil {
@@ -649,6 +650,14 @@ func (lbf *llbBridgeForwarder) ResolveSourceMeta(ctx context.Context, req *pb.Re
 			Digest: string(resp.Image.Digest),
 			Config: resp.Image.Config,
 		}
+		for _, att := range resp.Image.Attestations {
+			r.Image.Attestations = append(r.Image.Attestations, &pb.ResolveSourceImageAttestation{
+				Manifest:      string(att.Manifest),
+				PredicateType: att.PredicateType,
+				MediaType:     att.MediaType,
+				Data:          att.Data,
+			})
+		}
 	}
 	return r, nil
 }
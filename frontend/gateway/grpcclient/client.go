@@ -469,6 +469,11 @@ func (c *grpcClient) Solve(ctx context.Context, creq client.SolveRequest) (res *
 }
 
 func (c *grpcClient) ResolveSourceMetadata(ctx context.Context, op *opspb.SourceOp, opt sourceresolver.Opt) (*sourceresolver.MetaResponse, error) {
+	if opt.ImageOpt != nil && opt.ImageOpt.ResolveAttestations {
+		if err := c.caps.Supports(pb.CapSourceMetaResolverAttestations); err != nil {
+			return nil, err
+		}
+	}
 	if c.caps.Supports(pb.CapSourceMetaResolver) != nil {
 		var ref string
 		if v, ok := strings.CutPrefix(op.Identifier, "docker-image://"); ok {
@@ -514,6 +519,9 @@ func (c *grpcClient) ResolveSourceMetadata(ctx context.Context, op *opspb.Source
 		LogName:        opt.LogName,
 		SourcePolicies: opt.SourcePolicies,
 	}
+	if opt.ImageOpt != nil {
+		req.ResolveAttestations = opt.ImageOpt.ResolveAttestations
+	}
 	resp, err := c.client.ResolveSourceMeta(ctx, req)
 	if err != nil {
 		return nil, err
@@ -527,6 +535,14 @@ func (c *grpcClient) ResolveSourceMetadata(ctx context.Context, op *opspb.Source
 			Digest: digest.Digest(resp.Image.Digest),
 			Config: resp.Image.Config,
 		}
+		for _, att := range resp.Image.Attestations {
+			r.Image.Attestations = append(r.Image.Attestations, sourceresolver.ImageAttestation{
+				Manifest:      digest.Digest(att.Manifest),
+				PredicateType: att.PredicateType,
+				MediaType:     att.MediaType,
+				Data:          att.Data,
+			})
+		}
 	}
 	return r, nil
 }
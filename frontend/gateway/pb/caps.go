@@ -72,6 +72,10 @@ const (
 	// CapSourceMetaResolver is the capability to indicates support for ResolveSourceMetadata
 	// function in gateway API
 	CapSourceMetaResolver apicaps.CapID = "source.metaresolver"
+
+	// CapSourceMetaResolverAttestations is the capability to indicate support
+	// for resolving the attestations of images with ResolveSourceMetadata
+	CapSourceMetaResolverAttestations apicaps.CapID = "source.metaresolver.attestations"
 )
 
 func init() {
@@ -242,4 +246,11 @@ func init() {
 		Enabled: true,
 		Status:  apicaps.CapStatusExperimental,
 	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceMetaResolverAttestations,
+		Name:    "source meta resolver attestations",
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
 }
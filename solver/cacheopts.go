@@ -15,6 +15,20 @@ type progressKey struct{}
 
 type cacheOptGetterKey struct{}
 
+type cacheManagerKey struct{}
+
+// CacheManagerOf returns the cache manager of the vertex being executed,
+// including the cache imported for the build, or nil if ctx is not the
+// context of an executing vertex.
+func CacheManagerOf(ctx context.Context) CacheManager {
+	cm, _ := ctx.Value(cacheManagerKey{}).(CacheManager)
+	return cm
+}
+
+func withCacheManager(ctx context.Context, cm CacheManager) context.Context {
+	return context.WithValue(ctx, cacheManagerKey{}, cm)
+}
+
 func CacheOptGetterOf(ctx context.Context) func(includeAncestors bool, keys ...any) map[any]any {
 	if v := ctx.Value(cacheOptGetterKey{}); v != nil {
 		if getter, ok := v.(func(includeAncestors bool, keys ...any) map[any]any); ok {
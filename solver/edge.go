@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/internal/pipe"
 	"github.com/moby/buildkit/util/bklog"
 	digest "github.com/opencontainers/go-digest"
@@ -331,6 +332,12 @@ func (e *edge) unpark(incoming []pipeSender, updates, allPipes []pipeReceiver, f
 	// process all incoming changes
 	e.processUpdates(updates)
 
+	if e.err != nil && e.op.KeepGoing() {
+		if e.completeDeps(incoming, f) {
+			return
+		}
+	}
+
 	desiredState, done := e.respondToIncoming(incoming, allPipes)
 	if done {
 		return
@@ -363,6 +370,43 @@ func (e *edge) unpark(incoming []pipeSender, updates, allPipes []pipeReceiver, f
 	}
 }
 
+// completeDeps is called in keep-going mode after the edge has failed. The
+// inputs that did not fail are built to completion so that their results are
+// cached and their errors are reported together with the error of the edge.
+// Returns true while there are inputs left to complete.
+func (e *edge) completeDeps(incoming []pipeSender, f *pipeFactory) bool {
+	allCanceled := true
+	for _, req := range incoming {
+		if req.Request().Canceled {
+			e.finishIncoming(req)
+		} else {
+			allCanceled = false
+		}
+	}
+	if allCanceled {
+		return false
+	}
+
+	e.ensureDepsInitialized()
+	var active bool
+	var errs []error
+	for _, dep := range e.deps {
+		if dep.err != nil {
+			errs = append(errs, dep.err)
+			continue
+		}
+		if dep.state == edgeStatusComplete {
+			continue
+		}
+		e.createOutgoingRequest(dep, edgeStatusComplete, f)
+		active = true
+	}
+	if !active && len(errs) > 0 {
+		e.err = errdefs.NewFailuresError(append([]error{e.err}, errs...)...)
+	}
+	return active
+}
+
 func (e *edge) makeExportable(k *CacheKey, records []*CacheRecord) ExportableCacheKey {
 	return ExportableCacheKey{
 		CacheKey: k,
@@ -0,0 +1,60 @@
+package errdefs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/containerd/typeurl/v2"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/offline"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	typeurl.Register((*CacheOnly)(nil), "github.com/moby/buildkit", "errdefs.CacheOnly+json")
+}
+
+// CacheOnlyError is returned when a build in cache-only mode would need to
+// execute vertexes that could not be loaded from the cache.
+type CacheOnlyError struct {
+	*CacheOnly
+	error
+}
+
+func (e *CacheOnlyError) Unwrap() error {
+	return e.error
+}
+
+func (e *CacheOnlyError) ToProto() grpcerrors.TypedErrorProto {
+	return e.CacheOnly
+}
+
+func NewCacheOnlyError(vtxs ...*CacheOnlyVertex) error {
+	names := make([]string, 0, len(vtxs))
+	for _, v := range vtxs {
+		name := v.Name
+		if name == "" {
+			name = v.Digest
+		}
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	err := errors.Errorf("cache-only mode: %d vertexes could not be loaded from the cache: %s", len(vtxs), strings.Join(names, ", "))
+	return &CacheOnlyError{CacheOnly: &CacheOnly{Vertexes: vtxs}, error: err}
+}
+
+func (v *CacheOnly) WrapError(err error) error {
+	return &CacheOnlyError{error: err, CacheOnly: v}
+}
+
+// WithCacheOnlyVertex returns a CacheOnlyError for the vertex if err is caused
+// by a source that would need to access the network in cache-only mode.
+func WithCacheOnlyVertex(err error, dgst, name string) error {
+	if err == nil || !errors.Is(err, offline.ErrNetworkAccess) {
+		return err
+	}
+	var coe *CacheOnlyError
+	if errors.As(err, &coe) {
+		return err
+	}
+	return (&CacheOnly{Vertexes: []*CacheOnlyVertex{{Digest: dgst, Name: name}}}).WrapError(err)
+}
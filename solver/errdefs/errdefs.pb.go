@@ -462,6 +462,211 @@ func (x *ContentCache) GetIndex() int64 {
 	return 0
 }
 
+type CacheOnly struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Vertexes that would need to be executed because they could not be
+	// loaded from the cache.
+	Vertexes      []*CacheOnlyVertex `protobuf:"bytes,1,rep,name=vertexes,proto3" json:"vertexes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheOnly) Reset() {
+	*x = CacheOnly{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheOnly) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheOnly) ProtoMessage() {}
+
+func (x *CacheOnly) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheOnly.ProtoReflect.Descriptor instead.
+func (*CacheOnly) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CacheOnly) GetVertexes() []*CacheOnlyVertex {
+	if x != nil {
+		return x.Vertexes
+	}
+	return nil
+}
+
+type CacheOnlyVertex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheOnlyVertex) Reset() {
+	*x = CacheOnlyVertex{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheOnlyVertex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheOnlyVertex) ProtoMessage() {}
+
+func (x *CacheOnlyVertex) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheOnlyVertex.ProtoReflect.Descriptor instead.
+func (*CacheOnlyVertex) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CacheOnlyVertex) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *CacheOnlyVertex) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Failures contains the errors of all the vertexes that failed in a
+// keep-going build.
+type Failures struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Failure             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failures) Reset() {
+	*x = Failures{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failures) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failures) ProtoMessage() {}
+
+func (x *Failures) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failures.ProtoReflect.Descriptor instead.
+func (*Failures) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Failures) GetItems() []*Failure {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type Failure struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Digest of the vertex that failed.
+	Digest        string    `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Message       string    `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sources       []*Source `protobuf:"bytes,3,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failure) Reset() {
+	*x = Failure{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failure) ProtoMessage() {}
+
+func (x *Failure) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failure.ProtoReflect.Descriptor instead.
+func (*Failure) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Failure) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *Failure) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Failure) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
 var File_github_com_moby_buildkit_solver_errdefs_errdefs_proto protoreflect.FileDescriptor
 
 const file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc = "" +
@@ -495,7 +700,18 @@ const file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc = "" +
 	"FileAction\x12\x14\n" +
 	"\x05index\x18\x01 \x01(\x03R\x05index\"$\n" +
 	"\fContentCache\x12\x14\n" +
-	"\x05index\x18\x01 \x01(\x03R\x05indexB)Z'github.com/moby/buildkit/solver/errdefsb\x06proto3"
+	"\x05index\x18\x01 \x01(\x03R\x05index\"A\n" +
+	"\tCacheOnly\x124\n" +
+	"\bvertexes\x18\x01 \x03(\v2\x18.errdefs.CacheOnlyVertexR\bvertexes\"=\n" +
+	"\x0fCacheOnlyVertex\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"2\n" +
+	"\bFailures\x12&\n" +
+	"\x05items\x18\x01 \x03(\v2\x10.errdefs.FailureR\x05items\"f\n" +
+	"\aFailure\x12\x16\n" +
+	"\x06digest\x18\x01 \x01(\tR\x06digest\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
+	"\asources\x18\x03 \x03(\v2\x0f.errdefs.SourceR\asourcesB)Z'github.com/moby/buildkit/solver/errdefsb\x06proto3"
 
 var (
 	file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescOnce sync.Once
@@ -509,33 +725,40 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP() []
 	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescData
 }
 
-var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_goTypes = []any{
-	(*Vertex)(nil),        // 0: errdefs.Vertex
-	(*Source)(nil),        // 1: errdefs.Source
-	(*Frontend)(nil),      // 2: errdefs.Frontend
-	(*FrontendCap)(nil),   // 3: errdefs.FrontendCap
-	(*Subrequest)(nil),    // 4: errdefs.Subrequest
-	(*Solve)(nil),         // 5: errdefs.Solve
-	(*FileAction)(nil),    // 6: errdefs.FileAction
-	(*ContentCache)(nil),  // 7: errdefs.ContentCache
-	nil,                   // 8: errdefs.Solve.DescriptionEntry
-	(*pb.SourceInfo)(nil), // 9: pb.SourceInfo
-	(*pb.Range)(nil),      // 10: pb.Range
-	(*pb.Op)(nil),         // 11: pb.Op
+	(*Vertex)(nil),          // 0: errdefs.Vertex
+	(*Source)(nil),          // 1: errdefs.Source
+	(*Frontend)(nil),        // 2: errdefs.Frontend
+	(*FrontendCap)(nil),     // 3: errdefs.FrontendCap
+	(*Subrequest)(nil),      // 4: errdefs.Subrequest
+	(*Solve)(nil),           // 5: errdefs.Solve
+	(*FileAction)(nil),      // 6: errdefs.FileAction
+	(*ContentCache)(nil),    // 7: errdefs.ContentCache
+	(*CacheOnly)(nil),       // 8: errdefs.CacheOnly
+	(*CacheOnlyVertex)(nil), // 9: errdefs.CacheOnlyVertex
+	(*Failures)(nil),        // 10: errdefs.Failures
+	(*Failure)(nil),         // 11: errdefs.Failure
+	nil,                     // 12: errdefs.Solve.DescriptionEntry
+	(*pb.SourceInfo)(nil),   // 13: pb.SourceInfo
+	(*pb.Range)(nil),        // 14: pb.Range
+	(*pb.Op)(nil),           // 15: pb.Op
 }
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_depIdxs = []int32{
-	9,  // 0: errdefs.Source.info:type_name -> pb.SourceInfo
-	10, // 1: errdefs.Source.ranges:type_name -> pb.Range
-	11, // 2: errdefs.Solve.op:type_name -> pb.Op
+	13, // 0: errdefs.Source.info:type_name -> pb.SourceInfo
+	14, // 1: errdefs.Source.ranges:type_name -> pb.Range
+	15, // 2: errdefs.Solve.op:type_name -> pb.Op
 	6,  // 3: errdefs.Solve.file:type_name -> errdefs.FileAction
 	7,  // 4: errdefs.Solve.cache:type_name -> errdefs.ContentCache
-	8,  // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
-	6,  // [6:6] is the sub-list for method output_type
-	6,  // [6:6] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	12, // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
+	9,  // 6: errdefs.CacheOnly.vertexes:type_name -> errdefs.CacheOnlyVertex
+	11, // 7: errdefs.Failures.items:type_name -> errdefs.Failure
+	1,  // 8: errdefs.Failure.sources:type_name -> errdefs.Source
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_init() }
@@ -553,7 +776,7 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc), len(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
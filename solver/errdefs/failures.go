@@ -0,0 +1,86 @@
+package errdefs
+
+import (
+	"errors"
+
+	"github.com/containerd/typeurl/v2"
+	"github.com/moby/buildkit/util/grpcerrors"
+)
+
+func init() {
+	typeurl.Register((*Failures)(nil), "github.com/moby/buildkit", "errdefs.Failures+json")
+}
+
+// FailuresError is returned when more than one vertex failed in a keep-going
+// build. Items describe the failures with the source locations of the failed
+// vertexes.
+type FailuresError struct {
+	*Failures
+	error
+}
+
+func (e *FailuresError) Unwrap() error {
+	return e.error
+}
+
+func (e *FailuresError) ToProto() grpcerrors.TypedErrorProto {
+	return e.Failures
+}
+
+// NewFailuresError aggregates the errors of failed vertexes. Errors returned
+// by NewFailuresError are flattened and errors for the same vertex are only
+// kept once. If a single error remains it is returned as is.
+func NewFailuresError(errs ...error) error {
+	var (
+		out   []error
+		items []*Failure
+	)
+	seen := map[string]struct{}{}
+	add := func(err error) {
+		var dgst string
+		var ve *VertexError
+		if errors.As(err, &ve) {
+			dgst = ve.Digest
+		}
+		key := dgst
+		if key == "" {
+			key = err.Error()
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		out = append(out, err)
+		items = append(items, &Failure{
+			Digest:  dgst,
+			Message: err.Error(),
+			Sources: Sources(err),
+		})
+	}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var fe *FailuresError
+		if errors.As(err, &fe) {
+			if joined, ok := fe.error.(interface{ Unwrap() []error }); ok {
+				for _, err := range joined.Unwrap() {
+					add(err)
+				}
+				continue
+			}
+		}
+		add(err)
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	}
+	return &FailuresError{Failures: &Failures{Items: items}, error: errors.Join(out...)}
+}
+
+func (v *Failures) WrapError(err error) error {
+	return &FailuresError{error: err, Failures: v}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"time"
 
 	digest "github.com/opencontainers/go-digest"
 )
@@ -280,3 +281,12 @@ func (e *mergedExporter) ExportTo(ctx context.Context, t CacheExporterTarget, op
 	}
 	return
 }
+
+// ExportRootResult adds a result that is not the output of a vertex, eg. the
+// contents of a cache mount, to the export target under the key dgst. After
+// the exported cache has been imported the result can be found with
+// CacheManager.Query(nil, 0, dgst, 0).
+func ExportRootResult(t CacheExporterTarget, dgst digest.Digest, createdAt time.Time, remote *Remote) {
+	rec := t.Add(rootKey(dgst, 0))
+	rec.AddResult(dgst, 0, createdAt, remote)
+}
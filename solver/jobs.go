@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,7 +13,9 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/fairsem"
 	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/controller"
 	"github.com/moby/buildkit/util/tracing"
@@ -235,6 +238,71 @@ func (s *state) addJobs(srcState *state, memo map[*state]struct{}) {
 	}
 }
 
+// cacheOnly returns true if all the jobs that loaded the vertex are in
+// cache-only mode.
+func (s *state) cacheOnly() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return false
+	}
+	for j := range s.jobs {
+		if !j.isCacheOnly() {
+			return false
+		}
+	}
+	return true
+}
+
+// planOnly returns true if all the jobs that loaded the vertex are in plan
+// mode.
+func (s *state) planOnly() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return false
+	}
+	for j := range s.jobs {
+		if !j.isPlanOnly() {
+			return false
+		}
+	}
+	return true
+}
+
+// keepGoing returns true if all the jobs that loaded the vertex are in
+// keep-going mode.
+func (s *state) keepGoing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return false
+	}
+	for j := range s.jobs {
+		if !j.isKeepGoing() {
+			return false
+		}
+	}
+	return true
+}
+
+// schedGroup returns the job with the highest weight of the jobs that loaded
+// the vertex. Operations of the vertex are scheduled on its behalf.
+func (s *state) schedGroup() (string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		id     string
+		weight int
+	)
+	for j := range s.jobs {
+		if w := j.getWeight(); id == "" || w > weight {
+			id, weight = j.id, w
+		}
+	}
+	return id, weight
+}
+
 func (s *state) combinedCacheManager() CacheManager {
 	s.mu.Lock()
 	cms := make([]CacheManager, 0, len(s.cache)+1)
@@ -300,7 +368,7 @@ func (sb *subBuilder) EachValue(ctx context.Context, key string, fn func(any) er
 }
 
 type Job struct {
-	mu            sync.Mutex // protects completedTime, pw, span
+	mu            sync.Mutex // protects completedTime, pw, span, cacheOnly, planOnly, keepGoing, weight
 	list          *Solver
 	pr            *progress.MultiReader
 	pw            progress.Writer
@@ -309,6 +377,10 @@ type Job struct {
 	id            string
 	startedTime   time.Time
 	completedTime time.Time
+	cacheOnly     bool
+	planOnly      bool
+	keepGoing     bool
+	weight        int
 
 	progressCloser func(error)
 	SessionID      string
@@ -662,6 +734,17 @@ func (jl *Solver) Get(id string) (*Job, error) {
 	}
 }
 
+// Jobs returns the jobs that have not been discarded yet.
+func (jl *Solver) Jobs() []*Job {
+	jl.mu.RLock()
+	defer jl.mu.RUnlock()
+	jobs := make([]*Job, 0, len(jl.jobs))
+	for _, j := range jl.jobs {
+		jobs = append(jobs, j)
+	}
+	return jobs
+}
+
 // called with solver lock
 func (jl *Solver) deleteIfUnreferenced(k digest.Digest, st *state) {
 	if len(st.jobs) == 0 && len(st.parents) == 0 {
@@ -764,6 +847,79 @@ func (j *Job) walkProvenance(ctx context.Context, e Edge, f func(ProvenanceProvi
 	return nil
 }
 
+// VertexCacheInfo describes how the cache keys of a vertex were computed
+// during a job.
+type VertexCacheInfo struct {
+	Vertex Vertex
+	// Op is the resolved operation of the vertex. Nil if the vertex was never
+	// evaluated.
+	Op Op
+	// CacheMaps are the cache maps returned by the operation.
+	CacheMaps []*CacheMap
+	// ContentKeys are the content based cache keys computed for the inputs.
+	ContentKeys map[Index]digest.Digest
+	// Cached is set if the result of the vertex was loaded from cache.
+	Cached bool
+	// Executed is set if the operation of the vertex was run.
+	Executed bool
+}
+
+// CacheInfo returns the cache key information of all the vertexes loaded by
+// the job, including the ones loaded by its sub-builds.
+func (j *Job) CacheInfo() []VertexCacheInfo {
+	j.list.mu.RLock()
+	defer j.list.mu.RUnlock()
+
+	var out []VertexCacheInfo
+	visited := map[digest.Digest]struct{}{}
+
+	var walk func(k digest.Digest, st *state)
+	walk = func(k digest.Digest, st *state) {
+		if _, ok := visited[k]; ok {
+			return
+		}
+		visited[k] = struct{}{}
+
+		st.mu.Lock()
+		info := VertexCacheInfo{
+			Vertex: st.vtx,
+			Cached: st.clientVertex.Cached,
+		}
+		if so := st.op; so != nil {
+			info.Op = so.op
+			info.CacheMaps = append(info.CacheMaps, so.cacheRes...)
+			info.Executed = so.execDone && so.execErr == nil
+			so.slowMu.Lock()
+			if len(so.slowCacheRes) > 0 {
+				info.ContentKeys = maps.Clone(so.slowCacheRes)
+			}
+			so.slowMu.Unlock()
+		}
+		children := make([]digest.Digest, 0, len(st.childVtx))
+		for ch := range st.childVtx {
+			children = append(children, ch)
+		}
+		st.mu.Unlock()
+
+		out = append(out, info)
+		for _, ch := range children {
+			if chState, ok := j.list.actives[ch]; ok {
+				walk(ch, chState)
+			}
+		}
+	}
+
+	for k, st := range j.list.actives {
+		st.mu.Lock()
+		_, ok := st.jobs[j]
+		st.mu.Unlock()
+		if ok {
+			walk(k, st)
+		}
+	}
+	return out
+}
+
 func (j *Job) CloseProgress() {
 	j.progressCloser(errors.WithStack(context.Canceled))
 	j.pw.Close()
@@ -824,6 +980,65 @@ func (j *Job) InContext(ctx context.Context, f func(context.Context, session.Gro
 	return f(progress.WithProgress(ctx, j.pw), session.NewGroup(j.SessionID))
 }
 
+// SetCacheOnly disallows executing operations and accessing the network for
+// resolving sources. Vertexes of the job that can't be loaded from the cache
+// fail with errdefs.CacheOnlyError.
+func (j *Job) SetCacheOnly(v bool) {
+	j.mu.Lock()
+	j.cacheOnly = v
+	j.mu.Unlock()
+}
+
+func (j *Job) isCacheOnly() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.cacheOnly
+}
+
+// SetPlanOnly disallows executing operations. Unlike cache-only mode, sources
+// may still be resolved from the network to compute the cache keys of the
+// build plan.
+func (j *Job) SetPlanOnly(v bool) {
+	j.mu.Lock()
+	j.planOnly = v
+	j.mu.Unlock()
+}
+
+func (j *Job) isPlanOnly() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.planOnly
+}
+
+// SetKeepGoing continues building the inputs of a vertex that did not fail
+// after another input failed. The errors of all the failed vertexes are
+// returned in errdefs.FailuresError.
+func (j *Job) SetKeepGoing(v bool) {
+	j.mu.Lock()
+	j.keepGoing = v
+	j.mu.Unlock()
+}
+
+func (j *Job) isKeepGoing() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.keepGoing
+}
+
+// SetWeight sets the share of the worker parallelism the operations of the
+// job get when they compete with the operations of other jobs.
+func (j *Job) SetWeight(v int) {
+	j.mu.Lock()
+	j.weight = v
+	j.mu.Unlock()
+}
+
+func (j *Job) getWeight() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.weight
+}
+
 func (j *Job) SetValue(key string, v any) {
 	j.values.Store(key, v)
 }
@@ -846,6 +1061,7 @@ type activeOp interface {
 	LoadCache(ctx context.Context, rec *CacheRecord) (Result, error)
 	Exec(ctx context.Context, inputs []Result) (outputs []Result, exporters []ExportableCacheKey, err error)
 	IgnoreCache() bool
+	KeepGoing() bool
 	Cache() CacheManager
 	CalcSlowCache(context.Context, Index, PreprocessFunc, ResultBasedCacheFunc, Result) (digest.Digest, error)
 }
@@ -894,6 +1110,19 @@ func (s *sharedOp) IgnoreCache() bool {
 	return s.st.vtx.Options().IgnoreCache
 }
 
+// isRandom returns true if the cache key of the op is random and its result
+// can never be loaded from the cache.
+func (s *sharedOp) isRandom() bool {
+	if len(s.cacheRes) == 0 {
+		return false
+	}
+	return strings.HasPrefix(s.cacheRes[len(s.cacheRes)-1].Digest.String(), "random:")
+}
+
+func (s *sharedOp) KeepGoing() bool {
+	return s.st.keepGoing()
+}
+
 func (s *sharedOp) Cache() CacheManager {
 	return &cacheWithCacheOpts{s.st.combinedCacheManager(), s.st}
 }
@@ -1008,6 +1237,7 @@ func (s *sharedOp) CalcSlowCache(ctx context.Context, index Index, p PreprocessF
 
 func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp, err error) {
 	defer func() {
+		err = errdefs.WithCacheOnlyVertex(err, s.st.origDigest.String(), s.st.vtx.Name())
 		err = errdefs.WithOp(err, s.st.vtx.Sys(), s.st.vtx.Options().Description)
 		err = errdefs.WrapVertex(err, s.st.origDigest)
 	}()
@@ -1028,6 +1258,10 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 			ctx = trace.ContextWithSpan(ctx, s.st.mspan)
 		}
 		ctx = withAncestorCacheOpts(ctx, s.st)
+		ctx = withCacheManager(ctx, s.st.combinedCacheManager())
+		if s.st.cacheOnly() {
+			ctx = offline.WithOffline(ctx)
+		}
 		if len(s.st.vtx.Inputs()) == 0 {
 			// no cache hit. start evaluating the node
 			span, ctx := tracing.StartSpan(ctx, "cache request: "+s.st.vtx.Name(), trace.WithAttributes(attribute.String("vertex", s.st.vtx.Digest().String())))
@@ -1038,6 +1272,7 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 			}()
 		}
 		res, done, err := op.CacheMap(ctx, s.st, len(s.cacheRes))
+		err = errdefs.WithCacheOnlyVertex(err, s.st.origDigest.String(), s.st.vtx.Name())
 		complete := true
 		if err != nil {
 			select {
@@ -1081,6 +1316,7 @@ func (s *sharedOp) CacheMap(ctx context.Context, index int) (resp *cacheMapResp,
 
 func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result, exporters []ExportableCacheKey, err error) {
 	defer func() {
+		err = errdefs.WithCacheOnlyVertex(err, s.st.origDigest.String(), s.st.vtx.Name())
 		err = errdefs.WithOp(err, s.st.vtx.Sys(), s.st.vtx.Options().Description)
 		err = errdefs.WrapVertex(err, s.st.origDigest)
 	}()
@@ -1096,17 +1332,41 @@ func (s *sharedOp) Exec(ctx context.Context, inputs []Result) (outputs []Result,
 			}
 			return s.execRes, nil
 		}
-		release, err := op.Acquire(ctx)
+		// results loaded from the client session, eg. the local build context,
+		// are never cached and are allowed in cache-only mode
+		if s.st.cacheOnly() && !s.isRandom() {
+			return nil, errdefs.NewCacheOnlyError(&errdefs.CacheOnlyVertex{
+				Digest: s.st.origDigest.String(),
+				Name:   s.st.vtx.Name(),
+			})
+		}
+		if s.st.planOnly() && !s.isRandom() {
+			return nil, errors.Errorf("%s can not be executed in plan mode", s.st.vtx.Name())
+		}
+		id, weight := s.st.schedGroup()
+		acquire := func(ctx context.Context) (ReleaseFunc, error) {
+			release, err := op.Acquire(fairsem.WithGroup(ctx, id, weight))
+			return release, errors.Wrap(err, "acquire op resources")
+		}
+		release, err := acquire(ctx)
 		if err != nil {
-			return nil, errors.Wrap(err, "acquire op resources")
+			return nil, err
 		}
-		defer release()
+		slot := &opSlot{acquire: acquire, release: release}
+		defer func() {
+			slot.release()
+		}()
+		ctx = withOpSlot(ctx, slot)
 
 		ctx = progress.WithProgress(ctx, s.st.mpw)
 		if s.st.mspan.Span != nil {
 			ctx = trace.ContextWithSpan(ctx, s.st.mspan)
 		}
 		ctx = withAncestorCacheOpts(ctx, s.st)
+		ctx = withCacheManager(ctx, s.st.combinedCacheManager())
+		if s.st.cacheOnly() {
+			ctx = offline.WithOffline(ctx)
+		}
 
 		// no cache hit. start evaluating the node
 		span, ctx := tracing.StartSpan(ctx, s.st.vtx.Name(), trace.WithAttributes(attribute.String("vertex", s.st.vtx.Digest().String())))
@@ -42,6 +42,7 @@ type llbBridge struct {
 	cms                       map[string]solver.CacheManager
 	cmsMu                     sync.Mutex
 	sm                        *session.Manager
+	sourcePolicy              *daemonSourcePolicy
 
 	executorOnce sync.Once
 	executorErr  error
@@ -72,38 +73,60 @@ func (b *llbBridge) Warn(ctx context.Context, dgst digest.Digest, msg string, op
 }
 
 func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImports []gw.CacheOptionsEntry, pol []*spb.Policy) (solver.CachedResultWithProvenance, error) {
-	w, err := b.resolveWorker()
+	edge, err := b.loadEdge(ctx, def, cacheImports, pol)
 	if err != nil {
 		return nil, err
 	}
-	ent, err := loadEntitlements(b.builder)
+	res, err := b.builder.Build(ctx, edge)
 	if err != nil {
 		return nil, err
 	}
+	return res, nil
+}
+
+func (b *llbBridge) loadEdge(ctx context.Context, def *pb.Definition, cacheImports []gw.CacheOptionsEntry, pol []*spb.Policy) (solver.Edge, error) {
+	w, err := b.resolveWorker()
+	if err != nil {
+		return solver.Edge{}, err
+	}
+	ent, err := loadEntitlements(b.builder)
+	if err != nil {
+		return solver.Edge{}, err
+	}
 	srcPol, err := loadSourcePolicy(b.builder)
 	if err != nil {
-		return nil, err
+		return solver.Edge{}, err
+	}
+	frontendOpt, err := loadFrontendOpt(b.builder)
+	if err != nil {
+		return solver.Edge{}, err
 	}
+	ctx = sourcepolicy.WithFrontendAttrs(ctx, frontendOpt)
 	var polEngine SourcePolicyEvaluator
-	if srcPol != nil || len(pol) > 0 {
+	var polTraces []*sourcepolicy.Trace
+	if srcPol != nil || len(pol) > 0 || b.sourcePolicy != nil {
 		for _, p := range pol {
 			if p == nil {
-				return nil, errors.Errorf("invalid nil policy")
+				return solver.Edge{}, errors.Errorf("invalid nil policy")
 			}
 			if err := validateSourcePolicy(p); err != nil {
-				return nil, err
+				return solver.Edge{}, err
 			}
 		}
 		if srcPol != nil {
 			pol = append([]*spb.Policy{srcPol}, pol...)
 		}
-		polEngine = sourcepolicy.NewEngine(pol)
+		e := b.sourcePolicy.engine(ctx, pol)
+		e.SetTracer(func(tr *sourcepolicy.Trace) {
+			polTraces = append(polTraces, tr)
+		})
+		polEngine = e
 	}
 	var cms []solver.CacheManager
 	for _, im := range cacheImports {
 		cmID, err := cmKey(im)
 		if err != nil {
-			return nil, err
+			return solver.Edge{}, err
 		}
 		b.cmsMu.Lock()
 		var cm solver.CacheManager
@@ -139,23 +162,24 @@ func (b *llbBridge) loadResult(ctx context.Context, def *pb.Definition, cacheImp
 	dpc := &detectPrunedCacheID{}
 
 	edge, err := Load(ctx, def, polEngine, dpc.Load, ValidateEntitlements(ent, w.CDIManager()), WithCacheSources(cms), NormalizeRuntimePlatforms(), WithValidateCaps())
+	reportSourcePolicy(ctx, b.builder, polTraces)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load LLB")
+		return solver.Edge{}, errors.Wrap(err, "failed to load LLB")
 	}
 
-	if len(dpc.ids) > 0 {
+	planOnly, err := loadPlanOnly(b.builder)
+	if err != nil {
+		return solver.Edge{}, err
+	}
+	// cache mounts are not pruned while the build is only planned
+	if len(dpc.ids) > 0 && !planOnly {
 		if err := b.eachWorker(func(w worker.Worker) error {
 			return w.PruneCacheMounts(ctx, dpc.ids)
 		}); err != nil {
-			return nil, err
+			return solver.Edge{}, err
 		}
 	}
-
-	res, err := b.builder.Build(ctx, edge)
-	if err != nil {
-		return nil, err
-	}
-	return res, nil
+	return edge, nil
 }
 
 func (b *llbBridge) validateEntitlements(p executor.ProcessInfo) error {
@@ -262,23 +286,42 @@ func (rp *resultProxy) wrapError(err error) error {
 	if err == nil {
 		return nil
 	}
+	var fe *errdefs.FailuresError
+	if errors.As(err, &fe) {
+		for _, f := range fe.Items {
+			if len(f.Sources) == 0 {
+				f.Sources = rp.sources(f.Digest)
+			}
+		}
+		return err
+	}
 	var ve *errdefs.VertexError
 	if errors.As(err, &ve) {
-		if rp.req.Definition.Source != nil {
-			locs, ok := rp.req.Definition.Source.Locations[ve.Digest]
-			if ok {
-				for _, loc := range locs.Locations {
-					err = errdefs.WithSource(err, &errdefs.Source{
-						Info:   rp.req.Definition.Source.Infos[loc.SourceIndex],
-						Ranges: loc.Ranges,
-					})
-				}
-			}
+		for _, src := range rp.sources(ve.Digest) {
+			err = errdefs.WithSource(err, src)
 		}
 	}
 	return err
 }
 
+func (rp *resultProxy) sources(dgst string) []*errdefs.Source {
+	if rp.req.Definition.Source == nil {
+		return nil
+	}
+	locs, ok := rp.req.Definition.Source.Locations[dgst]
+	if !ok {
+		return nil
+	}
+	var out []*errdefs.Source
+	for _, loc := range locs.Locations {
+		out = append(out, &errdefs.Source{
+			Info:   rp.req.Definition.Source.Infos[loc.SourceIndex],
+			Ranges: loc.Ranges,
+		})
+	}
+	return out
+}
+
 func (rp *resultProxy) loadResult(ctx context.Context) (solver.CachedResultWithProvenance, error) {
 	res, err := rp.b.loadResult(ctx, rp.req.Definition, rp.req.CacheImports, rp.req.SourcePolicies)
 	var ee *llberrdefs.ExecError
@@ -366,7 +409,23 @@ func (b *llbBridge) ResolveSourceMetadata(ctx context.Context, op *pb.SourceOp,
 		opt.SourcePolicies = append(opt.SourcePolicies, pol)
 	}
 
-	if _, err := sourcepolicy.NewEngine(opt.SourcePolicies).Evaluate(ctx, op); err != nil {
+	frontendOpt, err := loadFrontendOpt(b.builder)
+	if err != nil {
+		return nil, err
+	}
+	polCtx := sourcepolicy.WithFrontendAttrs(ctx, frontendOpt)
+	if p := opt.Platform; p != nil {
+		polCtx = sourcepolicy.WithPlatform(polCtx, &pb.Platform{OS: p.OS, Architecture: p.Architecture, Variant: p.Variant})
+	}
+
+	var polTraces []*sourcepolicy.Trace
+	polEngine := b.sourcePolicy.engine(ctx, opt.SourcePolicies)
+	polEngine.SetTracer(func(tr *sourcepolicy.Trace) {
+		polTraces = append(polTraces, tr)
+	})
+	_, err = polEngine.Evaluate(polCtx, op)
+	reportSourcePolicy(ctx, b.builder, polTraces)
+	if err != nil {
 		return nil, errors.Wrap(err, "could not resolve image due to policy")
 	}
 
@@ -375,7 +434,7 @@ func (b *llbBridge) ResolveSourceMetadata(ctx context.Context, op *pb.SourceOp,
 
 	err = inBuilderContext(ctx, b.builder, opt.LogName, id, func(ctx context.Context, g session.Group) error {
 		resp, err = w.ResolveSourceMetadata(ctx, op, opt, b.sm, g)
-		return err
+		return errdefs.WithCacheOnlyVertex(err, "", op.Identifier)
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,97 @@
+// Package cachemiss records the cache keys computed during a build and
+// explains why a build did not reuse the cache of a previous one.
+package cachemiss
+
+import (
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// MediaType is the media type of the cache key record saved with build history.
+const MediaType = "application/vnd.buildkit.cachekeys.v0+json"
+
+// Reason types returned by Diff.
+const (
+	// ReasonNew is reported for a vertex that has no matching vertex in the
+	// base build.
+	ReasonNew = "new"
+	// ReasonOp is reported when the definition of the operation changed in a
+	// way not described by a more specific reason.
+	ReasonOp = "op"
+	// ReasonPlatform is reported when the platform of the operation changed.
+	ReasonPlatform = "platform"
+	// ReasonInput is reported when an input vertex changed. Key is the name of
+	// the input vertex.
+	ReasonInput = "input"
+	// ReasonContent is reported when the content based checksum of an input
+	// changed, eg. files in the local build context. Key is the name of the
+	// input vertex.
+	ReasonContent = "content"
+	// ReasonSource is reported when the identifier, an attribute or the
+	// resolved value (eg. image digest or git commit) of a source changed.
+	ReasonSource = "source"
+	// ReasonCacheKey is reported when the cache key returned by the operation
+	// changed without a change in its definition.
+	ReasonCacheKey = "cache-key"
+	// ReasonExec is reported when a command, working directory, user, network
+	// or security mode of a process changed. Key is the changed property.
+	ReasonExec = "exec"
+	// ReasonEnv is reported when an environment variable changed.
+	ReasonEnv = "env"
+	// ReasonBuildArg is reported when an environment variable changed that
+	// matches a build argument that changed between the builds.
+	ReasonBuildArg = "build-arg"
+	// ReasonMount is reported when a mount changed. Key is the mount target.
+	ReasonMount = "mount"
+	// ReasonSecret is reported when a secret was added or removed. Key is the
+	// ID of the secret.
+	ReasonSecret = "secret"
+	// ReasonSSH is reported when a SSH agent socket was added or removed. Key
+	// is the ID of the SSH socket.
+	ReasonSSH = "ssh"
+	// ReasonEvicted is reported when the cache key did not change but the
+	// cache record was no longer available.
+	ReasonEvicted = "evicted"
+)
+
+// Record contains the cache keys computed for all vertexes of a build.
+type Record struct {
+	Vertexes []Vertex `json:"vertexes,omitempty"`
+	// BuildArgs are the build arguments passed to the frontend.
+	BuildArgs map[string]string `json:"buildArgs,omitempty"`
+}
+
+// Vertex describes how the cache key of a single vertex was computed.
+type Vertex struct {
+	Digest digest.Digest `json:"digest"`
+	Name   string        `json:"name,omitempty"`
+	Op     *pb.Op        `json:"op,omitempty"`
+	// Inputs are the digests of the input vertexes.
+	Inputs []digest.Digest `json:"inputs,omitempty"`
+	// CacheKeys are the digests returned by the operation for calculating
+	// its cache key.
+	CacheKeys []digest.Digest `json:"cacheKeys,omitempty"`
+	// ContentKeys are the content based checksums of the inputs, by input index.
+	ContentKeys map[int]digest.Digest `json:"contentKeys,omitempty"`
+	// Pin is the resolved value of a source, eg. the image digest.
+	Pin      string `json:"pin,omitempty"`
+	Cached   bool   `json:"cached,omitempty"`
+	Executed bool   `json:"executed,omitempty"`
+}
+
+// Miss is a vertex that was executed in a build.
+type Miss struct {
+	Digest digest.Digest
+	Name   string
+	// BaseDigest is the digest of the matching vertex in the base build.
+	BaseDigest digest.Digest
+	Reasons    []Reason
+}
+
+// Reason describes a single change that caused a cache miss.
+type Reason struct {
+	Type string
+	Key  string
+	Old  string
+	New  string
+}
@@ -0,0 +1,511 @@
+package cachemiss
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+)
+
+const (
+	valueAbsent  = "absent"
+	valuePresent = "present"
+)
+
+// Diff explains why the vertexes executed in target did not reuse the cache
+// of the base build. Vertexes of the two builds are matched by digest, then
+// by their position in the graph relative to already matched vertexes and
+// finally by name. Results are sorted so that inputs are listed before the
+// vertexes that depend on them.
+func Diff(base, target *Record) []Miss {
+	a := newAligner(base, target)
+	a.align()
+
+	var out []Miss
+	changed := map[digest.Digest]struct{}{}
+	for _, v := range sortVertexes(target) {
+		if !v.Executed || isRandom(v) {
+			continue
+		}
+		m := Miss{
+			Digest: v.Digest,
+			Name:   v.Name,
+		}
+		if b, ok := a.match[v.Digest]; ok {
+			m.BaseDigest = b.Digest
+			m.Reasons = compareVertex(b, v, base, target, a, changed)
+		} else {
+			m.Reasons = []Reason{{Type: ReasonNew}}
+		}
+		if m.Reasons[0].Type != ReasonEvicted {
+			changed[v.Digest] = struct{}{}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+type aligner struct {
+	base    map[digest.Digest]*Vertex
+	target  map[digest.Digest]*Vertex
+	bOrder  []*Vertex
+	tOrder  []*Vertex
+	match   map[digest.Digest]*Vertex // target digest to base vertex
+	used    map[digest.Digest]struct{}
+	pending []digest.Digest
+}
+
+func newAligner(base, target *Record) *aligner {
+	a := &aligner{
+		base:   map[digest.Digest]*Vertex{},
+		target: map[digest.Digest]*Vertex{},
+		bOrder: sortVertexes(base),
+		tOrder: sortVertexes(target),
+		match:  map[digest.Digest]*Vertex{},
+		used:   map[digest.Digest]struct{}{},
+	}
+	for _, v := range a.bOrder {
+		a.base[v.Digest] = v
+	}
+	for _, v := range a.tOrder {
+		a.target[v.Digest] = v
+	}
+	return a
+}
+
+func (a *aligner) pair(t, b *Vertex) {
+	a.match[t.Digest] = b
+	a.used[b.Digest] = struct{}{}
+	a.pending = append(a.pending, t.Digest)
+}
+
+func (a *aligner) isPaired(t, b *Vertex) bool {
+	if _, ok := a.match[t.Digest]; ok {
+		return true
+	}
+	_, ok := a.used[b.Digest]
+	return ok
+}
+
+func (a *aligner) align() {
+	for _, v := range a.tOrder {
+		if b, ok := a.base[v.Digest]; ok {
+			a.pair(v, b)
+		}
+	}
+
+	tRoots := roots(a.tOrder)
+	bRoots := roots(a.bOrder)
+	for _, t := range tRoots {
+		for _, b := range bRoots {
+			if t.Name == b.Name && !a.isPaired(t, b) {
+				a.pair(t, b)
+				break
+			}
+		}
+	}
+	tRoots = a.unpairedTarget(tRoots)
+	bRoots = a.unpairedBase(bRoots)
+	if len(tRoots) == 1 && len(bRoots) == 1 {
+		a.pair(tRoots[0], bRoots[0])
+	}
+	a.propagate()
+
+	byName := map[string][]*Vertex{}
+	for _, b := range a.unpairedBase(a.bOrder) {
+		byName[b.Name] = append(byName[b.Name], b)
+	}
+	for _, t := range a.unpairedTarget(a.tOrder) {
+		if c := byName[t.Name]; len(c) == 1 && !a.isPaired(t, c[0]) {
+			a.pair(t, c[0])
+		}
+	}
+	a.propagate()
+}
+
+// propagate matches the inputs of matched vertexes by their position
+func (a *aligner) propagate() {
+	for len(a.pending) > 0 {
+		dgst := a.pending[0]
+		a.pending = a.pending[1:]
+		t := a.target[dgst]
+		b := a.match[dgst]
+		if len(t.Inputs) != len(b.Inputs) {
+			continue
+		}
+		for i := range t.Inputs {
+			ti, ok1 := a.target[t.Inputs[i]]
+			bi, ok2 := a.base[b.Inputs[i]]
+			if ok1 && ok2 && !a.isPaired(ti, bi) {
+				a.pair(ti, bi)
+			}
+		}
+	}
+}
+
+func (a *aligner) unpairedTarget(in []*Vertex) []*Vertex {
+	var out []*Vertex
+	for _, v := range in {
+		if _, ok := a.match[v.Digest]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (a *aligner) unpairedBase(in []*Vertex) []*Vertex {
+	var out []*Vertex
+	for _, v := range in {
+		if _, ok := a.used[v.Digest]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// compareVertex returns the reasons for the cache miss of t. Changed
+// contains the target vertexes that were already reported with a change.
+func compareVertex(b, t *Vertex, base, target *Record, a *aligner, changed map[digest.Digest]struct{}) []Reason {
+	var reasons []Reason
+	if b.Digest != t.Digest && b.Op != nil && t.Op != nil {
+		reasons = append(reasons, compareOp(b.Op, t.Op, base.BuildArgs, target.BuildArgs)...)
+	}
+	for i, inp := range t.Inputs {
+		if i < len(b.Inputs) && b.Inputs[i] == inp {
+			if _, ok := changed[inp]; !ok {
+				continue
+			}
+		}
+		r := Reason{
+			Type: ReasonInput,
+			Key:  a.name(inp),
+			New:  inp.String(),
+		}
+		if i < len(b.Inputs) {
+			r.Old = b.Inputs[i].String()
+		}
+		reasons = append(reasons, r)
+	}
+	if b.Pin != t.Pin {
+		reasons = append(reasons, Reason{
+			Type: ReasonSource,
+			Key:  "pin",
+			Old:  b.Pin,
+			New:  t.Pin,
+		})
+	}
+	for _, i := range slices.Sorted(maps.Keys(t.ContentKeys)) {
+		if old, ok := b.ContentKeys[i]; ok && old != t.ContentKeys[i] {
+			var key string
+			if i < len(t.Inputs) {
+				key = a.name(t.Inputs[i])
+			}
+			reasons = append(reasons, Reason{
+				Type: ReasonContent,
+				Key:  key,
+				Old:  old.String(),
+				New:  t.ContentKeys[i].String(),
+			})
+		}
+	}
+	if len(reasons) > 0 {
+		return reasons
+	}
+	if !slices.Equal(b.CacheKeys, t.CacheKeys) {
+		return []Reason{{
+			Type: ReasonCacheKey,
+			Old:  joinDigests(b.CacheKeys),
+			New:  joinDigests(t.CacheKeys),
+		}}
+	}
+	if b.Digest == t.Digest {
+		return []Reason{{Type: ReasonEvicted}}
+	}
+	return []Reason{{
+		Type: ReasonOp,
+		Old:  b.Digest.String(),
+		New:  t.Digest.String(),
+	}}
+}
+
+func (a *aligner) name(dgst digest.Digest) string {
+	if v, ok := a.target[dgst]; ok {
+		return v.Name
+	}
+	return dgst.String()
+}
+
+func compareOp(old, new *pb.Op, oldArgs, newArgs map[string]string) []Reason {
+	var reasons []Reason
+	if !old.Platform.EqualVT(new.Platform) {
+		reasons = append(reasons, Reason{
+			Type: ReasonPlatform,
+			Old:  formatPlatform(old.Platform),
+			New:  formatPlatform(new.Platform),
+		})
+	}
+
+	switch op := new.Op.(type) {
+	case *pb.Op_Source:
+		if src := old.GetSource(); src != nil {
+			return append(reasons, compareSource(src, op.Source)...)
+		}
+	case *pb.Op_Exec:
+		if exec := old.GetExec(); exec != nil {
+			if r := compareExec(exec, op.Exec, oldArgs, newArgs); len(r) > 0 {
+				return append(reasons, r...)
+			}
+		}
+	}
+
+	oldDgst, newDgst := opDigest(old), opDigest(new)
+	if oldDgst != newDgst {
+		reasons = append(reasons, Reason{
+			Type: ReasonOp,
+			Old:  oldDgst.String(),
+			New:  newDgst.String(),
+		})
+	}
+	return reasons
+}
+
+func compareSource(old, new *pb.SourceOp) []Reason {
+	var reasons []Reason
+	if old.Identifier != new.Identifier {
+		reasons = append(reasons, Reason{
+			Type: ReasonSource,
+			Key:  "identifier",
+			Old:  old.Identifier,
+			New:  new.Identifier,
+		})
+	}
+	for _, k := range unionKeys(old.Attrs, new.Attrs) {
+		if old.Attrs[k] != new.Attrs[k] {
+			reasons = append(reasons, Reason{
+				Type: ReasonSource,
+				Key:  k,
+				Old:  old.Attrs[k],
+				New:  new.Attrs[k],
+			})
+		}
+	}
+	return reasons
+}
+
+func compareExec(old, new *pb.ExecOp, oldArgs, newArgs map[string]string) []Reason {
+	var reasons []Reason
+	add := func(typ, key, o, n string) {
+		if o != n {
+			reasons = append(reasons, Reason{Type: typ, Key: key, Old: o, New: n})
+		}
+	}
+
+	om, nm := old.GetMeta(), new.GetMeta()
+	add(ReasonExec, "args", strings.Join(om.GetArgs(), " "), strings.Join(nm.GetArgs(), " "))
+	add(ReasonExec, "cwd", om.GetCwd(), nm.GetCwd())
+	add(ReasonExec, "user", om.GetUser(), nm.GetUser())
+	add(ReasonExec, "hostname", om.GetHostname(), nm.GetHostname())
+	add(ReasonExec, "network", old.Network.String(), new.Network.String())
+	add(ReasonExec, "security", old.Security.String(), new.Security.String())
+
+	oldEnv, newEnv := envMap(om.GetEnv()), envMap(nm.GetEnv())
+	for _, k := range unionKeys(oldEnv, newEnv) {
+		typ := ReasonEnv
+		if oldArgs[k] != newArgs[k] {
+			typ = ReasonBuildArg
+		}
+		add(typ, k, oldEnv[k], newEnv[k])
+	}
+
+	oldSecrets, newSecrets := secretIDs(old), secretIDs(new)
+	for _, k := range unionKeys(oldSecrets, newSecrets) {
+		add(ReasonSecret, k, presence(oldSecrets, k), presence(newSecrets, k))
+	}
+	oldSSH, newSSH := sshIDs(old), sshIDs(new)
+	for _, k := range unionKeys(oldSSH, newSSH) {
+		add(ReasonSSH, k, presence(oldSSH, k), presence(newSSH, k))
+	}
+
+	oldMounts, newMounts := mountMap(old), mountMap(new)
+	for _, k := range unionKeys(oldMounts, newMounts) {
+		o, n := oldMounts[k], newMounts[k]
+		if o != nil && n != nil && o.EqualVT(n) {
+			continue
+		}
+		add(ReasonMount, k, mountString(o), mountString(n))
+	}
+	return reasons
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		m[k] = v
+	}
+	return m
+}
+
+func secretIDs(op *pb.ExecOp) map[string]struct{} {
+	m := map[string]struct{}{}
+	for _, mnt := range op.Mounts {
+		if mnt.MountType == pb.MountType_SECRET {
+			m[mnt.SecretOpt.GetID()] = struct{}{}
+		}
+	}
+	for _, se := range op.Secretenv {
+		m[se.GetID()] = struct{}{}
+	}
+	return m
+}
+
+func sshIDs(op *pb.ExecOp) map[string]struct{} {
+	m := map[string]struct{}{}
+	for _, mnt := range op.Mounts {
+		if mnt.MountType == pb.MountType_SSH {
+			m[mnt.SSHOpt.GetID()] = struct{}{}
+		}
+	}
+	return m
+}
+
+func presence(m map[string]struct{}, k string) string {
+	if _, ok := m[k]; ok {
+		return valuePresent
+	}
+	return valueAbsent
+}
+
+// mountMap returns the mounts by target, skipping secrets and SSH sockets
+// that are reported separately. Input indexes are cleared as input changes
+// are reported separately.
+func mountMap(op *pb.ExecOp) map[string]*pb.Mount {
+	m := map[string]*pb.Mount{}
+	for _, mnt := range op.Mounts {
+		if mnt.MountType == pb.MountType_SECRET || mnt.MountType == pb.MountType_SSH {
+			continue
+		}
+		mnt = mnt.CloneVT()
+		mnt.Input = 0
+		mnt.Output = 0
+		m[mnt.Dest] = mnt
+	}
+	return m
+}
+
+func mountString(m *pb.Mount) string {
+	if m == nil {
+		return ""
+	}
+	s := strings.ToLower(m.MountType.String())
+	if m.Selector != "" {
+		s += " from " + m.Selector
+	}
+	if m.Readonly {
+		s += " (readonly)"
+	}
+	if id := m.CacheOpt.GetID(); id != "" {
+		s += " id=" + id
+	}
+	return s
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	m := map[string]struct{}{}
+	for k := range a {
+		m[k] = struct{}{}
+	}
+	for k := range b {
+		m[k] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(m))
+}
+
+// opDigest returns the digest of the operation without its inputs
+func opDigest(op *pb.Op) digest.Digest {
+	op = op.CloneVT()
+	op.Inputs = nil
+	dt, err := op.Marshal()
+	if err != nil {
+		return ""
+	}
+	return digest.FromBytes(dt)
+}
+
+func formatPlatform(p *pb.Platform) string {
+	if p == nil {
+		return ""
+	}
+	return platforms.FormatAll(p.Spec())
+}
+
+func joinDigests(dgsts []digest.Digest) string {
+	s := make([]string, len(dgsts))
+	for i, d := range dgsts {
+		s[i] = d.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// isRandom returns true for vertexes that are never cached, eg. local sources
+func isRandom(v *Vertex) bool {
+	if len(v.CacheKeys) == 0 {
+		return false
+	}
+	for _, k := range v.CacheKeys {
+		if !strings.HasPrefix(string(k), "random:") {
+			return false
+		}
+	}
+	return true
+}
+
+func roots(vtxs []*Vertex) []*Vertex {
+	inputs := map[digest.Digest]struct{}{}
+	for _, v := range vtxs {
+		for _, inp := range v.Inputs {
+			inputs[inp] = struct{}{}
+		}
+	}
+	var out []*Vertex
+	for _, v := range vtxs {
+		if _, ok := inputs[v.Digest]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// sortVertexes returns the vertexes of the record so that inputs are before
+// the vertexes that depend on them
+func sortVertexes(r *Record) []*Vertex {
+	if r == nil {
+		return nil
+	}
+	m := make(map[digest.Digest]*Vertex, len(r.Vertexes))
+	for i := range r.Vertexes {
+		m[r.Vertexes[i].Digest] = &r.Vertexes[i]
+	}
+	out := make([]*Vertex, 0, len(r.Vertexes))
+	visited := map[digest.Digest]struct{}{}
+	var visit func(*Vertex)
+	visit = func(v *Vertex) {
+		if _, ok := visited[v.Digest]; ok {
+			return
+		}
+		visited[v.Digest] = struct{}{}
+		for _, inp := range v.Inputs {
+			if iv, ok := m[inp]; ok {
+				visit(iv)
+			}
+		}
+		out = append(out, v)
+	}
+	for i := range r.Vertexes {
+		visit(&r.Vertexes[i])
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package cachemiss
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func sourceVertex(ref, pin string) Vertex {
+	op := &pb.Op{
+		Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: ref}},
+	}
+	return Vertex{
+		Digest:    opDigest(op),
+		Name:      "FROM " + ref,
+		Op:        op,
+		CacheKeys: []digest.Digest{digest.FromString(pin)},
+		Pin:       pin,
+		Executed:  true,
+	}
+}
+
+func execVertex(name string, env []string, inputs ...Vertex) Vertex {
+	op := &pb.Op{
+		Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+			Meta: &pb.Meta{
+				Args: []string{"/bin/sh", "-c", name},
+				Env:  env,
+				Cwd:  "/",
+			},
+			Mounts: []*pb.Mount{{Dest: "/", Input: 0, Output: 0}},
+		}},
+	}
+	v := Vertex{
+		Name:     "RUN " + name,
+		Op:       op,
+		Executed: true,
+	}
+	for _, inp := range inputs {
+		op.Inputs = append(op.Inputs, &pb.Input{Digest: string(inp.Digest)})
+		v.Inputs = append(v.Inputs, inp.Digest)
+	}
+	dt, err := op.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	v.Digest = digest.FromBytes(dt)
+	v.CacheKeys = []digest.Digest{v.Digest}
+	return v
+}
+
+func TestDiffBuildArg(t *testing.T) {
+	t.Parallel()
+
+	src := sourceVertex("docker.io/library/alpine:latest", "sha256:aaa")
+	src.Executed = false
+	base := &Record{
+		Vertexes:  []Vertex{src, execVertex("make", []string{"VERSION=1"}, src)},
+		BuildArgs: map[string]string{"VERSION": "1"},
+	}
+	target := &Record{
+		Vertexes:  []Vertex{src, execVertex("make", []string{"VERSION=2"}, src)},
+		BuildArgs: map[string]string{"VERSION": "2"},
+	}
+
+	misses := Diff(base, target)
+	require.Len(t, misses, 1)
+	require.Equal(t, "RUN make", misses[0].Name)
+	require.Equal(t, base.Vertexes[1].Digest, misses[0].BaseDigest)
+	require.Equal(t, []Reason{{
+		Type: ReasonBuildArg,
+		Key:  "VERSION",
+		Old:  "1",
+		New:  "2",
+	}}, misses[0].Reasons)
+}
+
+func TestDiffSourceChange(t *testing.T) {
+	t.Parallel()
+
+	baseSrc := sourceVertex("docker.io/library/alpine:latest", "sha256:aaa")
+	targetSrc := sourceVertex("docker.io/library/alpine:latest", "sha256:bbb")
+	base := &Record{
+		Vertexes: []Vertex{baseSrc, execVertex("make", nil, baseSrc)},
+	}
+	target := &Record{
+		Vertexes: []Vertex{targetSrc, execVertex("make", nil, targetSrc)},
+	}
+
+	misses := Diff(base, target)
+	require.Len(t, misses, 2)
+	require.Equal(t, baseSrc.Name, misses[0].Name)
+	require.Equal(t, []Reason{{
+		Type: ReasonSource,
+		Key:  "pin",
+		Old:  "sha256:aaa",
+		New:  "sha256:bbb",
+	}}, misses[0].Reasons)
+	require.Equal(t, "RUN make", misses[1].Name)
+	require.Len(t, misses[1].Reasons, 1)
+	require.Equal(t, ReasonInput, misses[1].Reasons[0].Type)
+	require.Equal(t, baseSrc.Name, misses[1].Reasons[0].Key)
+}
+
+func TestDiffContentAndInput(t *testing.T) {
+	t.Parallel()
+
+	src := sourceVertex("local://context", "")
+	src.Executed = false
+	baseCopy := execVertex("copy", nil, src)
+	baseCopy.ContentKeys = map[int]digest.Digest{0: digest.FromString("a")}
+	targetCopy := execVertex("copy", nil, src)
+	targetCopy.ContentKeys = map[int]digest.Digest{0: digest.FromString("b")}
+	targetCopy.CacheKeys = append(targetCopy.CacheKeys, digest.FromString("b"))
+
+	baseRun := execVertex("test", nil, baseCopy)
+	targetRun := execVertex("test", nil, targetCopy)
+
+	base := &Record{Vertexes: []Vertex{src, baseCopy, baseRun}}
+	target := &Record{Vertexes: []Vertex{src, targetCopy, targetRun}}
+
+	misses := Diff(base, target)
+	require.Len(t, misses, 2)
+	require.Equal(t, "RUN copy", misses[0].Name)
+	require.Equal(t, []Reason{{
+		Type: ReasonContent,
+		Key:  "FROM local://context",
+		Old:  digest.FromString("a").String(),
+		New:  digest.FromString("b").String(),
+	}}, misses[0].Reasons)
+
+	require.Equal(t, "RUN test", misses[1].Name)
+	require.Equal(t, baseRun.Digest, misses[1].BaseDigest)
+	require.Len(t, misses[1].Reasons, 1)
+	require.Equal(t, ReasonInput, misses[1].Reasons[0].Type)
+	require.Equal(t, "RUN copy", misses[1].Reasons[0].Key)
+}
+
+func TestDiffNewAndEvicted(t *testing.T) {
+	t.Parallel()
+
+	src := sourceVertex("docker.io/library/alpine:latest", "sha256:aaa")
+	src.Executed = false
+	run := execVertex("make", nil, src)
+	base := &Record{Vertexes: []Vertex{src, run}}
+	target := &Record{Vertexes: []Vertex{src, run, execVertex("test", nil, run)}}
+
+	misses := Diff(base, target)
+	require.Len(t, misses, 2)
+	require.Equal(t, "RUN make", misses[0].Name)
+	require.Equal(t, []Reason{{Type: ReasonEvicted}}, misses[0].Reasons)
+	require.Equal(t, "RUN test", misses[1].Name)
+	require.Equal(t, []Reason{{Type: ReasonNew}}, misses[1].Reasons)
+}
+
+func TestDiffRandomSkipped(t *testing.T) {
+	t.Parallel()
+
+	src := sourceVertex("local://context", "")
+	src.CacheKeys = []digest.Digest{"random:abc"}
+	base := &Record{Vertexes: []Vertex{src}}
+	target := &Record{Vertexes: []Vertex{src}}
+	require.Empty(t, Diff(base, target))
+}
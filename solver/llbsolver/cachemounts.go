@@ -0,0 +1,143 @@
+package llbsolver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/containerd/continuity/fs"
+	"github.com/docker/go-units"
+	"github.com/moby/buildkit/cache"
+	cacheconfig "github.com/moby/buildkit/cache/config"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/llbsolver/mounts"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/pkg/errors"
+	copy "github.com/tonistiigi/fsutil/copy"
+)
+
+// exportCacheMounts adds the contents of the cache mounts selected for the
+// exporter to the exported cache. Only cache mounts without a base are
+// exported. The returned function releases the snapshots of the cache mounts
+// and must be called after the exporter has been finalized.
+func exportCacheMounts(ctx context.Context, exp RemoteCacheExporter, cm cache.Manager, g session.Group) (func(), error) {
+	var refs []cache.ImmutableRef
+	release := func() {
+		for _, ref := range refs {
+			ref.Release(context.WithoutCancel(ctx))
+		}
+	}
+	compressionConfig := exp.Config().Compression
+
+	for _, id := range exp.CacheMounts {
+		mds, err := mounts.SearchCacheDir(ctx, cm, id, false)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		// private cache mounts may have multiple instances, export the latest
+		latest := map[pb.CacheSharingOpt]mounts.CacheRefMetadata{}
+		for _, md := range mds {
+			sharing := md.CacheDirSharing()
+			if cur, ok := latest[sharing]; !ok || md.GetCreatedAt().After(cur.GetCreatedAt()) {
+				latest[sharing] = md
+			}
+		}
+		for sharing, md := range latest {
+			ref, err := snapshotCacheDir(ctx, cm, md, exp.CacheMountsMaxSize, g)
+			if err != nil {
+				release()
+				return nil, errors.Wrapf(err, "failed to export cache mount %q", id)
+			}
+			if ref == nil {
+				continue
+			}
+			refs = append(refs, ref)
+
+			remotes, err := ref.GetRemotes(ctx, true, cacheconfig.RefConfig{Compression: compressionConfig}, false, g)
+			if err != nil {
+				release()
+				return nil, errors.Wrapf(err, "failed to export cache mount %q", id)
+			}
+			if len(remotes) == 0 {
+				continue
+			}
+			solver.ExportRootResult(exp, mounts.CacheMountKey(id, sharing), time.Now(), remotes[0])
+		}
+	}
+	return release, nil
+}
+
+// snapshotCacheDir copies the current contents of a cache mount to a new
+// immutable ref so that the cache mount itself stays mutable. It returns nil
+// if the cache mount is in use or bigger than maxSize.
+func snapshotCacheDir(ctx context.Context, cm cache.Manager, md mounts.CacheRefMetadata, maxSize int64, g session.Group) (_ cache.ImmutableRef, rerr error) {
+	mref, err := cm.GetMutable(ctx, md.ID())
+	if err != nil {
+		if errors.Is(err, cache.ErrLocked) {
+			progress.OneOff(ctx, fmt.Sprintf("skipping cache mount %s: in use", md.ID()))(nil)
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer mref.Release(context.WithoutCancel(ctx))
+
+	src, err := mref.Mount(ctx, true, g)
+	if err != nil {
+		return nil, err
+	}
+	srcLM := snapshot.LocalMounter(src)
+	srcDir, err := srcLM.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer srcLM.Unmount()
+
+	if maxSize > 0 {
+		usage, err := fs.DiskUsage(ctx, srcDir)
+		if err != nil {
+			return nil, err
+		}
+		if usage.Size > maxSize {
+			progress.OneOff(ctx, fmt.Sprintf("skipping cache mount %s: size %s exceeds %s", md.ID(), units.HumanSize(float64(usage.Size)), units.HumanSize(float64(maxSize))))(nil)
+			return nil, nil
+		}
+	}
+
+	newRef, err := cm.New(ctx, nil, g, cache.WithDescription(fmt.Sprintf("exported cache mount %s", md.ID())))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rerr != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	dst, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+	dstLM := snapshot.LocalMounter(dst)
+	dstDir, err := dstLM.Mount()
+	if err != nil {
+		return nil, err
+	}
+	err = copy.Copy(ctx, srcDir, "/", dstDir, "/", func(ci *copy.CopyInfo) {
+		ci.CopyDirContents = true
+	}, copy.WithXAttrErrorHandler(func(dst, src, key string, err error) error {
+		bklog.G(ctx).WithError(err).Debugf("failed to copy xattr %s of %s", key, src)
+		return nil
+	}))
+	if uerr := dstLM.Unmount(); err == nil {
+		err = uerr
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newRef.Commit(ctx)
+}
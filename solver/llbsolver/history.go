@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/leases"
 	"github.com/containerd/containerd/v2/pkg/filters"
 	cerrdefs "github.com/containerd/errdefs"
@@ -22,6 +23,7 @@ import (
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/identity"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
+	"github.com/moby/buildkit/solver/llbsolver/cachemiss"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/db"
 	"github.com/moby/buildkit/util/gitutil"
@@ -354,6 +356,123 @@ func (h *HistoryQueue) gc() error {
 	return nil
 }
 
+// HistoryRefs returns a function that reports if a layer chain is only
+// referenced by the results of build history records that completed before
+// cutoff. A result references the chains of all the refs its layers are built
+// from, so the whole chain of an old image can be released, not only its
+// last ref. Running and pinned records count as recent.
+func (h *HistoryQueue) HistoryRefs(ctx context.Context, cutoff time.Time) (func([]digest.Digest) bool, error) {
+	var records []*controlapi.BuildHistoryRecord
+	if err := h.opt.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(recordsBucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(key, dt []byte) error {
+			var br controlapi.BuildHistoryRecord
+			if err := br.UnmarshalVT(dt); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal build record %s", key)
+			}
+			records = append(records, &br)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	old := map[string]struct{}{}
+	recent := map[string]struct{}{}
+	for _, rec := range records {
+		layers := old
+		if rec.Pinned || rec.CompletedAt == nil || !rec.CompletedAt.AsTime().Before(cutoff) {
+			layers = recent
+		}
+		results := []*controlapi.BuildResultInfo{rec.Result}
+		for _, r := range rec.Results {
+			results = append(results, r)
+		}
+		for _, res := range results {
+			if res == nil {
+				continue
+			}
+			if err := h.resultLayers(ctx, res.ResultDeprecated, layers); err != nil {
+				return nil, err
+			}
+			for _, desc := range res.Results {
+				if err := h.resultLayers(ctx, desc, layers); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return matchLayerChains(old, recent), nil
+}
+
+// matchLayerChains returns a function that reports if a layer chain is in old
+// but not in recent.
+func matchLayerChains(old, recent map[string]struct{}) func([]digest.Digest) bool {
+	return func(chain []digest.Digest) bool {
+		if len(chain) == 0 {
+			return false
+		}
+		key := layerChainKey(chain)
+		if _, ok := recent[key]; ok {
+			return false
+		}
+		_, ok := old[key]
+		return ok
+	}
+}
+
+// resultLayers adds the layer chains of the images of a build result to
+// chains, for every image the chain of each of its layers. Only the manifests
+// are kept in the history content store, so the layers are not read.
+func (h *HistoryQueue) resultLayers(ctx context.Context, desc *controlapi.Descriptor, chains map[string]struct{}) error {
+	if desc == nil {
+		return nil
+	}
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
+		if images.IsLayerType(desc.MediaType) {
+			return nil, nil
+		}
+		children, err := images.Children(ctx, h.hContentStore, desc)
+		if err != nil {
+			return nil, images.ErrSkipDesc // allow missing blobs
+		}
+		if images.IsManifestType(desc.MediaType) {
+			addLayerChains(chains, children)
+		}
+		return children, nil
+	})
+	return images.Walk(ctx, handler, ocispecs.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    digest.Digest(desc.Digest),
+		Size:      desc.Size,
+	})
+}
+
+// addLayerChains adds the chain of every layer in the children of a manifest
+// to chains.
+func addLayerChains(chains map[string]struct{}, children []ocispecs.Descriptor) {
+	var chain []digest.Digest
+	for _, c := range children {
+		if images.IsLayerType(c.MediaType) {
+			chain = append(chain, c.Digest)
+			chains[layerChainKey(chain)] = struct{}{}
+		}
+	}
+}
+
+func layerChainKey(chain []digest.Digest) string {
+	var sb strings.Builder
+	for _, dgst := range chain {
+		sb.WriteString(dgst.String())
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
 func (h *HistoryQueue) clearOrphans() error {
 	ctx := context.Background()
 	var records []*controlapi.BuildHistoryRecord
@@ -572,6 +691,48 @@ func (h *HistoryQueue) Status(ctx context.Context, ref string, st chan<- *client
 	return nil
 }
 
+// CacheKeys returns the cache keys recorded for the build with the given ref.
+func (h *HistoryQueue) CacheKeys(ctx context.Context, ref string) (*cachemiss.Record, error) {
+	h.init()
+	var br controlapi.BuildHistoryRecord
+	if err := h.opt.DB.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(recordsBucket))
+		if b == nil {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve bucket %s", recordsBucket)
+		}
+		dt := b.Get([]byte(ref))
+		if dt == nil {
+			return errors.Wrapf(os.ErrNotExist, "failed to retrieve ref %s", ref)
+		}
+
+		if err := br.UnmarshalVT(dt); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal build record %s", ref)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if br.CacheKeys == nil {
+		return nil, errors.Wrapf(os.ErrNotExist, "no cache keys recorded for ref %s", ref)
+	}
+
+	dt, err := content.ReadBlob(ctx, h.hContentStore, ocispecs.Descriptor{
+		Digest:    digest.Digest(br.CacheKeys.Digest),
+		Size:      br.CacheKeys.Size,
+		MediaType: br.CacheKeys.MediaType,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cache keys for ref %s", ref)
+	}
+
+	var rec cachemiss.Record
+	if err := json.Unmarshal(dt, &rec); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal cache keys for ref %s", ref)
+	}
+	return &rec, nil
+}
+
 func (h *HistoryQueue) update(ctx context.Context, rec *controlapi.BuildHistoryRecord) error {
 	return h.opt.DB.Update(func(tx *bolt.Tx) (err error) {
 		b := tx.Bucket([]byte(recordsBucket))
@@ -608,6 +769,9 @@ func (h *HistoryQueue) update(ctx context.Context, rec *controlapi.BuildHistoryR
 		if err := h.addResource(ctx, l, rec.ExternalError, false); err != nil {
 			return err
 		}
+		if err := h.addResource(ctx, l, rec.CacheKeys, false); err != nil {
+			return err
+		}
 		if rec.Result != nil {
 			if err := h.addResource(ctx, l, rec.Result.ResultDeprecated, true); err != nil {
 				return err
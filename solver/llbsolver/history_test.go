@@ -5,6 +5,8 @@ import (
 	"time"
 
 	controlapi "github.com/moby/buildkit/api/services/control"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -147,3 +149,27 @@ func TestHistoryFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchLayerChains(t *testing.T) {
+	layer := func(s string) ocispecs.Descriptor {
+		return ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageLayerGzip, Digest: digest.FromString(s)}
+	}
+	config := ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageConfig, Digest: digest.FromString("config")}
+	base, oldTop, recentTop := layer("base"), layer("old"), layer("recent")
+
+	old := map[string]struct{}{}
+	recent := map[string]struct{}{}
+	addLayerChains(old, []ocispecs.Descriptor{config, base, oldTop, layer("old2")})
+	addLayerChains(recent, []ocispecs.Descriptor{config, base, recentTop})
+	match := matchLayerChains(old, recent)
+
+	// every ref in the chain of the old image is matched
+	require.True(t, match([]digest.Digest{base.Digest, oldTop.Digest}))
+	require.True(t, match([]digest.Digest{base.Digest, oldTop.Digest, digest.FromString("old2")}))
+	// the base is also used by a recent image
+	require.False(t, match([]digest.Digest{base.Digest}))
+	require.False(t, match([]digest.Digest{base.Digest, recentTop.Digest}))
+	// the same layer on top of another chain is not referenced
+	require.False(t, match([]digest.Digest{recentTop.Digest, oldTop.Digest}))
+	require.False(t, match(nil))
+}
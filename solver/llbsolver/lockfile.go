@@ -0,0 +1,77 @@
+package llbsolver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/sourcepolicy/lockfile"
+	"github.com/pkg/errors"
+)
+
+// addSourceLockfile adds the lockfile of the sources of a result to the
+// exporter response so that a later build can pin the same sources.
+func addSourceLockfile(resp map[string]string, res *provenance.Result) error {
+	if res == nil {
+		return nil
+	}
+	l := sourceLockfile(res)
+	if len(l.Sources) == 0 {
+		return nil
+	}
+	dt, err := json.Marshal(l)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp[lockfile.ExporterResponseKey] = base64.StdEncoding.EncodeToString(dt)
+	return nil
+}
+
+// sourceLockfile returns the lockfile of the sources recorded in the
+// provenance of all the refs of a result. Local sources can't be pinned and
+// are left out.
+func sourceLockfile(res *provenance.Result) *lockfile.Lockfile {
+	l := lockfile.New()
+	res.EachRef(func(c *provenance.Capture) error {
+		for _, img := range c.Sources.Images {
+			if img.Local || img.Digest == "" {
+				continue
+			}
+			l.Add(lockfile.Source{
+				Type: lockfile.SourceTypeImage,
+				Ref:  img.Ref,
+				Pin:  img.Digest.String(),
+			})
+		}
+		for _, a := range c.Sources.Artifacts {
+			l.Add(lockfile.Source{
+				Type: lockfile.SourceTypeOCIArtifact,
+				Ref:  a.Ref,
+				Pin:  a.Digest.String(),
+			})
+		}
+		for _, g := range c.Sources.Git {
+			l.Add(lockfile.Source{
+				Type: lockfile.SourceTypeGit,
+				Ref:  g.URL,
+				Pin:  g.Commit,
+			})
+		}
+		for _, h := range c.Sources.HTTP {
+			typ := lockfile.SourceTypeHTTP
+			if strings.HasPrefix(h.URL, srctypes.S3Scheme+"://") {
+				typ = lockfile.SourceTypeS3
+			}
+			l.Add(lockfile.Source{
+				Type: typ,
+				Ref:  h.URL,
+				Pin:  h.Digest.String(),
+			})
+		}
+		return nil
+	})
+	l.Sort()
+	return l
+}
@@ -0,0 +1,61 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/moby/buildkit/sourcepolicy/lockfile"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceLockfile(t *testing.T) {
+	dgst := digest.FromString("foo")
+	amd64 := &provenance.Capture{
+		Sources: provenancetypes.Sources{
+			Images: []provenancetypes.ImageSource{
+				{Ref: "docker.io/library/busybox:latest", Digest: dgst},
+				{Ref: "docker.io/library/local:latest", Digest: dgst, Local: true},
+			},
+			Artifacts: []provenancetypes.ArtifactSource{
+				{Ref: "ghcr.io/example/models:v1", Digest: dgst, ArtifactType: "application/vnd.example.model.v1"},
+			},
+			Git: []provenancetypes.GitSource{
+				{URL: "https://github.com/moby/buildkit.git#master", Commit: "2951a28cd7085eb18979b1f710678623d94ed578"},
+			},
+			HTTP: []provenancetypes.HTTPSource{
+				{URL: "https://example.com/foo", Digest: dgst},
+				{URL: "s3://bucket/key", Digest: dgst},
+			},
+			Local: []provenancetypes.LocalSource{
+				{Name: "context"},
+			},
+		},
+	}
+	arm64 := &provenance.Capture{
+		Sources: provenancetypes.Sources{
+			Images: []provenancetypes.ImageSource{
+				{Ref: "docker.io/library/busybox:latest", Digest: dgst},
+				{Ref: "docker.io/library/alpine:latest", Digest: dgst},
+			},
+		},
+	}
+
+	l := sourceLockfile(&result.Result[*provenance.Capture]{
+		Refs: map[string]*provenance.Capture{
+			"linux/amd64": amd64,
+			"linux/arm64": arm64,
+		},
+	})
+	require.Equal(t, lockfile.Version, l.Version)
+	require.Equal(t, []lockfile.Source{
+		{Type: lockfile.SourceTypeImage, Ref: "docker.io/library/alpine:latest", Pin: dgst.String()},
+		{Type: lockfile.SourceTypeImage, Ref: "docker.io/library/busybox:latest", Pin: dgst.String()},
+		{Type: lockfile.SourceTypeGit, Ref: "https://github.com/moby/buildkit.git#master", Pin: "2951a28cd7085eb18979b1f710678623d94ed578"},
+		{Type: lockfile.SourceTypeHTTP, Ref: "https://example.com/foo", Pin: dgst.String()},
+		{Type: lockfile.SourceTypeOCIArtifact, Ref: "ghcr.io/example/models:v1", Pin: dgst.String()},
+		{Type: lockfile.SourceTypeS3, Ref: "s3://bucket/key", Pin: dgst.String()},
+	}, l.Sources)
+}
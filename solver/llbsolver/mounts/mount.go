@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/continuity/fs"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/identity"
@@ -17,12 +19,15 @@ import (
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/session/sshforward"
 	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/worker"
 	"github.com/moby/locker"
 	"github.com/moby/sys/user"
 	"github.com/moby/sys/userns"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 )
@@ -57,6 +62,9 @@ func (mm *MountManager) getRefCacheDir(ctx context.Context, ref cache.ImmutableR
 		name:            name,
 		session:         s,
 	}
+	if m.CacheOpt != nil {
+		g.maxSize = m.CacheOpt.MaxSize
+	}
 	return g.getRefCacheDir(ctx, ref, id, sharing)
 }
 
@@ -67,6 +75,9 @@ type cacheRefGetter struct {
 	globalCacheRefs *cacheRefs
 	name            string
 	session         session.Group
+	// maxSize discards the contents of existing cache mounts bigger than
+	// maxSize bytes
+	maxSize int64
 }
 
 func (g *cacheRefGetter) getRefCacheDir(ctx context.Context, ref cache.ImmutableRef, id string, sharing pb.CacheSharingOpt) (mref cache.MutableRef, err error) {
@@ -92,20 +103,20 @@ func (g *cacheRefGetter) getRefCacheDir(ctx context.Context, ref cache.Immutable
 	switch sharing {
 	case pb.CacheSharingOpt_SHARED:
 		return g.globalCacheRefs.get(ctx, key, func() (cache.MutableRef, error) {
-			return g.getRefCacheDirNoCache(ctx, key, ref, id, false)
+			return g.getRefCacheDirNoCache(ctx, key, ref, id, sharing, false)
 		})
 	case pb.CacheSharingOpt_PRIVATE:
-		return g.getRefCacheDirNoCache(ctx, key, ref, id, false)
+		return g.getRefCacheDirNoCache(ctx, key, ref, id, sharing, false)
 	case pb.CacheSharingOpt_LOCKED:
-		return g.getRefCacheDirNoCache(ctx, key, ref, id, true)
+		return g.getRefCacheDirNoCache(ctx, key, ref, id, sharing, true)
 	default:
 		return nil, errors.Errorf("invalid cache sharing option: %s", sharing.String())
 	}
 }
 
-func (g *cacheRefGetter) getRefCacheDirNoCache(ctx context.Context, key string, ref cache.ImmutableRef, id string, block bool) (cache.MutableRef, error) {
+func (g *cacheRefGetter) getRefCacheDirNoCache(ctx context.Context, key string, ref cache.ImmutableRef, id string, sharing pb.CacheSharingOpt, block bool) (cache.MutableRef, error) {
 	makeMutable := func(ref cache.ImmutableRef) (cache.MutableRef, error) {
-		newRef, err := g.cm.New(ctx, ref, g.session, cache.WithRecordType(client.UsageRecordTypeCacheMount), cache.WithDescription(g.name), cache.CachePolicyRetain)
+		newRef, err := g.cm.New(ctx, ref, g.session, cache.WithRecordType(client.UsageRecordTypeCacheMount), cache.WithDescription(g.name), cache.WithCacheMountID(id), cache.CachePolicyRetain)
 		if err != nil {
 			return nil, err
 		}
@@ -123,8 +134,14 @@ func (g *cacheRefGetter) getRefCacheDirNoCache(ctx context.Context, key string,
 		locked := false
 		for _, si := range sis {
 			if mRef, err := g.cm.GetMutable(ctx, si.ID()); err == nil {
+				if over, err := g.discardOverMaxSize(ctx, mRef, id); err != nil {
+					mRef.Release(context.TODO())
+					return nil, err
+				} else if over {
+					continue
+				}
 				bklog.G(ctx).Debugf("reusing ref for cache dir %q: %s", id, mRef.ID())
-				return mRef, nil
+				return g.withSize(mRef), nil
 			} else if errors.Is(err, cache.ErrLocked) {
 				locked = true
 			} else {
@@ -144,6 +161,13 @@ func (g *cacheRefGetter) getRefCacheDirNoCache(ctx context.Context, key string,
 			break
 		}
 	}
+	if ref == nil {
+		// start from the contents imported from a remote cache, if any
+		if imported := loadImportedCacheDir(ctx, id, sharing); imported != nil {
+			defer imported.Release(context.WithoutCancel(ctx))
+			ref = imported
+		}
+	}
 	mRef, err := makeMutable(ref)
 	if err != nil {
 		return nil, err
@@ -154,7 +178,123 @@ func (g *cacheRefGetter) getRefCacheDirNoCache(ctx context.Context, key string,
 		mRef.Release(context.TODO())
 		return nil, err
 	}
-	return mRef, nil
+	if err := md.setCacheDirSharing(sharing); err != nil {
+		mRef.Release(context.TODO())
+		return nil, err
+	}
+	return g.withSize(mRef), nil
+}
+
+// discardOverMaxSize releases and removes the cache dir if the size of its
+// contents, recorded when it was last released, is bigger than the max size of
+// the mount. The caller must release the ref if an error is returned.
+func (g *cacheRefGetter) discardOverMaxSize(ctx context.Context, mRef cache.MutableRef, id string) (bool, error) {
+	md := CacheRefMetadata{mRef}
+	size, ok := md.cacheDirSize()
+	if g.maxSize <= 0 {
+		// the size is not recorded when this mount is released
+		if ok {
+			return false, md.clearCacheDirSize()
+		}
+		return false, nil
+	}
+	if !ok || size <= g.maxSize {
+		return false, nil
+	}
+	bklog.G(ctx).Infof("discarding cache dir %q: size %d exceeds max size %d", id, size, g.maxSize)
+	if err := md.ClearCacheDirIndex(); err != nil {
+		return false, err
+	}
+	// without the retain policy the ref is removed when released
+	if err := mRef.SetCachePolicyDefault(); err != nil {
+		return false, err
+	}
+	if err := mRef.Release(context.TODO()); err != nil {
+		bklog.G(ctx).WithError(err).Errorf("failed to release discarded cache dir %q: %s", id, mRef.ID())
+	}
+	return true, nil
+}
+
+// withSize returns mRef that records the size of its contents when released
+// if the mount has a max size, so that reusing the cache dir doesn't need to
+// walk its contents.
+func (g *cacheRefGetter) withSize(mRef cache.MutableRef) cache.MutableRef {
+	if g.maxSize <= 0 {
+		return mRef
+	}
+	return &sizedCacheRef{MutableRef: mRef, session: g.session}
+}
+
+type sizedCacheRef struct {
+	cache.MutableRef
+	session session.Group
+}
+
+func (r *sizedCacheRef) Release(ctx context.Context) error {
+	if err := r.recordSize(ctx); err != nil {
+		bklog.G(ctx).WithError(err).Warnf("failed to record size of cache dir %s", r.ID())
+	}
+	return r.MutableRef.Release(ctx)
+}
+
+func (r *sizedCacheRef) recordSize(ctx context.Context) error {
+	mnt, err := r.Mount(ctx, true, r.session)
+	if err != nil {
+		return err
+	}
+	lm := snapshot.LocalMounter(mnt)
+	dir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	usage, err := fs.DiskUsage(ctx, dir)
+	lm.Unmount()
+	if err != nil {
+		return err
+	}
+	return CacheRefMetadata{r.MutableRef}.setCacheDirSize(usage.Size)
+}
+
+// CacheMountKey returns the cache key that the contents of the cache mount id
+// with the sharing mode are exported to a remote cache with.
+func CacheMountKey(id string, sharing pb.CacheSharingOpt) digest.Digest {
+	return digest.FromString(fmt.Sprintf("buildkit.cachemount.v0:%s:%s", id, sharing))
+}
+
+// loadImportedCacheDir returns the contents of the cache mount id that were
+// imported from a remote cache for the build of the vertex being executed.
+func loadImportedCacheDir(ctx context.Context, id string, sharing pb.CacheSharingOpt) cache.ImmutableRef {
+	cm := solver.CacheManagerOf(ctx)
+	if cm == nil {
+		return nil
+	}
+	keys, err := cm.Query(nil, 0, CacheMountKey(id, sharing), 0)
+	if err != nil {
+		bklog.G(ctx).WithError(err).Debugf("failed to query imported cache dir %q", id)
+		return nil
+	}
+	for _, k := range keys {
+		recs, err := cm.Records(ctx, k)
+		if err != nil {
+			bklog.G(ctx).WithError(err).Debugf("failed to get records for imported cache dir %q", id)
+			continue
+		}
+		for _, rec := range recs {
+			res, err := cm.Load(ctx, rec)
+			if err != nil {
+				bklog.G(ctx).WithError(err).Debugf("failed to load imported cache dir %q", id)
+				continue
+			}
+			wref, ok := res.Sys().(*worker.WorkerRef)
+			if !ok || wref.ImmutableRef == nil {
+				res.Release(context.WithoutCancel(ctx))
+				continue
+			}
+			bklog.G(ctx).Debugf("using imported ref for cache dir %q: %s", id, wref.ImmutableRef.ID())
+			return wref.ImmutableRef
+		}
+	}
+	return nil
 }
 
 func (mm *MountManager) getSSHMountable(ctx context.Context, m *pb.Mount, g session.Group) (cache.Mountable, error) {
@@ -536,6 +676,9 @@ func (r *cacheRef) Release(ctx context.Context) error {
 const (
 	keyCacheDir   = "cache-dir"
 	cacheDirIndex = keyCacheDir + ":"
+
+	keyCacheDirSharing = "cache-dir.sharing"
+	keyCacheDirSize    = "cache-dir.size"
 )
 
 func SearchCacheDir(ctx context.Context, store cache.MetadataStore, id string, withNested bool) ([]CacheRefMetadata, error) {
@@ -569,6 +712,37 @@ func (md CacheRefMetadata) setCacheDirIndex(id string) error {
 	return md.SetString(keyCacheDir, id, cacheDirIndex+id)
 }
 
+func (md CacheRefMetadata) setCacheDirSharing(sharing pb.CacheSharingOpt) error {
+	return md.SetString(keyCacheDirSharing, sharing.String(), "")
+}
+
+// CacheDirSharing returns the sharing mode of the cache mount.
+func (md CacheRefMetadata) CacheDirSharing() pb.CacheSharingOpt {
+	return pb.CacheSharingOpt(pb.CacheSharingOpt_value[md.GetString(keyCacheDirSharing)])
+}
+
+func (md CacheRefMetadata) setCacheDirSize(size int64) error {
+	return md.SetString(keyCacheDirSize, strconv.FormatInt(size, 10), "")
+}
+
+func (md CacheRefMetadata) clearCacheDirSize() error {
+	return md.SetString(keyCacheDirSize, "", "")
+}
+
+// cacheDirSize returns the size of the contents of the cache dir when it was
+// last released by a mount with a max size.
+func (md CacheRefMetadata) cacheDirSize() (int64, bool) {
+	v := md.GetString(keyCacheDirSize)
+	if v == "" {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
 func (md CacheRefMetadata) ClearCacheDirIndex() error {
 	return md.ClearValueAndIndex(keyCacheDir, cacheDirIndex)
 }
@@ -201,6 +201,67 @@ func TestCacheMountPrivateRefs(t *testing.T) {
 	require.Equal(t, ref4.ID(), ref6.ID())
 }
 
+func TestCacheMountMaxSize(t *testing.T) {
+	t.Parallel()
+	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
+
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	co, err := newCacheManager(ctx, t, cmOpt{
+		snapshotter:     snapshotter,
+		snapshotterName: "native",
+	})
+	require.NoError(t, err)
+
+	getRef := func(maxSize int64) cache.MutableRef {
+		g := newRefGetter(co.manager, sharedCacheRefs)
+		g.maxSize = maxSize
+		ref, err := g.getRefCacheDir(ctx, nil, "foo", pb.CacheSharingOpt_PRIVATE)
+		require.NoError(t, err)
+		return ref
+	}
+
+	ref := getRef(1 << 20)
+	mnt, err := ref.Mount(ctx, false, nil)
+	require.NoError(t, err)
+	lm := snapshot.LocalMounter(mnt)
+	dir, err := lm.Mount()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data"), make([]byte, 64<<10), 0600))
+	require.NoError(t, lm.Unmount())
+	id := ref.ID()
+	require.NoError(t, ref.Release(ctx))
+
+	// the size is recorded on release and checked on reuse
+	ref = getRef(1 << 20)
+	require.Equal(t, id, ref.ID())
+	size, ok := CacheRefMetadata{ref}.cacheDirSize()
+	require.True(t, ok)
+	require.GreaterOrEqual(t, size, int64(64<<10))
+	require.NoError(t, ref.Release(ctx))
+
+	// a mount without max size doesn't keep the recorded size
+	ref = getRef(0)
+	require.Equal(t, id, ref.ID())
+	_, ok = CacheRefMetadata{ref}.cacheDirSize()
+	require.False(t, ok)
+	require.NoError(t, ref.Release(ctx))
+
+	ref = getRef(1 << 10)
+	require.Equal(t, id, ref.ID())
+	require.NoError(t, ref.Release(ctx))
+
+	ref = getRef(1 << 10)
+	require.NotEqual(t, id, ref.ID())
+	require.NoError(t, ref.Release(ctx))
+}
+
 func TestCacheMountSharedRefs(t *testing.T) {
 	t.Parallel()
 	ctx := namespaces.WithNamespace(context.Background(), "buildkit-test")
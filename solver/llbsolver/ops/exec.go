@@ -10,12 +10,16 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/containerd/platforms"
 	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/executor"
 	resourcestypes "github.com/moby/buildkit/executor/resources/types"
 	"github.com/moby/buildkit/frontend/gateway/container"
+	gatewayapi "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/solver"
@@ -23,6 +27,7 @@ import (
 	"github.com/moby/buildkit/solver/llbsolver/mounts"
 	"github.com/moby/buildkit/solver/llbsolver/ops/opsutils"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/logs"
 	utilsystem "github.com/moby/buildkit/util/system"
 	"github.com/moby/buildkit/worker"
@@ -35,6 +40,13 @@ import (
 
 const execCacheType = "buildkit.exec.v0"
 
+const (
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+)
+
+var errExecTimeout = errors.New("exec timeout exceeded")
+
 type ExecOp struct {
 	op          *pb.ExecOp
 	cm          cache.Manager
@@ -45,13 +57,14 @@ type ExecOp struct {
 	platform    *pb.Platform
 	numInputs   int
 	parallelism *semaphore.Weighted
+	opts        opOpts
 	rec         resourcestypes.Recorder
 	digest      digest.Digest
 }
 
 var _ solver.Op = &ExecOp{}
 
-func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.Manager, parallelism *semaphore.Weighted, sm *session.Manager, exec executor.Executor, w worker.Worker) (*ExecOp, error) {
+func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.Manager, parallelism *semaphore.Weighted, sm *session.Manager, exec executor.Executor, w worker.Worker, opts ...Opt) (*ExecOp, error) {
 	if err := opsutils.Validate(&pb.Op{Op: op}); err != nil {
 		return nil, err
 	}
@@ -66,6 +79,7 @@ func NewExecOp(v solver.Vertex, op *pb.Op_Exec, platform *pb.Platform, cm cache.
 		w:           w,
 		platform:    platform,
 		parallelism: parallelism,
+		opts:        newOpOpts(opts),
 		digest:      v.Digest(),
 	}, nil
 }
@@ -120,8 +134,15 @@ func (e *ExecOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 			m.CacheOpt.ID = ""
 			m.CacheOpt.Sharing = 0
 		}
+		if m.CacheOpt != nil {
+			// the size limit does not change the result of the process
+			m.CacheOpt.MaxSize = 0
+		}
 	}
 	op.Meta.ProxyEnv = nil
+	// timeout and retry policy do not change the result of the process
+	op.Meta.Timeout = 0
+	op.Meta.Retry = nil
 
 	var p ocispecs.Platform
 	if e.platform != nil {
@@ -357,9 +378,66 @@ func addDefaultEnvvar(env []string, k, v string) []string {
 	return append(env, k+"="+v)
 }
 
-func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) (results []solver.Result, err error) {
+func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Result) ([]solver.Result, error) {
 	trace.SpanFromContext(ctx).AddEvent("ExecOp started")
 
+	attempts := 1
+	backoff := defaultRetryBackoff
+	if r := e.op.Meta.Retry; r != nil {
+		attempts += int(r.Attempts)
+		if r.Backoff > 0 {
+			backoff = time.Duration(r.Backoff)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		results, err := e.execOnce(ctx, g, inputs)
+		if err == nil || attempt >= attempts || !isRetryable(ctx, err) {
+			return results, err
+		}
+		var ee *errdefs.ExecError
+		if errors.As(err, &ee) {
+			ee.Release()
+		}
+		writeEvent(ctx, client.VertexEvent{
+			Type:    client.VertexEventRetry,
+			Message: fmt.Sprintf("attempt %d/%d failed, retrying in %s: %v", attempt, attempts, backoff, err),
+			Attempt: attempt + 1,
+		})
+		// other ops may run while waiting for the next attempt
+		if err := solver.WithoutOpResources(ctx, func(ctx context.Context) error {
+			timer := time.NewTimer(backoff)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			case <-timer.C:
+				return nil
+			}
+		}); err != nil {
+			return nil, err
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// isRetryable returns true if the process of the exec failed, as opposed to
+// errors preparing the mounts or a canceled build.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var exitErr *gatewayapi.ExitError
+	return errors.As(err, &exitErr)
+}
+
+func writeEvent(ctx context.Context, ev client.VertexEvent) {
+	pw, _, _ := progress.NewFromContext(ctx)
+	pw.Write(identity.NewID(), ev)
+	pw.Close()
+}
+
+func (e *ExecOp) execOnce(ctx context.Context, g session.Group, inputs []solver.Result) (results []solver.Result, err error) {
 	refs := make([]*worker.WorkerRef, len(inputs))
 	for i, inp := range inputs {
 		var ok bool
@@ -429,8 +507,9 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 	if err != nil {
 		return nil, err
 	}
+	args := e.op.Meta.Args
 	if emu != nil {
-		e.op.Meta.Args = append([]string{qemuMountName}, e.op.Meta.Args...)
+		args = append([]string{qemuMountName}, args...)
 
 		p.Mounts = append(p.Mounts, executor.Mount{
 			Readonly: true,
@@ -440,7 +519,7 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 	}
 
 	meta := executor.Meta{
-		Args:                      e.op.Meta.Args,
+		Args:                      args,
 		Env:                       e.op.Meta.Env,
 		Cwd:                       e.op.Meta.Cwd,
 		User:                      e.op.Meta.User,
@@ -489,12 +568,27 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 		}
 	}()
 
-	rec, execErr := e.exec.Run(ctx, "", p.Root, p.Mounts, executor.ProcessInfo{
+	runCtx := ctx
+	if d := time.Duration(e.op.Meta.Timeout); d > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, d, errors.WithStack(errExecTimeout))
+		defer cancel()
+	}
+
+	rec, execErr := e.exec.Run(runCtx, "", p.Root, p.Mounts, executor.ProcessInfo{
 		Meta:   meta,
 		Stdin:  nil,
 		Stdout: stdout,
 		Stderr: stderr,
 	}, nil)
+	if execErr != nil && ctx.Err() == nil && errors.Is(context.Cause(runCtx), errExecTimeout) {
+		d := time.Duration(e.op.Meta.Timeout)
+		execErr = errors.Wrapf(execErr, "timed out after %s", d)
+		writeEvent(ctx, client.VertexEvent{
+			Type:    client.VertexEventTimeout,
+			Message: fmt.Sprintf("process %q timed out after %s", strings.Join(args, " "), d),
+		})
+	}
 
 	for i, out := range p.OutputRefs {
 		if mutable, ok := out.Ref.(cache.MutableRef); ok {
@@ -510,7 +604,7 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 		p.OutputRefs[i].Ref = nil
 	}
 	e.rec = rec
-	return results, errors.Wrapf(execErr, "process %q did not complete successfully", strings.Join(e.op.Meta.Args, " "))
+	return results, errors.Wrapf(execErr, "process %q did not complete successfully", strings.Join(args, " "))
 }
 
 func proxyEnvList(p *pb.ProxyEnv) []string {
@@ -534,16 +628,7 @@ func proxyEnvList(p *pb.ProxyEnv) []string {
 }
 
 func (e *ExecOp) Acquire(ctx context.Context) (solver.ReleaseFunc, error) {
-	if e.parallelism == nil {
-		return func() {}, nil
-	}
-	err := e.parallelism.Acquire(ctx, 1)
-	if err != nil {
-		return nil, err
-	}
-	return func() {
-		e.parallelism.Release(1)
-	}, nil
+	return acquireParallelism(ctx, e.parallelism, e.opts.fairParallelism)
 }
 
 func (e *ExecOp) loadSecretEnv(ctx context.Context, g session.Group) ([]string, error) {
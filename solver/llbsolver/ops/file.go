@@ -35,9 +35,10 @@ type fileOp struct {
 	refManager  *file.RefManager
 	numInputs   int
 	parallelism *semaphore.Weighted
+	opts        opOpts
 }
 
-func NewFileOp(v solver.Vertex, op *pb.Op_File, cm cache.Manager, parallelism *semaphore.Weighted, w worker.Worker) (solver.Op, error) {
+func NewFileOp(v solver.Vertex, op *pb.Op_File, cm cache.Manager, parallelism *semaphore.Weighted, w worker.Worker, opts ...Opt) (solver.Op, error) {
 	if err := opsutils.Validate(&pb.Op{Op: op}); err != nil {
 		return nil, err
 	}
@@ -48,6 +49,7 @@ func NewFileOp(v solver.Vertex, op *pb.Op_File, cm cache.Manager, parallelism *s
 		refManager:  refManager,
 		numInputs:   len(v.Inputs()),
 		parallelism: parallelism,
+		opts:        newOpOpts(opts),
 	}, nil
 }
 
@@ -194,16 +196,7 @@ func (f *fileOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 }
 
 func (f *fileOp) Acquire(ctx context.Context) (solver.ReleaseFunc, error) {
-	if f.parallelism == nil {
-		return func() {}, nil
-	}
-	err := f.parallelism.Acquire(ctx, 1)
-	if err != nil {
-		return nil, err
-	}
-	return func() {
-		f.parallelism.Release(1)
-	}, nil
+	return acquireParallelism(ctx, f.parallelism, f.opts.fairParallelism)
 }
 
 func addSelector(m map[int][]opsutils.Selector, idx int, sel string, wildcard, followLinks bool, includePatterns, excludePatterns []string) {
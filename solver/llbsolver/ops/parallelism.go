@@ -0,0 +1,55 @@
+package ops
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/fairsem"
+	"golang.org/x/sync/semaphore"
+)
+
+// Opt configures the ops created by NewSourceOp, NewExecOp and NewFileOp.
+type Opt func(*opOpts)
+
+type opOpts struct {
+	fairParallelism *fairsem.Semaphore
+}
+
+// WithFairParallelism makes the op acquire a slot of s before running. The
+// slots of s are shared between concurrent builds in proportion to their
+// scheduler weights.
+func WithFairParallelism(s *fairsem.Semaphore) Opt {
+	return func(o *opOpts) {
+		o.fairParallelism = s
+	}
+}
+
+func newOpOpts(opts []Opt) opOpts {
+	var o opOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// acquireParallelism acquires a slot of fair, or of sem if fair is nil. fair
+// replaces sem when both are set so that an op only waits in one queue.
+// Either may be nil.
+func acquireParallelism(ctx context.Context, sem *semaphore.Weighted, fair *fairsem.Semaphore) (solver.ReleaseFunc, error) {
+	if fair != nil {
+		release, err := fair.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return release, nil
+	}
+	if sem != nil {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+		return func() {
+			sem.Release(1)
+		}, nil
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,34 @@
+package ops
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/util/fairsem"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestAcquireParallelism(t *testing.T) {
+	ctx := context.TODO()
+	sem := semaphore.NewWeighted(1)
+	fair := fairsem.New(1)
+
+	// the slot of fair is acquired instead of the slot of sem
+	release, err := acquireParallelism(ctx, sem, fair)
+	require.NoError(t, err)
+	require.True(t, sem.TryAcquire(1))
+	sem.Release(1)
+	release()
+
+	release, err = acquireParallelism(ctx, sem, nil)
+	require.NoError(t, err)
+	require.False(t, sem.TryAcquire(1))
+	release()
+	require.True(t, sem.TryAcquire(1))
+	sem.Release(1)
+
+	release, err = acquireParallelism(ctx, nil, nil)
+	require.NoError(t, err)
+	release()
+}
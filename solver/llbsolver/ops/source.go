@@ -27,13 +27,14 @@ type SourceOp struct {
 	w           worker.Worker
 	vtx         solver.Vertex
 	parallelism *semaphore.Weighted
+	opts        opOpts
 	pin         string
 	id          source.Identifier
 }
 
 var _ solver.Op = &SourceOp{}
 
-func NewSourceOp(vtx solver.Vertex, op *pb.Op_Source, platform *pb.Platform, sm *source.Manager, parallelism *semaphore.Weighted, sessM *session.Manager, w worker.Worker) (*SourceOp, error) {
+func NewSourceOp(vtx solver.Vertex, op *pb.Op_Source, platform *pb.Platform, sm *source.Manager, parallelism *semaphore.Weighted, sessM *session.Manager, w worker.Worker, opts ...Opt) (*SourceOp, error) {
 	if err := opsutils.Validate(&pb.Op{Op: op}); err != nil {
 		return nil, err
 	}
@@ -45,6 +46,7 @@ func NewSourceOp(vtx solver.Vertex, op *pb.Op_Source, platform *pb.Platform, sm
 		platform:    platform,
 		vtx:         vtx,
 		parallelism: parallelism,
+		opts:        newOpOpts(opts),
 	}, nil
 }
 
@@ -113,14 +115,5 @@ func (s *SourceOp) Exec(ctx context.Context, g session.Group, _ []solver.Result)
 }
 
 func (s *SourceOp) Acquire(ctx context.Context) (solver.ReleaseFunc, error) {
-	if s.parallelism == nil {
-		return func() {}, nil
-	}
-	err := s.parallelism.Acquire(ctx, 1)
-	if err != nil {
-		return nil, err
-	}
-	return func() {
-		s.parallelism.Release(1)
-	}, nil
+	return acquireParallelism(ctx, s.parallelism, s.opts.fairParallelism)
 }
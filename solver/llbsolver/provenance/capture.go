@@ -32,6 +32,9 @@ func (c *Capture) Merge(c2 *Capture) error {
 	for _, i := range c2.Sources.Images {
 		c.AddImage(i)
 	}
+	for _, a := range c2.Sources.Artifacts {
+		c.AddArtifact(a)
+	}
 	for _, l := range c2.Sources.Local {
 		c.AddLocal(l)
 	}
@@ -60,6 +63,9 @@ func (c *Capture) Sort() {
 	slices.SortFunc(c.Sources.Images, func(a, b provenancetypes.ImageSource) int {
 		return cmp.Compare(a.Ref, b.Ref)
 	})
+	slices.SortFunc(c.Sources.Artifacts, func(a, b provenancetypes.ArtifactSource) int {
+		return cmp.Or(cmp.Compare(a.Ref, b.Ref), cmp.Compare(a.Digest, b.Digest))
+	})
 	slices.SortFunc(c.Sources.Local, func(a, b provenancetypes.LocalSource) int {
 		return cmp.Compare(a.Name, b.Name)
 	})
@@ -126,6 +132,18 @@ func (c *Capture) AddImage(i provenancetypes.ImageSource) {
 	c.Sources.Images = append(c.Sources.Images, i)
 }
 
+func (c *Capture) AddArtifact(a provenancetypes.ArtifactSource) {
+	for i, v := range c.Sources.Artifacts {
+		if v.Ref == a.Ref && v.Digest == a.Digest {
+			if v.ArtifactType == "" {
+				c.Sources.Artifacts[i].ArtifactType = a.ArtifactType
+			}
+			return
+		}
+	}
+	c.Sources.Artifacts = append(c.Sources.Artifacts, a)
+}
+
 func (c *Capture) AddLocal(l provenancetypes.LocalSource) {
 	for _, v := range c.Sources.Local {
 		if v.Name == l.Name {
@@ -137,8 +155,11 @@ func (c *Capture) AddLocal(l provenancetypes.LocalSource) {
 
 func (c *Capture) AddGit(g provenancetypes.GitSource) {
 	g.URL = urlutil.RedactCredentials(g.URL)
-	for _, v := range c.Sources.Git {
+	for i, v := range c.Sources.Git {
 		if v.URL == g.URL {
+			if v.Signature == nil {
+				c.Sources.Git[i].Signature = g.Signature
+			}
 			return
 		}
 	}
@@ -147,8 +168,11 @@ func (c *Capture) AddGit(g provenancetypes.GitSource) {
 
 func (c *Capture) AddHTTP(h provenancetypes.HTTPSource) {
 	h.URL = urlutil.RedactCredentials(h.URL)
-	for _, v := range c.Sources.HTTP {
+	for i, v := range c.Sources.HTTP {
 		if v.URL == h.URL {
+			if v.Signature == nil {
+				c.Sources.HTTP[i].Signature = h.Signature
+			}
 			return
 		}
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 func slsaMaterials(srcs provenancetypes.Sources) ([]slsa.ProvenanceMaterial, error) {
-	count := len(srcs.Images) + len(srcs.Git) + len(srcs.HTTP)
+	count := len(srcs.Images) + len(srcs.Artifacts) + len(srcs.Git) + len(srcs.HTTP)
 	out := make([]slsa.ProvenanceMaterial, 0, count)
 
 	for _, s := range srcs.Images {
@@ -38,6 +38,19 @@ func slsaMaterials(srcs provenancetypes.Sources) ([]slsa.ProvenanceMaterial, err
 		out = append(out, material)
 	}
 
+	for _, s := range srcs.Artifacts {
+		uri, err := purl.ArtifactToPURL(s.Ref, s.Digest, s.ArtifactType)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, slsa.ProvenanceMaterial{
+			URI: uri,
+			Digest: slsa.DigestSet{
+				s.Digest.Algorithm().String(): s.Digest.Hex(),
+			},
+		})
+	}
+
 	for _, s := range srcs.Git {
 		out = append(out, slsa.ProvenanceMaterial{
 			URI: s.URL,
@@ -176,6 +189,17 @@ func NewPredicate(c *Capture) (*provenancetypes.ProvenancePredicateSLSA02, error
 		pr.Metadata.BuildKitMetadata.VCS = vcs
 	}
 
+	for _, s := range c.Sources.Git {
+		if s.Signature != nil {
+			pr.Metadata.BuildKitMetadata.GitSignatures = append(pr.Metadata.BuildKitMetadata.GitSignatures, s.Signature)
+		}
+	}
+	for _, s := range c.Sources.HTTP {
+		if s.Signature != nil {
+			pr.Metadata.BuildKitMetadata.HTTPSignatures = append(pr.Metadata.BuildKitMetadata.HTTPSignatures, s.Signature)
+		}
+	}
+
 	return pr, nil
 }
 
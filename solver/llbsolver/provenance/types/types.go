@@ -60,14 +60,46 @@ type ImageSource struct {
 	Local    bool
 }
 
+// ArtifactSource is an OCI artifact. Digest is the digest of the manifest
+// and ArtifactType is the artifact type of the manifest, or the media type of
+// its config for artifacts without an artifact type.
+type ArtifactSource struct {
+	Ref          string
+	Digest       digest.Digest
+	ArtifactType string
+}
+
 type GitSource struct {
-	URL    string
-	Commit string
+	URL       string
+	Commit    string
+	Signature *GitSignature
+}
+
+// GitSignature records the public keys that the signature of a git source
+// was verified against, and the key that made the signature.
+type GitSignature struct {
+	URI               string        `json:"uri"`
+	Commit            string        `json:"commit"`
+	PubKeys           digest.Digest `json:"pubKeys,omitempty"`
+	PubKeySecret      string        `json:"pubKeySecret,omitempty"`
+	SignerType        string        `json:"signerType,omitempty"`
+	SignerFingerprint string        `json:"signerFingerprint,omitempty"`
 }
 
 type HTTPSource struct {
-	URL    string
-	Digest digest.Digest
+	URL       string
+	Digest    digest.Digest
+	Signature *HTTPSignature
+}
+
+// HTTPSignature records the detached signature and the public keys that an
+// HTTP source was verified against.
+type HTTPSignature struct {
+	URI          string        `json:"uri"`
+	Digest       digest.Digest `json:"digest"`
+	SignatureURI string        `json:"signatureURI"`
+	PubKeys      digest.Digest `json:"pubKeys,omitempty"`
+	PubKeySecret string        `json:"pubKeySecret,omitempty"`
 }
 
 type LocalSource struct {
@@ -85,10 +117,11 @@ type SSH struct {
 }
 
 type Sources struct {
-	Images []ImageSource
-	Git    []GitSource
-	HTTP   []HTTPSource
-	Local  []LocalSource
+	Images    []ImageSource
+	Artifacts []ArtifactSource
+	Git       []GitSource
+	HTTP      []HTTPSource
+	Local     []LocalSource
 }
 
 func (ps *ProvenanceSLSA) Validate() error {
@@ -180,6 +213,10 @@ type BuildKitMetadata struct {
 	Source   *Source                            `json:"source,omitempty"`
 	Layers   map[string][][]ocispecs.Descriptor `json:"layers,omitempty"`
 	SysUsage []*resourcestypes.SysSample        `json:"sysUsage,omitempty"`
+	// GitSignatures lists the git sources that were required to be signed
+	GitSignatures []*GitSignature `json:"gitSignatures,omitempty"`
+	// HTTPSignatures lists the HTTP sources that were required to be signed
+	HTTPSignatures []*HTTPSignature `json:"httpSignatures,omitempty"`
 }
 
 type BuildKitComplete struct {
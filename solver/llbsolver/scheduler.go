@@ -0,0 +1,110 @@
+package llbsolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/admission"
+	"github.com/moby/buildkit/util/progress"
+)
+
+const defaultWeight = 1
+
+// weight returns the scheduling weight of a build. The weight set in the
+// request takes precedence over the weights configured for the scheduler
+// labels of the build, but can't be higher than the highest configured
+// weight.
+func (s *Solver) weight(exp ExporterRequest) int {
+	if exp.Weight > 0 {
+		return min(exp.Weight, s.maxWeight())
+	}
+	cfg := s.scheduler
+	if cfg == nil {
+		return defaultWeight
+	}
+	for _, w := range cfg.Weights {
+		if w.Weight > 0 && matchLabels(w.Labels, exp.SchedulerLabels) {
+			return w.Weight
+		}
+	}
+	if cfg.DefaultWeight > 0 {
+		return cfg.DefaultWeight
+	}
+	return defaultWeight
+}
+
+// maxWeight returns the highest weight a build can request.
+func (s *Solver) maxWeight() int {
+	maxWeight := defaultWeight
+	if cfg := s.scheduler; cfg != nil {
+		maxWeight = max(maxWeight, cfg.DefaultWeight)
+		for _, w := range cfg.Weights {
+			maxWeight = max(maxWeight, w.Weight)
+		}
+	}
+	return maxWeight
+}
+
+func matchLabels(want, labels map[string]string) bool {
+	if len(want) == 0 {
+		return false
+	}
+	for k, v := range want {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// admittedBuilder holds back building the vertexes of a job until the job
+// has been admitted by the build queue. Frontends running in the daemon only
+// start after admission, this covers the requests of frontends running in
+// the client.
+type admittedBuilder struct {
+	solver.Builder
+	ticket *admission.Ticket
+}
+
+func (b *admittedBuilder) Build(ctx context.Context, e solver.Edge) (solver.CachedResultWithProvenance, error) {
+	if err := b.ticket.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Builder.Build(ctx, e)
+}
+
+// reportQueue shows the position of the build in the queue as a vertex in
+// the progress of the job until the build is admitted or stop is closed.
+func reportQueue(ctx context.Context, b solver.Builder, t *admission.Ticket, stop <-chan struct{}) {
+	select {
+	case <-t.Admitted():
+		return
+	default:
+	}
+	inBuilderContext(ctx, b, "[internal] waiting in build queue", "", func(ctx context.Context, _ session.Group) error {
+		for {
+			if pos, n := t.Position(); pos > 0 {
+				pw, _, _ := progress.NewFromContext(ctx)
+				pw.Write(identity.NewID(), client.VertexEvent{
+					Type:     client.VertexEventQueued,
+					Message:  fmt.Sprintf("position %d of %d in the build queue", pos, n),
+					Position: pos,
+				})
+				pw.Close()
+			}
+			select {
+			case <-t.Admitted():
+				return nil
+			case <-t.Updates():
+			case <-stop:
+				return nil
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+		}
+	})
+}
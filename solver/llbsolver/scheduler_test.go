@@ -0,0 +1,25 @@
+package llbsolver
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeight(t *testing.T) {
+	s := &Solver{}
+	require.Equal(t, 1, s.weight(ExporterRequest{}))
+	require.Equal(t, 1, s.weight(ExporterRequest{Weight: 100}))
+
+	s.scheduler = &config.SchedulerConfig{
+		DefaultWeight: 2,
+		Weights: []config.SchedulerWeight{
+			{Labels: map[string]string{"team": "release"}, Weight: 8},
+		},
+	}
+	require.Equal(t, 2, s.weight(ExporterRequest{}))
+	require.Equal(t, 8, s.weight(ExporterRequest{SchedulerLabels: map[string]string{"team": "release"}}))
+	require.Equal(t, 3, s.weight(ExporterRequest{Weight: 3}))
+	require.Equal(t, 8, s.weight(ExporterRequest{Weight: 1 << 30}))
+}
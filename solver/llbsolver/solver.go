@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/cache/remotecache"
 	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
 	controlgateway "github.com/moby/buildkit/control/gateway"
 	"github.com/moby/buildkit/errdefs"
 	"github.com/moby/buildkit/executor/resources"
@@ -31,15 +33,22 @@ import (
 	"github.com/moby/buildkit/session"
 	sessionexporter "github.com/moby/buildkit/session/exporter"
 	"github.com/moby/buildkit/solver"
+	serrdefs "github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/llbsolver/cachemiss"
+	"github.com/moby/buildkit/solver/llbsolver/ops"
 	"github.com/moby/buildkit/solver/llbsolver/provenance"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/solver/result"
+	"github.com/moby/buildkit/sourcepolicy/expr"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/admission"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/entitlements"
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/tracing"
 	"github.com/moby/buildkit/util/tracing/detect"
@@ -54,18 +63,40 @@ import (
 const (
 	keyEntitlements = "llb.entitlements"
 	keySourcePolicy = "llb.sourcepolicy"
+	keyFrontendOpt  = "llb.frontendopt"
+	keyPlanOnly     = "llb.planonly"
 )
 
 type ExporterRequest struct {
 	Exporters             []exporter.ExporterInstance
 	CacheExporters        []RemoteCacheExporter
 	EnableSessionExporter bool
+	// Plan skips building the result. The planned status of the vertexes is
+	// returned instead. Operations can't be executed, also not by the
+	// frontend, and no exporters can be set.
+	Plan bool
+	// CacheOnly fails the build instead of executing any operations or
+	// resolving sources from the network.
+	CacheOnly bool
+	// KeepGoing continues building the vertexes that do not depend on a
+	// failed vertex and returns the errors of all the failed vertexes.
+	KeepGoing bool
+	// Weight is the scheduling weight of the build. Zero uses the weight
+	// configured for the SchedulerLabels or the default weight.
+	Weight          int
+	SchedulerLabels map[string]string
 }
 
 type RemoteCacheExporter struct {
 	remotecache.Exporter
 	solver.CacheExportMode
 	IgnoreError bool
+	// CacheMounts are the IDs of the cache mounts whose contents are
+	// exported with the cache.
+	CacheMounts []string
+	// CacheMountsMaxSize skips exporting cache mounts bigger than the size
+	// in bytes. Zero means no limit.
+	CacheMountsMaxSize int64
 }
 
 // ResolveWorkerFunc returns default worker for the temporary default non-distributed use cases
@@ -82,6 +113,10 @@ type Opt struct {
 	WorkerController *worker.Controller
 	HistoryQueue     *HistoryQueue
 	ResourceMonitor  *resources.Monitor
+	SchedulerConfig  *config.SchedulerConfig
+	// SourcePolicyConfig lists source policy files that are evaluated for
+	// every build in addition to the policy of the client.
+	SourcePolicyConfig *config.SourcePolicyConfig
 }
 
 type Solver struct {
@@ -96,6 +131,9 @@ type Solver struct {
 	entitlements              []string
 	history                   *HistoryQueue
 	sysSampler                *resources.Sampler[*resourcestypes.SysSample]
+	scheduler                 *config.SchedulerConfig
+	queue                     *admission.Queue
+	sourcePolicy              *daemonSourcePolicy
 }
 
 // Processor defines a processing function to be applied after solving, but
@@ -113,6 +151,17 @@ func New(opt Opt) (*Solver, error) {
 		sm:                        opt.SessionManager,
 		entitlements:              opt.Entitlements,
 		history:                   opt.HistoryQueue,
+		scheduler:                 opt.SchedulerConfig,
+	}
+	if cfg := opt.SchedulerConfig; cfg != nil && cfg.MaxConcurrentBuilds > 0 {
+		s.queue = admission.NewQueue(cfg.MaxConcurrentBuilds, cfg.MaxQueuedBuilds)
+	}
+	if cfg := opt.SourcePolicyConfig; cfg != nil {
+		pol, err := newDaemonSourcePolicy(cfg.Files)
+		if err != nil {
+			return nil, err
+		}
+		s.sourcePolicy = pol
 	}
 
 	sampler, err := resources.NewSysSampler()
@@ -155,6 +204,7 @@ func (s *Solver) bridge(b solver.Builder) *provenanceBridge {
 		resolveCacheImporterFuncs: s.resolveCacheImporterFuncs,
 		cms:                       map[string]solver.CacheManager{},
 		sm:                        s.sm,
+		sourcePolicy:              s.sourcePolicy,
 	}}
 }
 
@@ -162,6 +212,51 @@ func (s *Solver) Bridge(b solver.Builder) frontend.FrontendLLBBridge {
 	return s.bridge(b)
 }
 
+// cacheKeysRecord collects the cache keys computed by the job so that cache
+// misses can later be explained by comparing the records of two builds.
+func cacheKeysRecord(j *solver.Job, req frontend.SolveRequest) *cachemiss.Record {
+	rec := &cachemiss.Record{}
+	for k, v := range req.FrontendOpt {
+		if name, ok := strings.CutPrefix(k, "build-arg:"); ok {
+			if rec.BuildArgs == nil {
+				rec.BuildArgs = map[string]string{}
+			}
+			rec.BuildArgs[name] = v
+		}
+	}
+	for _, info := range j.CacheInfo() {
+		v := cachemiss.Vertex{
+			Digest:   info.Vertex.Digest(),
+			Name:     info.Vertex.Name(),
+			Cached:   info.Cached,
+			Executed: info.Executed,
+		}
+		if op, ok := info.Vertex.Sys().(*pb.Op); ok {
+			v.Op = op
+		}
+		for _, inp := range info.Vertex.Inputs() {
+			v.Inputs = append(v.Inputs, inp.Vertex.Digest())
+		}
+		for _, cm := range info.CacheMaps {
+			v.CacheKeys = append(v.CacheKeys, cm.Digest)
+		}
+		for idx, dgst := range info.ContentKeys {
+			if v.ContentKeys == nil {
+				v.ContentKeys = map[int]digest.Digest{}
+			}
+			v.ContentKeys[int(idx)] = dgst
+		}
+		if op, ok := info.Op.(*ops.SourceOp); ok {
+			_, v.Pin = op.Pin()
+		}
+		rec.Vertexes = append(rec.Vertexes, v)
+	}
+	slices.SortFunc(rec.Vertexes, func(a, b cachemiss.Vertex) int {
+		return strings.Compare(string(a.Digest), string(b.Digest))
+	})
+	return rec
+}
+
 func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend.SolveRequest, exp ExporterRequest, j *solver.Job, usage *resources.SysSampler) (func(context.Context, *Result, []exporter.DescriptorReference, error) error, error) {
 	stopTrace, err := detect.Recorder.Record(ctx)
 	if err != nil {
@@ -326,6 +421,34 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 			}
 		}
 
+		eg.Go(func() error {
+			dt, err := json.Marshal(cacheKeysRecord(j, req))
+			if err != nil {
+				return err
+			}
+			w, err := s.history.OpenBlobWriter(ctx2, cachemiss.MediaType)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(dt); err != nil {
+				w.Discard()
+				return err
+			}
+			desc, release, err := w.Commit(ctx2)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			releasers = append(releasers, release)
+			rec.CacheKeys = &controlapi.Descriptor{
+				Digest:    string(desc.Digest),
+				Size:      desc.Size,
+				MediaType: desc.MediaType,
+			}
+			mu.Unlock()
+			return nil
+		})
+
 		eg.Go(func() error {
 			st, releaseStatus, err := s.history.ImportStatus(ctx2, ch)
 			if err != nil {
@@ -475,6 +598,10 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 }
 
 func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req frontend.SolveRequest, exp ExporterRequest, ent []entitlements.Entitlement, post []Processor, internal bool, srcPol *spb.Policy) (_ *client.SolveResponse, err error) {
+	if exp.Plan && (len(exp.Exporters) > 0 || len(exp.CacheExporters) > 0 || exp.EnableSessionExporter) {
+		return nil, errors.New("plan mode can not be used with exporters")
+	}
+
 	j, err := s.solver.NewJob(id)
 	if err != nil {
 		return nil, err
@@ -520,10 +647,39 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		}
 		j.SetValue(keySourcePolicy, srcPol)
 	}
+	j.SetValue(keyFrontendOpt, req.FrontendOpt)
 
 	j.SessionID = sessionID
 
-	br := s.bridge(j)
+	if exp.CacheOnly {
+		j.SetCacheOnly(true)
+		ctx = offline.WithOffline(ctx)
+	}
+	j.SetPlanOnly(exp.Plan)
+	j.SetValue(keyPlanOnly, exp.Plan)
+	j.SetKeepGoing(exp.KeepGoing)
+
+	weight := s.weight(exp)
+	j.SetWeight(weight)
+
+	var (
+		b      solver.Builder = j
+		ticket *admission.Ticket
+	)
+	if s.queue != nil {
+		t, err := s.queue.Enqueue(weight)
+		if err != nil {
+			return nil, err
+		}
+		defer t.Release()
+		ticket = t
+		stop := make(chan struct{})
+		defer close(stop)
+		go reportQueue(ctx, j, t, stop)
+		b = &admittedBuilder{Builder: j, ticket: t}
+	}
+
+	br := s.bridge(b)
 	var fwd gateway.LLBBridgeForwarder
 	if s.gatewayForwarder != nil && req.Definition == nil && req.Frontend == "" {
 		fwd = gateway.NewBridgeForwarder(ctx, br, br, s.workerController.Infos(), req.FrontendInputs, sessionID, s.sm)
@@ -549,6 +705,14 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		}()
 	}
 
+	// the frontend only runs after the build has been admitted so that
+	// queued builds don't use the daemon
+	if ticket != nil {
+		if err := ticket.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	if fwd != nil {
 		var err error
 		select {
@@ -582,15 +746,23 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		})
 	})
 
-	eg, ctx2 := errgroup.WithContext(ctx)
-	res.EachRef(func(ref solver.ResultProxy) error {
-		eg.Go(func() error {
-			_, err := ref.Result(ctx2)
-			return err
-		})
-		return nil
-	})
-	if err := eg.Wait(); err != nil {
+	if exp.Plan {
+		plan, err := planResult(ctx, j, res)
+		if err != nil {
+			return nil, err
+		}
+		return &client.SolveResponse{
+			Plan: plan,
+		}, nil
+	}
+
+	if exp.CacheOnly {
+		if err := checkCacheOnly(ctx, j, res); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := buildRefs(ctx, res, exp.KeepGoing); err != nil {
 		return nil, err
 	}
 
@@ -657,7 +829,7 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 		return nil, err
 	}
 
-	cacheExporterResponse, err := runCacheExporters(ctx, cacheExporters, j, cached, inp)
+	cacheExporterResponse, err := s.runCacheExporters(ctx, cacheExporters, j, cached, inp)
 	if err != nil {
 		return nil, err
 	}
@@ -676,6 +848,9 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 			exporterResponse[k] = v
 		}
 	}
+	if err := addSourceLockfile(exporterResponse, resProv.Provenance); err != nil {
+		return nil, err
+	}
 
 	return &client.SolveResponse{
 		ExporterResponse: exporterResponse,
@@ -735,6 +910,10 @@ func (s *Solver) getSessionExporters(ctx context.Context, sessionID string, id i
 	return out, nil
 }
 
+// maxSourcePolicyCondition is the maximum length of the condition of a source
+// policy selector.
+const maxSourcePolicyCondition = 4096
+
 func validateSourcePolicy(pol *spb.Policy) error {
 	for _, r := range pol.Rules {
 		if r == nil {
@@ -748,11 +927,147 @@ func validateSourcePolicy(pol *spb.Policy) error {
 				return errors.New("invalid nil constraint in policy")
 			}
 		}
+		if r.Selector.Condition != "" {
+			if len(r.Selector.Condition) > maxSourcePolicyCondition {
+				return errors.Errorf("condition for %s in policy is longer than %d bytes", r.Selector.Identifier, maxSourcePolicyCondition)
+			}
+			if _, err := expr.Parse(r.Selector.Condition); err != nil {
+				return errors.Wrapf(err, "invalid condition for %s in policy", r.Selector.Identifier)
+			}
+		}
+		if err := validateSignatureRequirement(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planResult resolves the cache keys of all the refs in the result without
+// building them and returns the planned status of their vertexes.
+func planResult(ctx context.Context, j *solver.Job, res *frontend.Result) ([]*client.VertexPlan, error) {
+	var plan []*client.VertexPlan
+	vtxs := map[digest.Digest]*client.VertexPlan{}
+	err := eachResultEdge(ctx, res, func(rp *resultProxy, edge solver.Edge) error {
+		vps, err := j.Plan(ctx, edge)
+		if err != nil {
+			return rp.wrapError(err)
+		}
+		for _, vp := range vps {
+			dgst := vp.Vertex.Digest()
+			if p, ok := vtxs[dgst]; ok {
+				if p.Status == client.PlanCached {
+					p.Status = client.PlanStatus(vp.Status)
+				}
+				continue
+			}
+			p := &client.VertexPlan{
+				Digest: dgst,
+				Name:   vp.Vertex.Name(),
+				Status: client.PlanStatus(vp.Status),
+			}
+			vtxs[dgst] = p
+			plan = append(plan, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// buildRefs builds all the refs in the result. In keep-going mode a failure
+// doesn't cancel the other refs and the errors of all refs are returned.
+func buildRefs(ctx context.Context, res *frontend.Result, keepGoing bool) error {
+	if !keepGoing {
+		eg, ctx := errgroup.WithContext(ctx)
+		res.EachRef(func(ref solver.ResultProxy) error {
+			eg.Go(func() error {
+				_, err := ref.Result(ctx)
+				return err
+			})
+			return nil
+		})
+		return eg.Wait()
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		eg   errgroup.Group
+	)
+	err := res.EachRef(func(ref solver.ResultProxy) error {
+		eg.Go(func() error {
+			if _, err := ref.Result(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+		return nil
+	})
+	eg.Wait()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	// errors that aren't from a vertex, like the build being canceled, are
+	// kept next to the vertex failures so the build never reports success
+	if err := context.Cause(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return serrdefs.NewFailuresError(errs...)
+}
+
+// checkCacheOnly returns a CacheOnlyError if building the refs in
+// the result would need to execute any vertexes that can't be loaded from the
+// cache.
+func checkCacheOnly(ctx context.Context, j *solver.Job, res *frontend.Result) error {
+	var vtxs []*serrdefs.CacheOnlyVertex
+	seen := map[digest.Digest]struct{}{}
+	err := eachResultEdge(ctx, res, func(rp *resultProxy, edge solver.Edge) error {
+		frontier, err := j.CacheOnlyFrontier(ctx, edge)
+		if err != nil {
+			return rp.wrapError(err)
+		}
+		for _, v := range frontier {
+			if _, ok := seen[v.Digest()]; ok {
+				continue
+			}
+			seen[v.Digest()] = struct{}{}
+			vtxs = append(vtxs, &serrdefs.CacheOnlyVertex{
+				Digest: v.Digest().String(),
+				Name:   v.Name(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(vtxs) > 0 {
+		return serrdefs.NewCacheOnlyError(vtxs...)
 	}
 	return nil
 }
 
-func runCacheExporters(ctx context.Context, exporters []RemoteCacheExporter, j *solver.Job, cached *result.Result[solver.CachedResult], inp *result.Result[cache.ImmutableRef]) (map[string]string, error) {
+// eachResultEdge loads the definitions of the refs in the result without
+// building them.
+func eachResultEdge(ctx context.Context, res *frontend.Result, fn func(*resultProxy, solver.Edge) error) error {
+	return res.EachRef(func(ref solver.ResultProxy) error {
+		rp, ok := ref.(*resultProxy)
+		if !ok {
+			return errors.Errorf("invalid result %T", ref)
+		}
+		edge, err := rp.b.loadEdge(ctx, rp.req.Definition, rp.req.CacheImports, rp.req.SourcePolicies)
+		if err != nil {
+			return rp.wrapError(err)
+		}
+		return fn(rp, edge)
+	})
+}
+
+func (s *Solver) runCacheExporters(ctx context.Context, exporters []RemoteCacheExporter, j *solver.Job, cached *result.Result[solver.CachedResult], inp *result.Result[cache.ImmutableRef]) (map[string]string, error) {
 	eg, ctx := errgroup.WithContext(ctx)
 	g := session.NewGroup(j.SessionID)
 	var cacheExporterResponse map[string]string
@@ -780,6 +1095,17 @@ func runCacheExporters(ctx context.Context, exporters []RemoteCacheExporter, j *
 				}); err != nil {
 					return prepareDone(err)
 				}
+				if len(exp.CacheMounts) > 0 {
+					w, err := s.resolveWorker()
+					if err != nil {
+						return prepareDone(err)
+					}
+					release, err := exportCacheMounts(ctx, exp, w.CacheManager(), g)
+					if err != nil {
+						return prepareDone(err)
+					}
+					defer release()
+				}
 				resps[i], err = exp.Finalize(ctx)
 				return prepareDone(err)
 			})
@@ -1138,6 +1464,26 @@ func allWorkers(wc *worker.Controller) func(func(w worker.Worker) error) error {
 	}
 }
 
+// WarnRunningBuilds adds a warning with an internal vertex called name to the
+// progress of all running builds.
+func (s *Solver) WarnRunningBuilds(ctx context.Context, name, msg string) {
+	for _, j := range s.solver.Jobs() {
+		id := identity.NewID()
+		err := inBuilderContext(ctx, j, name, id, func(ctx context.Context, _ session.Group) error {
+			pw, _, _ := progress.NewFromContext(ctx)
+			pw.Write(identity.NewID(), client.VertexWarning{
+				Vertex: digest.FromBytes([]byte(id)),
+				Level:  1,
+				Short:  []byte(msg),
+			})
+			return pw.Close()
+		})
+		if err != nil {
+			bklog.G(ctx).WithError(err).Debugf("failed to add warning to build %s", j.SessionID)
+		}
+	}
+}
+
 func inBuilderContext(ctx context.Context, b solver.Builder, name, id string, f func(ctx context.Context, g session.Group) error) error {
 	if id == "" {
 		id = name
@@ -1213,6 +1559,60 @@ func loadEntitlements(b solver.Builder) (entitlements.Set, error) {
 	return ent, nil
 }
 
+// loadPlanOnly returns true if all the jobs of the builder only plan the build,
+// so the state of the worker must not be changed.
+func loadPlanOnly(b solver.Builder) (bool, error) {
+	var jobs, plans int
+	err := b.EachValue(context.TODO(), keyPlanOnly, func(v any) error {
+		p, ok := v.(bool)
+		if !ok {
+			return errors.Errorf("invalid plan only value %T", v)
+		}
+		jobs++
+		if p {
+			plans++
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return jobs > 0 && plans == jobs, nil
+}
+
+// loadFrontendOpt returns the frontend options of the job of the builder. The
+// builder of a vertex that is shared by several jobs has no single job, so
+// only the options that all of its jobs set to the same value are returned.
+func loadFrontendOpt(b solver.Builder) (map[string]string, error) {
+	var opt map[string]string
+	err := b.EachValue(context.TODO(), keyFrontendOpt, func(v any) error {
+		m, ok := v.(map[string]string)
+		if !ok {
+			return errors.Errorf("invalid frontend opt %T", v)
+		}
+		if opt == nil {
+			opt = maps.Clone(m)
+			if opt == nil {
+				opt = map[string]string{}
+			}
+			return nil
+		}
+		for k, v := range opt {
+			if v2, ok := m[k]; !ok || v2 != v {
+				delete(opt, k)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opt == nil {
+		opt = map[string]string{}
+	}
+	return opt, nil
+}
+
 func loadSourcePolicy(b solver.Builder) (*spb.Policy, error) {
 	var srcPol spb.Policy
 	err := b.EachValue(context.TODO(), keySourcePolicy, func(v any) error {
@@ -0,0 +1,57 @@
+package llbsolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/solver"
+	serrdefs "github.com/moby/buildkit/solver/errdefs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type errResultProxy struct {
+	solver.ResultProxy
+	err error
+}
+
+func (p *errResultProxy) Result(ctx context.Context) (solver.CachedResult, error) {
+	return nil, p.err
+}
+
+func TestBuildRefsKeepGoing(t *testing.T) {
+	res := &frontend.Result{
+		Refs: map[string]solver.ResultProxy{
+			"a": &errResultProxy{err: errors.New("failed a")},
+			"b": &errResultProxy{err: errors.New("failed b")},
+		},
+	}
+	err := buildRefs(context.TODO(), res, true)
+	require.Error(t, err)
+	var fe *serrdefs.FailuresError
+	require.ErrorAs(t, err, &fe)
+	require.Len(t, fe.Items, 2)
+
+	// a canceled build is never reported as success, even if none of the refs
+	// returned an error
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	cancel(errors.New("build canceled"))
+	res = &frontend.Result{
+		Refs: map[string]solver.ResultProxy{
+			"a": &errResultProxy{},
+		},
+	}
+	err = buildRefs(ctx, res, true)
+	require.ErrorContains(t, err, "build canceled")
+
+	res = &frontend.Result{
+		Refs: map[string]solver.ResultProxy{
+			"a": &errResultProxy{err: errors.New("failed a")},
+		},
+	}
+	err = buildRefs(ctx, res, true)
+	require.ErrorAs(t, err, &fe)
+	require.ErrorContains(t, err, "failed a")
+	require.ErrorContains(t, err, "build canceled")
+}
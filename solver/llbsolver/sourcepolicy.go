@@ -2,10 +2,159 @@ package llbsolver
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/imagesig"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/pkg/errors"
 )
 
 type SourcePolicyEvaluator interface {
 	Evaluate(ctx context.Context, op *pb.SourceOp) (bool, error)
 }
+
+// validateSignatureRequirement checks that a verify rule has keys that
+// signatures can be verified with. The rule itself is enforced by the image
+// source, which verifies the resolved manifest before pulling it.
+func validateSignatureRequirement(r *spb.Rule) error {
+	if r.Action != spb.PolicyAction_VERIFY {
+		return nil
+	}
+	if _, err := imagesig.ParseKeys(r.Signature.GetPublicKeys()...); err != nil {
+		return errors.Wrapf(err, "invalid signature requirement for %s in policy", r.Selector.Identifier)
+	}
+	return nil
+}
+
+// daemonSourcePolicy holds the source policies that are loaded from the
+// buildkitd config. They are evaluated for every build, before the policies
+// of the build itself. Every policy must allow a source, so a source that is
+// denied by the daemon can't be allowed by the client.
+type daemonSourcePolicy struct {
+	files []*sourcePolicyFile
+}
+
+// sourcePolicyFile is a source policy that is reloaded when the modification
+// time or size of its file changes. If the changed file can't be loaded, the
+// previously loaded policy stays in effect.
+type sourcePolicyFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	policy  *spb.Policy
+}
+
+func newDaemonSourcePolicy(paths []string) (*daemonSourcePolicy, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	d := &daemonSourcePolicy{}
+	for _, p := range paths {
+		f := &sourcePolicyFile{path: p}
+		if err := f.load(); err != nil {
+			return nil, err
+		}
+		d.files = append(d.files, f)
+	}
+	return d, nil
+}
+
+func (f *sourcePolicyFile) load() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fp, err := os.Open(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open source policy file %s", f.path)
+	}
+	defer fp.Close()
+	fi, err := fp.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat source policy file %s", f.path)
+	}
+	if f.policy != nil && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return nil
+	}
+	dt, err := io.ReadAll(fp)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read source policy file %s", f.path)
+	}
+	var pol spb.Policy
+	if err := json.Unmarshal(dt, &pol); err != nil {
+		return errors.Wrapf(err, "failed to parse source policy file %s", f.path)
+	}
+	if err := validateSourcePolicy(&pol); err != nil {
+		return errors.Wrapf(err, "invalid source policy file %s", f.path)
+	}
+	if f.policy != nil {
+		bklog.L.Infof("reloaded source policy file %s", f.path)
+	}
+	f.modTime = fi.ModTime()
+	f.size = fi.Size()
+	f.policy = &pol
+	return nil
+}
+
+func (f *sourcePolicyFile) current(ctx context.Context) *spb.Policy {
+	if err := f.load(); err != nil {
+		bklog.G(ctx).Errorf("failed to reload source policy, keeping the previous version: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.policy
+}
+
+// engine returns a source policy engine that evaluates the daemon policies
+// followed by pol. Denials by a daemon policy name the file of the policy.
+func (d *daemonSourcePolicy) engine(ctx context.Context, pol []*spb.Policy) *sourcepolicy.Engine {
+	if d == nil {
+		return sourcepolicy.NewEngine(pol)
+	}
+	daemon := make([]*spb.Policy, 0, len(d.files)+len(pol))
+	for _, f := range d.files {
+		daemon = append(daemon, f.current(ctx))
+	}
+	e := sourcepolicy.NewEngine(append(daemon, pol...))
+	for i, f := range d.files {
+		e.SetPolicyName(daemon[i], "daemon source policy "+f.path)
+	}
+	return e
+}
+
+// reportSourcePolicy shows the source policy rules that matched the sources of
+// a build as events of an internal vertex, so that conversions and denials can
+// be followed in the progress and the history of the build.
+func reportSourcePolicy(ctx context.Context, b solver.Builder, traces []*sourcepolicy.Trace) {
+	var steps []sourcepolicy.TraceStep
+	for _, tr := range traces {
+		steps = append(steps, tr.Steps...)
+	}
+	if len(steps) == 0 {
+		return
+	}
+	inBuilderContext(ctx, b, "[internal] evaluating source policy", "", func(ctx context.Context, _ session.Group) error {
+		pw, _, _ := progress.NewFromContext(ctx)
+		defer pw.Close()
+		for _, s := range steps {
+			pw.Write(identity.NewID(), client.VertexEvent{
+				Type:    client.VertexEventSourcePolicy,
+				Message: s.String(),
+			})
+		}
+		return nil
+	})
+}
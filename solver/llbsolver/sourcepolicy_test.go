@@ -0,0 +1,127 @@
+package llbsolver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonSourcePolicy(t *testing.T) {
+	ctx := context.TODO()
+	fn := filepath.Join(t.TempDir(), "policy.json")
+	deny := func(id string) string {
+		return `{"rules":[{"action":"DENY","selector":{"identifier":"` + id + `"}}]}`
+	}
+	require.NoError(t, os.WriteFile(fn, []byte(deny("docker-image://docker.io/library/busybox:latest")), 0600))
+
+	_, err := newDaemonSourcePolicy([]string{filepath.Join(t.TempDir(), "missing.json")})
+	require.ErrorContains(t, err, "failed to open source policy file")
+
+	d, err := newDaemonSourcePolicy([]string{fn})
+	require.NoError(t, err)
+
+	allowAll := &spb.Policy{Rules: []*spb.Rule{{
+		Action:   spb.PolicyAction_ALLOW,
+		Selector: &spb.Selector{Identifier: "*", MatchType: spb.MatchType_WILDCARD},
+	}}}
+	evaluate := func(id string) error {
+		_, err := d.engine(ctx, []*spb.Policy{allowAll}).Evaluate(ctx, &pb.SourceOp{Identifier: id})
+		return err
+	}
+
+	err = evaluate("docker-image://docker.io/library/busybox:latest")
+	require.ErrorIs(t, err, sourcepolicy.ErrSourceDenied)
+	require.ErrorContains(t, err, "denied by rules[0] (DENY docker-image://docker.io/library/busybox:latest) of daemon source policy "+fn)
+	require.NoError(t, evaluate("docker-image://docker.io/library/alpine:latest"))
+
+	// changed file is reloaded
+	require.NoError(t, os.WriteFile(fn, []byte(deny("docker-image://docker.io/library/alpine:latest")), 0600))
+	require.NoError(t, evaluate("docker-image://docker.io/library/busybox:latest"))
+	require.ErrorIs(t, evaluate("docker-image://docker.io/library/alpine:latest"), sourcepolicy.ErrSourceDenied)
+
+	// invalid file keeps the previous policy
+	require.NoError(t, os.WriteFile(fn, []byte(`{"rules":[null]}`), 0600))
+	require.ErrorIs(t, evaluate("docker-image://docker.io/library/alpine:latest"), sourcepolicy.ErrSourceDenied)
+}
+
+func TestValidateSourcePolicyCondition(t *testing.T) {
+	pol := func(cond string) *spb.Policy {
+		return &spb.Policy{Rules: []*spb.Rule{{
+			Action:   spb.PolicyAction_DENY,
+			Selector: &spb.Selector{Identifier: "*", Condition: cond},
+		}}}
+	}
+	require.NoError(t, validateSourcePolicy(pol(`identifier.startsWith("https://")`)))
+
+	err := validateSourcePolicy(pol("true" + strings.Repeat(" && true", maxSourcePolicyCondition/8)))
+	require.ErrorContains(t, err, "longer than 4096 bytes")
+
+	err = validateSourcePolicy(pol(strings.Repeat("(", 64) + "true" + strings.Repeat(")", 64)))
+	require.ErrorContains(t, err, "expression nested deeper than")
+}
+
+// valuesBuilder is a builder of a vertex that is shared by jobs with the
+// values.
+type valuesBuilder struct {
+	solver.Builder
+	values []any
+}
+
+func (b *valuesBuilder) EachValue(ctx context.Context, key string, fn func(any) error) error {
+	for _, v := range b.values {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLoadFrontendOpt(t *testing.T) {
+	opt, err := loadFrontendOpt(&valuesBuilder{})
+	require.NoError(t, err)
+	require.Empty(t, opt)
+
+	opt, err = loadFrontendOpt(&valuesBuilder{values: []any{
+		map[string]string{"build-arg:FOO": "foo", "target": "release"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"build-arg:FOO": "foo", "target": "release"}, opt)
+
+	// options that the jobs disagree on are left out in any order
+	a := map[string]string{"build-arg:FOO": "foo", "target": "release", "filename": "Dockerfile"}
+	b := map[string]string{"build-arg:FOO": "foo", "target": "debug"}
+	for _, values := range [][]any{{a, b}, {b, a}} {
+		opt, err = loadFrontendOpt(&valuesBuilder{values: values})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"build-arg:FOO": "foo"}, opt)
+	}
+
+	_, err = loadFrontendOpt(&valuesBuilder{values: []any{"foo"}})
+	require.ErrorContains(t, err, "invalid frontend opt")
+}
+
+func TestLoadPlanOnly(t *testing.T) {
+	for _, tc := range []struct {
+		values   []any
+		expected bool
+	}{
+		{nil, false},
+		{[]any{true}, true},
+		{[]any{false}, false},
+		{[]any{true, true}, true},
+		// a vertex shared with a job that executes may change the state
+		{[]any{true, false}, false},
+	} {
+		planOnly, err := loadPlanOnly(&valuesBuilder{values: tc.values})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, planOnly, "%v", tc.values)
+	}
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"context"
+)
+
+type opSlotKey struct{}
+
+// opSlot is the resources acquired with Op.Acquire for an executing op.
+type opSlot struct {
+	acquire func(context.Context) (ReleaseFunc, error)
+	release ReleaseFunc
+}
+
+func withOpSlot(ctx context.Context, s *opSlot) context.Context {
+	return context.WithValue(ctx, opSlotKey{}, s)
+}
+
+// WithoutOpResources releases the resources the executing op acquired with
+// Op.Acquire while fn runs, so that other ops can use them, and acquires them
+// again before returning. It is meant for an op that waits without doing any
+// work, eg. before retrying a failed process. If ctx is not the context of an
+// executing op fn is called directly.
+func WithoutOpResources(ctx context.Context, fn func(context.Context) error) error {
+	s, ok := ctx.Value(opSlotKey{}).(*opSlot)
+	if !ok {
+		return fn(ctx)
+	}
+	s.release()
+	s.release = func() {}
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	s.release = release
+	return nil
+}
@@ -0,0 +1,48 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithoutOpResources(t *testing.T) {
+	var held, acquired int
+	acquire := func(context.Context) (ReleaseFunc, error) {
+		held++
+		acquired++
+		return func() { held-- }, nil
+	}
+	release, err := acquire(context.TODO())
+	require.NoError(t, err)
+	slot := &opSlot{acquire: acquire, release: release}
+	ctx := withOpSlot(context.TODO(), slot)
+
+	err = WithoutOpResources(ctx, func(context.Context) error {
+		require.Equal(t, 0, held)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, held)
+	require.Equal(t, 2, acquired)
+
+	err = WithoutOpResources(ctx, func(context.Context) error {
+		return errors.New("canceled")
+	})
+	require.ErrorContains(t, err, "canceled")
+	require.Equal(t, 0, held)
+	require.Equal(t, 2, acquired)
+	slot.release()
+	require.Equal(t, 0, held)
+
+	// outside of an executing op fn is only called
+	var called bool
+	err = WithoutOpResources(context.TODO(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, called)
+}
@@ -7,6 +7,11 @@ const AttrAuthTokenSecret = "git.authtokensecret"
 const AttrKnownSSHHosts = "git.knownsshhosts"
 const AttrMountSSHSock = "git.mountsshsock"
 const AttrGitChecksum = "git.checksum"
+const AttrGitFilter = "git.filter"
+const AttrGitSparsePaths = "git.sparsepaths"
+const AttrGitSignatureKeys = "git.sig.pubkeys"
+const AttrGitSignatureKeySecret = "git.sig.pubkeysecret"
+const AttrGitLFS = "git.lfs"
 
 const AttrLocalSessionID = "local.session"
 const AttrLocalUniqueID = "local.unique"
@@ -26,6 +31,19 @@ const AttrHTTPUID = "http.uid"
 const AttrHTTPGID = "http.gid"
 const AttrHTTPAuthHeaderSecret = "http.authheadersecret"
 const AttrHTTPHeaderPrefix = "http.header."
+const AttrHTTPMirrors = "http.mirrors"
+const AttrHTTPSignatureURL = "http.sig.url"
+const AttrHTTPSignatureKeys = "http.sig.pubkeys"
+const AttrHTTPSignatureKeySecret = "http.sig.pubkeysecret"
+
+const AttrS3Region = "s3.region"
+const AttrS3EndpointURL = "s3.endpoint"
+const AttrS3UsePathStyle = "s3.usepathstyle"
+const AttrS3VersionID = "s3.versionid"
+const AttrS3Filename = "s3.filename"
+
+const AttrOCIArtifactType = "ociartifact.artifacttype"
+const AttrOCIArtifactFiles = "ociartifact.files"
 
 const AttrImageResolveMode = "image.resolvemode"
 const AttrImageResolveModeDefault = "default"
@@ -34,6 +52,11 @@ const AttrImageResolveModePreferLocal = "local"
 const AttrImageRecordType = "image.recordtype"
 const AttrImageLayerLimit = "image.layerlimit"
 
+// AttrImageSignatureKeys is a JSON encoded list of signature requirements.
+// Each requirement is a list of PEM encoded public keys or root certificates
+// and is met if the image is signed with any one of them.
+const AttrImageSignatureKeys = "image.sig.pubkeys"
+
 const AttrOCILayoutSessionID = "oci.session"
 const AttrOCILayoutStoreID = "oci.store"
 const AttrOCILayoutLayerLimit = "oci.layerlimit"
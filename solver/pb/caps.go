@@ -12,6 +12,7 @@ const (
 	CapSourceImage            apicaps.CapID = "source.image"
 	CapSourceImageResolveMode apicaps.CapID = "source.image.resolvemode"
 	CapSourceImageLayerLimit  apicaps.CapID = "source.image.layerlimit"
+	CapSourceImageSignature   apicaps.CapID = "source.image.signature"
 
 	CapSourceLocal                apicaps.CapID = "source.local"
 	CapSourceLocalUnique          apicaps.CapID = "source.local.unique"
@@ -31,17 +32,29 @@ const (
 	CapSourceGitMountSSHSock  apicaps.CapID = "source.git.mountsshsock"
 	CapSourceGitSubdir        apicaps.CapID = "source.git.subdir"
 	CapSourceGitChecksum      apicaps.CapID = "source.git.checksum"
+	CapSourceGitPartialClone  apicaps.CapID = "source.git.partialclone"
+	CapSourceGitSparsePaths   apicaps.CapID = "source.git.sparsepaths"
+	CapSourceGitSignature     apicaps.CapID = "source.git.signature"
+	CapSourceGitLFS           apicaps.CapID = "source.git.lfs"
 
 	CapSourceHTTP         apicaps.CapID = "source.http"
 	CapSourceHTTPAuth     apicaps.CapID = "source.http.auth"
 	CapSourceHTTPChecksum apicaps.CapID = "source.http.checksum"
 	CapSourceHTTPPerm     apicaps.CapID = "source.http.perm"
 	// NOTE the historical typo
-	CapSourceHTTPUIDGID apicaps.CapID = "soruce.http.uidgid"
-	CapSourceHTTPHeader apicaps.CapID = "source.http.header"
+	CapSourceHTTPUIDGID  apicaps.CapID = "soruce.http.uidgid"
+	CapSourceHTTPHeader  apicaps.CapID = "source.http.header"
+	CapSourceHTTPMirrors apicaps.CapID = "source.http.mirrors"
+	// CapSourceHTTPChecksumAlgorithms is set for checksums other than sha256
+	CapSourceHTTPChecksumAlgorithms apicaps.CapID = "source.http.checksum.algorithms"
+	CapSourceHTTPSignature          apicaps.CapID = "source.http.signature"
+
+	CapSourceS3 apicaps.CapID = "source.s3"
 
 	CapSourceOCILayout apicaps.CapID = "source.ocilayout"
 
+	CapSourceOCIArtifact apicaps.CapID = "source.ociartifact"
+
 	CapBuildOpLLBFileName apicaps.CapID = "source.buildop.llbfilename"
 
 	CapExecMetaBase                      apicaps.CapID = "exec.meta.base"
@@ -58,6 +71,7 @@ const (
 	CapExecMountBindReadWriteNoOutput    apicaps.CapID = "exec.mount.bind.readwrite-nooutput"
 	CapExecMountCache                    apicaps.CapID = "exec.mount.cache"
 	CapExecMountCacheSharing             apicaps.CapID = "exec.mount.cache.sharing"
+	CapExecMountCacheMaxSize             apicaps.CapID = "exec.mount.cache.maxsize"
 	CapExecMountSelector                 apicaps.CapID = "exec.mount.selector"
 	CapExecMountTmpfs                    apicaps.CapID = "exec.mount.tmpfs"
 	CapExecMountTmpfsSize                apicaps.CapID = "exec.mount.tmpfs.size"
@@ -67,6 +81,8 @@ const (
 	CapExecCgroupsMounted                apicaps.CapID = "exec.cgroup"
 	CapExecSecretEnv                     apicaps.CapID = "exec.secretenv"
 	CapExecValidExitCode                 apicaps.CapID = "exec.validexitcode"
+	CapExecMetaTimeout                   apicaps.CapID = "exec.meta.timeout"
+	CapExecMetaRetry                     apicaps.CapID = "exec.meta.retry"
 
 	CapFileBase                               apicaps.CapID = "file.base"
 	CapFileRmWildcard                         apicaps.CapID = "file.rm.wildcard"
@@ -100,6 +116,9 @@ const (
 	CapSessionExporter   apicaps.CapID = "exporter.session"
 
 	CapSourcePolicy apicaps.CapID = "source.policy"
+	// CapSourcePolicyCondition is the capability to match source policy
+	// selectors by a condition expression
+	CapSourcePolicyCondition apicaps.CapID = "source.policy.condition"
 
 	// GC/Prune controls allow MinFreeSpace and MaxUsedSpace to be set
 	CapGCFreeSpaceFilter apicaps.CapID = "gc.freespacefilter"
@@ -127,6 +146,12 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceImageSignature,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapSourceLocal,
 		Enabled: true,
@@ -229,6 +254,30 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitPartialClone,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitSparsePaths,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitSignature,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceGitLFS,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapSourceHTTP,
 		Enabled: true,
@@ -265,12 +314,42 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceHTTPMirrors,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceHTTPChecksumAlgorithms,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceHTTPSignature,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceS3,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapSourceOCILayout,
 		Enabled: true,
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourceOCIArtifact,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapBuildOpLLBFileName,
 		Enabled: true,
@@ -355,6 +434,12 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapExecMountCacheMaxSize,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapExecMountSelector,
 		Enabled: true,
@@ -409,6 +494,18 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapExecMetaTimeout,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
+	Caps.Init(apicaps.Cap{
+		ID:      CapExecMetaRetry,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapFileBase,
 		Enabled: true,
@@ -546,6 +643,12 @@ func init() {
 		Status:  apicaps.CapStatusExperimental,
 	})
 
+	Caps.Init(apicaps.Cap{
+		ID:      CapSourcePolicyCondition,
+		Enabled: true,
+		Status:  apicaps.CapStatusExperimental,
+	})
+
 	Caps.Init(apicaps.Cap{
 		ID:      CapGCFreeSpaceFilter,
 		Enabled: true,
@@ -684,8 +684,12 @@ type Meta struct {
 	CgroupParent              string                 `protobuf:"bytes,10,opt,name=cgroupParent,proto3" json:"cgroupParent,omitempty"`
 	RemoveMountStubsRecursive bool                   `protobuf:"varint,11,opt,name=removeMountStubsRecursive,proto3" json:"removeMountStubsRecursive,omitempty"`
 	ValidExitCodes            []int32                `protobuf:"varint,12,rep,packed,name=validExitCodes,proto3" json:"validExitCodes,omitempty"`
-	unknownFields             protoimpl.UnknownFields
-	sizeCache                 protoimpl.SizeCache
+	// timeout is the maximum duration of a single run of the process in
+	// nanoseconds. The process is killed when it exceeds the timeout.
+	Timeout       int64  `protobuf:"varint,13,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Retry         *Retry `protobuf:"bytes,14,opt,name=retry,proto3" json:"retry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Meta) Reset() {
@@ -795,6 +799,77 @@ func (x *Meta) GetValidExitCodes() []int32 {
 	return nil
 }
 
+func (x *Meta) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Meta) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+// Retry is the policy for retrying a process that exited with a non-zero
+// exit code or exceeded its timeout.
+type Retry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// attempts is the maximum number of times the process is retried.
+	Attempts int64 `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// backoff is the delay before the first retry in nanoseconds. The delay
+	// is doubled for every following retry. Defaults to 1 second.
+	Backoff       int64 `protobuf:"varint,2,opt,name=backoff,proto3" json:"backoff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Retry) Reset() {
+	*x = Retry{}
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Retry) ProtoMessage() {}
+
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Retry) GetAttempts() int64 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Retry) GetBackoff() int64 {
+	if x != nil {
+		return x.Backoff
+	}
+	return 0
+}
+
 type HostIP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Host          string                 `protobuf:"bytes,1,opt,name=Host,proto3" json:"Host,omitempty"`
@@ -805,7 +880,7 @@ type HostIP struct {
 
 func (x *HostIP) Reset() {
 	*x = HostIP{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -817,7 +892,7 @@ func (x *HostIP) String() string {
 func (*HostIP) ProtoMessage() {}
 
 func (x *HostIP) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[5]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -830,7 +905,7 @@ func (x *HostIP) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HostIP.ProtoReflect.Descriptor instead.
 func (*HostIP) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{5}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *HostIP) GetHost() string {
@@ -858,7 +933,7 @@ type Ulimit struct {
 
 func (x *Ulimit) Reset() {
 	*x = Ulimit{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -870,7 +945,7 @@ func (x *Ulimit) String() string {
 func (*Ulimit) ProtoMessage() {}
 
 func (x *Ulimit) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[6]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -883,7 +958,7 @@ func (x *Ulimit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ulimit.ProtoReflect.Descriptor instead.
 func (*Ulimit) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{6}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Ulimit) GetName() string {
@@ -919,7 +994,7 @@ type SecretEnv struct {
 
 func (x *SecretEnv) Reset() {
 	*x = SecretEnv{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -931,7 +1006,7 @@ func (x *SecretEnv) String() string {
 func (*SecretEnv) ProtoMessage() {}
 
 func (x *SecretEnv) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[7]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -944,7 +1019,7 @@ func (x *SecretEnv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecretEnv.ProtoReflect.Descriptor instead.
 func (*SecretEnv) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{7}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SecretEnv) GetID() string {
@@ -982,7 +1057,7 @@ type CDIDevice struct {
 
 func (x *CDIDevice) Reset() {
 	*x = CDIDevice{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -994,7 +1069,7 @@ func (x *CDIDevice) String() string {
 func (*CDIDevice) ProtoMessage() {}
 
 func (x *CDIDevice) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[8]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1007,7 +1082,7 @@ func (x *CDIDevice) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CDIDevice.ProtoReflect.Descriptor instead.
 func (*CDIDevice) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{8}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *CDIDevice) GetName() string {
@@ -1045,7 +1120,7 @@ type Mount struct {
 
 func (x *Mount) Reset() {
 	*x = Mount{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1057,7 +1132,7 @@ func (x *Mount) String() string {
 func (*Mount) ProtoMessage() {}
 
 func (x *Mount) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[9]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1070,7 +1145,7 @@ func (x *Mount) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Mount.ProtoReflect.Descriptor instead.
 func (*Mount) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{9}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Mount) GetInput() int64 {
@@ -1168,7 +1243,7 @@ type TmpfsOpt struct {
 
 func (x *TmpfsOpt) Reset() {
 	*x = TmpfsOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1180,7 +1255,7 @@ func (x *TmpfsOpt) String() string {
 func (*TmpfsOpt) ProtoMessage() {}
 
 func (x *TmpfsOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[10]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1193,7 +1268,7 @@ func (x *TmpfsOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TmpfsOpt.ProtoReflect.Descriptor instead.
 func (*TmpfsOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{10}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *TmpfsOpt) GetSize() int64 {
@@ -1209,14 +1284,17 @@ type CacheOpt struct {
 	// ID is an optional namespace for the mount
 	ID string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	// Sharing is the sharing mode for the mount
-	Sharing       CacheSharingOpt `protobuf:"varint,2,opt,name=sharing,proto3,enum=pb.CacheSharingOpt" json:"sharing,omitempty"`
+	Sharing CacheSharingOpt `protobuf:"varint,2,opt,name=sharing,proto3,enum=pb.CacheSharingOpt" json:"sharing,omitempty"`
+	// MaxSize is the maximum size of the mount contents in bytes. Contents
+	// over the limit are discarded before the mount is used again.
+	MaxSize       int64 `protobuf:"varint,3,opt,name=maxSize,proto3" json:"maxSize,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CacheOpt) Reset() {
 	*x = CacheOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1228,7 +1306,7 @@ func (x *CacheOpt) String() string {
 func (*CacheOpt) ProtoMessage() {}
 
 func (x *CacheOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[11]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1241,7 +1319,7 @@ func (x *CacheOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CacheOpt.ProtoReflect.Descriptor instead.
 func (*CacheOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{11}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *CacheOpt) GetID() string {
@@ -1258,6 +1336,13 @@ func (x *CacheOpt) GetSharing() CacheSharingOpt {
 	return CacheSharingOpt_SHARED
 }
 
+func (x *CacheOpt) GetMaxSize() int64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
 // SecretOpt defines options describing secret mounts
 type SecretOpt struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1278,7 +1363,7 @@ type SecretOpt struct {
 
 func (x *SecretOpt) Reset() {
 	*x = SecretOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1290,7 +1375,7 @@ func (x *SecretOpt) String() string {
 func (*SecretOpt) ProtoMessage() {}
 
 func (x *SecretOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[12]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1303,7 +1388,7 @@ func (x *SecretOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecretOpt.ProtoReflect.Descriptor instead.
 func (*SecretOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{12}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SecretOpt) GetID() string {
@@ -1361,7 +1446,7 @@ type SSHOpt struct {
 
 func (x *SSHOpt) Reset() {
 	*x = SSHOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1373,7 +1458,7 @@ func (x *SSHOpt) String() string {
 func (*SSHOpt) ProtoMessage() {}
 
 func (x *SSHOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[13]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1386,7 +1471,7 @@ func (x *SSHOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SSHOpt.ProtoReflect.Descriptor instead.
 func (*SSHOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{13}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *SSHOpt) GetID() string {
@@ -1438,7 +1523,7 @@ type SourceOp struct {
 
 func (x *SourceOp) Reset() {
 	*x = SourceOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1450,7 +1535,7 @@ func (x *SourceOp) String() string {
 func (*SourceOp) ProtoMessage() {}
 
 func (x *SourceOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[14]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1463,7 +1548,7 @@ func (x *SourceOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SourceOp.ProtoReflect.Descriptor instead.
 func (*SourceOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{14}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *SourceOp) GetIdentifier() string {
@@ -1494,7 +1579,7 @@ type BuildOp struct {
 
 func (x *BuildOp) Reset() {
 	*x = BuildOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1506,7 +1591,7 @@ func (x *BuildOp) String() string {
 func (*BuildOp) ProtoMessage() {}
 
 func (x *BuildOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[15]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1519,7 +1604,7 @@ func (x *BuildOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildOp.ProtoReflect.Descriptor instead.
 func (*BuildOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{15}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BuildOp) GetBuilder() int64 {
@@ -1560,7 +1645,7 @@ type BuildInput struct {
 
 func (x *BuildInput) Reset() {
 	*x = BuildInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1572,7 +1657,7 @@ func (x *BuildInput) String() string {
 func (*BuildInput) ProtoMessage() {}
 
 func (x *BuildInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[16]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1585,7 +1670,7 @@ func (x *BuildInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildInput.ProtoReflect.Descriptor instead.
 func (*BuildInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{16}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *BuildInput) GetInput() int64 {
@@ -1613,7 +1698,7 @@ type OpMetadata struct {
 
 func (x *OpMetadata) Reset() {
 	*x = OpMetadata{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1625,7 +1710,7 @@ func (x *OpMetadata) String() string {
 func (*OpMetadata) ProtoMessage() {}
 
 func (x *OpMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[17]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1638,7 +1723,7 @@ func (x *OpMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OpMetadata.ProtoReflect.Descriptor instead.
 func (*OpMetadata) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{17}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *OpMetadata) GetIgnoreCache() bool {
@@ -1687,7 +1772,7 @@ type Source struct {
 
 func (x *Source) Reset() {
 	*x = Source{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1699,7 +1784,7 @@ func (x *Source) String() string {
 func (*Source) ProtoMessage() {}
 
 func (x *Source) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[18]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1712,7 +1797,7 @@ func (x *Source) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Source.ProtoReflect.Descriptor instead.
 func (*Source) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{18}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Source) GetLocations() map[string]*Locations {
@@ -1739,7 +1824,7 @@ type Locations struct {
 
 func (x *Locations) Reset() {
 	*x = Locations{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1751,7 +1836,7 @@ func (x *Locations) String() string {
 func (*Locations) ProtoMessage() {}
 
 func (x *Locations) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[19]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1764,7 +1849,7 @@ func (x *Locations) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Locations.ProtoReflect.Descriptor instead.
 func (*Locations) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{19}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Locations) GetLocations() []*Location {
@@ -1787,7 +1872,7 @@ type SourceInfo struct {
 
 func (x *SourceInfo) Reset() {
 	*x = SourceInfo{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1799,7 +1884,7 @@ func (x *SourceInfo) String() string {
 func (*SourceInfo) ProtoMessage() {}
 
 func (x *SourceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[20]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1812,7 +1897,7 @@ func (x *SourceInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SourceInfo.ProtoReflect.Descriptor instead.
 func (*SourceInfo) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{20}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *SourceInfo) GetFilename() string {
@@ -1854,7 +1939,7 @@ type Location struct {
 
 func (x *Location) Reset() {
 	*x = Location{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1866,7 +1951,7 @@ func (x *Location) String() string {
 func (*Location) ProtoMessage() {}
 
 func (x *Location) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[21]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1879,7 +1964,7 @@ func (x *Location) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Location.ProtoReflect.Descriptor instead.
 func (*Location) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{21}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *Location) GetSourceIndex() int32 {
@@ -1907,7 +1992,7 @@ type Range struct {
 
 func (x *Range) Reset() {
 	*x = Range{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1919,7 +2004,7 @@ func (x *Range) String() string {
 func (*Range) ProtoMessage() {}
 
 func (x *Range) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[22]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1932,7 +2017,7 @@ func (x *Range) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Range.ProtoReflect.Descriptor instead.
 func (*Range) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{22}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Range) GetStart() *Position {
@@ -1960,7 +2045,7 @@ type Position struct {
 
 func (x *Position) Reset() {
 	*x = Position{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1972,7 +2057,7 @@ func (x *Position) String() string {
 func (*Position) ProtoMessage() {}
 
 func (x *Position) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[23]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1985,7 +2070,7 @@ func (x *Position) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Position.ProtoReflect.Descriptor instead.
 func (*Position) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{23}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *Position) GetLine() int32 {
@@ -2011,7 +2096,7 @@ type ExportCache struct {
 
 func (x *ExportCache) Reset() {
 	*x = ExportCache{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2023,7 +2108,7 @@ func (x *ExportCache) String() string {
 func (*ExportCache) ProtoMessage() {}
 
 func (x *ExportCache) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[24]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2036,7 +2121,7 @@ func (x *ExportCache) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExportCache.ProtoReflect.Descriptor instead.
 func (*ExportCache) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{24}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *ExportCache) GetValue() bool {
@@ -2057,7 +2142,7 @@ type ProgressGroup struct {
 
 func (x *ProgressGroup) Reset() {
 	*x = ProgressGroup{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2069,7 +2154,7 @@ func (x *ProgressGroup) String() string {
 func (*ProgressGroup) ProtoMessage() {}
 
 func (x *ProgressGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[25]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2082,7 +2167,7 @@ func (x *ProgressGroup) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProgressGroup.ProtoReflect.Descriptor instead.
 func (*ProgressGroup) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{25}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ProgressGroup) GetId() string {
@@ -2119,7 +2204,7 @@ type ProxyEnv struct {
 
 func (x *ProxyEnv) Reset() {
 	*x = ProxyEnv{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2131,7 +2216,7 @@ func (x *ProxyEnv) String() string {
 func (*ProxyEnv) ProtoMessage() {}
 
 func (x *ProxyEnv) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[26]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2144,7 +2229,7 @@ func (x *ProxyEnv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProxyEnv.ProtoReflect.Descriptor instead.
 func (*ProxyEnv) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{26}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ProxyEnv) GetHttpProxy() string {
@@ -2192,7 +2277,7 @@ type WorkerConstraints struct {
 
 func (x *WorkerConstraints) Reset() {
 	*x = WorkerConstraints{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2204,7 +2289,7 @@ func (x *WorkerConstraints) String() string {
 func (*WorkerConstraints) ProtoMessage() {}
 
 func (x *WorkerConstraints) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[27]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2217,7 +2302,7 @@ func (x *WorkerConstraints) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WorkerConstraints.ProtoReflect.Descriptor instead.
 func (*WorkerConstraints) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{27}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *WorkerConstraints) GetFilter() []string {
@@ -2243,7 +2328,7 @@ type Definition struct {
 
 func (x *Definition) Reset() {
 	*x = Definition{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2255,7 +2340,7 @@ func (x *Definition) String() string {
 func (*Definition) ProtoMessage() {}
 
 func (x *Definition) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[28]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2268,7 +2353,7 @@ func (x *Definition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Definition.ProtoReflect.Descriptor instead.
 func (*Definition) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{28}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *Definition) GetDef() [][]byte {
@@ -2301,7 +2386,7 @@ type FileOp struct {
 
 func (x *FileOp) Reset() {
 	*x = FileOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2313,7 +2398,7 @@ func (x *FileOp) String() string {
 func (*FileOp) ProtoMessage() {}
 
 func (x *FileOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[29]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2326,7 +2411,7 @@ func (x *FileOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileOp.ProtoReflect.Descriptor instead.
 func (*FileOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{29}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *FileOp) GetActions() []*FileAction {
@@ -2356,7 +2441,7 @@ type FileAction struct {
 
 func (x *FileAction) Reset() {
 	*x = FileAction{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2368,7 +2453,7 @@ func (x *FileAction) String() string {
 func (*FileAction) ProtoMessage() {}
 
 func (x *FileAction) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2381,7 +2466,7 @@ func (x *FileAction) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileAction.ProtoReflect.Descriptor instead.
 func (*FileAction) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{30}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *FileAction) GetInput() int64 {
@@ -2534,7 +2619,7 @@ type FileActionCopy struct {
 
 func (x *FileActionCopy) Reset() {
 	*x = FileActionCopy{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2546,7 +2631,7 @@ func (x *FileActionCopy) String() string {
 func (*FileActionCopy) ProtoMessage() {}
 
 func (x *FileActionCopy) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2559,7 +2644,7 @@ func (x *FileActionCopy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionCopy.ProtoReflect.Descriptor instead.
 func (*FileActionCopy) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{31}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *FileActionCopy) GetSrc() string {
@@ -2685,7 +2770,7 @@ type FileActionMkFile struct {
 
 func (x *FileActionMkFile) Reset() {
 	*x = FileActionMkFile{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2697,7 +2782,7 @@ func (x *FileActionMkFile) String() string {
 func (*FileActionMkFile) ProtoMessage() {}
 
 func (x *FileActionMkFile) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[32]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2710,7 +2795,7 @@ func (x *FileActionMkFile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionMkFile.ProtoReflect.Descriptor instead.
 func (*FileActionMkFile) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{32}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *FileActionMkFile) GetPath() string {
@@ -2764,7 +2849,7 @@ type FileActionSymlink struct {
 
 func (x *FileActionSymlink) Reset() {
 	*x = FileActionSymlink{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2776,7 +2861,7 @@ func (x *FileActionSymlink) String() string {
 func (*FileActionSymlink) ProtoMessage() {}
 
 func (x *FileActionSymlink) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[33]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2789,7 +2874,7 @@ func (x *FileActionSymlink) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionSymlink.ProtoReflect.Descriptor instead.
 func (*FileActionSymlink) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{33}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *FileActionSymlink) GetOldpath() string {
@@ -2838,7 +2923,7 @@ type FileActionMkDir struct {
 
 func (x *FileActionMkDir) Reset() {
 	*x = FileActionMkDir{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2850,7 +2935,7 @@ func (x *FileActionMkDir) String() string {
 func (*FileActionMkDir) ProtoMessage() {}
 
 func (x *FileActionMkDir) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[34]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2863,7 +2948,7 @@ func (x *FileActionMkDir) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionMkDir.ProtoReflect.Descriptor instead.
 func (*FileActionMkDir) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{34}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *FileActionMkDir) GetPath() string {
@@ -2915,7 +3000,7 @@ type FileActionRm struct {
 
 func (x *FileActionRm) Reset() {
 	*x = FileActionRm{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2927,7 +3012,7 @@ func (x *FileActionRm) String() string {
 func (*FileActionRm) ProtoMessage() {}
 
 func (x *FileActionRm) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[35]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2940,7 +3025,7 @@ func (x *FileActionRm) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileActionRm.ProtoReflect.Descriptor instead.
 func (*FileActionRm) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{35}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *FileActionRm) GetPath() string {
@@ -2974,7 +3059,7 @@ type ChownOpt struct {
 
 func (x *ChownOpt) Reset() {
 	*x = ChownOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2986,7 +3071,7 @@ func (x *ChownOpt) String() string {
 func (*ChownOpt) ProtoMessage() {}
 
 func (x *ChownOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[36]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2999,7 +3084,7 @@ func (x *ChownOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChownOpt.ProtoReflect.Descriptor instead.
 func (*ChownOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{36}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *ChownOpt) GetUser() *UserOpt {
@@ -3031,7 +3116,7 @@ type UserOpt struct {
 
 func (x *UserOpt) Reset() {
 	*x = UserOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3043,7 +3128,7 @@ func (x *UserOpt) String() string {
 func (*UserOpt) ProtoMessage() {}
 
 func (x *UserOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3056,7 +3141,7 @@ func (x *UserOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserOpt.ProtoReflect.Descriptor instead.
 func (*UserOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{37}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *UserOpt) GetUser() isUserOpt_User {
@@ -3110,7 +3195,7 @@ type NamedUserOpt struct {
 
 func (x *NamedUserOpt) Reset() {
 	*x = NamedUserOpt{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3122,7 +3207,7 @@ func (x *NamedUserOpt) String() string {
 func (*NamedUserOpt) ProtoMessage() {}
 
 func (x *NamedUserOpt) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3135,7 +3220,7 @@ func (x *NamedUserOpt) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NamedUserOpt.ProtoReflect.Descriptor instead.
 func (*NamedUserOpt) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{38}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *NamedUserOpt) GetName() string {
@@ -3161,7 +3246,7 @@ type MergeInput struct {
 
 func (x *MergeInput) Reset() {
 	*x = MergeInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3173,7 +3258,7 @@ func (x *MergeInput) String() string {
 func (*MergeInput) ProtoMessage() {}
 
 func (x *MergeInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[39]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3186,7 +3271,7 @@ func (x *MergeInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MergeInput.ProtoReflect.Descriptor instead.
 func (*MergeInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{39}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *MergeInput) GetInput() int64 {
@@ -3205,7 +3290,7 @@ type MergeOp struct {
 
 func (x *MergeOp) Reset() {
 	*x = MergeOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3217,7 +3302,7 @@ func (x *MergeOp) String() string {
 func (*MergeOp) ProtoMessage() {}
 
 func (x *MergeOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[40]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3230,7 +3315,7 @@ func (x *MergeOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MergeOp.ProtoReflect.Descriptor instead.
 func (*MergeOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{40}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *MergeOp) GetInputs() []*MergeInput {
@@ -3249,7 +3334,7 @@ type LowerDiffInput struct {
 
 func (x *LowerDiffInput) Reset() {
 	*x = LowerDiffInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3261,7 +3346,7 @@ func (x *LowerDiffInput) String() string {
 func (*LowerDiffInput) ProtoMessage() {}
 
 func (x *LowerDiffInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[41]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3274,7 +3359,7 @@ func (x *LowerDiffInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LowerDiffInput.ProtoReflect.Descriptor instead.
 func (*LowerDiffInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{41}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *LowerDiffInput) GetInput() int64 {
@@ -3293,7 +3378,7 @@ type UpperDiffInput struct {
 
 func (x *UpperDiffInput) Reset() {
 	*x = UpperDiffInput{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3305,7 +3390,7 @@ func (x *UpperDiffInput) String() string {
 func (*UpperDiffInput) ProtoMessage() {}
 
 func (x *UpperDiffInput) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[42]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3318,7 +3403,7 @@ func (x *UpperDiffInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpperDiffInput.ProtoReflect.Descriptor instead.
 func (*UpperDiffInput) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{42}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *UpperDiffInput) GetInput() int64 {
@@ -3338,7 +3423,7 @@ type DiffOp struct {
 
 func (x *DiffOp) Reset() {
 	*x = DiffOp{}
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3350,7 +3435,7 @@ func (x *DiffOp) String() string {
 func (*DiffOp) ProtoMessage() {}
 
 func (x *DiffOp) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[43]
+	mi := &file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3363,7 +3448,7 @@ func (x *DiffOp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DiffOp.ProtoReflect.Descriptor instead.
 func (*DiffOp) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{43}
+	return file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *DiffOp) GetLower() *LowerDiffInput {
@@ -3419,7 +3504,7 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\tsecretenv\x18\x05 \x03(\v2\r.pb.SecretEnvR\tsecretenv\x12-\n" +
 	"\n" +
 	"cdiDevices\x18\x06 \x03(\v2\r.pb.CDIDeviceR\n" +
-	"cdiDevices\"\xf3\x02\n" +
+	"cdiDevices\"\xae\x03\n" +
 	"\x04Meta\x12\x12\n" +
 	"\x04args\x18\x01 \x03(\tR\x04args\x12\x10\n" +
 	"\x03env\x18\x02 \x03(\tR\x03env\x12\x10\n" +
@@ -3436,7 +3521,12 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\fcgroupParent\x18\n" +
 	" \x01(\tR\fcgroupParent\x12<\n" +
 	"\x19removeMountStubsRecursive\x18\v \x01(\bR\x19removeMountStubsRecursive\x12&\n" +
-	"\x0evalidExitCodes\x18\f \x03(\x05R\x0evalidExitCodes\",\n" +
+	"\x0evalidExitCodes\x18\f \x03(\x05R\x0evalidExitCodes\x12\x18\n" +
+	"\atimeout\x18\r \x01(\x03R\atimeout\x12\x1f\n" +
+	"\x05retry\x18\x0e \x01(\v2\t.pb.RetryR\x05retry\"=\n" +
+	"\x05Retry\x12\x1a\n" +
+	"\battempts\x18\x01 \x01(\x03R\battempts\x12\x18\n" +
+	"\abackoff\x18\x02 \x01(\x03R\abackoff\",\n" +
 	"\x06HostIP\x12\x12\n" +
 	"\x04Host\x18\x01 \x01(\tR\x04Host\x12\x0e\n" +
 	"\x02IP\x18\x02 \x01(\tR\x02IP\"D\n" +
@@ -3466,10 +3556,11 @@ const file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc = "" +
 	"\bresultID\x18\x17 \x01(\tR\bresultID\x129\n" +
 	"\fcontentCache\x18\x18 \x01(\x0e2\x15.pb.MountContentCacheR\fcontentCache\"\x1e\n" +
 	"\bTmpfsOpt\x12\x12\n" +
-	"\x04size\x18\x01 \x01(\x03R\x04size\"I\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\"c\n" +
 	"\bCacheOpt\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12-\n" +
-	"\asharing\x18\x02 \x01(\x0e2\x13.pb.CacheSharingOptR\asharing\"o\n" +
+	"\asharing\x18\x02 \x01(\x0e2\x13.pb.CacheSharingOptR\asharing\x12\x18\n" +
+	"\amaxSize\x18\x03 \x01(\x03R\amaxSize\"o\n" +
 	"\tSecretOpt\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x10\n" +
 	"\x03uid\x18\x02 \x01(\rR\x03uid\x12\x10\n" +
@@ -3680,7 +3771,7 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_rawDescGZIP() []byte {
 }
 
 var file_github_com_moby_buildkit_solver_pb_ops_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 51)
+var file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_github_com_moby_buildkit_solver_pb_ops_proto_goTypes = []any{
 	(NetMode)(0),              // 0: pb.NetMode
 	(SecurityMode)(0),         // 1: pb.SecurityMode
@@ -3692,120 +3783,122 @@ var file_github_com_moby_buildkit_solver_pb_ops_proto_goTypes = []any{
 	(*Input)(nil),             // 7: pb.Input
 	(*ExecOp)(nil),            // 8: pb.ExecOp
 	(*Meta)(nil),              // 9: pb.Meta
-	(*HostIP)(nil),            // 10: pb.HostIP
-	(*Ulimit)(nil),            // 11: pb.Ulimit
-	(*SecretEnv)(nil),         // 12: pb.SecretEnv
-	(*CDIDevice)(nil),         // 13: pb.CDIDevice
-	(*Mount)(nil),             // 14: pb.Mount
-	(*TmpfsOpt)(nil),          // 15: pb.TmpfsOpt
-	(*CacheOpt)(nil),          // 16: pb.CacheOpt
-	(*SecretOpt)(nil),         // 17: pb.SecretOpt
-	(*SSHOpt)(nil),            // 18: pb.SSHOpt
-	(*SourceOp)(nil),          // 19: pb.SourceOp
-	(*BuildOp)(nil),           // 20: pb.BuildOp
-	(*BuildInput)(nil),        // 21: pb.BuildInput
-	(*OpMetadata)(nil),        // 22: pb.OpMetadata
-	(*Source)(nil),            // 23: pb.Source
-	(*Locations)(nil),         // 24: pb.Locations
-	(*SourceInfo)(nil),        // 25: pb.SourceInfo
-	(*Location)(nil),          // 26: pb.Location
-	(*Range)(nil),             // 27: pb.Range
-	(*Position)(nil),          // 28: pb.Position
-	(*ExportCache)(nil),       // 29: pb.ExportCache
-	(*ProgressGroup)(nil),     // 30: pb.ProgressGroup
-	(*ProxyEnv)(nil),          // 31: pb.ProxyEnv
-	(*WorkerConstraints)(nil), // 32: pb.WorkerConstraints
-	(*Definition)(nil),        // 33: pb.Definition
-	(*FileOp)(nil),            // 34: pb.FileOp
-	(*FileAction)(nil),        // 35: pb.FileAction
-	(*FileActionCopy)(nil),    // 36: pb.FileActionCopy
-	(*FileActionMkFile)(nil),  // 37: pb.FileActionMkFile
-	(*FileActionSymlink)(nil), // 38: pb.FileActionSymlink
-	(*FileActionMkDir)(nil),   // 39: pb.FileActionMkDir
-	(*FileActionRm)(nil),      // 40: pb.FileActionRm
-	(*ChownOpt)(nil),          // 41: pb.ChownOpt
-	(*UserOpt)(nil),           // 42: pb.UserOpt
-	(*NamedUserOpt)(nil),      // 43: pb.NamedUserOpt
-	(*MergeInput)(nil),        // 44: pb.MergeInput
-	(*MergeOp)(nil),           // 45: pb.MergeOp
-	(*LowerDiffInput)(nil),    // 46: pb.LowerDiffInput
-	(*UpperDiffInput)(nil),    // 47: pb.UpperDiffInput
-	(*DiffOp)(nil),            // 48: pb.DiffOp
-	nil,                       // 49: pb.SourceOp.AttrsEntry
-	nil,                       // 50: pb.BuildOp.InputsEntry
-	nil,                       // 51: pb.BuildOp.AttrsEntry
-	nil,                       // 52: pb.OpMetadata.DescriptionEntry
-	nil,                       // 53: pb.OpMetadata.CapsEntry
-	nil,                       // 54: pb.Source.LocationsEntry
-	nil,                       // 55: pb.Definition.MetadataEntry
+	(*Retry)(nil),             // 10: pb.Retry
+	(*HostIP)(nil),            // 11: pb.HostIP
+	(*Ulimit)(nil),            // 12: pb.Ulimit
+	(*SecretEnv)(nil),         // 13: pb.SecretEnv
+	(*CDIDevice)(nil),         // 14: pb.CDIDevice
+	(*Mount)(nil),             // 15: pb.Mount
+	(*TmpfsOpt)(nil),          // 16: pb.TmpfsOpt
+	(*CacheOpt)(nil),          // 17: pb.CacheOpt
+	(*SecretOpt)(nil),         // 18: pb.SecretOpt
+	(*SSHOpt)(nil),            // 19: pb.SSHOpt
+	(*SourceOp)(nil),          // 20: pb.SourceOp
+	(*BuildOp)(nil),           // 21: pb.BuildOp
+	(*BuildInput)(nil),        // 22: pb.BuildInput
+	(*OpMetadata)(nil),        // 23: pb.OpMetadata
+	(*Source)(nil),            // 24: pb.Source
+	(*Locations)(nil),         // 25: pb.Locations
+	(*SourceInfo)(nil),        // 26: pb.SourceInfo
+	(*Location)(nil),          // 27: pb.Location
+	(*Range)(nil),             // 28: pb.Range
+	(*Position)(nil),          // 29: pb.Position
+	(*ExportCache)(nil),       // 30: pb.ExportCache
+	(*ProgressGroup)(nil),     // 31: pb.ProgressGroup
+	(*ProxyEnv)(nil),          // 32: pb.ProxyEnv
+	(*WorkerConstraints)(nil), // 33: pb.WorkerConstraints
+	(*Definition)(nil),        // 34: pb.Definition
+	(*FileOp)(nil),            // 35: pb.FileOp
+	(*FileAction)(nil),        // 36: pb.FileAction
+	(*FileActionCopy)(nil),    // 37: pb.FileActionCopy
+	(*FileActionMkFile)(nil),  // 38: pb.FileActionMkFile
+	(*FileActionSymlink)(nil), // 39: pb.FileActionSymlink
+	(*FileActionMkDir)(nil),   // 40: pb.FileActionMkDir
+	(*FileActionRm)(nil),      // 41: pb.FileActionRm
+	(*ChownOpt)(nil),          // 42: pb.ChownOpt
+	(*UserOpt)(nil),           // 43: pb.UserOpt
+	(*NamedUserOpt)(nil),      // 44: pb.NamedUserOpt
+	(*MergeInput)(nil),        // 45: pb.MergeInput
+	(*MergeOp)(nil),           // 46: pb.MergeOp
+	(*LowerDiffInput)(nil),    // 47: pb.LowerDiffInput
+	(*UpperDiffInput)(nil),    // 48: pb.UpperDiffInput
+	(*DiffOp)(nil),            // 49: pb.DiffOp
+	nil,                       // 50: pb.SourceOp.AttrsEntry
+	nil,                       // 51: pb.BuildOp.InputsEntry
+	nil,                       // 52: pb.BuildOp.AttrsEntry
+	nil,                       // 53: pb.OpMetadata.DescriptionEntry
+	nil,                       // 54: pb.OpMetadata.CapsEntry
+	nil,                       // 55: pb.Source.LocationsEntry
+	nil,                       // 56: pb.Definition.MetadataEntry
 }
 var file_github_com_moby_buildkit_solver_pb_ops_proto_depIdxs = []int32{
 	7,  // 0: pb.Op.inputs:type_name -> pb.Input
 	8,  // 1: pb.Op.exec:type_name -> pb.ExecOp
-	19, // 2: pb.Op.source:type_name -> pb.SourceOp
-	34, // 3: pb.Op.file:type_name -> pb.FileOp
-	20, // 4: pb.Op.build:type_name -> pb.BuildOp
-	45, // 5: pb.Op.merge:type_name -> pb.MergeOp
-	48, // 6: pb.Op.diff:type_name -> pb.DiffOp
+	20, // 2: pb.Op.source:type_name -> pb.SourceOp
+	35, // 3: pb.Op.file:type_name -> pb.FileOp
+	21, // 4: pb.Op.build:type_name -> pb.BuildOp
+	46, // 5: pb.Op.merge:type_name -> pb.MergeOp
+	49, // 6: pb.Op.diff:type_name -> pb.DiffOp
 	6,  // 7: pb.Op.platform:type_name -> pb.Platform
-	32, // 8: pb.Op.constraints:type_name -> pb.WorkerConstraints
+	33, // 8: pb.Op.constraints:type_name -> pb.WorkerConstraints
 	9,  // 9: pb.ExecOp.meta:type_name -> pb.Meta
-	14, // 10: pb.ExecOp.mounts:type_name -> pb.Mount
+	15, // 10: pb.ExecOp.mounts:type_name -> pb.Mount
 	0,  // 11: pb.ExecOp.network:type_name -> pb.NetMode
 	1,  // 12: pb.ExecOp.security:type_name -> pb.SecurityMode
-	12, // 13: pb.ExecOp.secretenv:type_name -> pb.SecretEnv
-	13, // 14: pb.ExecOp.cdiDevices:type_name -> pb.CDIDevice
-	31, // 15: pb.Meta.proxy_env:type_name -> pb.ProxyEnv
-	10, // 16: pb.Meta.extraHosts:type_name -> pb.HostIP
-	11, // 17: pb.Meta.ulimit:type_name -> pb.Ulimit
-	2,  // 18: pb.Mount.mountType:type_name -> pb.MountType
-	15, // 19: pb.Mount.TmpfsOpt:type_name -> pb.TmpfsOpt
-	16, // 20: pb.Mount.cacheOpt:type_name -> pb.CacheOpt
-	17, // 21: pb.Mount.secretOpt:type_name -> pb.SecretOpt
-	18, // 22: pb.Mount.SSHOpt:type_name -> pb.SSHOpt
-	3,  // 23: pb.Mount.contentCache:type_name -> pb.MountContentCache
-	4,  // 24: pb.CacheOpt.sharing:type_name -> pb.CacheSharingOpt
-	49, // 25: pb.SourceOp.attrs:type_name -> pb.SourceOp.AttrsEntry
-	50, // 26: pb.BuildOp.inputs:type_name -> pb.BuildOp.InputsEntry
-	33, // 27: pb.BuildOp.def:type_name -> pb.Definition
-	51, // 28: pb.BuildOp.attrs:type_name -> pb.BuildOp.AttrsEntry
-	52, // 29: pb.OpMetadata.description:type_name -> pb.OpMetadata.DescriptionEntry
-	29, // 30: pb.OpMetadata.export_cache:type_name -> pb.ExportCache
-	53, // 31: pb.OpMetadata.caps:type_name -> pb.OpMetadata.CapsEntry
-	30, // 32: pb.OpMetadata.progress_group:type_name -> pb.ProgressGroup
-	54, // 33: pb.Source.locations:type_name -> pb.Source.LocationsEntry
-	25, // 34: pb.Source.infos:type_name -> pb.SourceInfo
-	26, // 35: pb.Locations.locations:type_name -> pb.Location
-	33, // 36: pb.SourceInfo.definition:type_name -> pb.Definition
-	27, // 37: pb.Location.ranges:type_name -> pb.Range
-	28, // 38: pb.Range.start:type_name -> pb.Position
-	28, // 39: pb.Range.end:type_name -> pb.Position
-	55, // 40: pb.Definition.metadata:type_name -> pb.Definition.MetadataEntry
-	23, // 41: pb.Definition.Source:type_name -> pb.Source
-	35, // 42: pb.FileOp.actions:type_name -> pb.FileAction
-	36, // 43: pb.FileAction.copy:type_name -> pb.FileActionCopy
-	37, // 44: pb.FileAction.mkfile:type_name -> pb.FileActionMkFile
-	39, // 45: pb.FileAction.mkdir:type_name -> pb.FileActionMkDir
-	40, // 46: pb.FileAction.rm:type_name -> pb.FileActionRm
-	38, // 47: pb.FileAction.symlink:type_name -> pb.FileActionSymlink
-	41, // 48: pb.FileActionCopy.owner:type_name -> pb.ChownOpt
-	41, // 49: pb.FileActionMkFile.owner:type_name -> pb.ChownOpt
-	41, // 50: pb.FileActionSymlink.owner:type_name -> pb.ChownOpt
-	41, // 51: pb.FileActionMkDir.owner:type_name -> pb.ChownOpt
-	42, // 52: pb.ChownOpt.user:type_name -> pb.UserOpt
-	42, // 53: pb.ChownOpt.group:type_name -> pb.UserOpt
-	43, // 54: pb.UserOpt.byName:type_name -> pb.NamedUserOpt
-	44, // 55: pb.MergeOp.inputs:type_name -> pb.MergeInput
-	46, // 56: pb.DiffOp.lower:type_name -> pb.LowerDiffInput
-	47, // 57: pb.DiffOp.upper:type_name -> pb.UpperDiffInput
-	21, // 58: pb.BuildOp.InputsEntry.value:type_name -> pb.BuildInput
-	24, // 59: pb.Source.LocationsEntry.value:type_name -> pb.Locations
-	22, // 60: pb.Definition.MetadataEntry.value:type_name -> pb.OpMetadata
-	61, // [61:61] is the sub-list for method output_type
-	61, // [61:61] is the sub-list for method input_type
-	61, // [61:61] is the sub-list for extension type_name
-	61, // [61:61] is the sub-list for extension extendee
-	0,  // [0:61] is the sub-list for field type_name
+	13, // 13: pb.ExecOp.secretenv:type_name -> pb.SecretEnv
+	14, // 14: pb.ExecOp.cdiDevices:type_name -> pb.CDIDevice
+	32, // 15: pb.Meta.proxy_env:type_name -> pb.ProxyEnv
+	11, // 16: pb.Meta.extraHosts:type_name -> pb.HostIP
+	12, // 17: pb.Meta.ulimit:type_name -> pb.Ulimit
+	10, // 18: pb.Meta.retry:type_name -> pb.Retry
+	2,  // 19: pb.Mount.mountType:type_name -> pb.MountType
+	16, // 20: pb.Mount.TmpfsOpt:type_name -> pb.TmpfsOpt
+	17, // 21: pb.Mount.cacheOpt:type_name -> pb.CacheOpt
+	18, // 22: pb.Mount.secretOpt:type_name -> pb.SecretOpt
+	19, // 23: pb.Mount.SSHOpt:type_name -> pb.SSHOpt
+	3,  // 24: pb.Mount.contentCache:type_name -> pb.MountContentCache
+	4,  // 25: pb.CacheOpt.sharing:type_name -> pb.CacheSharingOpt
+	50, // 26: pb.SourceOp.attrs:type_name -> pb.SourceOp.AttrsEntry
+	51, // 27: pb.BuildOp.inputs:type_name -> pb.BuildOp.InputsEntry
+	34, // 28: pb.BuildOp.def:type_name -> pb.Definition
+	52, // 29: pb.BuildOp.attrs:type_name -> pb.BuildOp.AttrsEntry
+	53, // 30: pb.OpMetadata.description:type_name -> pb.OpMetadata.DescriptionEntry
+	30, // 31: pb.OpMetadata.export_cache:type_name -> pb.ExportCache
+	54, // 32: pb.OpMetadata.caps:type_name -> pb.OpMetadata.CapsEntry
+	31, // 33: pb.OpMetadata.progress_group:type_name -> pb.ProgressGroup
+	55, // 34: pb.Source.locations:type_name -> pb.Source.LocationsEntry
+	26, // 35: pb.Source.infos:type_name -> pb.SourceInfo
+	27, // 36: pb.Locations.locations:type_name -> pb.Location
+	34, // 37: pb.SourceInfo.definition:type_name -> pb.Definition
+	28, // 38: pb.Location.ranges:type_name -> pb.Range
+	29, // 39: pb.Range.start:type_name -> pb.Position
+	29, // 40: pb.Range.end:type_name -> pb.Position
+	56, // 41: pb.Definition.metadata:type_name -> pb.Definition.MetadataEntry
+	24, // 42: pb.Definition.Source:type_name -> pb.Source
+	36, // 43: pb.FileOp.actions:type_name -> pb.FileAction
+	37, // 44: pb.FileAction.copy:type_name -> pb.FileActionCopy
+	38, // 45: pb.FileAction.mkfile:type_name -> pb.FileActionMkFile
+	40, // 46: pb.FileAction.mkdir:type_name -> pb.FileActionMkDir
+	41, // 47: pb.FileAction.rm:type_name -> pb.FileActionRm
+	39, // 48: pb.FileAction.symlink:type_name -> pb.FileActionSymlink
+	42, // 49: pb.FileActionCopy.owner:type_name -> pb.ChownOpt
+	42, // 50: pb.FileActionMkFile.owner:type_name -> pb.ChownOpt
+	42, // 51: pb.FileActionSymlink.owner:type_name -> pb.ChownOpt
+	42, // 52: pb.FileActionMkDir.owner:type_name -> pb.ChownOpt
+	43, // 53: pb.ChownOpt.user:type_name -> pb.UserOpt
+	43, // 54: pb.ChownOpt.group:type_name -> pb.UserOpt
+	44, // 55: pb.UserOpt.byName:type_name -> pb.NamedUserOpt
+	45, // 56: pb.MergeOp.inputs:type_name -> pb.MergeInput
+	47, // 57: pb.DiffOp.lower:type_name -> pb.LowerDiffInput
+	48, // 58: pb.DiffOp.upper:type_name -> pb.UpperDiffInput
+	22, // 59: pb.BuildOp.InputsEntry.value:type_name -> pb.BuildInput
+	25, // 60: pb.Source.LocationsEntry.value:type_name -> pb.Locations
+	23, // 61: pb.Definition.MetadataEntry.value:type_name -> pb.OpMetadata
+	62, // [62:62] is the sub-list for method output_type
+	62, // [62:62] is the sub-list for method input_type
+	62, // [62:62] is the sub-list for extension type_name
+	62, // [62:62] is the sub-list for extension extendee
+	0,  // [0:62] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_solver_pb_ops_proto_init() }
@@ -3821,14 +3914,14 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_init() {
 		(*Op_Merge)(nil),
 		(*Op_Diff)(nil),
 	}
-	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[30].OneofWrappers = []any{
+	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[31].OneofWrappers = []any{
 		(*FileAction_Copy)(nil),
 		(*FileAction_Mkfile)(nil),
 		(*FileAction_Mkdir)(nil),
 		(*FileAction_Rm)(nil),
 		(*FileAction_Symlink)(nil),
 	}
-	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[37].OneofWrappers = []any{
+	file_github_com_moby_buildkit_solver_pb_ops_proto_msgTypes[38].OneofWrappers = []any{
 		(*UserOpt_ByName)(nil),
 		(*UserOpt_ByID)(nil),
 	}
@@ -3838,7 +3931,7 @@ func file_github_com_moby_buildkit_solver_pb_ops_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc), len(file_github_com_moby_buildkit_solver_pb_ops_proto_rawDesc)),
 			NumEnums:      5,
-			NumMessages:   51,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
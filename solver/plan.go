@@ -0,0 +1,251 @@
+package solver
+
+import (
+	"context"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// PlanStatus describes what building an edge would do with a vertex.
+type PlanStatus string
+
+const (
+	// PlanCached is set for vertexes that would be loaded from the cache.
+	PlanCached PlanStatus = "cached"
+	// PlanWouldRun is set for vertexes that would be executed.
+	PlanWouldRun PlanStatus = "would-run"
+	// PlanUnknownUntilInput is set for vertexes whose cache key depends on
+	// the contents of an input, eg. the files copied from the build context,
+	// and can not be computed without building the input.
+	PlanUnknownUntilInput PlanStatus = "unknown-until-input"
+)
+
+// VertexPlan is the planned status of a vertex.
+type VertexPlan struct {
+	Vertex Vertex
+	Status PlanStatus
+}
+
+type planKey struct {
+	dgst  digest.Digest
+	index Index
+}
+
+type plannedEdge struct {
+	status PlanStatus
+	// random is set if the cache key of the edge is random, eg. for results
+	// loaded from the client session
+	random bool
+	// keys are the definition based cache keys of the edge that are known to
+	// the cache
+	keys []*CacheKey
+}
+
+type planner struct {
+	j     *Job
+	edges map[planKey]*plannedEdge
+}
+
+// Plan resolves the cache keys of the edge and its inputs without executing
+// any of the operations. It returns the vertexes that building the edge would
+// need to load, inputs before the vertexes that depend on them.
+func (j *Job) Plan(ctx context.Context, e Edge) ([]VertexPlan, error) {
+	p, e, err := j.newPlanner(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []VertexPlan
+	visited := map[planKey]struct{}{}
+	index := map[digest.Digest]int{}
+	var walk func(e Edge)
+	walk = func(e Edge) {
+		k := planKey{e.Vertex.Digest(), e.Index}
+		if _, ok := visited[k]; ok {
+			return
+		}
+		visited[k] = struct{}{}
+		pe := p.edges[k]
+		if pe.status != PlanCached {
+			for _, inp := range e.Vertex.Inputs() {
+				walk(inp)
+			}
+		}
+		if i, ok := index[e.Vertex.Digest()]; ok {
+			// another output of the same vertex
+			if out[i].Status == PlanCached {
+				out[i].Status = pe.status
+			}
+			return
+		}
+		index[e.Vertex.Digest()] = len(out)
+		out = append(out, VertexPlan{Vertex: e.Vertex, Status: pe.status})
+	}
+	walk(e)
+	return out, nil
+}
+
+// CacheOnlyFrontier returns the first vertexes that building the edge would
+// need to execute, ie. the vertexes that can't be loaded from the cache while
+// all their inputs can. Vertexes with random cache keys, such as the local
+// build context loaded from the client session, are never cached and are not
+// returned.
+func (j *Job) CacheOnlyFrontier(ctx context.Context, e Edge) ([]Vertex, error) {
+	p, e, err := j.newPlanner(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Vertex
+	visited := map[digest.Digest]struct{}{}
+	var walk func(e Edge)
+	walk = func(e Edge) {
+		pe := p.edges[planKey{e.Vertex.Digest(), e.Index}]
+		if pe.status == PlanCached || pe.random {
+			return
+		}
+		if _, ok := visited[e.Vertex.Digest()]; ok {
+			return
+		}
+		visited[e.Vertex.Digest()] = struct{}{}
+		frontier := pe.status == PlanWouldRun
+		for _, inp := range e.Vertex.Inputs() {
+			dep := p.edges[planKey{inp.Vertex.Digest(), inp.Index}]
+			if dep.status != PlanCached && !dep.random {
+				frontier = false
+			}
+			walk(inp)
+		}
+		if frontier {
+			out = append(out, e.Vertex)
+		}
+	}
+	walk(e)
+	return out, nil
+}
+
+func (j *Job) newPlanner(ctx context.Context, e Edge) (*planner, Edge, error) {
+	v, err := j.list.load(ctx, e.Vertex, nil, j)
+	if err != nil {
+		return nil, e, err
+	}
+	e.Vertex = v
+
+	p := &planner{j: j, edges: map[planKey]*plannedEdge{}}
+	if _, err := p.plan(ctx, e); err != nil {
+		return nil, e, err
+	}
+	return p, e, nil
+}
+
+func (p *planner) plan(ctx context.Context, e Edge) (*plannedEdge, error) {
+	k := planKey{e.Vertex.Digest(), e.Index}
+	if pe, ok := p.edges[k]; ok {
+		return pe, nil
+	}
+
+	ed := p.j.list.getEdge(e)
+	if ed == nil {
+		return nil, errors.Errorf("inactive edge %s", e.Vertex.Digest())
+	}
+	op := ed.op
+
+	var cms []*CacheMap
+	for i := 0; ; i++ {
+		res, err := op.CacheMap(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		cms = append(cms, res.CacheMap)
+		if res.complete {
+			break
+		}
+	}
+	cm := cms[len(cms)-1]
+
+	deps := make([]*plannedEdge, len(e.Vertex.Inputs()))
+	for i, inp := range e.Vertex.Inputs() {
+		dep, err := p.plan(ctx, inp)
+		if err != nil {
+			return nil, err
+		}
+		deps[i] = dep
+	}
+
+	pe := &plannedEdge{
+		status: PlanWouldRun,
+		random: strings.HasPrefix(cm.Digest.String(), "random:"),
+	}
+	cache := op.Cache()
+	if len(deps) == 0 {
+		for _, cm := range cms {
+			keys, err := cache.Query(nil, 0, cm.Digest, e.Index)
+			if err != nil {
+				return nil, err
+			}
+			pe.keys = append(pe.keys, keys...)
+		}
+	} else {
+		// a key matches only if it can be reached from all the inputs
+		var matches map[string]*CacheKey
+		for i, dep := range deps {
+			depKeys := make([]CacheKeyWithSelector, 0, len(dep.keys))
+			for _, k := range dep.keys {
+				depKeys = append(depKeys, CacheKeyWithSelector{
+					CacheKey: ExportableCacheKey{CacheKey: k},
+					Selector: cm.Deps[i].Selector,
+				})
+			}
+			found := map[string]*CacheKey{}
+			if len(depKeys) > 0 {
+				keys, err := cache.Query(depKeys, Index(i), cm.Digest, e.Index)
+				if err != nil {
+					return nil, err
+				}
+				for _, k := range keys {
+					if matches == nil || matches[k.ID] != nil {
+						found[k.ID] = k
+					}
+				}
+			}
+			matches = found
+			if len(matches) == 0 {
+				break
+			}
+		}
+		for _, k := range matches {
+			pe.keys = append(pe.keys, k)
+		}
+	}
+
+	if op.IgnoreCache() {
+		p.edges[k] = pe
+		return pe, nil
+	}
+
+	for _, k := range pe.keys {
+		k.vtx = e.Vertex.Digest()
+		records, err := cache.Records(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			pe.status = PlanCached
+			break
+		}
+	}
+
+	if pe.status != PlanCached {
+		for i, dep := range deps {
+			if cm.Deps[i].ComputeDigestFunc != nil || dep.status == PlanUnknownUntilInput {
+				pe.status = PlanUnknownUntilInput
+				break
+			}
+		}
+	}
+
+	p.edges[k] = pe
+	return pe, nil
+}
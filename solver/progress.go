@@ -76,6 +76,17 @@ func (j *Job) Status(ctx context.Context, ch chan *client.SolveStatus) error {
 					v.Vertex = vtx.(digest.Digest)
 				}
 				ss.Warnings = append(ss.Warnings, &v)
+			case client.VertexEvent:
+				vtx, ok := p.Meta("vertex")
+				if !ok {
+					bklog.G(ctx).Warnf("progress %s event without vertex info", p.ID)
+					continue
+				}
+				if v.Vertex == "" {
+					v.Vertex = vtx.(digest.Digest)
+				}
+				v.Timestamp = p.Timestamp
+				ss.Events = append(ss.Events, &v)
 			}
 		}
 		slices.SortFunc(ss.Vertexes, func(a, b *client.Vertex) int {
@@ -13,6 +13,8 @@ import (
 
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/util/offline"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
@@ -3580,6 +3582,308 @@ func TestStaleEdgeMerge(t *testing.T) {
 	require.NotContains(t, s.actives, depV2.Digest())
 }
 
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer l.Close()
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	newGraph := func(seed string, slow bool) Edge {
+		opt := vtxOpt{
+			name:         "v2-" + seed,
+			cacheKeySeed: seed,
+			value:        "result2",
+			inputs: []Edge{
+				{Vertex: vtx(vtxOpt{
+					name:         "v0",
+					cacheKeySeed: "seed0",
+					value:        "result0",
+				})},
+				{Vertex: vtx(vtxOpt{
+					name:         "v1",
+					cacheKeySeed: "seed1",
+					value:        "result1",
+				})},
+			},
+		}
+		if slow {
+			opt.slowCacheCompute = map[int]ResultBasedCacheFunc{
+				0: digestFromResult,
+			}
+		}
+		g := Edge{Vertex: vtx(opt)}
+		g.Vertex.(*vertex).setupCallCounters()
+		return g
+	}
+
+	g0 := newGraph("seed2", false)
+	res, err := j0.Build(ctx, g0)
+	require.NoError(t, err)
+	require.Equal(t, "result2", unwrap(res))
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	g1 := newGraph("seed2", false)
+	plan, err := j1.Plan(ctx, g1)
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	require.Equal(t, "v2-seed2", plan[0].Vertex.Name())
+	require.Equal(t, PlanCached, plan[0].Status)
+
+	g2 := newGraph("seed3", false)
+	plan, err = j1.Plan(ctx, g2)
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+	require.Equal(t, "v0", plan[0].Vertex.Name())
+	require.Equal(t, PlanCached, plan[0].Status)
+	require.Equal(t, "v1", plan[1].Vertex.Name())
+	require.Equal(t, PlanCached, plan[1].Status)
+	require.Equal(t, "v2-seed3", plan[2].Vertex.Name())
+	require.Equal(t, PlanWouldRun, plan[2].Status)
+
+	g3 := newGraph("seed4", true)
+	plan, err = j1.Plan(ctx, g3)
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+	require.Equal(t, "v2-seed4", plan[2].Vertex.Name())
+	require.Equal(t, PlanUnknownUntilInput, plan[2].Status)
+
+	require.Equal(t, int64(0), *g2.Vertex.(*vertex).execCallCount)
+	require.Equal(t, int64(0), *g3.Vertex.(*vertex).execCallCount)
+
+	// the frontend can't execute operations in plan mode
+	j1.SetPlanOnly(true)
+	res, err = j1.Build(ctx, g1)
+	require.NoError(t, err)
+	require.Equal(t, "result2", unwrap(res))
+
+	_, err = j1.Build(ctx, g2)
+	require.ErrorContains(t, err, "v2-seed3 can not be executed in plan mode")
+	require.Equal(t, int64(0), *g2.Vertex.(*vertex).execCallCount)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
+
+func TestCacheOnly(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer l.Close()
+
+	j0, err := l.NewJob("j0")
+	require.NoError(t, err)
+
+	defer func() {
+		if j0 != nil {
+			j0.Discard()
+		}
+	}()
+
+	newGraph := func(seed string) Edge {
+		g := Edge{Vertex: vtx(vtxOpt{
+			name:         "v2-" + seed,
+			cacheKeySeed: seed,
+			value:        "result2",
+			inputs: []Edge{
+				{Vertex: vtx(vtxOpt{
+					name:         "v0",
+					cacheKeySeed: "seed0",
+					value:        "result0",
+				})},
+				{Vertex: vtx(vtxOpt{
+					name:         "v1",
+					cacheKeySeed: "seed1",
+					value:        "result1",
+				})},
+			},
+		})}
+		g.Vertex.(*vertex).setupCallCounters()
+		return g
+	}
+
+	res, err := j0.Build(ctx, newGraph("seed2"))
+	require.NoError(t, err)
+	require.Equal(t, "result2", unwrap(res))
+
+	require.NoError(t, j0.Discard())
+	j0 = nil
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+	j1.SetCacheOnly(true)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	g1 := newGraph("seed2")
+	frontier, err := j1.CacheOnlyFrontier(ctx, g1)
+	require.NoError(t, err)
+	require.Empty(t, frontier)
+
+	res, err = j1.Build(ctx, g1)
+	require.NoError(t, err)
+	require.Equal(t, "result2", unwrap(res))
+
+	g2 := Edge{Vertex: vtx(vtxOpt{
+		name:         "v3",
+		cacheKeySeed: "seed4",
+		value:        "result3",
+		inputs:       []Edge{newGraph("seed3")},
+	})}
+	g2.Vertex.(*vertex).setupCallCounters()
+
+	frontier, err = j1.CacheOnlyFrontier(ctx, g2)
+	require.NoError(t, err)
+	require.Len(t, frontier, 1)
+	require.Equal(t, "v2-seed3", frontier[0].Name())
+
+	_, err = j1.Build(ctx, g2)
+	require.Error(t, err)
+	var coe *errdefs.CacheOnlyError
+	require.ErrorAs(t, err, &coe)
+	require.Len(t, coe.Vertexes, 1)
+	require.Equal(t, "v2-seed3", coe.Vertexes[0].Name)
+	require.Equal(t, int64(0), *g2.Vertex.(*vertex).execCallCount)
+
+	// sources that need the network are reported as cache-only errors
+	g3 := Edge{Vertex: vtx(vtxOpt{
+		name: "v4",
+		cachePreFunc: func(ctx context.Context) error {
+			if !offline.IsOffline(ctx) {
+				return errors.New("expected offline context")
+			}
+			return errors.Wrap(offline.ErrNetworkAccess, "remote source")
+		},
+	})}
+	_, err = j1.Build(ctx, g3)
+	require.ErrorAs(t, err, &coe)
+	require.Len(t, coe.Vertexes, 1)
+	require.Equal(t, "v4", coe.Vertexes[0].Name)
+	require.ErrorIs(t, err, offline.ErrNetworkAccess)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+}
+
+func TestKeepGoing(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	l := NewSolver(SolverOpt{
+		ResolveOpFunc: testOpResolver,
+	})
+	defer l.Close()
+
+	newGraph := func() (Edge, *vertex) {
+		failing := func(name string) Edge {
+			return Edge{Vertex: vtx(vtxOpt{
+				name:  name,
+				value: "result-" + name,
+				execPreFunc: func(context.Context) error {
+					return errors.Errorf("error-%s", name)
+				},
+			})}
+		}
+		slow := vtx(vtxOpt{
+			name:         "v1",
+			cacheKeySeed: "seed1",
+			value:        "result1",
+			execDelay:    200 * time.Millisecond,
+		})
+		g := Edge{Vertex: vtx(vtxOpt{
+			name:  "v3",
+			value: "result3",
+			inputs: []Edge{
+				failing("v0"),
+				{Vertex: slow},
+				failing("v2"),
+			},
+		})}
+		g.Vertex.(*vertex).setupCallCounters()
+		return g, slow
+	}
+
+	j1, err := l.NewJob("j1")
+	require.NoError(t, err)
+	j1.SetKeepGoing(true)
+
+	defer func() {
+		if j1 != nil {
+			j1.Discard()
+		}
+	}()
+
+	g1, slow1 := newGraph()
+	_, err = j1.Build(ctx, g1)
+	require.Error(t, err)
+	var fe *errdefs.FailuresError
+	require.True(t, errors.As(err, &fe))
+	require.Len(t, fe.Items, 2)
+	var msgs []string
+	for _, f := range fe.Items {
+		require.NotEmpty(t, f.Digest)
+		msgs = append(msgs, f.Message)
+	}
+	require.ElementsMatch(t, []string{"error-v0", "error-v2"}, msgs)
+	require.Equal(t, int64(1), *slow1.execCallCount)
+
+	require.NoError(t, j1.Discard())
+	j1 = nil
+
+	// the input that did not fail was cached
+	j2, err := l.NewJob("j2")
+	require.NoError(t, err)
+
+	defer func() {
+		if j2 != nil {
+			j2.Discard()
+		}
+	}()
+
+	g2 := Edge{Vertex: vtx(vtxOpt{
+		name:         "v1",
+		cacheKeySeed: "seed1",
+		value:        "result1",
+	})}
+	g2.Vertex.(*vertex).setupCallCounters()
+	res, err := j2.Build(ctx, g2)
+	require.NoError(t, err)
+	require.Equal(t, "result1", unwrap(res))
+	require.Equal(t, int64(0), *g2.Vertex.(*vertex).execCallCount)
+
+	require.NoError(t, j2.Discard())
+	j2 = nil
+}
+
 func generateSubGraph(nodes int) (Edge, int) {
 	if nodes == 1 {
 		value := rand.Int() % 500 //nolint:gosec
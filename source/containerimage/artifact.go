@@ -0,0 +1,394 @@
+package containerimage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/offline"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/resolver"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxArtifactManifestSize limits the size of the manifests and indexes read
+// when resolving an artifact.
+const maxArtifactManifestSize = 4 << 20
+
+// ArtifactSource writes the blobs of OCI artifacts in a registry to files.
+// Unlike images, the blobs are not unpacked as layers, so any media type is
+// supported.
+type ArtifactSource struct {
+	SourceOpt
+}
+
+var _ source.Source = &ArtifactSource{}
+
+func NewArtifactSource(opt SourceOpt) (*ArtifactSource, error) {
+	return &ArtifactSource{
+		SourceOpt: opt,
+	}, nil
+}
+
+func (as *ArtifactSource) Schemes() []string {
+	return []string{srctypes.OCIArtifactScheme}
+}
+
+func (as *ArtifactSource) Identifier(scheme, ref string, attrs map[string]string, platform *pb.Platform) (source.Identifier, error) {
+	id, err := NewArtifactIdentifier(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if platform != nil {
+		id.Platform = &ocispecs.Platform{
+			OS:           platform.OS,
+			Architecture: platform.Architecture,
+			Variant:      platform.Variant,
+			OSVersion:    platform.OSVersion,
+		}
+		if platform.OSFeatures != nil {
+			id.Platform.OSFeatures = slices.Clone(platform.OSFeatures)
+		}
+	}
+
+	for k, v := range attrs {
+		switch k {
+		case pb.AttrOCIArtifactType:
+			id.ArtifactType = v
+		case pb.AttrOCIArtifactFiles:
+			if err := json.Unmarshal([]byte(v), &id.Files); err != nil {
+				return nil, errors.Wrap(err, "failed to parse OCI artifact files")
+			}
+			for _, f := range id.Files {
+				if err := validateArtifactFilename(f); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return id, nil
+}
+
+func (as *ArtifactSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, vtx solver.Vertex) (source.SourceInstance, error) {
+	artifactIdentifier, ok := id.(*ArtifactIdentifier)
+	if !ok {
+		return nil, errors.Errorf("invalid OCI artifact identifier %v", id)
+	}
+
+	platform := platforms.DefaultSpec()
+	if artifactIdentifier.Platform != nil {
+		platform = *artifactIdentifier.Platform
+	}
+	return &artifactPuller{
+		ArtifactSource: as,
+		id:             artifactIdentifier,
+		platform:       platform,
+		sm:             sm,
+	}, nil
+}
+
+type artifactBlob struct {
+	name string
+	desc ocispecs.Descriptor
+}
+
+type artifactPuller struct {
+	*ArtifactSource
+	id       *ArtifactIdentifier
+	platform ocispecs.Platform
+	sm       *session.Manager
+
+	g            flightcontrol.Group[struct{}]
+	name         string
+	manifestDesc ocispecs.Descriptor
+	blobs        []artifactBlob
+	cacheKey     digest.Digest
+}
+
+func (p *artifactPuller) resolver(g session.Group) *resolver.Resolver {
+	return resolver.DefaultPool.GetResolver(p.RegistryHosts, p.id.Reference.String(), "pull", p.sm, g)
+}
+
+func (p *artifactPuller) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	_, err := p.g.Do(ctx, "", func(ctx context.Context) (_ struct{}, err error) {
+		if p.cacheKey != "" {
+			return struct{}{}, nil
+		}
+		resolveProgressDone := progress.OneOff(ctx, "resolve "+p.id.Reference.String())
+		defer func() {
+			resolveProgressDone(err)
+		}()
+		return struct{}{}, p.resolve(ctx, g)
+	})
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	return p.cacheKey.String(), p.manifestDesc.Digest.String(), nil, true, nil
+}
+
+// resolve selects the manifest of the artifact and the blobs that are written
+// to files.
+func (p *artifactPuller) resolve(ctx context.Context, g session.Group) error {
+	ref := p.id.Reference.String()
+	if offline.IsOffline(ctx) {
+		// artifacts are not kept in the content store, so they can only be
+		// resolved from the registry
+		return errors.Wrapf(offline.ErrNetworkAccess, "OCI artifact %s", ref)
+	}
+	r := p.resolver(g)
+	name, desc, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+	fetcher, err := r.Fetcher(ctx, name)
+	if err != nil {
+		return err
+	}
+	provider := contentutil.FromFetcher(fetcher)
+
+	dt, err := readArtifactManifest(ctx, provider, desc)
+	if err != nil {
+		return err
+	}
+	switch desc.MediaType {
+	case images.MediaTypeDockerSchema2ManifestList, ocispecs.MediaTypeImageIndex:
+		var idx ocispecs.Index
+		if err := json.Unmarshal(dt, &idx); err != nil {
+			return errors.Wrapf(err, "failed to parse index of %s", ref)
+		}
+		desc, err = selectArtifactManifest(idx.Manifests, p.platform)
+		if err != nil {
+			return errors.Wrapf(err, "failed to select manifest of %s", ref)
+		}
+		dt, err = readArtifactManifest(ctx, provider, desc)
+		if err != nil {
+			return err
+		}
+	}
+	switch desc.MediaType {
+	case images.MediaTypeDockerSchema2Manifest, ocispecs.MediaTypeImageManifest:
+	default:
+		return errors.Errorf("unsupported media type %s of OCI artifact %s", desc.MediaType, ref)
+	}
+
+	var mfst ocispecs.Manifest
+	if err := json.Unmarshal(dt, &mfst); err != nil {
+		return errors.Wrapf(err, "failed to parse manifest of %s", ref)
+	}
+	// artifacts created before the artifactType field existed use the media
+	// type of the config
+	artifactType := mfst.ArtifactType
+	if artifactType == "" {
+		artifactType = mfst.Config.MediaType
+	}
+	if p.id.ArtifactType != "" {
+		if artifactType != p.id.ArtifactType {
+			return errors.Errorf("OCI artifact %s has artifact type %s, expected %s", ref, artifactType, p.id.ArtifactType)
+		}
+	}
+	blobs, err := selectArtifactBlobs(mfst.Layers, p.id.Files)
+	if err != nil {
+		return errors.Wrapf(err, "invalid OCI artifact %s", ref)
+	}
+
+	files := make([]string, 0, len(blobs))
+	for _, b := range blobs {
+		files = append(files, b.name)
+	}
+	dt, err = json.Marshal(struct {
+		Manifest digest.Digest
+		Files    []string
+	}{
+		Manifest: desc.Digest,
+		Files:    files,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	p.id.resolvedArtifactType = artifactType
+	p.name = name
+	p.manifestDesc = desc
+	p.blobs = blobs
+	p.cacheKey = digest.FromBytes(dt)
+	return nil
+}
+
+func (p *artifactPuller) Snapshot(ctx context.Context, g session.Group) (_ cache.ImmutableRef, retErr error) {
+	if p.cacheKey == "" {
+		return nil, errors.Errorf("OCI artifact %s has not been resolved", p.id.Reference.String())
+	}
+	fetcher, err := p.resolver(g).Fetcher(ctx, p.name)
+	if err != nil {
+		return nil, err
+	}
+	provider := contentutil.FromFetcher(fetcher)
+
+	newRef, err := p.CacheAccessor.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("oci artifact %s", p.id.Reference.String())))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil && lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	for _, b := range p.blobs {
+		if err := writeArtifactBlob(ctx, provider, b.desc, filepath.Join(dir, filepath.FromSlash(b.name))); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s from OCI artifact %s", b.name, p.id.Reference.String())
+		}
+	}
+
+	lm.Unmount()
+	lm = nil
+
+	ref, err := newRef.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newRef = nil
+	return ref, nil
+}
+
+func readArtifactManifest(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor) ([]byte, error) {
+	if desc.Size > maxArtifactManifestSize {
+		return nil, errors.Errorf("manifest %s is too large: %d bytes", desc.Digest, desc.Size)
+	}
+	dt, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest %s", desc.Digest)
+	}
+	if dgst := desc.Digest.Algorithm().FromBytes(dt); dgst != desc.Digest {
+		return nil, errors.Errorf("digest mismatch %s: %s", dgst, desc.Digest)
+	}
+	return dt, nil
+}
+
+// selectArtifactManifest returns the manifest of an index that best matches
+// platform. An index with a single manifest without a platform, as created
+// for artifacts with referrers, matches any platform.
+func selectArtifactManifest(manifests []ocispecs.Descriptor, platform ocispecs.Platform) (ocispecs.Descriptor, error) {
+	if len(manifests) == 1 && manifests[0].Platform == nil {
+		return manifests[0], nil
+	}
+	matcher := platforms.Only(platform)
+	var best *ocispecs.Descriptor
+	for i, m := range manifests {
+		if m.Platform == nil || !matcher.Match(*m.Platform) {
+			continue
+		}
+		if best == nil || matcher.Less(*m.Platform, *best.Platform) {
+			best = &manifests[i]
+		}
+	}
+	if best == nil {
+		return ocispecs.Descriptor{}, errors.Errorf("no manifest for platform %s", platforms.FormatAll(platform))
+	}
+	return *best, nil
+}
+
+// selectArtifactBlobs names the blobs of an artifact after their title
+// annotation, or their digest if they have no title, and returns the blobs
+// selected by files. All blobs are returned if files is empty.
+func selectArtifactBlobs(layers []ocispecs.Descriptor, files []string) ([]artifactBlob, error) {
+	var blobs []artifactBlob
+	names := make(map[string]struct{}, len(layers))
+	for _, l := range layers {
+		name := l.Annotations[ocispecs.AnnotationTitle]
+		if name == "" {
+			name = l.Digest.Encoded()
+		}
+		if err := validateArtifactFilename(name); err != nil {
+			return nil, err
+		}
+		if _, ok := names[name]; ok {
+			return nil, errors.Errorf("duplicate file %s", name)
+		}
+		names[name] = struct{}{}
+		if len(files) > 0 && !slices.Contains(files, name) {
+			continue
+		}
+		blobs = append(blobs, artifactBlob{name: name, desc: l})
+	}
+	for _, f := range files {
+		if _, ok := names[f]; !ok {
+			return nil, errors.Errorf("file %s not found", f)
+		}
+	}
+	return blobs, nil
+}
+
+func validateArtifactFilename(name string) error {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return errors.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
+
+func writeArtifactBlob(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, fp string) error {
+	ra, err := provider.ReaderAt(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer ra.Close()
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	verifier := desc.Digest.Verifier()
+	n, err := io.Copy(io.MultiWriter(f, verifier), content.NewReader(ra))
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n != desc.Size || !verifier.Verified() {
+		return errors.Errorf("digest mismatch for blob %s", desc.Digest)
+	}
+	// blobs have no modification time, use a fixed one for reproducible
+	// results
+	mTime := time.Unix(0, 0)
+	return errors.WithStack(os.Chtimes(fp, mTime, mTime))
+}
@@ -0,0 +1,96 @@
+package containerimage
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectArtifactBlobs(t *testing.T) {
+	t.Parallel()
+
+	titled := func(name, dt string) ocispecs.Descriptor {
+		return ocispecs.Descriptor{
+			Digest:      digest.FromString(dt),
+			Size:        int64(len(dt)),
+			Annotations: map[string]string{ocispecs.AnnotationTitle: name},
+		}
+	}
+	untitled := ocispecs.Descriptor{Digest: digest.FromString("untitled")}
+	layers := []ocispecs.Descriptor{titled("a.bin", "a"), titled("dir/b.json", "b"), untitled}
+
+	blobs, err := selectArtifactBlobs(layers, nil)
+	require.NoError(t, err)
+	require.Len(t, blobs, 3)
+	require.Equal(t, "a.bin", blobs[0].name)
+	require.Equal(t, "dir/b.json", blobs[1].name)
+	require.Equal(t, untitled.Digest.Encoded(), blobs[2].name)
+
+	blobs, err = selectArtifactBlobs(layers, []string{"dir/b.json"})
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	require.Equal(t, layers[1].Digest, blobs[0].desc.Digest)
+
+	_, err = selectArtifactBlobs(layers, []string{"c.bin"})
+	require.ErrorContains(t, err, "file c.bin not found")
+
+	_, err = selectArtifactBlobs([]ocispecs.Descriptor{titled("a.bin", "a"), titled("a.bin", "b")}, nil)
+	require.ErrorContains(t, err, "duplicate file a.bin")
+
+	for _, name := range []string{"../a.bin", "/etc/passwd", "dir/../../a.bin"} {
+		_, err = selectArtifactBlobs([]ocispecs.Descriptor{titled(name, "a")}, nil)
+		require.ErrorContains(t, err, "invalid file name", name)
+	}
+}
+
+func TestSelectArtifactManifest(t *testing.T) {
+	t.Parallel()
+
+	amd64 := ocispecs.Descriptor{
+		Digest:   digest.FromString("amd64"),
+		Platform: &ocispecs.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	arm64 := ocispecs.Descriptor{
+		Digest:   digest.FromString("arm64"),
+		Platform: &ocispecs.Platform{OS: "linux", Architecture: "arm64"},
+	}
+
+	desc, err := selectArtifactManifest([]ocispecs.Descriptor{amd64, arm64}, ocispecs.Platform{OS: "linux", Architecture: "arm64"})
+	require.NoError(t, err)
+	require.Equal(t, arm64.Digest, desc.Digest)
+
+	_, err = selectArtifactManifest([]ocispecs.Descriptor{amd64, arm64}, ocispecs.Platform{OS: "windows", Architecture: "amd64"})
+	require.ErrorContains(t, err, "no manifest for platform")
+
+	single := ocispecs.Descriptor{Digest: digest.FromString("single")}
+	desc, err = selectArtifactManifest([]ocispecs.Descriptor{single}, ocispecs.Platform{OS: "linux", Architecture: "amd64"})
+	require.NoError(t, err)
+	require.Equal(t, single.Digest, desc.Digest)
+}
+
+func TestArtifactIdentifierCapture(t *testing.T) {
+	t.Parallel()
+
+	dgst := digest.FromString("manifest")
+	id, err := NewArtifactIdentifier("ghcr.io/example/models:v1")
+	require.NoError(t, err)
+	id.resolvedArtifactType = "application/vnd.example.model.v1"
+
+	var c provenance.Capture
+	require.NoError(t, id.Capture(&c, dgst.String()))
+	require.Empty(t, c.Sources.Images)
+	require.Equal(t, []provenancetypes.ArtifactSource{{
+		Ref:          "ghcr.io/example/models:v1",
+		Digest:       dgst,
+		ArtifactType: "application/vnd.example.model.v1",
+	}}, c.Sources.Artifacts)
+
+	pr, err := provenance.NewPredicate(&c)
+	require.NoError(t, err)
+	require.Len(t, pr.Materials, 1)
+	require.Equal(t, "pkg:oci/models@"+dgst.String()+"?artifact_type=application%2Fvnd.example.model.v1&repository_url=ghcr.io%2Fexample%2Fmodels&tag=v1", pr.Materials[0].URI)
+}
@@ -0,0 +1,164 @@
+package containerimage
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/pkg/reference"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/client/llb/sourceresolver"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/resolver"
+	"github.com/moby/buildkit/util/tracing"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxAttestationSize limits the size of the attestation layers read when
+// resolving the attestations of an image.
+const maxAttestationSize = 32 << 20
+
+// referrersFunc returns the attestation manifests that refer to the manifest
+// dgst.
+type referrersFunc func(ctx context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error)
+
+// ResolveAttestations returns the attestations of the image ref with the
+// manifest or index dgst, for the platform of opt. Attestation manifests are
+// found in the image index and, for registries, as referrers of the platform
+// manifest.
+func (is *Source) ResolveAttestations(ctx context.Context, ref string, dgst digest.Digest, opt sourceresolver.Opt, sm *session.Manager, g session.Group) (_ []sourceresolver.ImageAttestation, retErr error) {
+	span, ctx := tracing.StartSpan(ctx, "resolving attestations of "+ref)
+	defer func() {
+		tracing.FinishWithError(span, retErr)
+	}()
+
+	refspec, err := reference.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	ref = refspec.Locator + "@" + dgst.String()
+
+	var (
+		rslvr     remotes.Resolver
+		referrers referrersFunc
+	)
+	switch is.ResolverType {
+	case ResolverTypeRegistry:
+		r := resolver.DefaultPool.GetResolver(is.RegistryHosts, ref, "pull", sm, g)
+		rslvr = r
+		referrers = func(ctx context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error) {
+			return r.Referrers(ctx, ref, dgst, attestation.ArtifactTypeAttestationManifest)
+		}
+	case ResolverTypeOCILayout:
+		iopt := opt.OCILayoutOpt
+		if iopt == nil {
+			return nil, errors.Errorf("missing ocilayoutopt for resolve")
+		}
+		rslvr = getOCILayoutResolver(iopt.Store, sm, g)
+	}
+
+	name, desc, err := rslvr.Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, err := rslvr.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := platforms.DefaultSpec()
+	if opt.Platform != nil {
+		platform = *opt.Platform
+	}
+	return readAttestations(ctx, contentutil.FromFetcher(fetcher), desc, platforms.Normalize(platform), referrers)
+}
+
+// readAttestations reads the attestations of the manifest for platform in
+// root. The attestation manifests are looked up in root if it is an index,
+// and with referrers if it is not nil.
+func readAttestations(ctx context.Context, provider content.Provider, root ocispecs.Descriptor, platform ocispecs.Platform, referrers referrersFunc) ([]sourceresolver.ImageAttestation, error) {
+	var attestationManifests []ocispecs.Descriptor
+	mfstDesc := root
+	switch root.MediaType {
+	case images.MediaTypeDockerSchema2ManifestList, ocispecs.MediaTypeImageIndex:
+		dt, err := readArtifactManifest(ctx, provider, root)
+		if err != nil {
+			return nil, err
+		}
+		var idx ocispecs.Index
+		if err := json.Unmarshal(dt, &idx); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse index %s", root.Digest)
+		}
+		var manifests []ocispecs.Descriptor
+		for _, m := range idx.Manifests {
+			if _, ok := m.Annotations[attestation.DockerAnnotationReferenceType]; !ok {
+				manifests = append(manifests, m)
+			}
+		}
+		mfstDesc, err = selectArtifactManifest(manifests, platform)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to select manifest of %s", root.Digest)
+		}
+		for _, m := range idx.Manifests {
+			if m.Annotations[attestation.DockerAnnotationReferenceType] == attestation.DockerAnnotationReferenceTypeDefault &&
+				m.Annotations[attestation.DockerAnnotationReferenceDigest] == mfstDesc.Digest.String() {
+				attestationManifests = append(attestationManifests, m)
+			}
+		}
+	}
+
+	if referrers != nil {
+		descs, err := referrers(ctx, mfstDesc.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get referrers of %s", mfstDesc.Digest)
+		}
+		for _, desc := range descs {
+			// attestations pushed with the referrers API may also be in the
+			// index of older images
+			if !slices.ContainsFunc(attestationManifests, func(m ocispecs.Descriptor) bool { return m.Digest == desc.Digest }) {
+				attestationManifests = append(attestationManifests, desc)
+			}
+		}
+	}
+
+	var atts []sourceresolver.ImageAttestation
+	for _, desc := range attestationManifests {
+		dt, err := readArtifactManifest(ctx, provider, desc)
+		if err != nil {
+			return nil, err
+		}
+		var mfst ocispecs.Manifest
+		if err := json.Unmarshal(dt, &mfst); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse attestation manifest %s", desc.Digest)
+		}
+		if mfst.Subject != nil && mfst.Subject.Digest != mfstDesc.Digest {
+			return nil, errors.Errorf("attestation manifest %s refers to %s, expected %s", desc.Digest, mfst.Subject.Digest, mfstDesc.Digest)
+		}
+		for _, l := range mfst.Layers {
+			if l.Size > maxAttestationSize {
+				return nil, errors.Errorf("attestation %s is too large: %d bytes", l.Digest, l.Size)
+			}
+			dt, err := content.ReadBlob(ctx, provider, l)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read attestation %s", l.Digest)
+			}
+			if dgst := l.Digest.Algorithm().FromBytes(dt); dgst != l.Digest {
+				return nil, errors.Errorf("digest mismatch %s: %s", dgst, l.Digest)
+			}
+			atts = append(atts, sourceresolver.ImageAttestation{
+				Manifest:      desc.Digest,
+				PredicateType: l.Annotations["in-toto.io/predicate-type"],
+				MediaType:     l.MediaType,
+				Data:          dt,
+			})
+		}
+	}
+	return atts, nil
+}
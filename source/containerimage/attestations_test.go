@@ -0,0 +1,99 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAttestations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+	buf := contentutil.NewBuffer()
+	write := func(mediaType string, v any) ocispecs.Descriptor {
+		dt, ok := v.([]byte)
+		if !ok {
+			var err error
+			dt, err = json.Marshal(v)
+			require.NoError(t, err)
+		}
+		desc := ocispecs.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(dt),
+			Size:      int64(len(dt)),
+		}
+		require.NoError(t, content.WriteBlob(ctx, buf, desc.Digest.String(), bytes.NewReader(dt), desc))
+		return desc
+	}
+	attestationManifest := func(predicateType string, subject *ocispecs.Descriptor) ocispecs.Descriptor {
+		l := write("application/vnd.in-toto+json", []byte(`{"predicateType":"`+predicateType+`"}`))
+		l.Annotations = map[string]string{"in-toto.io/predicate-type": predicateType}
+		desc := write(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+			MediaType: ocispecs.MediaTypeImageManifest,
+			Config:    ocispecs.DescriptorEmptyJSON,
+			Layers:    []ocispecs.Descriptor{l},
+			Subject:   subject,
+		})
+		desc.ArtifactType = attestation.ArtifactTypeAttestationManifest
+		return desc
+	}
+
+	amd64 := write(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{MediaType: ocispecs.MediaTypeImageManifest})
+	amd64.Platform = &ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := write(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{MediaType: ocispecs.MediaTypeImageManifest, Annotations: map[string]string{"arch": "arm64"}})
+	arm64.Platform = &ocispecs.Platform{OS: "linux", Architecture: "arm64"}
+
+	inIndex := attestationManifest("https://slsa.dev/provenance/v0.2", nil)
+	inIndex.Platform = &ocispecs.Platform{OS: "unknown", Architecture: "unknown"}
+	inIndex.Annotations = map[string]string{
+		attestation.DockerAnnotationReferenceType:   attestation.DockerAnnotationReferenceTypeDefault,
+		attestation.DockerAnnotationReferenceDigest: arm64.Digest.String(),
+	}
+	idx := write(ocispecs.MediaTypeImageIndex, ocispecs.Index{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Manifests: []ocispecs.Descriptor{amd64, arm64, inIndex},
+	})
+
+	subject := &ocispecs.Descriptor{MediaType: arm64.MediaType, Digest: arm64.Digest, Size: arm64.Size}
+	referrer := attestationManifest("https://spdx.dev/Document", subject)
+	referrers := map[digest.Digest][]ocispecs.Descriptor{
+		arm64.Digest: {referrer, inIndex},
+		amd64.Digest: {attestationManifest("https://spdx.dev/Document", subject)},
+	}
+	referrersFn := func(ctx context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error) {
+		return referrers[dgst], nil
+	}
+
+	atts, err := readAttestations(ctx, buf, idx, ocispecs.Platform{OS: "linux", Architecture: "arm64"}, referrersFn)
+	require.NoError(t, err)
+	require.Len(t, atts, 2)
+	require.Equal(t, inIndex.Digest, atts[0].Manifest)
+	require.Equal(t, "https://slsa.dev/provenance/v0.2", atts[0].PredicateType)
+	require.Equal(t, "application/vnd.in-toto+json", atts[0].MediaType)
+	require.JSONEq(t, `{"predicateType":"https://slsa.dev/provenance/v0.2"}`, string(atts[0].Data))
+	require.Equal(t, referrer.Digest, atts[1].Manifest)
+	require.Equal(t, "https://spdx.dev/Document", atts[1].PredicateType)
+
+	// a single platform manifest only has referrers
+	atts, err = readAttestations(ctx, buf, arm64, ocispecs.Platform{OS: "linux", Architecture: "arm64"}, referrersFn)
+	require.NoError(t, err)
+	require.Len(t, atts, 2)
+
+	atts, err = readAttestations(ctx, buf, idx, ocispecs.Platform{OS: "linux", Architecture: "arm64"}, nil)
+	require.NoError(t, err)
+	require.Len(t, atts, 1)
+	require.Equal(t, inIndex.Digest, atts[0].Manifest)
+
+	// referrers must refer to the platform manifest
+	_, err = readAttestations(ctx, buf, amd64, ocispecs.Platform{OS: "linux", Architecture: "amd64"}, referrersFn)
+	require.ErrorContains(t, err, "expected "+amd64.Digest.String())
+}
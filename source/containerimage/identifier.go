@@ -19,6 +19,10 @@ type ImageIdentifier struct {
 	ResolveMode resolver.ResolveMode
 	RecordType  client.UsageRecordType
 	LayerLimit  *int
+	// SignatureKeys are the PEM encoded keys of the signature requirements
+	// of the image. Each requirement must be met by a signature made with
+	// one of its keys.
+	SignatureKeys [][]string
 }
 
 func NewImageIdentifier(str string) (*ImageIdentifier, error) {
@@ -91,3 +95,51 @@ func (id *OCIIdentifier) Capture(c *provenance.Capture, pin string) error {
 	})
 	return nil
 }
+
+type ArtifactIdentifier struct {
+	Reference    reference.Spec
+	Platform     *ocispecs.Platform
+	ArtifactType string
+	Files        []string
+
+	// resolvedArtifactType is the artifact type of the manifest that the
+	// source was resolved to
+	resolvedArtifactType string
+}
+
+func NewArtifactIdentifier(str string) (*ArtifactIdentifier, error) {
+	ref, err := reference.Parse(str)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if ref.Object == "" {
+		return nil, errors.WithStack(reference.ErrObjectRequired)
+	}
+	return &ArtifactIdentifier{Reference: ref}, nil
+}
+
+var _ source.Identifier = (*ArtifactIdentifier)(nil)
+
+func (*ArtifactIdentifier) Scheme() string {
+	return srctypes.OCIArtifactScheme
+}
+
+func (id *ArtifactIdentifier) Capture(c *provenance.Capture, pin string) error {
+	dgst, err := digest.Parse(pin)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse OCI artifact digest %s", pin)
+	}
+	// the pin is the digest of the selected manifest, so the platform used
+	// to select it from an index is not needed
+	artifactType := id.resolvedArtifactType
+	if artifactType == "" {
+		artifactType = id.ArtifactType
+	}
+	c.AddArtifact(provenancetypes.ArtifactSource{
+		Ref:          id.Reference.String(),
+		Digest:       dgst,
+		ArtifactType: artifactType,
+	})
+	return nil
+}
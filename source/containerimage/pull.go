@@ -23,6 +23,7 @@ import (
 	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/util/estargz"
 	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/moby/buildkit/util/imageutil"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/progress"
@@ -45,6 +46,7 @@ type puller struct {
 	Ref            string
 	SessionManager *session.Manager
 	layerLimit     *int
+	signatureKeys  [][]string
 	vtx            solver.Vertex
 	ResolverType
 	store sourceresolver.ResolveImageConfigOptStore
@@ -60,6 +62,31 @@ type puller struct {
 	*pull.Puller
 }
 
+// verifySignature checks that the resolved manifest is signed with one of the
+// keys of every signature requirement. The exact digest that was resolved is
+// verified so the image can't change between the check and the pull.
+func (p *puller) verifySignature(ctx context.Context, g session.Group) (err error) {
+	if p.ResolverType != ResolverTypeRegistry {
+		return errors.Errorf("image signatures can only be verified for registry images")
+	}
+	dgst := p.manifest.MainManifestDesc.Digest
+	done := progress.OneOff(ctx, "verify signature of "+p.Src.String()+"@"+dgst.String())
+	defer func() {
+		done(err)
+	}()
+	r := resolver.DefaultPool.GetResolver(p.RegistryHosts, p.Ref, "pull", p.SessionManager, g)
+	for _, pubKeys := range p.signatureKeys {
+		keys, err := imagesig.ParseKeys(pubKeys...)
+		if err != nil {
+			return err
+		}
+		if _, err := imagesig.Verify(ctx, r, p.Ref, dgst, keys); err != nil {
+			return errors.Wrapf(err, "failed to verify signature of %s", p.Src.String())
+		}
+	}
+	return nil
+}
+
 func mainManifestKey(desc ocispecs.Descriptor, platform ocispecs.Platform, layerLimit *int) (digest.Digest, error) {
 	dt, err := json.Marshal(struct {
 		Digest     digest.Digest
@@ -129,6 +156,12 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 			return struct{}{}, err
 		}
 
+		if len(p.signatureKeys) > 0 {
+			if err := p.verifySignature(ctx, g); err != nil {
+				return struct{}{}, err
+			}
+		}
+
 		if ll := p.layerLimit; ll != nil {
 			if *ll > len(p.manifest.Descriptors) {
 				return struct{}{}, errors.Errorf("layer limit %d is greater than the number of layers in the image %d", *ll, len(p.manifest.Descriptors))
@@ -2,6 +2,7 @@ package containerimage
 
 import (
 	"context"
+	"encoding/json"
 	"slices"
 	"strconv"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/moby/buildkit/source"
 	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/moby/buildkit/util/imageutil"
 	"github.com/moby/buildkit/util/pull"
 	"github.com/moby/buildkit/util/resolver"
@@ -93,6 +95,7 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		ref        reference.Spec
 		store      sourceresolver.ResolveImageConfigOptStore
 		layerLimit *int
+		sigKeys    [][]string
 	)
 	switch is.ResolverType {
 	case ResolverTypeRegistry:
@@ -108,6 +111,7 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		recordType = imageIdentifier.RecordType
 		ref = imageIdentifier.Reference
 		layerLimit = imageIdentifier.LayerLimit
+		sigKeys = imageIdentifier.SignatureKeys
 	case ResolverTypeOCILayout:
 		ociIdentifier, ok := id.(*OCIIdentifier)
 		if !ok {
@@ -146,6 +150,7 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		vtx:            vtx,
 		store:          store,
 		layerLimit:     layerLimit,
+		signatureKeys:  sigKeys,
 	}
 	return p, nil
 }
@@ -245,6 +250,17 @@ func (is *Source) registryIdentifier(ref string, attrs map[string]string, platfo
 				return nil, errors.Errorf("invalid layer limit %s", v)
 			}
 			id.LayerLimit = &l
+		case pb.AttrImageSignatureKeys:
+			var keys [][]string
+			if err := json.Unmarshal([]byte(v), &keys); err != nil {
+				return nil, errors.Wrapf(err, "invalid image signature keys %s", v)
+			}
+			for _, k := range keys {
+				if _, err := imagesig.ParseKeys(k...); err != nil {
+					return nil, errors.Wrap(err, "invalid image signature keys")
+				}
+			}
+			id.SignatureKeys = keys
 		}
 	}
 
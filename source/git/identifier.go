@@ -8,6 +8,8 @@ import (
 	"github.com/moby/buildkit/source"
 	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/urlutil"
+	digest "github.com/opencontainers/go-digest"
 )
 
 type GitIdentifier struct {
@@ -20,6 +22,16 @@ type GitIdentifier struct {
 	AuthHeaderSecret string
 	MountSSHSock     string
 	KnownSSHHosts    string
+	Filter           string
+	SparsePaths      []string
+	LFS              bool
+
+	SignatureKeys      string
+	SignatureKeySecret string
+
+	// signer is the key that the signature of the source was verified with
+	// when the source was snapshotted.
+	signer *gitutil.Signer
 }
 
 func NewGitIdentifier(remoteURL string) (*GitIdentifier, error) {
@@ -53,10 +65,30 @@ func (id *GitIdentifier) Capture(c *provenance.Capture, pin string) error {
 	if id.Ref != "" {
 		url += "#" + id.Ref
 	}
-	c.AddGit(provenancetypes.GitSource{
+	gs := provenancetypes.GitSource{
 		URL:    url,
 		Commit: pin,
-	})
+	}
+	if id.SignatureKeys != "" || id.SignatureKeySecret != "" {
+		gs.Signature = &provenancetypes.GitSignature{
+			URI:          urlutil.RedactCredentials(url),
+			Commit:       pin,
+			PubKeySecret: id.SignatureKeySecret,
+		}
+		if id.SignatureKeys != "" {
+			gs.Signature.PubKeys = digest.FromString(id.SignatureKeys)
+		}
+		if id.signer != nil {
+			gs.Signature.SignerType = id.signer.Type
+			gs.Signature.SignerFingerprint = id.signer.Fingerprint
+		}
+	}
+	c.AddGit(gs)
+	if id.SignatureKeySecret != "" {
+		c.AddSecret(provenancetypes.Secret{
+			ID: id.SignatureKeySecret,
+		})
+	}
 	if id.AuthTokenSecret != "" {
 		c.AddSecret(provenancetypes.Secret{
 			ID:       id.AuthTokenSecret,
@@ -0,0 +1,412 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/offline"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/util/urlutil"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+	// git-lfs does not treat bigger files as pointers
+	lfsMaxPointerSize = 1024
+	lfsMediaType      = "application/vnd.git-lfs+json"
+	lfsBatchSize      = 100
+	lfsConcurrency    = 4
+	// check-attr is called with the paths as arguments
+	lfsCheckAttrBatch = 1000
+)
+
+var lfsOID = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type lfsPointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// parseLFSPointer parses the contents of a Git LFS pointer file. It returns
+// false if dt is not a pointer.
+func parseLFSPointer(dt []byte) (lfsPointer, bool) {
+	var p lfsPointer
+	s := bufio.NewScanner(bytes.NewReader(dt))
+	if !s.Scan() || s.Text() != lfsPointerVersion {
+		return p, false
+	}
+	size := int64(-1)
+	for s.Scan() {
+		k, v, ok := strings.Cut(s.Text(), " ")
+		if !ok {
+			return p, false
+		}
+		switch k {
+		case "oid":
+			oid, ok := strings.CutPrefix(v, "sha256:")
+			if !ok || !lfsOID.MatchString(oid) {
+				return p, false
+			}
+			p.OID = oid
+		case "size":
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				return p, false
+			}
+			size = n
+		}
+	}
+	if p.OID == "" || size < 0 {
+		return p, false
+	}
+	p.Size = size
+	return p, true
+}
+
+// lfsEndpoint returns the URL of the Git LFS server of a remote, as derived
+// by git-lfs when it is not configured.
+func lfsEndpoint(remote string) (*url.URL, error) {
+	gu, err := gitutil.ParseURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	u := &url.URL{Scheme: gu.Scheme, Host: gu.Host, User: gu.User}
+	switch gu.Scheme {
+	case gitutil.HTTPProtocol, gitutil.HTTPSProtocol:
+	case gitutil.SSHProtocol, gitutil.GitProtocol:
+		// the ssh user and port are not valid for https
+		u = &url.URL{Scheme: gitutil.HTTPSProtocol, Host: strings.Split(gu.Host, ":")[0]}
+	default:
+		return nil, errors.Errorf("unsupported protocol %q for Git LFS", gu.Scheme)
+	}
+	p := "/" + strings.Trim(gu.Path, "/")
+	if !strings.HasSuffix(p, ".git") {
+		p += ".git"
+	}
+	u.Path = p + "/info/lfs"
+	return u, nil
+}
+
+// lfsPointerFiles returns the paths, relative to the work tree, of the files
+// that are tracked by Git LFS and are still pointers.
+func lfsPointerFiles(ctx context.Context, git *gitutil.GitCLI, workTree string) (map[string]lfsPointer, error) {
+	candidates := map[string]lfsPointer{}
+	err := filepath.WalkDir(workTree, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if fi.Size() > lfsMaxPointerSize {
+			return nil
+		}
+		dt, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if ptr, ok := parseLFSPointer(dt); ok {
+			rel, err := filepath.Rel(workTree, p)
+			if err != nil {
+				return err
+			}
+			candidates[filepath.ToSlash(rel)] = ptr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search Git LFS pointers")
+	}
+
+	paths := make([]string, 0, len(candidates))
+	for p := range candidates {
+		paths = append(paths, p)
+	}
+	files := map[string]lfsPointer{}
+	for len(paths) > 0 {
+		n := min(len(paths), lfsCheckAttrBatch)
+		dt, err := git.Run(ctx, append([]string{"check-attr", "-z", "filter", "--"}, paths[:n]...)...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check Git LFS attributes")
+		}
+		// output is a sequence of path, attribute and value
+		fields := strings.Split(string(dt), "\x00")
+		for i := 0; i+2 < len(fields); i += 3 {
+			if fields[i+2] == "lfs" {
+				files[fields[i]] = candidates[fields[i]]
+			}
+		}
+		paths = paths[n:]
+	}
+	return files, nil
+}
+
+// lfsStore is the Git LFS object directory of a repository. Objects are
+// stored by their OID with the same layout as git-lfs uses.
+type lfsStore string
+
+func (s lfsStore) path(oid string) string {
+	return filepath.Join(string(s), "objects", oid[0:2], oid[2:4], oid)
+}
+
+func (s lfsStore) has(p lfsPointer) bool {
+	fi, err := os.Stat(s.path(p.OID))
+	return err == nil && fi.Size() == p.Size
+}
+
+// write stores the object read from r after verifying its size and OID.
+func (s lfsStore) write(p lfsPointer, r io.Reader) (retErr error) {
+	tmpDir := filepath.Join(string(s), "tmp")
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.CreateTemp(tmpDir, p.OID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		f.Close()
+		if retErr != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, p.Size+1))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n != p.Size {
+		return errors.Errorf("unexpected size %d for Git LFS object %s, expected %d", n, p.OID, p.Size)
+	}
+	if oid := hex.EncodeToString(h.Sum(nil)); oid != p.OID {
+		return errors.Errorf("Git LFS object %s has unexpected digest %s", p.OID, oid)
+	}
+	if err := f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path(p.OID)), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(f.Name(), s.path(p.OID)))
+}
+
+// smudge replaces the contents of the pointer file at dst with the object.
+func (s lfsStore) smudge(p lfsPointer, dst string) error {
+	src, err := os.Open(s.path(p.OID))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer src.Close()
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(f.Close())
+}
+
+type lfsBatchRequest struct {
+	Operation string       `json:"operation"`
+	Transfers []string     `json:"transfers"`
+	Objects   []lfsPointer `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Transfer string `json:"transfer"`
+	Objects  []struct {
+		lfsPointer
+		Actions struct {
+			Download *lfsAction `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+// fetchLFS replaces the Git LFS pointer files in the work tree of git with
+// the contents of the objects. The objects are cached in store so that they
+// are only downloaded once for a remote. The LFS server is read from the
+// .lfsconfig file of ref, or derived from the remote.
+func (gs *gitSourceHandler) fetchLFS(ctx context.Context, git *gitutil.GitCLI, workTree, ref string, store lfsStore) error {
+	files, err := lfsPointerFiles(ctx, git, workTree)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	var missing []lfsPointer
+	seen := map[string]struct{}{}
+	for _, p := range files {
+		if _, ok := seen[p.OID]; ok || store.has(p) {
+			continue
+		}
+		seen[p.OID] = struct{}{}
+		missing = append(missing, p)
+	}
+
+	if len(missing) > 0 {
+		if offline.IsOffline(ctx) {
+			return errors.Wrapf(offline.ErrNetworkAccess, "%d Git LFS objects of %s are not cached", len(missing), urlutil.RedactCredentials(gs.src.Remote))
+		}
+		endpoint, err := gs.lfsEndpoint(ctx, git, ref)
+		if err != nil {
+			return err
+		}
+		bklog.G(ctx).Debugf("fetching %d Git LFS objects from %s", len(missing), urlutil.RedactCredentials(endpoint.String()))
+		for len(missing) > 0 {
+			n := min(len(missing), lfsBatchSize)
+			if err := gs.downloadLFS(ctx, endpoint, missing[:n], store); err != nil {
+				return err
+			}
+			missing = missing[n:]
+		}
+	}
+
+	for p, ptr := range files {
+		if err := store.smudge(ptr, filepath.Join(workTree, filepath.FromSlash(p))); err != nil {
+			return errors.Wrapf(err, "failed to check out Git LFS object for %s", p)
+		}
+	}
+	return nil
+}
+
+func (gs *gitSourceHandler) lfsEndpoint(ctx context.Context, git *gitutil.GitCLI, ref string) (*url.URL, error) {
+	// fails if there is no .lfsconfig or it does not set the url
+	if dt, err := git.Run(ctx, "config", "--blob", ref+":.lfsconfig", "--get", "lfs.url"); err == nil {
+		u, err := url.Parse(strings.TrimSpace(string(dt)))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid lfs.url in .lfsconfig")
+		}
+		if u.Scheme != gitutil.HTTPProtocol && u.Scheme != gitutil.HTTPSProtocol {
+			return nil, errors.Errorf("unsupported Git LFS url %s", urlutil.RedactCredentials(u.String()))
+		}
+		return u, nil
+	}
+	return lfsEndpoint(gs.src.Remote)
+}
+
+func (gs *gitSourceHandler) downloadLFS(ctx context.Context, endpoint *url.URL, objects []lfsPointer, store lfsStore) error {
+	dt, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   objects,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.JoinPath("objects", "batch").String(), bytes.NewReader(dt))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	gs.setLFSAuth(req)
+
+	resp, err := tracing.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to request Git LFS objects")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to request Git LFS objects from %s: %s", urlutil.RedactCredentials(endpoint.String()), resp.Status)
+	}
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return errors.Wrap(err, "failed to decode Git LFS batch response")
+	}
+	if batch.Transfer != "" && batch.Transfer != "basic" {
+		return errors.Errorf("unsupported Git LFS transfer %q", batch.Transfer)
+	}
+
+	for _, obj := range batch.Objects {
+		if obj.Error != nil {
+			return errors.Errorf("failed to get Git LFS object %s: %s (%d)", obj.OID, obj.Error.Message, obj.Error.Code)
+		}
+		if obj.Actions.Download == nil {
+			return errors.Errorf("Git LFS object %s is not available for download from %s", obj.OID, urlutil.RedactCredentials(endpoint.String()))
+		}
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(lfsConcurrency)
+	for _, obj := range batch.Objects {
+		eg.Go(func() error {
+			return errors.Wrapf(gs.downloadLFSObject(ctx, obj.lfsPointer, obj.Actions.Download, store), "failed to download Git LFS object %s", obj.OID)
+		})
+	}
+	return eg.Wait()
+}
+
+func (gs *gitSourceHandler) downloadLFSObject(ctx context.Context, p lfsPointer, action *lfsAction, store lfsStore) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, action.Href, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("Authorization") == "" {
+		gs.setLFSAuth(req)
+	}
+	resp, err := tracing.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return store.write(p, resp.Body)
+}
+
+// setLFSAuth adds the authorization header of the remote to requests to the
+// same host and scheme. The header is never sent over plain http.
+func (gs *gitSourceHandler) setLFSAuth(req *http.Request) {
+	if gs.authHeader == "" {
+		return
+	}
+	remote, err := gitutil.ParseURL(gs.src.Remote)
+	if err != nil || !strings.EqualFold(remote.Host, req.URL.Host) {
+		return
+	}
+	if req.URL.Scheme != gitutil.HTTPSProtocol || !strings.EqualFold(remote.Scheme, req.URL.Scheme) {
+		return
+	}
+	req.Header.Set("Authorization", gs.authHeader)
+}
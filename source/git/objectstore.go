@@ -0,0 +1,285 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/offline"
+	"github.com/moby/buildkit/util/urlutil"
+	"github.com/pkg/errors"
+	copy "github.com/tonistiigi/fsutil/copy"
+)
+
+// Shared repositories of remotes can store their objects in a shared object
+// store so that forks of the same repository do not keep duplicate objects.
+// The objects directory of a linked repository is a symlink to the objects
+// directory of the store, that is updated every time the store is mounted.
+const (
+	configObjectStore   = "buildkit.objectstore"
+	configObjectStoreID = "buildkit.objectstoreid"
+)
+
+// objectStoreAlias returns the configured alias for remote, if any.
+func (gs *gitSource) objectStoreAlias(remote string) string {
+	u := urlutil.RedactCredentials(remote)
+	aliases := make([]string, 0, len(gs.objectStoreAliases))
+	for alias := range gs.objectStoreAliases {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	for _, alias := range aliases {
+		for _, pattern := range gs.objectStoreAliases[alias] {
+			if ok, _ := path.Match(pattern, u); ok {
+				return alias
+			}
+		}
+	}
+	return ""
+}
+
+// objectStoreKey returns the key of the object store a shared repository is
+// linked to, or should be linked to because of a configured alias.
+func (gs *gitSource) objectStoreKey(ctx context.Context, git *gitutil.GitCLI, remote, filter string) (string, error) {
+	dt, err := git.Run(ctx, "config", "--default", "", "--get", configObjectStore)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read git object store config")
+	}
+	if key := strings.TrimSpace(string(dt)); key != "" {
+		return key, nil
+	}
+	if alias := gs.objectStoreAlias(remote); alias != "" {
+		return objectStoreKey("alias:"+alias, filter), nil
+	}
+	return "", nil
+}
+
+// rootCommitKey returns the key of the object store for the repository that
+// ref belongs to. The root commit is only known if the history of ref has
+// been fetched completely.
+func rootCommitKey(ctx context.Context, git *gitutil.GitCLI, gitDir, ref, filter string) (string, bool) {
+	if isShallow(gitDir) {
+		return "", false
+	}
+	dt, err := git.Run(ctx, "rev-list", "--max-parents=0", ref+"^{commit}")
+	if err != nil {
+		return "", false
+	}
+	roots := strings.Fields(string(dt))
+	if len(roots) == 0 {
+		return "", false
+	}
+	// repositories with multiple roots are keyed by the first one
+	return objectStoreKey("root:"+slices.Min(roots), filter), true
+}
+
+// remoteURLKey returns the key of the object store for a shallow clone of
+// remote. Credentials, the scheme and a ".git" suffix are not part of the key,
+// so that different URLs of the same repository share the store.
+func remoteURLKey(remote, filter string) (string, bool) {
+	u, err := gitutil.ParseURL(remote)
+	if err != nil {
+		return "", false
+	}
+	p := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	return objectStoreKey("remote:"+strings.ToLower(u.Host)+"/"+p, filter), true
+}
+
+func isShallow(gitDir string) bool {
+	_, err := os.Lstat(filepath.Join(gitDir, "shallow"))
+	return err == nil
+}
+
+func objectStoreKey(key, filter string) string {
+	// partial clones are kept apart, see mountRemote
+	if filter != "" {
+		key += "#filter=" + filter
+	}
+	return key
+}
+
+// isLinked returns true if the shared repository at gitDir uses an object
+// store.
+func isLinked(gitDir string) bool {
+	fi, err := os.Lstat(filepath.Join(gitDir, "objects"))
+	return err == nil && fi.Mode()&os.ModeSymlink != 0
+}
+
+// linkObjectStore mounts the object store for key and links the shared
+// repository at gitDir to it. Objects of a repository that was not linked
+// before are moved to the store. If the store was pruned, the refs of the
+// repository are removed as their objects no longer exist.
+//
+// needs to be called with repo lock
+func (gs *gitSource) linkObjectStore(ctx context.Context, git *gitutil.GitCLI, gitDir, key string, g session.Group) (release func() error, retErr error) {
+	gs.locker.Lock(key)
+	defer func() {
+		if retErr != nil {
+			gs.locker.Unlock(key)
+		}
+	}()
+
+	sis, err := searchGitObjectStore(ctx, gs.cache, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to search metadata for git object store %s", key)
+	}
+	var storeRef cache.MutableRef
+	for _, si := range sis {
+		storeRef, err = gs.cache.GetMutable(ctx, si.ID())
+		if err != nil {
+			if errors.Is(err, cache.ErrLocked) {
+				bklog.G(ctx).Warnf("mutable ref for git object store %s %s was locked: %v", key, si.ID(), err)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get mutable ref for git object store %s", key)
+		}
+		break
+	}
+
+	initializeStore := false
+	if storeRef == nil {
+		storeRef, err = gs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithRecordType(client.UsageRecordTypeGitObjects), cache.WithDescription(fmt.Sprintf("git object store for %s", key)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create new mutable for git object store %s", key)
+		}
+		initializeStore = true
+	}
+	defer func() {
+		if retErr != nil {
+			storeRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := storeRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+	lm := snapshot.LocalMounter(mount)
+	storeDir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			lm.Unmount()
+		}
+	}()
+
+	objectsDir := filepath.Join(storeDir, "objects")
+	if initializeStore {
+		for _, dir := range []string{"pack", "info"} {
+			if err := os.MkdirAll(filepath.Join(objectsDir, dir), 0755); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		md := cacheRefMetadata{storeRef}
+		if err := md.setGitObjectStore(key); err != nil {
+			return nil, err
+		}
+	}
+
+	repoObjects := filepath.Join(gitDir, "objects")
+	if isLinked(gitDir) {
+		dt, err := git.Run(ctx, "config", "--default", "", "--get", configObjectStoreID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read git object store config")
+		}
+		if strings.TrimSpace(string(dt)) != storeRef.ID() {
+			if err := resetRefs(gitDir); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		err := copy.Copy(ctx, repoObjects, "/", objectsDir, "/", func(ci *copy.CopyInfo) {
+			ci.CopyDirContents = true
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to move objects to git object store %s", key)
+		}
+	}
+	if err := os.RemoveAll(repoObjects); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.Symlink(objectsDir, repoObjects); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, kv := range [][]string{
+		{configObjectStore, key},
+		{configObjectStoreID, storeRef.ID()},
+	} {
+		if _, err := git.Run(ctx, "config", kv[0], kv[1]); err != nil {
+			return nil, errors.Wrapf(err, "failed to link git object store %s", key)
+		}
+	}
+
+	return func() error {
+		defer gs.locker.Unlock(key)
+		err := lm.Unmount()
+		if err1 := storeRef.Release(context.WithoutCancel(ctx)); err == nil {
+			err = err1
+		}
+		return err
+	}, nil
+}
+
+// checkRemoteCommit checks that the commit sha that exists in the object store
+// of a linked repository can be fetched from the remote of the repository.
+// Fetching a commit that is already in the store does not ask the remote for
+// it, so instead the branches and tags of the remote are fetched and sha has
+// to be reachable from one of them.
+//
+// needs to be called with repo lock
+func (gs *gitSourceHandler) checkRemoteCommit(ctx context.Context, git *gitutil.GitCLI, sha string) error {
+	if ok, err := hasRefContaining(ctx, git, sha); err != nil || ok {
+		return err
+	}
+	if offline.IsOffline(ctx) {
+		return errors.Wrapf(offline.ErrNetworkAccess, "git commit %s of %s", sha, urlutil.RedactCredentials(gs.src.Remote))
+	}
+	if _, err := git.Run(ctx, "fetch", "--tags", "--force", "origin", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return errors.Wrapf(err, "failed to fetch remote %s", urlutil.RedactCredentials(gs.src.Remote))
+	}
+	ok, err := hasRefContaining(ctx, git, sha)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("commit %s is not reachable from the branches or tags of remote %s", sha, urlutil.RedactCredentials(gs.src.Remote))
+	}
+	return nil
+}
+
+// hasRefContaining returns true if sha is reachable from a ref of the
+// repository.
+func hasRefContaining(ctx context.Context, git *gitutil.GitCLI, sha string) (bool, error) {
+	dt, err := git.Run(ctx, "for-each-ref", "--count=1", "--format=%(refname)", "--contains", sha)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list refs containing %s", sha)
+	}
+	return strings.TrimSpace(string(dt)) != "", nil
+}
+
+// resetRefs removes the refs of a shared repository.
+func resetRefs(gitDir string) error {
+	for _, p := range []string{"refs", "packed-refs", "shallow", "FETCH_HEAD"} {
+		if err := os.RemoveAll(filepath.Join(gitDir, p)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, p := range []string{"refs/heads", "refs/tags"} {
+		if err := os.MkdirAll(filepath.Join(gitDir, p), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
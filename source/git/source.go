@@ -1,8 +1,10 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
@@ -12,9 +14,11 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/identity"
@@ -28,9 +32,11 @@ import (
 	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/progress/logs"
 	"github.com/moby/buildkit/util/urlutil"
 	"github.com/moby/locker"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -40,11 +46,15 @@ var defaultBranch = regexp.MustCompile(`refs/heads/(\S+)`)
 
 type Opt struct {
 	CacheAccessor cache.Accessor
+	// ObjectStoreAliases maps aliases to patterns of remote URLs. Remotes
+	// with the same alias share their git objects.
+	ObjectStoreAliases map[string][]string
 }
 
 type gitSource struct {
-	cache  cache.Accessor
-	locker *locker.Locker
+	cache              cache.Accessor
+	locker             *locker.Locker
+	objectStoreAliases map[string][]string
 }
 
 // Supported returns nil if the system supports Git source
@@ -57,8 +67,9 @@ func Supported() error {
 
 func NewSource(opt Opt) (source.Source, error) {
 	gs := &gitSource{
-		cache:  opt.CacheAccessor,
-		locker: locker.New(),
+		cache:              opt.CacheAccessor,
+		locker:             locker.New(),
+		objectStoreAliases: opt.ObjectStoreAliases,
 	}
 	return gs, nil
 }
@@ -94,15 +105,92 @@ func (gs *gitSource) Identifier(scheme, ref string, attrs map[string]string, pla
 			id.MountSSHSock = v
 		case pb.AttrGitChecksum:
 			id.Checksum = v
+		case pb.AttrGitFilter:
+			if err := validateFilter(v); err != nil {
+				return nil, err
+			}
+			id.Filter = v
+		case pb.AttrGitSparsePaths:
+			var paths []string
+			if err := json.Unmarshal([]byte(v), &paths); err != nil {
+				return nil, errors.Wrap(err, "failed to parse git sparse paths")
+			}
+			paths, err := cleanSparsePaths(paths)
+			if err != nil {
+				return nil, err
+			}
+			id.SparsePaths = paths
+		case pb.AttrGitSignatureKeys:
+			id.SignatureKeys = v
+		case pb.AttrGitSignatureKeySecret:
+			id.SignatureKeySecret = v
+		case pb.AttrGitLFS:
+			if v == "true" {
+				id.LFS = true
+			}
 		}
 	}
 
 	return id, nil
 }
 
+var blobLimitFilter = regexp.MustCompile(`^blob:limit=[0-9]+[kmg]?$`)
+
+// validateFilter checks that filter is a partial clone filter that only
+// omits objects that git can fetch again on demand.
+func validateFilter(filter string) error {
+	switch filter {
+	case "blob:none", "tree:0":
+		return nil
+	}
+	if blobLimitFilter.MatchString(filter) {
+		return nil
+	}
+	return errors.Errorf("unsupported git filter %q, expected blob:none, blob:limit=<n> or tree:0", filter)
+}
+
+// cleanSparsePaths returns the sorted, deduplicated sparse paths relative to
+// the repository root.
+func cleanSparsePaths(paths []string) ([]string, error) {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		cleaned := strings.TrimPrefix(path.Clean(path.Join("/", p)), "/")
+		if cleaned == "" {
+			return nil, errors.Errorf("invalid git sparse path %q", p)
+		}
+		out = append(out, cleaned)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// checkSparseSubdir returns an error if subdir is not the root of the
+// repository or inside one of the sparse paths, so that the checkout would not
+// contain it.
+func checkSparseSubdir(subdir string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	subdir = strings.TrimPrefix(path.Clean(path.Join("/", subdir)), "/")
+	if subdir == "" {
+		return nil
+	}
+	for _, p := range paths {
+		if subdir == p || strings.HasPrefix(subdir, p+"/") {
+			return nil
+		}
+	}
+	return errors.Errorf("git subdir %q is not inside the sparse paths %s", subdir, strings.Join(paths, ", "))
+}
+
 // needs to be called with repo lock
-func (gs *gitSource) mountRemote(ctx context.Context, remote string, authArgs []string, g session.Group) (target string, release func() error, retErr error) {
-	sis, err := searchGitRemote(ctx, gs.cache, remote)
+func (gs *gitSource) mountRemote(ctx context.Context, remote, filter string, authArgs []string, g session.Group) (target string, release func() error, retErr error) {
+	// partial clones are kept apart from the full clones of the same remote
+	remoteKey := remote
+	if filter != "" {
+		remoteKey += "#filter=" + filter
+	}
+	sis, err := searchGitRemote(ctx, gs.cache, remoteKey)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "failed to search metadata for %s", urlutil.RedactCredentials(remote))
 	}
@@ -175,9 +263,25 @@ func (gs *gitSource) mountRemote(ctx context.Context, remote string, authArgs []
 			return "", nil, errors.Wrapf(err, "failed add origin repo at %s", dir)
 		}
 
+		if filter != "" {
+			// fetches from origin use the filter and missing objects are
+			// fetched on demand. uploadpack options allow cloning the partial
+			// repo for keeping the .git directory.
+			for _, kv := range [][]string{
+				{"remote.origin.promisor", "true"},
+				{"remote.origin.partialclonefilter", filter},
+				{"uploadpack.allowFilter", "true"},
+				{"uploadpack.allowAnySHA1InWant", "true"},
+			} {
+				if _, err := git.Run(ctx, "config", kv[0], kv[1]); err != nil {
+					return "", nil, errors.Wrapf(err, "failed to configure partial clone at %s", dir)
+				}
+			}
+		}
+
 		// save new remote metadata
 		md := cacheRefMetadata{remoteRef}
-		if err := md.setGitRemote(remote); err != nil {
+		if err := md.setGitRemote(remoteKey); err != nil {
 			return "", nil, err
 		}
 	}
@@ -193,9 +297,13 @@ func (gs *gitSource) mountRemote(ctx context.Context, remote string, authArgs []
 type gitSourceHandler struct {
 	*gitSource
 	src      GitIdentifier
+	id       *GitIdentifier
 	cacheKey string
 	sm       *session.Manager
 	authArgs []string
+	// authHeader is the authorization header in authArgs, used for Git LFS
+	authHeader string
+	sigKeys    []byte
 }
 
 func (gs *gitSourceHandler) shaToCacheKey(sha, ref string) string {
@@ -206,6 +314,15 @@ func (gs *gitSourceHandler) shaToCacheKey(sha, ref string) string {
 			key += "#" + ref
 		}
 	}
+	if len(gs.sigKeys) > 0 {
+		key += ";sig=" + digest.FromBytes(gs.sigKeys).Encoded()
+	}
+	if len(gs.src.SparsePaths) > 0 {
+		key += ";sparse=" + digest.FromString(strings.Join(gs.src.SparsePaths, "\n")).Encoded()
+	}
+	if gs.src.LFS {
+		key += ";lfs"
+	}
 	if gs.src.Subdir != "" {
 		key += ":" + gs.src.Subdir
 	}
@@ -220,6 +337,7 @@ func (gs *gitSource) Resolve(ctx context.Context, id source.Identifier, sm *sess
 
 	return &gitSourceHandler{
 		src:       *gitIdentifier,
+		id:        gitIdentifier,
 		gitSource: gs,
 		sm:        sm,
 	}, nil
@@ -272,7 +390,8 @@ func (gs *gitSourceHandler) getAuthToken(ctx context.Context, g session.Group) e
 			if s.token {
 				dt = []byte("basic " + base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "x-access-token:%s", dt)))
 			}
-			gs.authArgs = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + string(dt)}
+			gs.authHeader = string(dt)
+			gs.authArgs = []string{"-c", "http." + tokenScope(gs.src.Remote) + ".extraheader=Authorization: " + gs.authHeader}
 			break
 		}
 		return err
@@ -283,6 +402,68 @@ func (gs *gitSourceHandler) getAuthToken(ctx context.Context, g session.Group) e
 	return err
 }
 
+// getSignatureKeys loads the public keys that the signature of the ref is
+// verified against.
+func (gs *gitSourceHandler) getSignatureKeys(ctx context.Context, g session.Group) error {
+	if gs.sigKeys != nil {
+		return nil
+	}
+	keys := []byte(gs.src.SignatureKeys)
+	if gs.src.SignatureKeySecret != "" {
+		err := gs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+			dt, err := secrets.GetSecret(ctx, caller, gs.src.SignatureKeySecret)
+			if err != nil {
+				return err
+			}
+			keys = append(append(keys, '\n'), dt...)
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get git signature keys from secret %s", gs.src.SignatureKeySecret)
+		}
+	}
+	if len(bytes.TrimSpace(keys)) == 0 {
+		keys = []byte{}
+	}
+	gs.sigKeys = keys
+	return nil
+}
+
+// verifySignature checks that the commit, or the annotated tag, that ref
+// points to is signed by one of the keys.
+func verifySignature(ctx context.Context, git *gitutil.GitCLI, ref string, keys []byte) (*gitutil.Signer, error) {
+	var errs error
+	buf, err := git.Run(ctx, "cat-file", "-t", ref)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(buf)) == "tag" {
+		signer, err := verifyObjectSignature(ctx, git, "tag", ref, keys)
+		if err == nil {
+			return signer, nil
+		}
+		errs = multierror.Append(errs, errors.Wrapf(err, "tag %s", ref))
+	}
+	signer, err := verifyObjectSignature(ctx, git, "commit", ref+"^{commit}", keys)
+	if err == nil {
+		return signer, nil
+	}
+	errs = multierror.Append(errs, errors.Wrapf(err, "commit %s", ref))
+	return nil, errors.Wrapf(errs, "failed to verify signature of %s", ref)
+}
+
+func verifyObjectSignature(ctx context.Context, git *gitutil.GitCLI, typ, ref string, keys []byte) (*gitutil.Signer, error) {
+	raw, err := git.Run(ctx, "cat-file", typ, ref)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := gitutil.ParseSignedObject(typ, raw)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Verify(ctx, keys)
+}
+
 func (gs *gitSourceHandler) mountSSHAuthSock(ctx context.Context, sshID string, g session.Group) (string, func() error, error) {
 	var caller session.Caller
 	err := gs.sm.Any(ctx, g, func(ctx context.Context, _ string, c session.Caller) error {
@@ -358,6 +539,15 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, g session.Group, index
 		}
 	}
 
+	if gs.src.SignatureKeys != "" || gs.src.SignatureKeySecret != "" {
+		if err := gs.getSignatureKeys(ctx, g); err != nil {
+			return "", "", nil, false, err
+		}
+		if len(gs.sigKeys) == 0 {
+			return "", "", nil, false, errors.Errorf("no public keys to verify the signature of %s", urlutil.RedactCredentials(remote))
+		}
+	}
+
 	var refCommitFullHash, ref2 string
 	if gitutil.IsCommitSHA(gs.src.Checksum) && !gs.src.KeepGitDir {
 		refCommitFullHash = gs.src.Checksum
@@ -369,10 +559,15 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, g session.Group, index
 	if refCommitFullHash != "" {
 		cacheKey := gs.shaToCacheKey(refCommitFullHash, ref2)
 		gs.cacheKey = cacheKey
+		gs.loadSigner(ctx, cacheKey)
 		// gs.src.Checksum is verified when checking out the commit
 		return cacheKey, refCommitFullHash, nil, true, nil
 	}
 
+	if offline.IsOffline(ctx) {
+		return "", "", nil, false, errors.Wrapf(offline.ErrNetworkAccess, "git source %s is not pinned to a commit", urlutil.RedactCredentials(remote))
+	}
+
 	gs.getAuthToken(ctx, g)
 
 	git, cleanup, err := gs.gitCli(ctx, g)
@@ -438,10 +633,33 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, g session.Group, index
 	}
 	cacheKey := gs.shaToCacheKey(sha, usedRef)
 	gs.cacheKey = cacheKey
+	gs.loadSigner(ctx, cacheKey)
 	return cacheKey, sha, nil, true, nil
 }
 
+// loadSigner sets the signer of the identifier from the snapshot of cacheKey
+// if it has been checked out before. The snapshot is not loaded again when the
+// build result is found in the solver cache, so this keeps the signer in the
+// provenance of cached builds.
+func (gs *gitSourceHandler) loadSigner(ctx context.Context, cacheKey string) {
+	if len(gs.sigKeys) == 0 {
+		return
+	}
+	sis, err := searchGitSnapshot(ctx, gs.cache, cacheKey+":"+gs.src.Subdir)
+	if err != nil {
+		bklog.G(ctx).WithError(err).Debugf("failed to search git snapshot for signer of %s", urlutil.RedactCredentials(gs.src.Remote))
+		return
+	}
+	if len(sis) > 0 {
+		gs.id.signer = sis[0].getGitSigner()
+	}
+}
+
 func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out cache.ImmutableRef, retErr error) {
+	if err := checkSparseSubdir(gs.src.Subdir, gs.src.SparsePaths); err != nil {
+		return nil, err
+	}
+
 	cacheKey := gs.cacheKey
 	if cacheKey == "" {
 		var err error
@@ -462,6 +680,9 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		return nil, errors.Wrapf(err, "failed to search metadata for %s", snapshotKey)
 	}
 	if len(sis) > 0 {
+		if len(gs.sigKeys) > 0 {
+			gs.id.signer = sis[0].getGitSigner()
+		}
 		return gs.cache.Get(ctx, sis[0].ID(), nil)
 	}
 
@@ -491,6 +712,14 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		// skip fetch if commit already exists
 		if _, err := git.Run(ctx, "cat-file", "-e", ref+"^{commit}"); err == nil {
 			doFetch = false
+			// the objects of a shared object store may have been fetched
+			// from another remote, so the commit needs to be reachable from
+			// the refs of this remote
+			if isLinked(gitDir) {
+				if err := gs.checkRemoteCommit(ctx, git, ref); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
@@ -503,13 +732,15 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 			args = append(args, "--depth=1", "--no-tags")
 		} else {
 			args = append(args, "--tags")
-			if _, err := os.Lstat(filepath.Join(gitDir, "shallow")); err == nil {
+			if isShallow(gitDir) {
 				args = append(args, "--unshallow")
 			}
 		}
 		args = append(args, "origin")
 		if gitutil.IsCommitSHA(ref) {
-			args = append(args, ref)
+			// the ref records that the commit was fetched from this remote,
+			// see checkRemoteCommit
+			args = append(args, ref+":refs/buildkit/commits/"+ref)
 		} else {
 			// local refs are needed so they would be advertised on next fetches. Force is used
 			// in case the ref is a branch and it now points to a different commit sha
@@ -525,6 +756,32 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		}
 	}
 
+	if !isLinked(gitDir) {
+		// a repository stays linked to the store it was linked to first, so a
+		// remote that is cloned shallow first only shares objects with other
+		// URLs of the same repository, not with its forks
+		key, ok := rootCommitKey(ctx, git, gitDir, ref, gs.src.Filter)
+		if !ok && isShallow(gitDir) {
+			key, ok = remoteURLKey(gs.src.Remote, gs.src.Filter)
+		}
+		if ok {
+			releaseStore, err := gs.linkObjectStore(ctx, git, gitDir, key, g)
+			if err != nil {
+				return nil, err
+			}
+			defer releaseStore()
+		}
+	}
+
+	var signer *gitutil.Signer
+	if len(gs.sigKeys) > 0 {
+		signer, err = verifySignature(ctx, git, ref, gs.sigKeys)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to verify %s", urlutil.RedactCredentials(gs.src.Remote))
+		}
+		bklog.G(ctx).Debugf("verified %s#%s signed by %s key %s", urlutil.RedactCredentials(gs.src.Remote), ref, signer.Type, signer.Fingerprint)
+	}
+
 	checkoutRef, err := gs.cache.New(ctx, nil, g, cache.WithRecordType(client.UsageRecordTypeGitCheckout), cache.WithDescription(fmt.Sprintf("git snapshot for %s#%s", urlutil.RedactCredentials(gs.src.Remote), ref)))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create new mutable for %s", urlutil.RedactCredentials(gs.src.Remote))
@@ -567,6 +824,9 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		}
 	}
 
+	// LFS objects are cached in the shared repo of the remote
+	lfs := lfsStore(filepath.Join(gitDir, "lfs"))
+
 	if gs.src.KeepGitDir && subdir == "." {
 		checkoutDirGit := filepath.Join(checkoutDir, ".git")
 		if err := os.MkdirAll(checkoutDir, 0711); err != nil {
@@ -603,14 +863,36 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		} else {
 			pullref += ":" + pullref
 		}
-		_, err = checkoutGit.Run(ctx, "fetch", "-u", "--depth=1", "origin", pullref)
+		fetchArgs := []string{"fetch", "-u", "--depth=1"}
+		if gs.src.Filter != "" {
+			fetchArgs = append(fetchArgs, "--filter="+gs.src.Filter)
+		}
+		_, err = checkoutGit.Run(ctx, append(fetchArgs, "origin", pullref)...)
 		if err != nil {
 			return nil, err
 		}
+		if len(gs.src.SparsePaths) > 0 {
+			if err := writeSparseCheckout(ctx, checkoutGit, checkoutDirGit, gs.src.SparsePaths); err != nil {
+				return nil, err
+			}
+		}
+		if gs.src.Filter != "" {
+			// git does not fetch missing objects through the local clone,
+			// so they are fetched from the remote directly
+			_, err = checkoutGit.Run(ctx, "remote", "set-url", "origin", gs.src.Remote)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to set remote origin to %s", urlutil.RedactCredentials(gs.src.Remote))
+			}
+		}
 		_, err = checkoutGit.Run(ctx, "checkout", "FETCH_HEAD")
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
+		if gs.src.LFS {
+			if err := gs.fetchLFS(ctx, checkoutGit, checkoutDir, "FETCH_HEAD", lfs); err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch Git LFS objects for %s", urlutil.RedactCredentials(gs.src.Remote))
+			}
+		}
 		_, err = checkoutGit.Run(ctx, "remote", "set-url", "origin", urlutil.RedactCredentials(gs.src.Remote))
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to set remote origin to %s", urlutil.RedactCredentials(gs.src.Remote))
@@ -632,10 +914,15 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 			}
 		}
 		checkoutGit := git.New(gitutil.WithWorkTree(cd), gitutil.WithGitDir(gitDir))
-		_, err = checkoutGit.Run(ctx, "checkout", ref, "--", ".")
+		_, err = checkoutGit.Run(ctx, append([]string{"checkout", ref, "--"}, gs.pathspecs()...)...)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
+		if gs.src.LFS {
+			if err := gs.fetchLFS(ctx, checkoutGit, cd, ref, lfs); err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch Git LFS objects for %s", urlutil.RedactCredentials(gs.src.Remote))
+			}
+		}
 		if subdir != "." {
 			d, err := os.Open(filepath.Join(cd, subdir))
 			if err != nil {
@@ -666,7 +953,7 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 	}
 
 	git = git.New(gitutil.WithWorkTree(checkoutDir), gitutil.WithGitDir(gitDir))
-	_, err = git.Run(ctx, "submodule", "update", "--init", "--recursive", "--depth=1")
+	_, err = git.Run(ctx, append([]string{"submodule", "update", "--init", "--recursive", "--depth=1", "--"}, gs.pathspecs()...)...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to update submodules for %s", urlutil.RedactCredentials(gs.src.Remote))
 	}
@@ -700,9 +987,43 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 	if err := md.setGitSnapshot(snapshotKey); err != nil {
 		return nil, err
 	}
+	if signer != nil {
+		if err := md.setGitSigner(signer); err != nil {
+			return nil, err
+		}
+		gs.id.signer = signer
+	}
 	return snap, nil
 }
 
+// pathspecs returns the pathspecs of the paths to check out.
+func (gs *gitSourceHandler) pathspecs() []string {
+	if len(gs.src.SparsePaths) == 0 {
+		return []string{"."}
+	}
+	pathspecs := make([]string, len(gs.src.SparsePaths))
+	for i, p := range gs.src.SparsePaths {
+		pathspecs[i] = ":(literal)" + p
+	}
+	return pathspecs
+}
+
+// writeSparseCheckout enables sparse checkout for the paths in a repository
+// that has not been checked out yet.
+func writeSparseCheckout(ctx context.Context, git *gitutil.GitCLI, gitDir string, paths []string) error {
+	if _, err := git.Run(ctx, "config", "core.sparseCheckout", "true"); err != nil {
+		return errors.Wrap(err, "failed to enable sparse checkout")
+	}
+	var patterns strings.Builder
+	for _, p := range paths {
+		patterns.WriteString("/" + p + "\n")
+	}
+	if err := os.MkdirAll(filepath.Join(gitDir, "info"), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(gitDir, "info", "sparse-checkout"), []byte(patterns.String()), 0644))
+}
+
 func (gs *gitSourceHandler) gitCli(ctx context.Context, g session.Group, opts ...gitutil.Option) (*gitutil.GitCLI, func() error, error) {
 	var cleanups []func() error
 	cleanup := func() error {
@@ -717,13 +1038,28 @@ func (gs *gitSourceHandler) gitCli(ctx context.Context, g session.Group, opts ..
 	}
 	var err error
 
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, gs.authArgs, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, gs.src.Filter, gs.authArgs, g)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
 	cleanups = append(cleanups, unmountGitDir)
 
+	git := gitCLI(gitutil.WithGitDir(gitDir))
+	storeKey, err := gs.objectStoreKey(ctx, git, gs.src.Remote, gs.src.Filter)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if storeKey != "" {
+		releaseStore, err := gs.linkObjectStore(ctx, git, gitDir, storeKey, g)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		cleanups = append(cleanups, releaseStore)
+	}
+
 	var sock string
 	if gs.src.MountSSHSock != "" {
 		var unmountSock func() error
@@ -785,6 +1121,9 @@ const (
 	gitRemoteIndex   = keyGitRemote + "::"
 	keyGitSnapshot   = "git-snapshot"
 	gitSnapshotIndex = keyGitSnapshot + "::"
+	keyGitObjects    = "git-objects"
+	gitObjectsIndex  = keyGitObjects + "::"
+	keyGitSigner     = "git-signer"
 )
 
 func search(ctx context.Context, store cache.MetadataStore, key string, idx string) ([]cacheRefMetadata, error) {
@@ -807,6 +1146,10 @@ func searchGitSnapshot(ctx context.Context, store cache.MetadataStore, key strin
 	return search(ctx, store, key, gitSnapshotIndex)
 }
 
+func searchGitObjectStore(ctx context.Context, store cache.MetadataStore, key string) ([]cacheRefMetadata, error) {
+	return search(ctx, store, key, gitObjectsIndex)
+}
+
 type cacheRefMetadata struct {
 	cache.RefMetadata
 }
@@ -819,6 +1162,30 @@ func (md cacheRefMetadata) setGitRemote(key string) error {
 	return md.SetString(keyGitRemote, key, gitRemoteIndex+key)
 }
 
+func (md cacheRefMetadata) setGitObjectStore(key string) error {
+	return md.SetString(keyGitObjects, key, gitObjectsIndex+key)
+}
+
+func (md cacheRefMetadata) setGitSigner(signer *gitutil.Signer) error {
+	dt, err := json.Marshal(signer)
+	if err != nil {
+		return err
+	}
+	return md.SetString(keyGitSigner, string(dt), "")
+}
+
+func (md cacheRefMetadata) getGitSigner() *gitutil.Signer {
+	dt := md.GetString(keyGitSigner)
+	if dt == "" {
+		return nil
+	}
+	var signer gitutil.Signer
+	if err := json.Unmarshal([]byte(dt), &signer); err != nil {
+		return nil
+	}
+	return &signer
+}
+
 func gitCLI(opts ...gitutil.Option) *gitutil.GitCLI {
 	opts = append([]gitutil.Option{
 		gitutil.WithExec(runWithStandardUmask),
@@ -3,17 +3,21 @@ package git
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/cgi"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/containerd/containerd/v2/core/diff/apply"
@@ -28,6 +32,8 @@ import (
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/snapshot"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/source"
 	"github.com/moby/buildkit/util/gitutil"
 	"github.com/moby/buildkit/util/leaseutil"
@@ -806,7 +812,532 @@ func testSubdir(t *testing.T, keepGitDir bool) {
 	require.Equal(t, "abc\n", string(dt))
 }
 
+func TestSparsePaths(t *testing.T) {
+	testSparsePaths(t, false)
+}
+
+func TestSparsePathsKeepGitDir(t *testing.T) {
+	testSparsePaths(t, true)
+}
+
+func testSparsePaths(t *testing.T, keepGitDir bool) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+
+	ctx := logProgressStreams(context.Background(), t)
+
+	gs := setupGitSource(t, t.TempDir())
+
+	repodir := t.TempDir()
+
+	runShell(t, repodir,
+		"git -c init.defaultBranch=master init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"git config --local uploadpack.allowFilter true",
+		"echo foo > abc",
+		"mkdir sub other",
+		"echo abc > sub/bar",
+		"echo def > other/baz",
+		"git add abc sub other",
+		"git commit -m initial",
+	)
+
+	repoURL := serveGitRepo(t, repodir)
+
+	g, err := gs.Resolve(ctx, &GitIdentifier{Remote: repoURL, KeepGitDir: keepGitDir}, nil, nil)
+	require.NoError(t, err)
+	fullKey, _, _, _, err := g.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	id := &GitIdentifier{Remote: repoURL, KeepGitDir: keepGitDir, Filter: "blob:none", SparsePaths: []string{"sub"}}
+	g, err = gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	key1, _, _, done, err := g.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.NotEqual(t, fullKey, key1)
+
+	ref1, err := g.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref1.Release(context.TODO())
+
+	mount, err := ref1.Mount(ctx, true, nil)
+	require.NoError(t, err)
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	require.NoError(t, err)
+	defer lm.Unmount()
+
+	dt, err := os.ReadFile(filepath.Join(dir, "sub/bar"))
+	require.NoError(t, err)
+	require.Equal(t, "abc\n", string(dt))
+
+	_, err = os.Lstat(filepath.Join(dir, "abc"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Lstat(filepath.Join(dir, "other"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	if keepGitDir {
+		git := gitutil.NewGitCLI(
+			gitutil.WithExec(runWithStandardUmask),
+			gitutil.WithWorkTree(dir),
+		)
+		dt, err := git.Run(ctx, "config", "remote.origin.promisor")
+		require.NoError(t, err)
+		require.Equal(t, "true", strings.TrimSpace(string(dt)))
+	}
+
+	// a subdir outside of the sparse paths is not checked out
+	id = &GitIdentifier{Remote: repoURL, KeepGitDir: keepGitDir, Filter: "blob:none", SparsePaths: []string{"sub"}, Subdir: "other"}
+	g, err = gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, err = g.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "not inside the sparse paths")
+}
+
+func TestCheckSparseSubdir(t *testing.T) {
+	paths := []string{"docs", "frontend/dockerfile"}
+	require.NoError(t, checkSparseSubdir("docs", paths))
+	require.NoError(t, checkSparseSubdir("/frontend/dockerfile/docs/", paths))
+	require.NoError(t, checkSparseSubdir("other", nil))
+	require.Error(t, checkSparseSubdir("frontend", paths))
+	require.Error(t, checkSparseSubdir("docsite", paths))
+	require.NoError(t, checkSparseSubdir("/", paths))
+}
+
+func TestCleanSparsePaths(t *testing.T) {
+	paths, err := cleanSparsePaths([]string{"b/", "/a", "./b", "a/../c"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, paths)
+
+	_, err = cleanSparsePaths([]string{"../"})
+	require.Error(t, err)
+}
+
+func TestValidateFilter(t *testing.T) {
+	for _, f := range []string{"blob:none", "tree:0", "blob:limit=1k"} {
+		require.NoError(t, validateFilter(f), f)
+	}
+	for _, f := range []string{"", "tree:1", "sparse:oid=abc", "blob:limit=foo"} {
+		require.Error(t, validateFilter(f), f)
+	}
+}
+
+func TestLFS(t *testing.T) {
+	testLFS(t, false)
+}
+
+func TestLFSKeepGitDir(t *testing.T) {
+	testLFS(t, true)
+}
+
+func testLFS(t *testing.T, keepGitDir bool) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+
+	ctx := logProgressStreams(context.Background(), t)
+
+	gs := setupGitSource(t, t.TempDir())
+
+	content := "lfs contents\n"
+	oid := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+
+	var downloads atomic.Int32
+	mux := http.NewServeMux()
+	lfsServer := httptest.NewServer(mux)
+	t.Cleanup(lfsServer.Close)
+	mux.HandleFunc("POST /lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req lfsBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := map[string]any{"transfer": "basic"}
+		var objects []map[string]any
+		for _, obj := range req.Objects {
+			objects = append(objects, map[string]any{
+				"oid":  obj.OID,
+				"size": obj.Size,
+				"actions": map[string]any{
+					"download": map[string]any{"href": lfsServer.URL + "/objects/" + obj.OID},
+				},
+			})
+		}
+		resp["objects"] = objects
+		w.Header().Set("Content-Type", lfsMediaType)
+		json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("GET /objects/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("oid") != oid {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		io.WriteString(w, content)
+	})
+
+	repodir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "data.bin"), []byte(pointer), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repodir, "pointer.txt"), []byte(pointer), 0644))
+
+	runShell(t, repodir,
+		"git -c init.defaultBranch=master init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"echo '*.bin filter=lfs diff=lfs merge=lfs -text' > .gitattributes",
+		"git config -f .lfsconfig lfs.url "+lfsServer.URL+"/lfs",
+		"git add .gitattributes .lfsconfig data.bin pointer.txt",
+		"git commit -m initial",
+		"git tag --no-sign v1",
+		"echo foo > abc",
+		"git add abc",
+		"git commit -m second",
+		"git tag --no-sign v2",
+	)
+
+	repoURL := serveGitRepo(t, repodir)
+
+	readFiles := func(ref string, lfs bool) (string, string) {
+		id := &GitIdentifier{Remote: repoURL, Ref: ref, KeepGitDir: keepGitDir, LFS: lfs}
+		g, err := gs.Resolve(ctx, id, nil, nil)
+		require.NoError(t, err)
+		ref1, err := g.Snapshot(ctx, nil)
+		require.NoError(t, err)
+		defer ref1.Release(context.TODO())
+
+		mount, err := ref1.Mount(ctx, true, nil)
+		require.NoError(t, err)
+		lm := snapshot.LocalMounter(mount)
+		dir, err := lm.Mount()
+		require.NoError(t, err)
+		defer lm.Unmount()
+
+		data, err := os.ReadFile(filepath.Join(dir, "data.bin"))
+		require.NoError(t, err)
+		txt, err := os.ReadFile(filepath.Join(dir, "pointer.txt"))
+		require.NoError(t, err)
+		return string(data), string(txt)
+	}
+
+	data, txt := readFiles("v1", false)
+	require.Equal(t, pointer, data)
+	require.Equal(t, pointer, txt)
+	require.Equal(t, int32(0), downloads.Load())
+
+	data, txt = readFiles("v1", true)
+	require.Equal(t, content, data)
+	require.Equal(t, pointer, txt) // not tracked by LFS
+	require.Equal(t, int32(1), downloads.Load())
+
+	// objects are cached by OID
+	data, _ = readFiles("v2", true)
+	require.Equal(t, content, data)
+	require.Equal(t, int32(1), downloads.Load())
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	oid := strings.Repeat("a", 64)
+	p, ok := parseLFSPointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12\n"))
+	require.True(t, ok)
+	require.Equal(t, lfsPointer{OID: oid, Size: 12}, p)
+
+	_, ok = parseLFSPointer([]byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12\n"))
+	require.False(t, ok)
+	_, ok = parseLFSPointer([]byte("oid sha256:" + oid + "\nsize 12\n"))
+	require.False(t, ok)
+}
+
+func TestLFSEndpoint(t *testing.T) {
+	for remote, expected := range map[string]string{
+		"https://github.com/moby/buildkit":      "https://github.com/moby/buildkit.git/info/lfs",
+		"https://github.com/moby/buildkit.git/": "https://github.com/moby/buildkit.git/info/lfs",
+		"git@github.com:moby/buildkit.git":      "https://github.com/moby/buildkit.git/info/lfs",
+		"ssh://git@example.com:2222/repo":       "https://example.com/repo.git/info/lfs",
+	} {
+		u, err := lfsEndpoint(remote)
+		require.NoError(t, err, remote)
+		require.Equal(t, expected, u.String(), remote)
+	}
+}
+
+func TestSetLFSAuth(t *testing.T) {
+	gs := &gitSourceHandler{
+		src:        GitIdentifier{Remote: "https://example.com/repo.git"},
+		authHeader: "basic dG9rZW4=",
+	}
+	for u, expected := range map[string]bool{
+		"https://example.com/repo.git/info/lfs/objects/batch": true,
+		"https://EXAMPLE.com/objects/abc":                     true,
+		"http://example.com/repo.git/info/lfs/objects/batch":  false,
+		"https://lfs.example.com/objects/abc":                 false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+		gs.setLFSAuth(req)
+		require.Equal(t, expected, req.Header.Get("Authorization") != "", u)
+	}
+
+	// credentials of a plain http remote are not sent to the LFS server
+	gs.src = GitIdentifier{Remote: "http://example.com/repo.git"}
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/repo.git/info/lfs/objects/batch", nil)
+	require.NoError(t, err)
+	gs.setLFSAuth(req)
+	require.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestLFSObjectNotAvailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req lfsBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var objects []map[string]any
+		for _, obj := range req.Objects {
+			objects = append(objects, map[string]any{"oid": obj.OID, "size": obj.Size})
+		}
+		w.Header().Set("Content-Type", lfsMediaType)
+		json.NewEncoder(w).Encode(map[string]any{"transfer": "basic", "objects": objects})
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	oid := strings.Repeat("a", 64)
+	gs := &gitSourceHandler{}
+	err = gs.downloadLFS(context.TODO(), endpoint, []lfsPointer{{OID: oid, Size: 12}}, lfsStore(t.TempDir()))
+	require.ErrorContains(t, err, "Git LFS object "+oid+" is not available")
+}
+
+func TestObjectStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+
+	t.Parallel()
+
+	ctx := logProgressStreams(context.Background(), t)
+	ctx = namespaces.WithNamespace(ctx, "buildkit")
+
+	root := t.TempDir()
+	srv := serveGitRepo(t, root)
+	upstreamURL, forkURL, privateURL := srv+"/upstream", srv+"/fork", srv+"/private"
+
+	runShell(t, root,
+		"git -c init.defaultBranch=master init upstream",
+		"git -C upstream config --local user.email test",
+		"git -C upstream config --local user.name test",
+		"echo foo > upstream/abc",
+		"git -C upstream add abc",
+		"git -C upstream commit -m initial",
+		"git clone -q upstream fork",
+		"git -C fork config --local user.email test",
+		"git -C fork config --local user.name test",
+		"echo bar > fork/def",
+		"git -C fork add def",
+		"git -C fork commit -m fork",
+		"git clone -q upstream private",
+		"git -C private config --local user.email test",
+		"git -C private config --local user.name test",
+		"echo baz > private/secret",
+		"git -C private add secret",
+		"git -C private commit -m private",
+	)
+	revParse := func(repo string) string {
+		cmd := exec.Command("git", "rev-parse", "HEAD")
+		cmd.Dir = filepath.Join(root, repo)
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	upstreamSHA, forkSHA, privateSHA := revParse("upstream"), revParse("fork"), revParse("private")
+
+	objectStores := func(cm cache.Manager) []*client.UsageInfo {
+		du, err := cm.DiskUsage(ctx, client.DiskUsageInfo{Filter: []string{"type==" + string(client.UsageRecordTypeGitObjects)}})
+		require.NoError(t, err)
+		return du
+	}
+	snapshot := func(gs source.Source, remote, ref string, keepGitDir bool) error {
+		g, err := gs.Resolve(ctx, &GitIdentifier{Remote: remote, Ref: ref, KeepGitDir: keepGitDir}, nil, nil)
+		require.NoError(t, err)
+		ref1, err := g.Snapshot(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ref1.Release(context.TODO())
+	}
+
+	// remotes with the same alias share an object store
+	gs, cm := setupGitSourceWithOpt(t, t.TempDir(), Opt{
+		ObjectStoreAliases: map[string][]string{"project": {srv + "/*"}},
+	})
+	require.NoError(t, snapshot(gs, upstreamURL, "master", false))
+	require.NoError(t, snapshot(gs, forkURL, "master", false))
+	require.Len(t, objectStores(cm), 1)
+
+	// the refs of the remotes are reset after the store has been pruned
+	require.NoError(t, cm.Prune(ctx, nil, client.PruneInfo{Filter: []string{"type==" + string(client.UsageRecordTypeGitObjects)}}))
+	require.Empty(t, objectStores(cm))
+	require.NoError(t, snapshot(gs, forkURL, forkSHA, true))
+	require.Len(t, objectStores(cm), 1)
+
+	// commits in the store that were fetched from another remote are only
+	// used if the remote has them
+	require.NoError(t, snapshot(gs, privateURL, privateSHA, false))
+	require.NoError(t, snapshot(gs, forkURL, upstreamSHA, false))
+	err := snapshot(gs, forkURL, privateSHA, false)
+	require.ErrorContains(t, err, "is not reachable")
+	require.NoError(t, snapshot(gs, privateURL, privateSHA, true))
+
+	// without an alias, remotes are linked by their root commit once the full
+	// history has been fetched
+	gs, cm = setupGitSourceWithOpt(t, t.TempDir(), Opt{})
+	require.NoError(t, snapshot(gs, upstreamURL, upstreamSHA, false))
+	require.Len(t, objectStores(cm), 1)
+	require.NoError(t, snapshot(gs, forkURL, forkSHA, false))
+	require.Len(t, objectStores(cm), 1)
+
+	// shallow clones are linked by their remote URL
+	gs, cm = setupGitSourceWithOpt(t, t.TempDir(), Opt{})
+	require.NoError(t, snapshot(gs, forkURL, "master", false))
+	require.Len(t, objectStores(cm), 1)
+	require.NoError(t, snapshot(gs, strings.Replace(forkURL, "://", "://user@", 1), "master", false))
+	require.Len(t, objectStores(cm), 1)
+	require.NoError(t, snapshot(gs, upstreamURL, "master", false))
+	require.Len(t, objectStores(cm), 2)
+}
+
+func TestSignatureVerification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Depends on unimplemented containerd bind-mount support on Windows")
+	}
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	ctx := logProgressStreams(context.Background(), t)
+
+	gs := setupGitSource(t, t.TempDir())
+
+	keydir := t.TempDir()
+	runShell(t, keydir,
+		"ssh-keygen -q -t ed25519 -N '' -f signer",
+		"ssh-keygen -q -t ed25519 -N '' -f other",
+	)
+	signerKey, err := os.ReadFile(filepath.Join(keydir, "signer.pub"))
+	require.NoError(t, err)
+	otherKey, err := os.ReadFile(filepath.Join(keydir, "other.pub"))
+	require.NoError(t, err)
+
+	repodir := t.TempDir()
+	runShell(t, repodir,
+		"git -c init.defaultBranch=master init",
+		"git config --local user.email test",
+		"git config --local user.name test",
+		"git config --local gpg.format ssh",
+		"git config --local user.signingkey "+filepath.Join(keydir, "signer"),
+		"echo foo > abc",
+		"git add abc",
+		"git commit -S -m signed",
+		"git tag -s -m signed v1",
+		"echo bar > def",
+		"git add def",
+		"git commit --no-gpg-sign -m unsigned",
+		"git tag --no-sign unsigned",
+	)
+	repoURL := serveGitRepo(t, repodir)
+
+	var id *GitIdentifier
+	snapshot := func(ref string, keys []byte) error {
+		id = &GitIdentifier{Remote: repoURL, Ref: ref, SignatureKeys: string(keys)}
+		g, err := gs.Resolve(ctx, id, nil, nil)
+		require.NoError(t, err)
+		ref1, err := g.Snapshot(ctx, nil)
+		if err != nil {
+			return err
+		}
+		return ref1.Release(context.TODO())
+	}
+	signer := func() *provenancetypes.GitSignature {
+		var c provenance.Capture
+		require.NoError(t, id.Capture(&c, "sha"))
+		require.Len(t, c.Sources.Git, 1)
+		require.NotNil(t, c.Sources.Git[0].Signature)
+		return c.Sources.Git[0].Signature
+	}
+
+	require.NoError(t, snapshot("v1", signerKey))
+	sig := signer()
+	require.Equal(t, gitutil.SignatureTypeSSH, sig.SignerType)
+	require.True(t, strings.HasPrefix(sig.SignerFingerprint, "SHA256:"), sig.SignerFingerprint)
+
+	// the signer is kept for the cached snapshot
+	require.NoError(t, snapshot("v1", signerKey))
+	require.Equal(t, sig, signer())
+
+	// on a solver cache hit only the cache key is computed
+	g, err := gs.Resolve(ctx, &GitIdentifier{Remote: repoURL, Ref: "v1", SignatureKeys: string(signerKey)}, nil, nil)
+	require.NoError(t, err)
+	ref1, err := g.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	id = &GitIdentifier{Remote: repoURL, Ref: "v1", SignatureKeys: string(signerKey)}
+	g, err = gs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = g.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, sig, signer())
+	require.NoError(t, ref1.Release(context.TODO()))
+
+	require.NoError(t, snapshot("v1", append(otherKey, signerKey...)))
+	require.Equal(t, sig.SignerFingerprint, signer().SignerFingerprint)
+
+	err = snapshot("v1", otherKey)
+	require.ErrorContains(t, err, "failed to verify")
+
+	err = snapshot("unsigned", signerKey)
+	require.ErrorIs(t, err, gitutil.ErrNotSigned)
+
+	if _, err := exec.LookPath("gpg"); err != nil {
+		return
+	}
+	gnupgHome := filepath.Join(keydir, "gnupg")
+	require.NoError(t, os.Mkdir(gnupgHome, 0700))
+	t.Setenv("GNUPGHOME", gnupgHome)
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	})
+	runShell(t, keydir,
+		"gpg --batch --passphrase '' --quick-gen-key test@example.com default default never",
+		"gpg --armor --export test@example.com > signer.asc",
+	)
+	runShell(t, repodir,
+		"git -c gpg.format=openpgp -c user.signingkey=test@example.com tag -s -m signed v2 unsigned",
+	)
+	gpgKey, err := os.ReadFile(filepath.Join(keydir, "signer.asc"))
+	require.NoError(t, err)
+
+	require.NoError(t, snapshot("v2", append(otherKey, gpgKey...)))
+
+	err = snapshot("v2", otherKey)
+	require.ErrorContains(t, err, "no GPG public keys")
+}
+
 func setupGitSource(t *testing.T, tmpdir string) source.Source {
+	gs, _ := setupGitSourceWithOpt(t, tmpdir, Opt{})
+	return gs
+}
+
+func setupGitSourceWithOpt(t *testing.T, tmpdir string, opt Opt) (source.Source, cache.Manager) {
 	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
 	require.NoError(t, err)
 
@@ -840,12 +1371,11 @@ func setupGitSource(t *testing.T, tmpdir string) source.Source {
 	})
 	require.NoError(t, err)
 
-	gs, err := NewSource(Opt{
-		CacheAccessor: cm,
-	})
+	opt.CacheAccessor = cm
+	gs, err := NewSource(opt)
 	require.NoError(t, err)
 
-	return gs
+	return gs, cm
 }
 
 type gitRepoFixture struct {
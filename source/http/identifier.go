@@ -5,6 +5,7 @@ import (
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/source"
 	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/urlutil"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 )
@@ -27,6 +28,14 @@ type HTTPIdentifier struct {
 	GID              int
 	AuthHeaderSecret string
 	Header           []HeaderField
+	// Mirrors are tried in order if the download from URL fails
+	Mirrors []string
+
+	// SignatureURL is the URL of a detached signature of the file that is
+	// verified against SignatureKeys and the keys from SignatureKeySecret
+	SignatureURL       string
+	SignatureKeys      string
+	SignatureKeySecret string
 }
 
 type HeaderField struct {
@@ -48,9 +57,26 @@ func (id *HTTPIdentifier) Capture(c *provenance.Capture, pin string) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to parse HTTP digest %s", pin)
 	}
-	c.AddHTTP(provenancetypes.HTTPSource{
+	hs := provenancetypes.HTTPSource{
 		URL:    id.URL,
 		Digest: dgst,
-	})
+	}
+	if id.SignatureURL != "" {
+		hs.Signature = &provenancetypes.HTTPSignature{
+			URI:          urlutil.RedactCredentials(id.URL),
+			Digest:       dgst,
+			SignatureURI: urlutil.RedactCredentials(id.SignatureURL),
+			PubKeySecret: id.SignatureKeySecret,
+		}
+		if id.SignatureKeys != "" {
+			hs.Signature.PubKeys = digest.FromString(id.SignatureKeys)
+		}
+	}
+	c.AddHTTP(hs)
+	if id.SignatureKeySecret != "" {
+		c.AddSecret(provenancetypes.Secret{
+			ID: id.SignatureKeySecret,
+		})
+	}
 	return nil
 }
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/util/sigutil"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Detached signatures of downloaded files are supported in these formats.
+const (
+	signatureTypePGP      = "gpg"
+	signatureTypeMinisign = "minisign"
+	signatureTypeCosign   = "cosign"
+)
+
+// maxSignatureSize limits the size of a downloaded signature.
+const maxSignatureSize = 1 << 20
+
+// publicKeys are the keys that a detached signature is verified against.
+type publicKeys struct {
+	pgp      []byte
+	minisign []minisignKey
+	pkix     []crypto.PublicKey
+}
+
+type minisignKey struct {
+	id  []byte
+	key ed25519.PublicKey
+}
+
+// parsePublicKeys reads armored GPG public key blocks, PEM encoded public
+// keys as used by cosign and minisign public keys from dt.
+func parsePublicKeys(dt []byte) (*publicKeys, error) {
+	keys := &publicKeys{}
+	var pgp, pemBlock bytes.Buffer
+	inPGP, inPEM := false, false
+	s := bufio.NewScanner(bytes.NewReader(dt))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case inPGP:
+			pgp.WriteString(line + "\n")
+			if line == "-----END PGP PUBLIC KEY BLOCK-----" {
+				inPGP = false
+			}
+		case inPEM:
+			pemBlock.WriteString(line + "\n")
+			if line == "-----END PUBLIC KEY-----" {
+				inPEM = false
+				block, _ := pem.Decode(pemBlock.Bytes())
+				if block == nil {
+					return nil, errors.New("invalid PEM public key")
+				}
+				pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to parse PEM public key")
+				}
+				keys.pkix = append(keys.pkix, pub)
+				pemBlock.Reset()
+			}
+		case line == "-----BEGIN PGP PUBLIC KEY BLOCK-----":
+			inPGP = true
+			pgp.WriteString(line + "\n")
+		case line == "-----BEGIN PUBLIC KEY-----":
+			inPEM = true
+			pemBlock.WriteString(line + "\n")
+		case line == "" || strings.HasPrefix(line, "untrusted comment:"):
+		default:
+			raw, err := base64.StdEncoding.DecodeString(line)
+			if err != nil || len(raw) != 42 || string(raw[:2]) != "Ed" {
+				return nil, errors.Errorf("unsupported public key format %q", line)
+			}
+			keys.minisign = append(keys.minisign, minisignKey{id: raw[2:10], key: ed25519.PublicKey(raw[10:])})
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if inPGP || inPEM {
+		return nil, errors.New("unterminated public key block")
+	}
+	keys.pgp = pgp.Bytes()
+	return keys, nil
+}
+
+func (keys *publicKeys) empty() bool {
+	return len(keys.pgp) == 0 && len(keys.minisign) == 0 && len(keys.pkix) == 0
+}
+
+// detectSignatureType returns the format of a detached signature. Signatures
+// that are neither armored nor base64 encoded are assumed to be binary GPG
+// signatures.
+func detectSignatureType(sig []byte) string {
+	switch {
+	case bytes.HasPrefix(sig, []byte("-----BEGIN PGP SIGNATURE-----")):
+		return signatureTypePGP
+	case bytes.HasPrefix(sig, []byte("untrusted comment:")):
+		return signatureTypeMinisign
+	}
+	if _, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig))); err == nil {
+		return signatureTypeCosign
+	}
+	return signatureTypePGP
+}
+
+// verifyFileSignature checks that sig is a valid signature of the file at fp
+// made by one of the keys.
+func verifyFileSignature(ctx context.Context, fp string, sig []byte, keys *publicKeys) error {
+	switch typ := detectSignatureType(sig); typ {
+	case signatureTypePGP:
+		if len(keys.pgp) == 0 {
+			return errors.New("file is signed with GPG but no GPG public keys were provided")
+		}
+		return verifyPGP(ctx, fp, sig, keys.pgp)
+	case signatureTypeMinisign:
+		if len(keys.minisign) == 0 {
+			return errors.New("file is signed with minisign but no minisign public keys were provided")
+		}
+		return verifyMinisign(fp, sig, keys.minisign)
+	default:
+		if len(keys.pkix) == 0 {
+			return errors.New("file is signed with cosign but no PEM public keys were provided")
+		}
+		return verifyCosign(fp, sig, keys.pkix)
+	}
+}
+
+func verifyPGP(ctx context.Context, fp string, sig, keys []byte) error {
+	f, err := os.Open(fp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	_, err = sigutil.VerifyPGP(ctx, f, sig, keys)
+	return err
+}
+
+// verifyMinisign checks a minisign signature. The signature consists of an
+// untrusted comment, the signature of the file, a trusted comment and a
+// global signature over the file signature and the trusted comment.
+func verifyMinisign(fp string, sig []byte, keys []minisignKey) error {
+	lines := strings.Split(strings.TrimSpace(string(sig)), "\n")
+	if len(lines) < 4 {
+		return errors.New("invalid minisign signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 74 {
+		return errors.New("invalid minisign signature")
+	}
+	algo, keyID, fileSig := string(raw[:2]), raw[2:10], raw[10:]
+	trustedComment, ok := strings.CutPrefix(strings.TrimSpace(lines[2]), "trusted comment: ")
+	if !ok {
+		return errors.New("invalid minisign signature: missing trusted comment")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign signature: invalid global signature")
+	}
+
+	var key ed25519.PublicKey
+	for _, k := range keys {
+		if bytes.Equal(k.id, keyID) {
+			key = k.key
+			break
+		}
+	}
+	if key == nil {
+		return errors.Errorf("file is signed with unknown minisign key %s", hex.EncodeToString(keyID))
+	}
+
+	var msg []byte
+	switch algo {
+	case "ED":
+		// prehashed signatures sign the BLAKE2b-512 digest of the file
+		h, err := blake2b.New512(nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := hashFile(fp, h); err != nil {
+			return err
+		}
+		msg = h.Sum(nil)
+	case "Ed":
+		msg, err = os.ReadFile(fp)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	default:
+		return errors.Errorf("unsupported minisign signature algorithm %q", algo)
+	}
+	if !ed25519.Verify(key, msg, fileSig) {
+		return errors.New("failed to verify minisign signature")
+	}
+	if !ed25519.Verify(key, append(fileSig, trustedComment...), globalSig) {
+		return errors.New("failed to verify minisign signature: invalid trusted comment")
+	}
+	return nil
+}
+
+// verifyCosign checks a base64 encoded signature as created by
+// `cosign sign-blob`.
+func verifyCosign(fp string, sig []byte, keys []crypto.PublicKey) error {
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil {
+		return errors.Wrap(err, "invalid cosign signature")
+	}
+	h := sha256.New()
+	if err := hashFile(fp, h); err != nil {
+		return err
+	}
+	dgst := h.Sum(nil)
+	// Ed25519 keys sign the file itself instead of its digest
+	var msg []byte
+	if slices.ContainsFunc(keys, func(k crypto.PublicKey) bool {
+		_, ok := k.(ed25519.PublicKey)
+		return ok
+	}) {
+		msg, err = os.ReadFile(fp)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, k := range keys {
+		if sigutil.VerifyPublicKey(k, msg, dgst, raw) {
+			return nil
+		}
+	}
+	return errors.New("failed to verify cosign signature")
+}
+
+func hashFile(fp string, w io.Writer) error {
+	f, err := os.Open(fp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(w, f); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"cmp"
 	"context"
-	"crypto/sha256"
+	_ "crypto/sha512" // for sha384 and sha512 checksums
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"mime"
 	"net/http"
@@ -19,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets"
@@ -28,7 +30,9 @@ import (
 	"github.com/moby/buildkit/source"
 	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/util/urlutil"
 	"github.com/moby/buildkit/version"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -39,6 +43,10 @@ const (
 	HTTPAuthTokenSecretPrefix  = "HTTP_AUTH_TOKEN_"
 )
 
+// maxResumeAttempts is the number of times an interrupted download is
+// continued with a range request before giving up.
+const maxResumeAttempts = 5
+
 // supportedUserHeaders defines supported user-defined header fields. Fields
 // not included here will be silently dropped.
 var supportedUserDefinedHeaders = map[string]bool{
@@ -108,6 +116,34 @@ func (hs *httpSource) Identifier(scheme, ref string, attrs map[string]string, pl
 			id.GID = int(i)
 		case pb.AttrHTTPAuthHeaderSecret:
 			id.AuthHeaderSecret = v
+		case pb.AttrHTTPMirrors:
+			var mirrors []string
+			if err := json.Unmarshal([]byte(v), &mirrors); err != nil {
+				return nil, errors.Wrap(err, "failed to parse http mirrors")
+			}
+			for _, m := range mirrors {
+				u, err := url.Parse(m)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid http mirror %s", urlutil.RedactCredentials(m))
+				}
+				if u.Scheme != "http" && u.Scheme != "https" {
+					return nil, errors.Errorf("invalid http mirror %s: unsupported scheme", urlutil.RedactCredentials(m))
+				}
+			}
+			id.Mirrors = mirrors
+		case pb.AttrHTTPSignatureURL:
+			u, err := url.Parse(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid signature URL %s", urlutil.RedactCredentials(v))
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				return nil, errors.Errorf("invalid signature URL %s: unsupported scheme", urlutil.RedactCredentials(v))
+			}
+			id.SignatureURL = v
+		case pb.AttrHTTPSignatureKeys:
+			id.SignatureKeys = v
+		case pb.AttrHTTPSignatureKeySecret:
+			id.SignatureKeySecret = v
 		default:
 			if name, found := strings.CutPrefix(k, pb.AttrHTTPHeaderPrefix); found {
 				name = http.CanonicalHeaderKey(name)
@@ -133,6 +169,7 @@ type httpSourceHandler struct {
 	refID    string
 	cacheKey digest.Digest
 	sm       *session.Manager
+	sigKeys  *publicKeys
 }
 
 func (hs *httpSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
@@ -140,6 +177,11 @@ func (hs *httpSource) Resolve(ctx context.Context, id source.Identifier, sm *ses
 	if !ok {
 		return nil, errors.Errorf("invalid http identifier %v", id)
 	}
+	// the content from a mirror is stored with the cache key of the URL, so
+	// it has to be verified against a checksum
+	if len(httpIdentifier.Mirrors) > 0 && httpIdentifier.Checksum == "" {
+		return nil, errors.Errorf("http source %s with mirrors requires a checksum", urlutil.RedactCredentials(httpIdentifier.URL))
+	}
 
 	return &httpSourceHandler{
 		src:        *httpIdentifier,
@@ -160,6 +202,7 @@ func (hs *httpSourceHandler) urlHash() (digest.Digest, error) {
 		Perm, UID, GID   int
 		AuthHeaderSecret string `json:",omitempty"`
 		Header           []HeaderField
+		Signature        *signatureKey `json:",omitempty"`
 	}{
 		Filename: bytes.Join([][]byte{
 			[]byte(hs.src.URL),
@@ -170,6 +213,7 @@ func (hs *httpSourceHandler) urlHash() (digest.Digest, error) {
 		GID:              hs.src.GID,
 		AuthHeaderSecret: hs.src.AuthHeaderSecret,
 		Header:           hs.src.Header,
+		Signature:        hs.signatureKey(),
 	})
 	if err != nil {
 		return "", err
@@ -185,6 +229,7 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 		LastModTime      string        `json:",omitempty"`
 		AuthHeaderSecret string        `json:",omitempty"`
 		Header           []HeaderField `json:",omitempty"`
+		Signature        *signatureKey `json:",omitempty"`
 	}{
 		Filename:         filename,
 		Perm:             hs.src.Perm,
@@ -194,6 +239,7 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 		LastModTime:      lastModTime,
 		AuthHeaderSecret: hs.src.AuthHeaderSecret,
 		Header:           hs.src.Header,
+		Signature:        hs.signatureKey(),
 	})
 	if err != nil {
 		return dgst
@@ -201,12 +247,34 @@ func (hs *httpSourceHandler) formatCacheKey(filename string, dgst digest.Digest,
 	return digest.FromBytes(dt)
 }
 
+// signatureKey is the part of the cache key for the signature verification.
+type signatureKey struct {
+	URL          string
+	PubKeys      string `json:",omitempty"`
+	PubKeySecret string `json:",omitempty"`
+}
+
+func (hs *httpSourceHandler) signatureKey() *signatureKey {
+	if hs.src.SignatureURL == "" {
+		return nil
+	}
+	return &signatureKey{
+		URL:          hs.src.SignatureURL,
+		PubKeys:      hs.src.SignatureKeys,
+		PubKeySecret: hs.src.SignatureKeySecret,
+	}
+}
+
 func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
 	if hs.src.Checksum != "" {
 		hs.cacheKey = hs.src.Checksum
 		return hs.formatCacheKey(getFileName(hs.src.URL, hs.src.Filename, nil), hs.src.Checksum, "").String(), hs.src.Checksum.String(), nil, true, nil
 	}
 
+	if offline.IsOffline(ctx) {
+		return "", "", nil, false, errors.Wrapf(offline.ErrNetworkAccess, "http source %s has no checksum", urlutil.RedactCredentials(hs.src.URL))
+	}
+
 	uh, err := hs.urlHash()
 	if err != nil {
 		return "", "", nil, false, err
@@ -218,7 +286,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", uh)
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
+	req, err := hs.newHTTPRequest(ctx, hs.src.URL, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -298,13 +366,11 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		req.Header.Del("Accept-Encoding")
 	}
 
-	resp, err := client.Do(req)
+	resp, primary, err := hs.fetch(ctx, client, req, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return "", "", nil, false, errors.Errorf("invalid response status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusNotModified {
 		respETag := etagValue(resp.Header.Get("ETag"))
 		if respETag == "" && onlyETag != "" {
@@ -330,7 +396,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return hs.formatCacheKey(getFileName(hs.src.URL, hs.src.Filename, resp), dgst, modTime).String(), dgst.String(), nil, true, nil
 	}
 
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, err := hs.save(ctx, resp, primary, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -341,7 +407,10 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 	return hs.formatCacheKey(getFileName(hs.src.URL, hs.src.Filename, resp), dgst, resp.Header.Get("Last-Modified")).String(), dgst.String(), nil, true, nil
 }
 
-func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
+// save writes the response body to a new snapshot. ETag metadata is only
+// stored for responses from the primary URL as mirrors are not part of the
+// cache key.
+func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, primary bool, s session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
 	newRef, err := hs.cache.New(ctx, nil, s, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("http url %s", hs.src.URL)))
 	if err != nil {
 		return nil, "", err
@@ -389,9 +458,16 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 		}
 	}()
 
-	h := sha256.New()
+	algo := digest.SHA256
+	if hs.src.Checksum != "" {
+		algo = hs.src.Checksum.Algorithm()
+		if !algo.Available() {
+			return nil, "", errors.Errorf("unsupported checksum algorithm %s", algo)
+		}
+	}
+	h := algo.Hash()
 
-	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+	if err := hs.download(ctx, f, h, resp, s); err != nil {
 		return nil, "", err
 	}
 
@@ -400,6 +476,12 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 	}
 	f = nil
 
+	if hs.src.SignatureURL != "" {
+		if err := hs.verifySignature(ctx, fp, s); err != nil {
+			return nil, "", err
+		}
+	}
+
 	uid := hs.src.UID
 	gid := hs.src.GID
 	if idmap := mount.IdentityMapping(); idmap != nil {
@@ -438,9 +520,9 @@ func (hs *httpSourceHandler) save(ctx context.Context, resp *http.Response, s se
 	md := cacheRefMetadata{ref}
 
 	hs.refID = ref.ID()
-	dgst = digest.NewDigest(digest.SHA256, h)
+	dgst = digest.NewDigest(algo, h)
 
-	if respETag := resp.Header.Get("ETag"); respETag != "" {
+	if respETag := resp.Header.Get("ETag"); respETag != "" && primary {
 		respETag = etagValue(respETag)
 		if err := md.setETag(respETag); err != nil {
 			return nil, "", err
@@ -473,14 +555,14 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 		}
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
+	req, err := hs.newHTTPRequest(ctx, hs.src.URL, g)
 	if err != nil {
 		return nil, err
 	}
 
 	client := hs.client(g)
 
-	resp, err := client.Do(req)
+	resp, primary, err := hs.fetch(ctx, client, req, g)
 	if err != nil {
 		return nil, err
 	}
@@ -488,7 +570,7 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 		_ = resp.Body.Close()
 	}()
 
-	ref, dgst, err := hs.save(ctx, resp, g)
+	ref, dgst, err := hs.save(ctx, resp, primary, g)
 	if err != nil {
 		return nil, err
 	}
@@ -500,8 +582,196 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 	return ref, nil
 }
 
-func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group) (*http.Request, error) {
-	req, err := http.NewRequest(http.MethodGet, hs.src.URL, nil)
+// fetch sends req for the URL of the source. If the request fails, the
+// mirrors are tried in order. The returned bool is false if the response came
+// from a mirror.
+func (hs *httpSourceHandler) fetch(ctx context.Context, client *http.Client, req *http.Request, g session.Group) (*http.Response, bool, error) {
+	resp, err := doRequest(client, req)
+	if err == nil || len(hs.src.Mirrors) == 0 {
+		return resp, true, err
+	}
+	errs := multierror.Append(nil, errors.Wrapf(err, "failed to download %s", urlutil.RedactCredentials(hs.src.URL)))
+	for _, mirror := range hs.src.Mirrors {
+		bklog.G(ctx).Warnf("failed to download %s, trying mirror %s: %v", urlutil.RedactCredentials(hs.src.URL), urlutil.RedactCredentials(mirror), err)
+		req, err := hs.newHTTPRequest(ctx, mirror, g)
+		if err != nil {
+			return nil, false, err
+		}
+		resp, err = doRequest(client, req)
+		if err == nil {
+			return resp, false, nil
+		}
+		errs = multierror.Append(errs, errors.Wrapf(err, "failed to download from mirror %s", urlutil.RedactCredentials(mirror)))
+	}
+	return nil, false, errs.ErrorOrNil()
+}
+
+func doRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, errors.Errorf("invalid response status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// download writes the body of resp to f. If reading the body fails, the
+// download continues from the current offset with a range request.
+func (hs *httpSourceHandler) download(ctx context.Context, f *os.File, h hash.Hash, resp *http.Response, g session.Group) error {
+	var written int64
+	body := resp.Body
+	for attempt := 0; ; attempt++ {
+		n, err := io.Copy(io.MultiWriter(f, h), body)
+		if body != resp.Body {
+			body.Close()
+		}
+		written += n
+		if err == nil {
+			return nil
+		}
+		if attempt == maxResumeAttempts || ctx.Err() != nil {
+			return err
+		}
+		validator, ok := hs.resumeValidator(resp)
+		if !ok {
+			return err
+		}
+		bklog.G(ctx).Warnf("download of %s interrupted after %d bytes, resuming: %v", urlutil.RedactCredentials(resp.Request.URL.String()), written, err)
+
+		var offset int64
+		body, offset, err = hs.resume(ctx, resp.Request, written, validator, g)
+		if err != nil {
+			return errors.Wrap(err, "failed to resume download")
+		}
+		if offset != written {
+			// the server sent the complete file
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				body.Close()
+				return errors.WithStack(err)
+			}
+			if err := f.Truncate(0); err != nil {
+				body.Close()
+				return errors.WithStack(err)
+			}
+			h.Reset()
+			written = 0
+		}
+	}
+}
+
+// resumeValidator returns the value for the If-Range header of a range
+// request continuing resp. Without a strong validator, downloads are only
+// resumed if the checksum of the content is known.
+func (hs *httpSourceHandler) resumeValidator(resp *http.Response) (string, bool) {
+	// ranges of transparently decompressed bodies can't be requested
+	if resp.Uncompressed || resp.Header.Get("Accept-Ranges") == "none" {
+		return "", false
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag, true
+	}
+	if lastMod := resp.Header.Get("Last-Modified"); lastMod != "" {
+		return lastMod, true
+	}
+	return "", hs.src.Checksum != ""
+}
+
+// resume requests the content of req starting at offset. The returned offset
+// is 0 if the server responded with the complete content.
+func (hs *httpSourceHandler) resume(ctx context.Context, req *http.Request, offset int64, validator string, g session.Group) (io.ReadCloser, int64, error) {
+	req = req.Clone(ctx)
+	req.Method = http.MethodGet
+	req.Header.Del("If-None-Match")
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	if validator != "" {
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := hs.client(g).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			resp.Body.Close()
+			return nil, 0, errors.Errorf("invalid content range %q", resp.Header.Get("Content-Range"))
+		}
+		return resp.Body, offset, nil
+	case http.StatusOK:
+		return resp.Body, 0, nil
+	default:
+		resp.Body.Close()
+		return nil, 0, errors.Errorf("invalid response status %d", resp.StatusCode)
+	}
+}
+
+// getSignatureKeys loads the public keys that the signature of the file is
+// verified against.
+func (hs *httpSourceHandler) getSignatureKeys(ctx context.Context, g session.Group) (*publicKeys, error) {
+	if hs.sigKeys != nil {
+		return hs.sigKeys, nil
+	}
+	dt := []byte(hs.src.SignatureKeys)
+	if hs.src.SignatureKeySecret != "" {
+		err := hs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+			v, err := secrets.GetSecret(ctx, caller, hs.src.SignatureKeySecret)
+			if err != nil {
+				return err
+			}
+			dt = append(append(dt, '\n'), v...)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get signature keys from secret %s", hs.src.SignatureKeySecret)
+		}
+	}
+	keys, err := parsePublicKeys(dt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signature keys")
+	}
+	hs.sigKeys = keys
+	return keys, nil
+}
+
+// verifySignature downloads the detached signature of the source and checks
+// that it is a valid signature of the file at fp.
+func (hs *httpSourceHandler) verifySignature(ctx context.Context, fp string, g session.Group) error {
+	keys, err := hs.getSignatureKeys(ctx, g)
+	if err != nil {
+		return err
+	}
+	if keys.empty() {
+		return errors.Errorf("no public keys to verify the signature of %s", urlutil.RedactCredentials(hs.src.URL))
+	}
+
+	req, err := hs.newHTTPRequest(ctx, hs.src.SignatureURL, g)
+	if err != nil {
+		return err
+	}
+	resp, err := doRequest(hs.client(g), req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download signature %s", urlutil.RedactCredentials(hs.src.SignatureURL))
+	}
+	defer resp.Body.Close()
+	sig, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize+1))
+	if err != nil {
+		return errors.Wrapf(err, "failed to download signature %s", urlutil.RedactCredentials(hs.src.SignatureURL))
+	}
+	if len(sig) > maxSignatureSize {
+		return errors.Errorf("signature %s exceeds %d bytes", urlutil.RedactCredentials(hs.src.SignatureURL), maxSignatureSize)
+	}
+
+	if err := verifyFileSignature(ctx, fp, sig, keys); err != nil {
+		return errors.Wrapf(err, "failed to verify signature of %s", urlutil.RedactCredentials(hs.src.URL))
+	}
+	return nil
+}
+
+func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, urlStr string, g session.Group) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -516,11 +786,18 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 		token bool
 	}
 
+	// the explicit auth header secret is only sent to the URL of the source,
+	// mirrors use the secrets for their host
+	authHeaderSecret := hs.src.AuthHeaderSecret
+	if urlStr != hs.src.URL {
+		authHeaderSecret = ""
+	}
+
 	var secretNames []authSecret
-	if hs.src.AuthHeaderSecret != "" {
-		secretNames = append(secretNames, authSecret{name: hs.src.AuthHeaderSecret})
+	if authHeaderSecret != "" {
+		secretNames = append(secretNames, authSecret{name: authHeaderSecret})
 	} else {
-		u, err := url.Parse(hs.src.URL)
+		u, err := url.Parse(urlStr)
 		if err == nil {
 			secretNames = append(secretNames, authSecret{name: HTTPAuthHeaderSecretPrefix + u.Hostname()})
 			secretNames = append(secretNames, authSecret{name: HTTPAuthTokenSecretPrefix + u.Hostname(), token: true})
@@ -540,8 +817,8 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 			req.Header.Set("Authorization", v)
 			return nil
 		})
-		if err != nil && hs.src.AuthHeaderSecret != "" {
-			return nil, errors.Wrapf(err, "failed to retrieve HTTP auth secret %s", hs.src.AuthHeaderSecret)
+		if err != nil && authHeaderSecret != "" {
+			return nil, errors.Wrapf(err, "failed to retrieve HTTP auth secret %s", authHeaderSecret)
 		}
 	}
 
@@ -1,10 +1,25 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/diff/apply"
 	ctdmetadata "github.com/containerd/containerd/v2/core/metadata"
@@ -24,6 +39,7 @@ import (
 	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestHTTPSource(t *testing.T) {
@@ -284,6 +300,264 @@ func TestHTTPChecksum(t *testing.T) {
 	ref = nil
 }
 
+func TestHTTPResume(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") == "" {
+			// close the connection after sending half of the content
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(content[:len(content)/2])
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		http.ServeContent(w, r, "foo", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	id := &HTTPIdentifier{URL: server.URL + "/foo"}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(content).String(), p)
+	require.Equal(t, []string{"", fmt.Sprintf("bytes=%d-", len(content)/2)}, ranges)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.WithoutCancel(ctx))
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, content, dt)
+}
+
+func TestHTTPMirrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{})
+	defer server.Close()
+
+	mirror := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/mirror/foo": {
+			Etag:    identity.NewID(),
+			Content: []byte("content1"),
+		},
+		"/mirror/bad": {
+			Etag:    identity.NewID(),
+			Content: []byte("content2"),
+		},
+	})
+	defer mirror.Close()
+
+	// mirrors require a checksum
+	_, err = hs.Resolve(ctx, &HTTPIdentifier{
+		URL:     server.URL + "/foo",
+		Mirrors: []string{mirror.URL + "/mirror/foo"},
+	}, nil, nil)
+	require.ErrorContains(t, err, "requires a checksum")
+
+	checksum := digest.FromBytes([]byte("content1"))
+	id := &HTTPIdentifier{
+		URL:      server.URL + "/foo",
+		Mirrors:  []string{server.URL + "/bar", mirror.URL + "/mirror/foo"},
+		Checksum: checksum,
+	}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, checksum.String(), p)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.WithoutCancel(ctx))
+	require.Equal(t, 1, mirror.Stats("/mirror/foo").AllRequests)
+
+	// the filename is taken from the URL of the source
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("content1"), dt)
+
+	// content from a mirror that doesn't match the checksum is rejected
+	id = &HTTPIdentifier{
+		URL:      server.URL + "/foo",
+		Mirrors:  []string{mirror.URL + "/mirror/bad"},
+		Checksum: digest.FromBytes([]byte("content3")),
+	}
+
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	_, err = h.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "digest mismatch")
+
+	id = &HTTPIdentifier{
+		URL:      server.URL + "/foo",
+		Mirrors:  []string{server.URL + "/bar"},
+		Checksum: checksum,
+	}
+
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	_, err = h.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "failed to download from mirror")
+}
+
+func TestHTTPChecksumSHA512(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/foo": {
+			Etag:    identity.NewID(),
+			Content: []byte("content-correct"),
+		},
+	})
+	defer server.Close()
+
+	id := &HTTPIdentifier{URL: server.URL + "/foo", Checksum: digest.SHA512.FromBytes([]byte("content-different"))}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, id.Checksum.String(), p)
+
+	_, err = h.Snapshot(ctx, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "digest mismatch")
+
+	id = &HTTPIdentifier{URL: server.URL + "/foo", Checksum: digest.SHA512.FromBytes([]byte("content-correct"))}
+
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, id.Checksum.String(), p)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.WithoutCancel(ctx))
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("content-correct"), dt)
+}
+
+func TestHTTPSignature(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	content := []byte("content1")
+
+	// minisign key and prehashed signature
+	_, minisignPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte("buildkit")
+	minisignKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), minisignPriv.Public().(ed25519.PublicKey)...))
+	prehashed := blake2b.Sum512(content)
+	fileSig := ed25519.Sign(minisignPriv, prehashed[:])
+	trustedComment := "timestamp:1700000000"
+	minisig := "untrusted comment: signature\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), fileSig...)) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(ed25519.Sign(minisignPriv, append(fileSig, trustedComment...))) + "\n"
+
+	// cosign key and signature
+	ecdsaPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(&ecdsaPriv.PublicKey)
+	require.NoError(t, err)
+	cosignKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	dgst := sha256.Sum256(content)
+	cosignSig, err := ecdsa.SignASN1(rand.Reader, ecdsaPriv, dgst[:])
+	require.NoError(t, err)
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/foo":         {Content: content},
+		"/foo.minisig": {Content: []byte(minisig)},
+		"/foo.sig":     {Content: []byte(base64.StdEncoding.EncodeToString(cosignSig))},
+		"/bar":         {Content: []byte("content2")},
+	})
+	defer server.Close()
+
+	for _, tc := range []struct {
+		name   string
+		url    string
+		sigURL string
+		keys   string
+		err    string
+	}{
+		{name: "minisign", url: "/foo", sigURL: "/foo.minisig", keys: "untrusted comment: key\n" + minisignKey},
+		{name: "cosign", url: "/foo", sigURL: "/foo.sig", keys: minisignKey + "\n" + cosignKey},
+		{name: "unknown key", url: "/foo", sigURL: "/foo.minisig", keys: base64.StdEncoding.EncodeToString(append([]byte("Edotherkey"), make([]byte, 32)...)), err: "unknown minisign key"},
+		{name: "missing key type", url: "/foo", sigURL: "/foo.sig", keys: minisignKey, err: "no PEM public keys"},
+		{name: "wrong content", url: "/bar", sigURL: "/foo.minisig", keys: minisignKey, err: "failed to verify minisign signature"},
+		{name: "missing signature", url: "/foo", sigURL: "/foo.asc", keys: minisignKey, err: "failed to download signature"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			id := &HTTPIdentifier{URL: server.URL + tc.url, SignatureURL: server.URL + tc.sigURL, SignatureKeys: tc.keys}
+
+			h, err := hs.Resolve(ctx, id, nil, nil)
+			require.NoError(t, err)
+
+			_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			ref, err := h.Snapshot(ctx, nil)
+			require.NoError(t, err)
+			defer ref.Release(context.WithoutCancel(ctx))
+
+			dt, err := readFile(ctx, ref, "foo")
+			require.NoError(t, err)
+			require.Equal(t, content, dt)
+		})
+	}
+}
+
 func readFile(ctx context.Context, ref cache.ImmutableRef, fp string) ([]byte, error) {
 	mount, err := ref.Mount(ctx, true, nil)
 	if err != nil {
@@ -0,0 +1,85 @@
+package s3
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+type S3Identifier struct {
+	Bucket       string
+	Key          string
+	Region       string
+	EndpointURL  string
+	UsePathStyle bool
+	VersionID    string
+	Filename     string
+
+	// resolvedVersionID is the version of the object that the cache key was
+	// computed for.
+	resolvedVersionID string
+}
+
+var _ source.Identifier = (*S3Identifier)(nil)
+
+// NewS3Identifier parses a reference in the form of <bucket>/<key>.
+func NewS3Identifier(ref string) (*S3Identifier, error) {
+	bucket, key, _ := strings.Cut(ref, "/")
+	if bucket == "" || key == "" {
+		return nil, errors.Errorf("invalid s3 reference %q, expected s3://<bucket>/<key>", ref)
+	}
+	return &S3Identifier{Bucket: bucket, Key: key}, nil
+}
+
+func (*S3Identifier) Scheme() string {
+	return srctypes.S3Scheme
+}
+
+// URL returns the s3:// URL of the object.
+func (id *S3Identifier) URL() string {
+	u := srctypes.S3Scheme + "://" + id.Bucket + "/" + id.Key
+	if id.VersionID != "" {
+		u += "?versionId=" + id.VersionID
+	}
+	return u
+}
+
+func (id *S3Identifier) Capture(c *provenance.Capture, pin string) error {
+	dgst, err := digest.Parse(pin)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse s3 digest %s", pin)
+	}
+	// objects are recorded like files downloaded over HTTP
+	c.AddHTTP(provenancetypes.HTTPSource{
+		URL:    id.provenanceURL(),
+		Digest: dgst,
+	})
+	return nil
+}
+
+// provenanceURL returns the s3:// URL of the object with the custom endpoint
+// and the version of the object that was read.
+func (id *S3Identifier) provenanceURL() string {
+	q := url.Values{}
+	if id.EndpointURL != "" {
+		q.Set("endpoint", id.EndpointURL)
+	}
+	versionID := id.VersionID
+	if versionID == "" {
+		versionID = id.resolvedVersionID
+	}
+	if versionID != "" {
+		q.Set("versionId", versionID)
+	}
+	u := srctypes.S3Scheme + "://" + id.Bucket + "/" + id.Key
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
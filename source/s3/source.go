@@ -0,0 +1,429 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/offline"
+	"github.com/moby/buildkit/util/s3util"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Names of the build secrets that credentials for S3 are read from. If the
+// secrets don't exist, the build fails unless the source allows the
+// credentials of the daemon environment to be used.
+const (
+	AccessKeyIDSecret     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeySecret = "AWS_SECRET_ACCESS_KEY"
+	SessionTokenSecret    = "AWS_SESSION_TOKEN"
+)
+
+type Opt struct {
+	CacheAccessor cache.Accessor
+	// AllowDaemonCredentials makes sources without credentials in the build
+	// secrets use the credentials of the daemon environment.
+	AllowDaemonCredentials bool
+	// DaemonCredentialsEndpoints are the custom endpoint URLs that sources may
+	// use with the credentials of the daemon environment. The credentials are
+	// never sent to other endpoints set by the build.
+	DaemonCredentialsEndpoints []string
+}
+
+type s3Source struct {
+	cache                      cache.Accessor
+	allowDaemonCredentials     bool
+	daemonCredentialsEndpoints []string
+}
+
+func NewSource(opt Opt) (source.Source, error) {
+	return &s3Source{
+		cache:                      opt.CacheAccessor,
+		allowDaemonCredentials:     opt.AllowDaemonCredentials,
+		daemonCredentialsEndpoints: opt.DaemonCredentialsEndpoints,
+	}, nil
+}
+
+func (ss *s3Source) Schemes() []string {
+	return []string{srctypes.S3Scheme}
+}
+
+func (ss *s3Source) Identifier(scheme, ref string, attrs map[string]string, platform *pb.Platform) (source.Identifier, error) {
+	id, err := NewS3Identifier(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range attrs {
+		switch k {
+		case pb.AttrS3Region:
+			id.Region = v
+		case pb.AttrS3EndpointURL:
+			id.EndpointURL = v
+		case pb.AttrS3UsePathStyle:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value %q for %s", v, k)
+			}
+			id.UsePathStyle = b
+		case pb.AttrS3VersionID:
+			id.VersionID = v
+		case pb.AttrS3Filename:
+			id.Filename = v
+		}
+	}
+
+	return id, nil
+}
+
+type s3SourceHandler struct {
+	*s3Source
+	src S3Identifier
+	id  *S3Identifier
+	sm  *session.Manager
+
+	// object that the cache key was computed for
+	etag      string
+	versionID string
+
+	refID    string
+	cacheKey digest.Digest
+}
+
+func (ss *s3Source) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
+	s3Identifier, ok := id.(*S3Identifier)
+	if !ok {
+		return nil, errors.Errorf("invalid s3 identifier %v", id)
+	}
+
+	return &s3SourceHandler{
+		src:      *s3Identifier,
+		id:       s3Identifier,
+		s3Source: ss,
+		sm:       sm,
+	}, nil
+}
+
+func (ss *s3SourceHandler) client(ctx context.Context, g session.Group) (*s3.Client, error) {
+	config := s3util.Config{
+		Region:       ss.src.Region,
+		EndpointURL:  ss.src.EndpointURL,
+		UsePathStyle: ss.src.UsePathStyle,
+	}
+	for name, v := range map[string]*string{
+		AccessKeyIDSecret:     &config.AccessKeyID,
+		SecretAccessKeySecret: &config.SecretAccessKey,
+		SessionTokenSecret:    &config.SessionToken,
+	} {
+		_ = ss.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+			dt, err := secrets.GetSecret(ctx, caller, name)
+			if err != nil {
+				return err
+			}
+			*v = string(dt)
+			return nil
+		})
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		if !ss.allowDaemonCredentials {
+			return nil, errors.Errorf("no credentials for s3://%s/%s: %s and %s build secrets are required", ss.src.Bucket, ss.src.Key, AccessKeyIDSecret, SecretAccessKeySecret)
+		}
+		if ss.src.EndpointURL != "" && !slices.Contains(ss.daemonCredentialsEndpoints, ss.src.EndpointURL) {
+			return nil, errors.Errorf("no credentials for s3://%s/%s: daemon credentials are not allowed for endpoint %s, %s and %s build secrets are required", ss.src.Bucket, ss.src.Key, ss.src.EndpointURL, AccessKeyIDSecret, SecretAccessKeySecret)
+		}
+	}
+	return s3util.NewClient(ctx, config)
+}
+
+func (ss *s3SourceHandler) filename() string {
+	if ss.src.Filename != "" {
+		return ss.src.Filename
+	}
+	if base := path.Base(ss.src.Key); base != "." && base != "/" {
+		return base
+	}
+	return "download"
+}
+
+// objectHash is the internal hash the metadata of downloaded objects is
+// stored by.
+func (ss *s3SourceHandler) objectHash() (digest.Digest, error) {
+	dt, err := json.Marshal(struct {
+		EndpointURL string `json:",omitempty"`
+		Bucket      string
+		Key         string
+		Filename    string
+	}{
+		EndpointURL: ss.src.EndpointURL,
+		Bucket:      ss.src.Bucket,
+		Key:         ss.src.Key,
+		Filename:    ss.filename(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(dt), nil
+}
+
+func (ss *s3SourceHandler) formatCacheKey(dgst digest.Digest) digest.Digest {
+	dt, err := json.Marshal(struct {
+		Filename string
+		Checksum digest.Digest
+	}{
+		Filename: ss.filename(),
+		Checksum: dgst,
+	})
+	if err != nil {
+		return dgst
+	}
+	return digest.FromBytes(dt)
+}
+
+func (ss *s3SourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	if offline.IsOffline(ctx) {
+		return "", "", nil, false, errors.Wrapf(offline.ErrNetworkAccess, "s3 source %s", ss.src.URL())
+	}
+
+	client, err := ss.client(ctx, g)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(ss.src.Bucket),
+		Key:    aws.String(ss.src.Key),
+	}
+	if ss.src.VersionID != "" {
+		input.VersionId = aws.String(ss.src.VersionID)
+	}
+	head, err := client.HeadObject(ctx, input)
+	if err != nil {
+		return "", "", nil, false, errors.Wrapf(err, "failed to get s3 object %s", ss.src.URL())
+	}
+	ss.etag = aws.ToString(head.ETag)
+	ss.versionID = aws.ToString(head.VersionId)
+	ss.id.resolvedVersionID = ss.versionID
+
+	oh, err := ss.objectHash()
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	mds, err := searchS3ObjectDigest(ctx, ss.cache, oh)
+	if err != nil {
+		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", oh)
+	}
+	for _, md := range mds {
+		if md.getETag() != ss.etag || md.getVersionID() != ss.versionID {
+			continue
+		}
+		dgst := md.getS3Checksum()
+		if dgst == "" {
+			continue
+		}
+		// check that ref still exists
+		ref, err := ss.cache.Get(ctx, md.ID(), nil)
+		if err != nil {
+			continue
+		}
+		ref.Release(context.WithoutCancel(ctx))
+		ss.refID = md.ID()
+		ss.cacheKey = dgst
+		return ss.formatCacheKey(dgst).String(), dgst.String(), nil, true, nil
+	}
+
+	ref, dgst, err := ss.save(ctx, client, g)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	ref.Release(context.WithoutCancel(ctx))
+
+	ss.cacheKey = dgst
+	return ss.formatCacheKey(dgst).String(), dgst.String(), nil, true, nil
+}
+
+// save downloads the object that the cache key was computed for to a new
+// snapshot.
+func (ss *s3SourceHandler) save(ctx context.Context, client *s3.Client, g session.Group) (ref cache.ImmutableRef, dgst digest.Digest, retErr error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(ss.src.Bucket),
+		Key:    aws.String(ss.src.Key),
+	}
+	if ss.versionID != "" {
+		input.VersionId = aws.String(ss.versionID)
+	}
+	if ss.etag != "" {
+		input.IfMatch = aws.String(ss.etag)
+	}
+	obj, err := client.GetObject(ctx, input)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to get s3 object %s", ss.src.URL())
+	}
+	defer obj.Body.Close()
+
+	newRef, err := ss.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("s3 object %s", ss.src.URL())))
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, "", err
+	}
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if retErr != nil && lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	fp := filepath.Join(dir, ss.filename())
+	f, err := os.OpenFile(fp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), obj.Body); err != nil {
+		f.Close()
+		return nil, "", errors.Wrapf(err, "failed to download s3 object %s", ss.src.URL())
+	}
+	if err := f.Close(); err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	mTime := time.Unix(0, 0)
+	if obj.LastModified != nil {
+		mTime = *obj.LastModified
+	}
+	if err := os.Chtimes(fp, mTime, mTime); err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	lm.Unmount()
+	lm = nil
+
+	ref, err = newRef.Commit(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	newRef = nil
+
+	ss.refID = ref.ID()
+	dgst = digest.NewDigest(digest.SHA256, h)
+
+	md := cacheRefMetadata{ref}
+	if err := md.setETag(aws.ToString(obj.ETag)); err != nil {
+		return nil, "", err
+	}
+	if err := md.setVersionID(aws.ToString(obj.VersionId)); err != nil {
+		return nil, "", err
+	}
+	oh, err := ss.objectHash()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := md.setS3Checksum(oh, dgst); err != nil {
+		return nil, "", err
+	}
+
+	return ref, dgst, nil
+}
+
+func (ss *s3SourceHandler) Snapshot(ctx context.Context, g session.Group) (cache.ImmutableRef, error) {
+	if ss.refID != "" {
+		ref, err := ss.cache.Get(ctx, ss.refID, nil)
+		if err != nil {
+			bklog.G(ctx).WithError(err).Warnf("failed to get s3 snapshot for ref %s (%s)", ss.refID, ss.src.URL())
+		} else {
+			return ref, nil
+		}
+	}
+
+	client, err := ss.client(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	ref, dgst, err := ss.save(ctx, client, g)
+	if err != nil {
+		return nil, err
+	}
+	if dgst != ss.cacheKey {
+		ref.Release(context.WithoutCancel(ctx))
+		return nil, errors.Errorf("digest mismatch %s: %s", dgst, ss.cacheKey)
+	}
+	return ref, nil
+}
+
+func searchS3ObjectDigest(ctx context.Context, store cache.MetadataStore, dgst digest.Digest) ([]cacheRefMetadata, error) {
+	var results []cacheRefMetadata
+	mds, err := store.Search(ctx, string(dgst), false)
+	if err != nil {
+		return nil, err
+	}
+	for _, md := range mds {
+		results = append(results, cacheRefMetadata{md})
+	}
+	return results, nil
+}
+
+type cacheRefMetadata struct {
+	cache.RefMetadata
+}
+
+const (
+	keyS3Checksum = "s3.checksum"
+	keyETag       = "s3.etag"
+	keyVersionID  = "s3.versionid"
+)
+
+func (md cacheRefMetadata) getS3Checksum() digest.Digest {
+	return digest.Digest(md.GetString(keyS3Checksum))
+}
+
+func (md cacheRefMetadata) setS3Checksum(objectDgst digest.Digest, d digest.Digest) error {
+	return md.SetString(keyS3Checksum, d.String(), objectDgst.String())
+}
+
+func (md cacheRefMetadata) getETag() string {
+	return md.GetString(keyETag)
+}
+
+func (md cacheRefMetadata) setETag(s string) error {
+	return md.SetString(keyETag, s, "")
+}
+
+func (md cacheRefMetadata) getVersionID() string {
+	return md.GetString(keyVersionID)
+}
+
+func (md cacheRefMetadata) setVersionID(s string) error {
+	return md.SetString(keyVersionID, s, "")
+}
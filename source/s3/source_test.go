@@ -0,0 +1,331 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/diff/apply"
+	ctdmetadata "github.com/containerd/containerd/v2/core/metadata"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/containerd/v2/plugins/diff/walking"
+	"github.com/containerd/containerd/v2/plugins/snapshots/native"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/cache/metadata"
+	"github.com/moby/buildkit/snapshot"
+	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/winlayers"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestS3Source(t *testing.T) {
+	ctx := context.TODO()
+
+	server := newFakeS3()
+	defer server.Close()
+	server.put("bucket/dir/foo.tar", "content1")
+	setS3Env(t)
+
+	ss, err := newS3Source(t, true, server.URL)
+	require.NoError(t, err)
+
+	id := &S3Identifier{
+		Bucket:       "bucket",
+		Key:          "dir/foo.tar",
+		Region:       "us-east-1",
+		EndpointURL:  server.URL,
+		UsePathStyle: true,
+	}
+
+	h, err := ss.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromString("content1").String(), p)
+	require.Equal(t, 1, server.gets)
+
+	// the endpoint and the version that was read are recorded in provenance
+	var c provenance.Capture
+	require.NoError(t, id.Capture(&c, p))
+	require.Len(t, c.Sources.HTTP, 1)
+	require.Equal(t, "s3://bucket/dir/foo.tar?endpoint="+url.QueryEscape(server.URL)+"&versionId=1", c.Sources.HTTP[0].URL)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	dt, err := readFile(ctx, ref, "foo.tar")
+	require.NoError(t, err)
+	require.Equal(t, "content1", string(dt))
+	ref.Release(context.TODO())
+
+	// unchanged object is not downloaded again
+	h, err = ss.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, p, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromString("content1").String(), p)
+	require.Equal(t, 1, server.gets)
+
+	ref, err = h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	ref.Release(context.TODO())
+
+	// new version of the object
+	v1 := server.objects["bucket/dir/foo.tar"].version
+	server.put("bucket/dir/foo.tar", "content2")
+
+	h, err = ss.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, p, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromString("content2").String(), p)
+	require.Equal(t, 2, server.gets)
+
+	ref, err = h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	dt, err = readFile(ctx, ref, "foo.tar")
+	require.NoError(t, err)
+	require.Equal(t, "content2", string(dt))
+	ref.Release(context.TODO())
+
+	// pinned version
+	pinned := *id
+	pinned.VersionID = v1
+	pinned.Filename = "bar"
+	h, err = ss.Resolve(ctx, &pinned, nil, nil)
+	require.NoError(t, err)
+	_, p, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromString("content1").String(), p)
+
+	ref, err = h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	dt, err = readFile(ctx, ref, "bar")
+	require.NoError(t, err)
+	require.Equal(t, "content1", string(dt))
+	ref.Release(context.TODO())
+
+	missing := *id
+	missing.Key = "dir/missing"
+	h, err = ss.Resolve(ctx, &missing, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.ErrorContains(t, err, "failed to get s3 object s3://bucket/dir/missing")
+}
+
+func TestS3SourceRequiresCredentials(t *testing.T) {
+	ctx := context.TODO()
+
+	server := newFakeS3()
+	defer server.Close()
+	server.put("bucket/foo", "content1")
+	setS3Env(t)
+
+	ss, err := newS3Source(t, false)
+	require.NoError(t, err)
+
+	id := &S3Identifier{
+		Bucket:       "bucket",
+		Key:          "foo",
+		Region:       "us-east-1",
+		EndpointURL:  server.URL,
+		UsePathStyle: true,
+	}
+
+	// the credentials of the daemon environment are not used
+	h, err := ss.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.ErrorContains(t, err, "no credentials for s3://bucket/foo")
+	require.Equal(t, 0, server.gets)
+
+	// daemon credentials are only sent to allowed custom endpoints
+	ss, err = newS3Source(t, true, "https://s3.example.com")
+	require.NoError(t, err)
+	h, err = ss.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.ErrorContains(t, err, "daemon credentials are not allowed for endpoint "+server.URL)
+	require.Equal(t, 0, server.gets)
+}
+
+func TestS3Identifier(t *testing.T) {
+	id, err := NewS3Identifier("bucket/dir/foo.tar")
+	require.NoError(t, err)
+	require.Equal(t, "bucket", id.Bucket)
+	require.Equal(t, "dir/foo.tar", id.Key)
+	require.Equal(t, "s3://bucket/dir/foo.tar", id.URL())
+
+	for _, ref := range []string{"bucket", "bucket/", "/key"} {
+		_, err := NewS3Identifier(ref)
+		require.Error(t, err, ref)
+	}
+}
+
+type fakeObject struct {
+	content string
+	etag    string
+	version string
+}
+
+// fakeS3 serves versioned objects with path-style addressing.
+type fakeS3 struct {
+	*httptest.Server
+	mu       sync.Mutex
+	objects  map[string]fakeObject
+	versions map[string]fakeObject
+	gets     int
+}
+
+func newFakeS3() *fakeS3 {
+	s := &fakeS3{
+		objects:  map[string]fakeObject{},
+		versions: map[string]fakeObject{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *fakeS3) put(key, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj := fakeObject{
+		content: content,
+		etag:    `"` + digest.FromString(content).Encoded()[:32] + `"`,
+		version: strconv.Itoa(len(s.versions) + 1),
+	}
+	s.objects[key] = obj
+	s.versions[obj.version] = obj
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.objects[strings.TrimPrefix(r.URL.Path, "/")]
+	if v := r.URL.Query().Get("versionId"); v != "" && ok {
+		obj, ok = s.versions[v]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if m := r.Header.Get("If-Match"); m != "" && m != obj.etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("X-Amz-Version-Id", obj.version)
+	w.Header().Set("Last-Modified", time.Unix(1700000000, 0).UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.content)))
+	if r.Method == http.MethodGet {
+		s.gets++
+		w.Write([]byte(obj.content))
+	}
+}
+
+func setS3Env(t *testing.T) {
+	tmpdir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(tmpdir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpdir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+}
+
+func readFile(ctx context.Context, ref cache.ImmutableRef, fp string) ([]byte, error) {
+	mount, err := ref.Mount(ctx, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+
+	defer lm.Unmount()
+
+	return os.ReadFile(filepath.Join(dir, fp))
+}
+
+func newS3Source(t *testing.T, allowDaemonCredentials bool, daemonCredentialsEndpoints ...string) (source.Source, error) {
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	store, err := local.NewStore(tmpdir)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(tmpdir, "containerdmeta.db"), 0644, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	mdb := ctdmetadata.NewDB(db, store, map[string]snapshots.Snapshotter{
+		"native": snapshotter,
+	})
+
+	md, err := metadata.NewStore(filepath.Join(tmpdir, "metadata.db"))
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		require.NoError(t, md.Close())
+	})
+
+	lm := leaseutil.WithNamespace(ctdmetadata.NewLeaseManager(mdb), "buildkit")
+	c := mdb.ContentStore()
+	applier := winlayers.NewFileSystemApplierWithWindows(c, apply.NewFileSystemApplier(c))
+	differ := winlayers.NewWalkingDiffWithWindows(c, walking.NewWalkingDiff(c))
+
+	cm, err := cache.NewManager(cache.ManagerOpt{
+		Snapshotter:    snapshot.FromContainerdSnapshotter("native", containerdsnapshot.NSSnapshotter("buildkit", mdb.Snapshotter("native")), nil),
+		MetadataStore:  md,
+		LeaseManager:   lm,
+		ContentStore:   c,
+		Applier:        applier,
+		Differ:         differ,
+		GarbageCollect: mdb.GarbageCollect,
+		Root:           tmpdir,
+		MountPoolRoot:  filepath.Join(tmpdir, "cachemounts"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() {
+		require.NoError(t, cm.Close())
+	})
+
+	return NewSource(Opt{
+		CacheAccessor:              cm,
+		AllowDaemonCredentials:     allowDaemonCredentials,
+		DaemonCredentialsEndpoints: daemonCredentialsEndpoints,
+	})
+}
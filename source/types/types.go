@@ -7,4 +7,6 @@ const (
 	HTTPScheme        = "http"
 	HTTPSScheme       = "https"
 	OCIScheme         = "oci-layout"
+	S3Scheme          = "s3"
+	OCIArtifactScheme = "oci-artifact"
 )
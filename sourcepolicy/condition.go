@@ -0,0 +1,56 @@
+package sourcepolicy
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy/expr"
+)
+
+type platformKey struct{}
+
+type frontendAttrsKey struct{}
+
+// WithPlatform returns a context that makes the platform that a source is
+// requested for available to the conditions of selectors.
+func WithPlatform(ctx context.Context, p *pb.Platform) context.Context {
+	return context.WithValue(ctx, platformKey{}, p)
+}
+
+// WithFrontendAttrs returns a context that makes the frontend attrs of the
+// build available to the conditions of selectors.
+func WithFrontendAttrs(ctx context.Context, attrs map[string]string) context.Context {
+	return context.WithValue(ctx, frontendAttrsKey{}, attrs)
+}
+
+// conditionVars returns the variables of a selector condition:
+//
+//	identifier: the identifier of the source
+//	attrs:      the attributes of the source
+//	platform:   the os, architecture and variant that the source is requested for
+//	frontend:   the frontend attrs of the build, e.g. frontend["target"]
+func conditionVars(ctx context.Context, ident string, attrs map[string]string) expr.Vars {
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	platform := map[string]string{
+		"os":           "",
+		"architecture": "",
+		"variant":      "",
+	}
+	if p, _ := ctx.Value(platformKey{}).(*pb.Platform); p != nil {
+		platform["os"] = p.OS
+		platform["architecture"] = p.Architecture
+		platform["variant"] = p.Variant
+	}
+	frontend, _ := ctx.Value(frontendAttrsKey{}).(map[string]string)
+	if frontend == nil {
+		frontend = map[string]string{}
+	}
+	return expr.Vars{
+		"identifier": ident,
+		"attrs":      attrs,
+		"platform":   platform,
+		"frontend":   frontend,
+	}
+}
@@ -2,6 +2,7 @@ package sourcepolicy
 
 import (
 	"context"
+	"maps"
 
 	"github.com/moby/buildkit/solver/pb"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
@@ -26,6 +27,8 @@ var (
 // Mutations are delegated to the `Mutater` interface.
 type Engine struct {
 	pol     []*spb.Policy
+	names   map[*spb.Policy]string
+	tracer  func(*Trace)
 	sources map[string]*selectorCache
 }
 
@@ -36,6 +39,21 @@ func NewEngine(pol []*spb.Policy) *Engine {
 	}
 }
 
+// SetPolicyName sets the name that the engine refers to the policy by when the
+// policy denies a source, e.g. the file that the policy was loaded from.
+func (e *Engine) SetPolicyName(pol *spb.Policy, name string) {
+	if e.names == nil {
+		e.names = map[*spb.Policy]string{}
+	}
+	e.names[pol] = name
+}
+
+// SetTracer sets a function that is called with the trace of every source
+// that the engine evaluates.
+func (e *Engine) SetTracer(fn func(*Trace)) {
+	e.tracer = fn
+}
+
 // TODO: The key here can't be used to cache attr constraint regexes.
 func (e *Engine) selectorCache(src *spb.Selector) *selectorCache {
 	if e.sources == nil {
@@ -43,6 +61,9 @@ func (e *Engine) selectorCache(src *spb.Selector) *selectorCache {
 	}
 
 	key := src.MatchType.String() + " " + src.Identifier
+	if src.Condition != "" {
+		key += " if " + src.Condition
+	}
 
 	if s, ok := e.sources[key]; ok {
 		return s
@@ -61,11 +82,23 @@ func (e *Engine) selectorCache(src *spb.Selector) *selectorCache {
 // This function may error out even if the op was mutated, in which case `true` will be returned along with the error.
 //
 // An error is returned when the source is denied by the policy.
-func (e *Engine) Evaluate(ctx context.Context, op *pb.SourceOp) (bool, error) {
+func (e *Engine) Evaluate(ctx context.Context, op *pb.SourceOp) (_ bool, retErr error) {
 	if len(e.pol) == 0 || op == nil {
 		return false, nil
 	}
 
+	var tr *Trace
+	if e.tracer != nil {
+		tr = &Trace{Source: op.Identifier}
+		defer func() {
+			tr.Result = op.Identifier
+			if retErr != nil {
+				tr.Error = retErr.Error()
+			}
+			e.tracer(tr)
+		}()
+	}
+
 	var mutated bool
 	const maxIterr = 20
 
@@ -81,7 +114,7 @@ func (e *Engine) Evaluate(ctx context.Context, op *pb.SourceOp) (bool, error) {
 			ctx = bklog.WithLogger(ctx, bklog.G(ctx).WithField("updated", op))
 		}
 
-		mut, err := e.evaluatePolicies(ctx, op)
+		mut, err := e.evaluatePolicies(ctx, op, tr)
 		if mut {
 			mutated = true
 		}
@@ -96,9 +129,9 @@ func (e *Engine) Evaluate(ctx context.Context, op *pb.SourceOp) (bool, error) {
 	return mutated, nil
 }
 
-func (e *Engine) evaluatePolicies(ctx context.Context, srcOp *pb.SourceOp) (bool, error) {
-	for _, pol := range e.pol {
-		mut, err := e.evaluatePolicy(ctx, pol, srcOp)
+func (e *Engine) evaluatePolicies(ctx context.Context, srcOp *pb.SourceOp, tr *Trace) (bool, error) {
+	for i, pol := range e.pol {
+		mut, err := e.evaluatePolicy(ctx, i, pol, srcOp, tr)
 		if mut || err != nil {
 			return mut, err
 		}
@@ -109,10 +142,11 @@ func (e *Engine) evaluatePolicies(ctx context.Context, srcOp *pb.SourceOp) (bool
 // evaluatePolicy evaluates a single policy against a source operation.
 // If the source is mutated the policy is short-circuited and `true` is returned.
 // If the source is denied, an error will be returned.
+// The rules that match the source are recorded in tr if it is not nil.
 //
 // For Allow/Deny rules, the last matching rule wins.
 // E.g. `ALLOW foo; DENY foo` will deny `foo`, `DENY foo; ALLOW foo` will allow `foo`.
-func (e *Engine) evaluatePolicy(ctx context.Context, pol *spb.Policy, srcOp *pb.SourceOp) (retMut bool, retErr error) {
+func (e *Engine) evaluatePolicy(ctx context.Context, idx int, pol *spb.Policy, srcOp *pb.SourceOp, tr *Trace) (retMut bool, retErr error) {
 	ident := srcOp.GetIdentifier()
 
 	ctx = bklog.WithLogger(ctx, bklog.G(ctx).WithField("ref", ident))
@@ -128,9 +162,10 @@ func (e *Engine) evaluatePolicy(ctx context.Context, pol *spb.Policy, srcOp *pb.
 	}()
 
 	var deny bool
-	for _, rule := range pol.Rules {
+	var denyRule int
+	for i, rule := range pol.Rules {
 		selector := e.selectorCache(rule.Selector)
-		matched, err := match(selector, ident, srcOp.Attrs)
+		matched, err := match(ctx, selector, ident, srcOp.Attrs)
 		if err != nil {
 			return false, errors.Wrap(err, "error matching source policy")
 		}
@@ -138,22 +173,50 @@ func (e *Engine) evaluatePolicy(ctx context.Context, pol *spb.Policy, srcOp *pb.
 			continue
 		}
 
+		if tr != nil {
+			tr.Steps = append(tr.Steps, newTraceStep(idx, e.names[pol], i, rule, ident))
+		}
+
 		switch rule.Action {
 		case spb.PolicyAction_ALLOW:
 			deny = false
 		case spb.PolicyAction_DENY:
 			deny = true
+			denyRule = i
 		case spb.PolicyAction_CONVERT:
+			var attrs map[string]string
+			if tr != nil {
+				attrs = maps.Clone(srcOp.Attrs)
+			}
 			mut, err := mutate(ctx, srcOp, rule, selector, ident)
+			if mut && tr != nil {
+				tr.traceUpdate(srcOp, attrs)
+			}
 			if err != nil || mut {
 				return mut, errors.Wrap(err, "error mutating source policy")
 			}
+		case spb.PolicyAction_VERIFY:
+			var attrs map[string]string
+			if tr != nil {
+				attrs = maps.Clone(srcOp.Attrs)
+			}
+			mut, err := requireSignature(ctx, srcOp, rule)
+			if mut && tr != nil {
+				tr.traceUpdate(srcOp, attrs)
+			}
+			if err != nil || mut {
+				return mut, errors.Wrap(err, "error requiring signature for source policy")
+			}
 		default:
 			return false, errors.Errorf("source policy: rule %s %s: unknown type %q", rule.Action, rule.Selector.Identifier, ident)
 		}
 	}
 
 	if deny {
+		if name, ok := e.names[pol]; ok {
+			rule := pol.Rules[denyRule]
+			return false, errors.Wrapf(ErrSourceDenied, "source %q denied by rules[%d] (%s %s) of %s", ident, denyRule, rule.Action, rule.Selector.Identifier, name)
+		}
 		return false, errors.Wrapf(ErrSourceDenied, "source %q denied by policy", ident)
 	}
 	return false, nil
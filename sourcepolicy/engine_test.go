@@ -2,11 +2,13 @@ package sourcepolicy
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/moby/buildkit/solver/pb"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/bklog"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -23,7 +25,128 @@ func TestEngineEvaluate(t *testing.T) {
 	t.Run("Test convert wildcard", testConvertWildcard)
 	t.Run("Test convert multiple", testConvertMultiple)
 	t.Run("test multiple policies", testMultiplePolicies)
+	t.Run("Named policy deny", testNamedPolicyDeny)
+	t.Run("Trace", testTrace)
+	t.Run("TraceAttrs", testTraceAttrs)
+	t.Run("Conditions", testConditions)
 	t.Run("Last rule wins", testLastRuleWins)
+	t.Run("Verify", testVerify)
+	t.Run("Verify non-image", testVerifyNonImage)
+}
+
+func testVerify(t *testing.T) {
+	pol := []*spb.Policy{
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_CONVERT,
+					Selector: &spb.Selector{
+						Identifier: "docker-image://docker.io/library/busybox:latest",
+					},
+					Updates: &spb.Update{
+						Identifier: "docker-image://docker.io/library/alpine:latest",
+					},
+				},
+				{
+					Action: spb.PolicyAction_VERIFY,
+					Selector: &spb.Selector{
+						Identifier: "docker-image://docker.io/library/*",
+						MatchType:  spb.MatchType_WILDCARD,
+					},
+					Signature: &spb.SignatureRequirement{
+						PublicKeys: []string{"key1", "key2"},
+					},
+				},
+			},
+		},
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_VERIFY,
+					Selector: &spb.Selector{
+						Identifier: "docker-image://docker.io/library/alpine:latest",
+					},
+					Signature: &spb.SignatureRequirement{
+						PublicKeys: []string{"key3"},
+					},
+				},
+			},
+		},
+	}
+
+	e := NewEngine(pol)
+	op := &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/busybox:latest",
+	}
+	mut, err := e.Evaluate(context.Background(), op)
+	require.NoError(t, err)
+	require.True(t, mut)
+	require.Equal(t, "docker-image://docker.io/library/alpine:latest", op.Identifier)
+	require.JSONEq(t, `[["key1","key2"],["key3"]]`, op.Attrs[pb.AttrImageSignatureKeys])
+
+	// evaluating again does not add the requirements twice
+	mut, err = e.Evaluate(context.Background(), op)
+	require.NoError(t, err)
+	require.False(t, mut)
+	require.JSONEq(t, `[["key1","key2"],["key3"]]`, op.Attrs[pb.AttrImageSignatureKeys])
+
+	// requirements set by the client are kept
+	op = &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/alpine:latest",
+		Attrs: map[string]string{
+			pb.AttrImageSignatureKeys: `[["key4"]]`,
+		},
+	}
+	mut, err = e.Evaluate(context.Background(), op)
+	require.NoError(t, err)
+	require.True(t, mut)
+	require.JSONEq(t, `[["key4"],["key1","key2"],["key3"]]`, op.Attrs[pb.AttrImageSignatureKeys])
+}
+
+func testVerifyNonImage(t *testing.T) {
+	pol := []*spb.Policy{
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_VERIFY,
+					Selector: &spb.Selector{
+						Identifier: "*",
+						MatchType:  spb.MatchType_WILDCARD,
+					},
+					Signature: &spb.SignatureRequirement{
+						PublicKeys: []string{"key1"},
+					},
+				},
+			},
+		},
+	}
+
+	// sources other than images are not verified
+	e := NewEngine(pol)
+	for _, ident := range []string{
+		"https://example.com/foo",
+		"git://github.com/moby/buildkit.git#main",
+		"local://context",
+	} {
+		op := &pb.SourceOp{Identifier: ident}
+		mut, err := e.Evaluate(context.Background(), op)
+		require.NoError(t, err, ident)
+		require.False(t, mut, ident)
+		require.Empty(t, op.Attrs, ident)
+	}
+
+	op := &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"}
+	mut, err := e.Evaluate(context.Background(), op)
+	require.NoError(t, err)
+	require.True(t, mut)
+	require.JSONEq(t, `[["key1"]]`, op.Attrs[pb.AttrImageSignatureKeys])
+
+	pol[0].Rules[0].Signature = nil
+	e = NewEngine(pol)
+	_, err = e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/busybox:latest",
+	})
+	require.ErrorContains(t, err, "missing public keys for verify rule")
 }
 
 func testLastRuleWins(t *testing.T) {
@@ -92,6 +215,220 @@ func testMultiplePolicies(t *testing.T) {
 	require.False(t, mut)
 }
 
+func testNamedPolicyDeny(t *testing.T) {
+	daemon := &spb.Policy{
+		Rules: []*spb.Rule{
+			{
+				Action: spb.PolicyAction_DENY,
+				Selector: &spb.Selector{
+					Identifier: "docker-image://docker.io/*",
+					MatchType:  spb.MatchType_WILDCARD,
+				},
+			},
+			{
+				Action: spb.PolicyAction_ALLOW,
+				Selector: &spb.Selector{
+					Identifier: "docker-image://docker.io/library/*",
+					MatchType:  spb.MatchType_WILDCARD,
+				},
+			},
+		},
+	}
+	client := &spb.Policy{
+		Rules: []*spb.Rule{
+			{
+				Action: spb.PolicyAction_ALLOW,
+				Selector: &spb.Selector{
+					Identifier: "docker-image://*",
+					MatchType:  spb.MatchType_WILDCARD,
+				},
+			},
+		},
+	}
+
+	e := NewEngine([]*spb.Policy{daemon, client})
+	e.SetPolicyName(daemon, "daemon policy")
+
+	_, err := e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/busybox:latest",
+	})
+	require.NoError(t, err)
+
+	_, err = e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "docker-image://docker.io/foo/bar:latest",
+	})
+	require.ErrorIs(t, err, ErrSourceDenied)
+	require.ErrorContains(t, err, `source "docker-image://docker.io/foo/bar:latest" denied by rules[0] (DENY docker-image://docker.io/*) of daemon policy`)
+}
+
+func testTrace(t *testing.T) {
+	pol := []*spb.Policy{
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_CONVERT,
+					Selector: &spb.Selector{
+						Identifier: `^docker-image://docker\.io/library/golang:([0-9.]+)$`,
+						MatchType:  spb.MatchType_REGEX,
+					},
+					Updates: &spb.Update{
+						Identifier: "docker-image://docker.io/library/golang:${1}-alpine",
+					},
+				},
+			},
+		},
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_DENY,
+					Selector: &spb.Selector{
+						Identifier: "docker-image://docker.io/library/golang:*-alpine",
+						MatchType:  spb.MatchType_WILDCARD,
+						Constraints: []*spb.AttrConstraint{
+							{Key: "foo", Value: "bar", Condition: spb.AttrMatch_NOTEQUAL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var traces []*Trace
+	e := NewEngine(pol)
+	e.SetPolicyName(pol[1], "deny policy")
+	e.SetTracer(func(tr *Trace) {
+		traces = append(traces, tr)
+	})
+
+	_, err := e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/golang:1.22",
+	})
+	require.ErrorIs(t, err, ErrSourceDenied)
+	_, err = e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/busybox:latest",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, traces, 2)
+	tr := traces[0]
+	require.Equal(t, "docker-image://docker.io/library/golang:1.22", tr.Source)
+	require.Equal(t, "docker-image://docker.io/library/golang:1.22-alpine", tr.Result)
+	require.Contains(t, tr.Error, "denied by rules[0]")
+	require.Len(t, tr.Steps, 2)
+	require.Equal(t, `policies[0] rules[0]: CONVERT REGEX "^docker-image://docker\\.io/library/golang:([0-9.]+)$" matched docker-image://docker.io/library/golang:1.22 -> docker-image://docker.io/library/golang:1.22-alpine`, tr.Steps[0].String())
+	require.Equal(t, `deny policy rules[0]: DENY WILDCARD "docker-image://docker.io/library/golang:*-alpine" [foo NOTEQUAL "bar"] matched docker-image://docker.io/library/golang:1.22-alpine`, tr.Steps[1].String())
+
+	tr = traces[1]
+	require.Empty(t, tr.Steps)
+	require.Equal(t, tr.Source, tr.Result)
+	require.Empty(t, tr.Error)
+}
+
+func testTraceAttrs(t *testing.T) {
+	key := "-----BEGIN PUBLIC KEY-----\n" + strings.Repeat("A", 200) + "\n-----END PUBLIC KEY-----\n"
+	pol := []*spb.Policy{
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_CONVERT,
+					Selector: &spb.Selector{
+						Identifier: "https://example.com/foo",
+					},
+					Updates: &spb.Update{
+						Attrs: map[string]string{pb.AttrHTTPChecksum: "sha256:1234"},
+					},
+				},
+				{
+					Action: spb.PolicyAction_VERIFY,
+					Selector: &spb.Selector{
+						Identifier: "docker-image://docker.io/library/alpine:latest",
+					},
+					Signature: &spb.SignatureRequirement{
+						PublicKeys: []string{key},
+					},
+				},
+			},
+		},
+	}
+
+	var traces []*Trace
+	e := NewEngine(pol)
+	e.SetTracer(func(tr *Trace) {
+		traces = append(traces, tr)
+	})
+
+	// only the attributes that the rule changed are recorded
+	_, err := e.Evaluate(context.Background(), &pb.SourceOp{
+		Identifier: "https://example.com/foo",
+		Attrs:      map[string]string{pb.AttrHTTPFilename: "foo"},
+	})
+	require.NoError(t, err)
+
+	// long values are recorded by their digest
+	op := &pb.SourceOp{
+		Identifier: "docker-image://docker.io/library/alpine:latest",
+	}
+	_, err = e.Evaluate(context.Background(), op)
+	require.NoError(t, err)
+
+	require.Len(t, traces, 2)
+	require.NotEmpty(t, traces[0].Steps)
+	require.Equal(t, map[string]string{pb.AttrHTTPChecksum: "sha256:1234"}, traces[0].Steps[0].Attrs)
+	require.NotEmpty(t, traces[1].Steps)
+	require.Equal(t, map[string]string{
+		pb.AttrImageSignatureKeys: digest.FromString(op.Attrs[pb.AttrImageSignatureKeys]).String(),
+	}, traces[1].Steps[0].Attrs)
+	require.NotContains(t, traces[1].Steps[0].String(), "BEGIN PUBLIC KEY")
+}
+
+func testConditions(t *testing.T) {
+	pol := []*spb.Policy{
+		{
+			Rules: []*spb.Rule{
+				{
+					Action: spb.PolicyAction_DENY,
+					Selector: &spb.Selector{
+						Identifier: "https://*",
+						Condition:  `!("http.checksum" in attrs)`,
+					},
+				},
+				{
+					Action: spb.PolicyAction_DENY,
+					Selector: &spb.Selector{
+						Identifier: "git://*",
+					},
+				},
+				{
+					Action: spb.PolicyAction_ALLOW,
+					Selector: &spb.Selector{
+						Identifier: "git://*",
+						Condition:  `"git.checksum" in attrs`,
+					},
+				},
+			},
+		},
+	}
+
+	e := NewEngine(pol)
+	for _, tc := range []struct {
+		op     *pb.SourceOp
+		denied bool
+	}{
+		{op: &pb.SourceOp{Identifier: "https://example.com/foo.tar.gz"}, denied: true},
+		{op: &pb.SourceOp{Identifier: "https://example.com/foo.tar.gz", Attrs: map[string]string{pb.AttrHTTPChecksum: "sha256:aaaa"}}},
+		{op: &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git"}, denied: true},
+		{op: &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git", Attrs: map[string]string{pb.AttrGitChecksum: "2951a28cd7085eb18979b1f710678623d94ed578"}}},
+		{op: &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"}},
+	} {
+		_, err := e.Evaluate(context.Background(), tc.op)
+		if tc.denied {
+			require.ErrorIs(t, err, ErrSourceDenied, tc.op.Identifier)
+		} else {
+			require.NoError(t, err, tc.op.Identifier)
+		}
+	}
+}
+
 func testConvertMultiple(t *testing.T) {
 	pol := []*spb.Policy{
 		{
@@ -0,0 +1,292 @@
+package expr
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Vars are the variables that an expression is evaluated with. Values must be
+// of type string, bool, int64, []string or map[string]string.
+type Vars map[string]any
+
+// EvalBool evaluates the expression and returns its result, which must be a
+// boolean.
+func (e *Expr) EvalBool(vars Vars) (bool, error) {
+	v, err := eval(e.root, vars)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to evaluate %q", e.src)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("failed to evaluate %q: expected bool result but got %s", e.src, typeName(v))
+	}
+	return b, nil
+}
+
+func eval(n node, vars Vars) (any, error) {
+	switch n := n.(type) {
+	case *literal:
+		return n.v, nil
+	case *ident:
+		v, ok := vars[n.name]
+		if !ok {
+			return nil, errors.Errorf("undeclared reference to %q", n.name)
+		}
+		if l, ok := v.([]string); ok {
+			return toList(l), nil
+		}
+		return v, nil
+	case *list:
+		l := make([]any, 0, len(n.elems))
+		for _, e := range n.elems {
+			v, err := eval(e, vars)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		return l, nil
+	case *unary:
+		x, err := evalBool(n.x, vars)
+		if err != nil {
+			return nil, err
+		}
+		return !x, nil
+	case *binary:
+		return evalBinary(n, vars)
+	case *index:
+		return evalIndex(n, vars)
+	case *call:
+		return evalCall(n, vars)
+	}
+	return nil, errors.Errorf("invalid node %T", n)
+}
+
+func evalBool(n node, vars Vars) (bool, error) {
+	v, err := eval(n, vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, errors.Errorf("expected bool but got %s", typeName(v))
+	}
+	return b, nil
+}
+
+func evalBinary(n *binary, vars Vars) (any, error) {
+	switch n.op {
+	case "&&", "||":
+		x, err := evalBool(n.x, vars)
+		if err != nil {
+			return nil, err
+		}
+		if x == (n.op == "||") {
+			return x, nil
+		}
+		return evalBool(n.y, vars)
+	}
+
+	x, err := eval(n.x, vars)
+	if err != nil {
+		return nil, err
+	}
+	y, err := eval(n.y, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==", "!=":
+		eq, err := equal(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return eq == (n.op == "=="), nil
+	case "in":
+		switch c := y.(type) {
+		case map[string]string:
+			k, ok := x.(string)
+			if !ok {
+				return nil, errors.Errorf("no such overload: %s in map", typeName(x))
+			}
+			_, ok = c[k]
+			return ok, nil
+		case []any:
+			for _, e := range c {
+				if eq, err := equal(x, e); err == nil && eq {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return nil, errors.Errorf("no such overload: %s in %s", typeName(x), typeName(y))
+	}
+
+	var c int
+	switch x := x.(type) {
+	case int64:
+		y, ok := y.(int64)
+		if !ok {
+			return nil, errors.Errorf("no such overload: int %s %s", n.op, typeName(y))
+		}
+		c = cmp.Compare(x, y)
+	case string:
+		y, ok := y.(string)
+		if !ok {
+			return nil, errors.Errorf("no such overload: string %s %s", n.op, typeName(y))
+		}
+		c = strings.Compare(x, y)
+	default:
+		return nil, errors.Errorf("no such overload: %s %s %s", typeName(x), n.op, typeName(y))
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+	return nil, errors.Errorf("invalid operator %q", n.op)
+}
+
+func evalIndex(n *index, vars Vars) (any, error) {
+	x, err := eval(n.x, vars)
+	if err != nil {
+		return nil, err
+	}
+	i, err := eval(n.i, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case map[string]string:
+		k, ok := i.(string)
+		if !ok {
+			return nil, errors.Errorf("no such overload: map[%s]", typeName(i))
+		}
+		v, ok := x[k]
+		if !ok {
+			return nil, errors.Errorf("no such key: %q", k)
+		}
+		return v, nil
+	case []any:
+		k, ok := i.(int64)
+		if !ok {
+			return nil, errors.Errorf("no such overload: list[%s]", typeName(i))
+		}
+		if k < 0 || k >= int64(len(x)) {
+			return nil, errors.Errorf("index out of range: %d", k)
+		}
+		return x[k], nil
+	}
+	return nil, errors.Errorf("no such overload: %s[%s]", typeName(x), typeName(i))
+}
+
+func evalCall(n *call, vars Vars) (any, error) {
+	args := make([]any, 0, len(n.args)+1)
+	if n.recv != nil {
+		v, err := eval(n.recv, vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	for _, a := range n.args {
+		v, err := eval(a, vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	if n.name == "size" && len(args) == 1 {
+		switch v := args[0].(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case map[string]string:
+			return int64(len(v)), nil
+		case []any:
+			return int64(len(v)), nil
+		}
+		return nil, errors.Errorf("no such overload: size(%s)", typeName(args[0]))
+	}
+
+	if n.recv == nil || len(args) != 2 {
+		return nil, errors.Errorf("unknown function %s with %d arguments", n.name, len(args))
+	}
+	s, ok1 := args[0].(string)
+	arg, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return nil, errors.Errorf("no such overload: %s.%s(%s)", typeName(args[0]), n.name, typeName(args[1]))
+	}
+	switch n.name {
+	case "startsWith":
+		return strings.HasPrefix(s, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(s, arg), nil
+	case "contains":
+		return strings.Contains(s, arg), nil
+	case "matches":
+		if n.re != nil {
+			return n.re.MatchString(s), nil
+		}
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regex %q", arg)
+		}
+		return re.MatchString(s), nil
+	}
+	return nil, errors.Errorf("unknown method %s", n.name)
+}
+
+func equal(x, y any) (bool, error) {
+	switch x := x.(type) {
+	case string, bool, int64:
+		if typeName(x) != typeName(y) {
+			return false, errors.Errorf("no such overload: %s == %s", typeName(x), typeName(y))
+		}
+		return x == y, nil
+	case []any:
+		y, ok := y.([]any)
+		if !ok {
+			return false, errors.Errorf("no such overload: list == %s", typeName(y))
+		}
+		return slices.EqualFunc(x, y, func(a, b any) bool {
+			eq, err := equal(a, b)
+			return err == nil && eq
+		}), nil
+	}
+	return false, errors.Errorf("no such overload: %s == %s", typeName(x), typeName(y))
+}
+
+func toList(l []string) []any {
+	out := make([]any, len(l))
+	for i, v := range l {
+		out[i] = v
+	}
+	return out
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case []any:
+		return "list"
+	case map[string]string:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
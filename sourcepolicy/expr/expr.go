@@ -0,0 +1,435 @@
+// Package expr implements the conditions of source policy selectors. The
+// language is a small subset of CEL (https://cel.dev) over strings, booleans,
+// integers, lists and string maps:
+//
+//	identifier.startsWith("https://") && !("http.checksum" in attrs)
+//	platform.architecture == "arm64" || size(attrs) > 2
+//
+// Supported are the operators `!`, `&&`, `||`, `==`, `!=`, `<`, `<=`, `>`,
+// `>=` and `in`, indexing with `[]` and `.`, list literals, the function
+// `size` and the string methods `startsWith`, `endsWith`, `contains`,
+// `matches` and `size`.
+package expr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxDepth is the maximum nesting depth of parentheses, lists, indexes,
+// function arguments and negations in an expression.
+const maxDepth = 32
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses an expression.
+func Parse(src string) (*Expr, error) {
+	p := &parser{lex: lexer{src: src}}
+	p.next()
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression %q", src)
+	}
+	if p.tok.kind != tokEOF {
+		return nil, errors.Errorf("invalid expression %q: unexpected %s at offset %d", src, p.tok, p.tok.pos)
+	}
+	return &Expr{src: src, root: n}, nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+type node interface{}
+
+type literal struct {
+	v any
+}
+
+type ident struct {
+	name string
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+type binary struct {
+	op   string
+	x, y node
+}
+
+type index struct {
+	x, i node
+}
+
+type call struct {
+	recv node // nil for global functions
+	name string
+	args []node
+	re   *regexp.Regexp // compiled argument of matches with a string literal
+}
+
+type list struct {
+	elems []node
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	val  string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.val)
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", "."}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && strings.ContainsRune(" \t\r\n", rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '"' || c == '\'':
+		var sb strings.Builder
+		l.pos++
+		for {
+			if l.pos >= len(l.src) {
+				return token{}, errors.Errorf("unterminated string at offset %d", start)
+			}
+			c2 := l.src[l.pos]
+			if c2 == c {
+				l.pos++
+				return token{kind: tokString, val: sb.String(), pos: start}, nil
+			}
+			if c2 == '\\' {
+				if l.pos+1 >= len(l.src) {
+					return token{}, errors.Errorf("unterminated string at offset %d", start)
+				}
+				switch e := l.src[l.pos+1]; e {
+				case '\\', '"', '\'':
+					sb.WriteByte(e)
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					return token{}, errors.Errorf("invalid escape sequence \\%c at offset %d", e, l.pos)
+				}
+				l.pos += 2
+				continue
+			}
+			sb.WriteByte(c2)
+			l.pos++
+		}
+	case c >= '0' && c <= '9':
+		for l.pos < len(l.src) && l.src[l.pos] >= '0' && l.src[l.pos] <= '9' {
+			l.pos++
+		}
+		return token{kind: tokInt, val: l.src[start:l.pos], pos: start}, nil
+	case c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z'):
+		for l.pos < len(l.src) {
+			c := l.src[l.pos]
+			if c != '_' && (c|0x20 < 'a' || c|0x20 > 'z') && (c < '0' || c > '9') {
+				break
+			}
+			l.pos++
+		}
+		return token{kind: tokIdent, val: l.src[start:l.pos], pos: start}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, val: op, pos: start}, nil
+		}
+	}
+	return token{}, errors.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+type parser struct {
+	lex   lexer
+	tok   token
+	err   error
+	depth int
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+}
+
+func (p *parser) is(op string) bool {
+	return p.err == nil && p.tok.kind == tokOp && p.tok.val == op
+}
+
+func (p *parser) expect(op string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if !p.is(op) {
+		return errors.Errorf("expected %q but got %s at offset %d", op, p.tok, p.tok.pos)
+	}
+	p.next()
+	return p.err
+}
+
+// enter is called before parsing a nested expression. The returned function
+// must be called when the nested expression has been parsed.
+func (p *parser) enter() (func(), error) {
+	if p.depth >= maxDepth {
+		return nil, errors.Errorf("expression nested deeper than %d levels at offset %d", maxDepth, p.tok.pos)
+	}
+	p.depth++
+	return func() { p.depth-- }, nil
+}
+
+func (p *parser) parseExpr() (node, error) {
+	leave, err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (node, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("||") {
+		p.next()
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: "||", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	x, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("&&") {
+		p.next()
+		y, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op: "&&", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	var op string
+	switch {
+	case p.err != nil:
+		return nil, p.err
+	case p.tok.kind == tokIdent && p.tok.val == "in":
+		op = "in"
+	case p.tok.kind == tokOp && strings.Contains(" == != < <= > >= ", " "+p.tok.val+" "):
+		op = p.tok.val
+	default:
+		return x, nil
+	}
+	p.next()
+	y, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: op, x: x, y: y}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.is("!") {
+		leave, err := p.enter()
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: "!", x: x}, nil
+	}
+	return p.parseMember()
+}
+
+func (p *parser) parseMember() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.is("."):
+			p.next()
+			if p.err != nil {
+				return nil, p.err
+			}
+			if p.tok.kind != tokIdent {
+				return nil, errors.Errorf("expected field or method name but got %s at offset %d", p.tok, p.tok.pos)
+			}
+			name := p.tok.val
+			p.next()
+			if p.is("(") {
+				args, err := p.parseArgs(")")
+				if err != nil {
+					return nil, err
+				}
+				c := &call{recv: x, name: name, args: args}
+				if name == "matches" && len(args) == 1 {
+					if l, ok := args[0].(*literal); ok {
+						if s, ok := l.v.(string); ok {
+							re, err := regexp.Compile(s)
+							if err != nil {
+								return nil, errors.Wrapf(err, "invalid regex %q", s)
+							}
+							c.re = re
+						}
+					}
+				}
+				x = c
+			} else {
+				x = &index{x: x, i: &literal{v: name}}
+			}
+		case p.is("["):
+			p.next()
+			i, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &index{x: x, i: i}
+		default:
+			return x, p.err
+		}
+	}
+}
+
+// parseArgs parses a comma separated list of expressions after the opening
+// token up to the closing token.
+func (p *parser) parseArgs(end string) ([]node, error) {
+	p.next()
+	var args []node
+	for !p.is(end) {
+		if p.err != nil {
+			return nil, p.err
+		}
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		a, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+	}
+	p.next()
+	return args, p.err
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokString:
+		p.next()
+		return &literal{v: tok.val}, p.err
+	case tokInt:
+		v, err := strconv.ParseInt(tok.val, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid integer at offset %d", tok.pos)
+		}
+		p.next()
+		return &literal{v: v}, p.err
+	case tokIdent:
+		p.next()
+		switch tok.val {
+		case "true":
+			return &literal{v: true}, p.err
+		case "false":
+			return &literal{v: false}, p.err
+		case "in":
+			return nil, errors.Errorf("unexpected \"in\" at offset %d", tok.pos)
+		}
+		if p.is("(") {
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			return &call{name: tok.val, args: args}, nil
+		}
+		return &ident{name: tok.val}, p.err
+	case tokOp:
+		switch tok.val {
+		case "(":
+			p.next()
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		case "[":
+			elems, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+			return &list{elems: elems}, nil
+		}
+	}
+	return nil, errors.Errorf("unexpected %s at offset %d", tok, tok.pos)
+}
@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalBool(t *testing.T) {
+	vars := Vars{
+		"identifier": "https://example.com/foo.tar.gz",
+		"attrs": map[string]string{
+			"http.checksum": "sha256:aaaa",
+			"http.filename": "foo.tar.gz",
+		},
+		"platform": map[string]string{
+			"os":           "linux",
+			"architecture": "arm64",
+			"variant":      "",
+		},
+		"tags": []string{"a", "b"},
+	}
+	for _, tc := range []struct {
+		expr     string
+		expected bool
+	}{
+		{`identifier.startsWith("https://")`, true},
+		{`identifier.endsWith(".zip")`, false},
+		{`identifier.contains("example.com")`, true},
+		{`identifier.matches("^https://[a-z.]+/")`, true},
+		{`"http.checksum" in attrs`, true},
+		{`!("git.checksum" in attrs)`, true},
+		{`identifier.startsWith("https://") && !("http.checksum" in attrs)`, false},
+		{`attrs["http.checksum"] == "sha256:aaaa"`, true},
+		{`attrs["http.checksum"] != 'sha256:aaaa'`, false},
+		{`platform.architecture == "arm64" && platform.os == "linux"`, true},
+		{`platform["variant"] == ""`, true},
+		{`size(attrs) == 2 && attrs.size() > 1`, true},
+		{`identifier.size() >= 30`, true},
+		{`"a" in tags && !("c" in tags)`, true},
+		{`platform.architecture in ["amd64", "arm64"]`, true},
+		{`tags == ["a", "b"]`, true},
+		{`"a" < "b" && 1 <= 1 && 2 > 1`, true},
+		{`false || ("git.checksum" in attrs && attrs["git.checksum"] == "x")`, false},
+		{`true || attrs["missing"] == "x"`, true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			require.NoError(t, err)
+			v, err := e.EvalBool(vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, v)
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := Vars{
+		"identifier": "docker-image://docker.io/library/alpine:latest",
+		"attrs":      map[string]string{},
+		"pattern":    "(",
+	}
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{`attrs["missing"] == "x"`, `no such key: "missing"`},
+		{`unknown == "x"`, `undeclared reference to "unknown"`},
+		{`identifier`, `expected bool result but got string`},
+		{`identifier == 1`, `no such overload: string == int`},
+		{`identifier.foo("x")`, `unknown method foo`},
+		{`identifier.matches(pattern)`, `invalid regex`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			require.NoError(t, err)
+			_, err = e.EvalBool(vars)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{``, `unexpected end of expression`},
+		{`"foo`, `unterminated string`},
+		{`a &&`, `unexpected end of expression`},
+		{`(a`, `expected ")" but got end of expression`},
+		{`a b`, `unexpected "b"`},
+		{`a.`, `expected field or method name`},
+		{`a # b`, `unexpected character '#'`},
+		{`[a, b`, `expected "," but got end of expression`},
+		{strings.Repeat("(", 100) + "a" + strings.Repeat(")", 100), `expression nested deeper than 32 levels`},
+		{strings.Repeat("!", 100) + "a", `expression nested deeper than 32 levels`},
+		{"a" + strings.Repeat("[a", 100), `expression nested deeper than 32 levels`},
+		{`a.matches("(")`, `invalid regex`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	// regex literals are compiled once when the expression is parsed
+	e, err := Parse(`identifier.matches("^https://")`)
+	require.NoError(t, err)
+	c, ok := e.root.(*call)
+	require.True(t, ok)
+	require.NotNil(t, c.re)
+	v, err := e.EvalBool(Vars{"identifier": "https://example.com"})
+	require.NoError(t, err)
+	require.True(t, v)
+
+	e, err = Parse(`identifier.matches(pattern)`)
+	require.NoError(t, err)
+	require.Nil(t, e.root.(*call).re)
+	v, err = e.EvalBool(Vars{"identifier": "https://example.com", "pattern": "^git://"})
+	require.NoError(t, err)
+	require.False(t, v)
+}
+
+func TestParseNesting(t *testing.T) {
+	_, err := Parse(strings.Repeat("(", 30) + "true" + strings.Repeat(")", 30))
+	require.NoError(t, err)
+	_, err = Parse(strings.Repeat("!", 30) + "true")
+	require.NoError(t, err)
+
+	// long expressions without nesting are not limited by the depth
+	_, err = Parse("true" + strings.Repeat(" && true", 100))
+	require.NoError(t, err)
+}
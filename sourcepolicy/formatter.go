@@ -3,6 +3,7 @@ package sourcepolicy
 import (
 	"regexp"
 
+	"github.com/moby/buildkit/sourcepolicy/expr"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/moby/buildkit/util/wildcard"
 	"github.com/pkg/errors"
@@ -12,8 +13,9 @@ import (
 type selectorCache struct {
 	*spb.Selector
 
-	re *regexp.Regexp
-	w  *wildcardCache
+	re   *regexp.Regexp
+	w    *wildcardCache
+	cond *expr.Expr
 }
 
 // Format formats the provided ref according to the match/type of the source.
@@ -90,3 +92,15 @@ func (s *selectorCache) regex() (*regexp.Regexp, error) {
 	s.re = re
 	return re, nil
 }
+
+func (s *selectorCache) condition() (*expr.Expr, error) {
+	if s.cond != nil {
+		return s.cond, nil
+	}
+	cond, err := expr.Parse(s.Condition)
+	if err != nil {
+		return nil, err
+	}
+	s.cond = cond
+	return cond, nil
+}
@@ -0,0 +1,177 @@
+// Package lockfile records the sources that a build resolved and converts the
+// record into a source policy that pins the sources for a later build.
+package lockfile
+
+import (
+	"cmp"
+	"encoding/json"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/solver/pb"
+	srctypes "github.com/moby/buildkit/source/types"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/gitutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// ExporterResponseKey is the key of the base64 encoded lockfile in the
+// response of a build.
+const ExporterResponseKey = "source.lockfile"
+
+// Version is the version of the lockfile format.
+const Version = 1
+
+type SourceType string
+
+const (
+	SourceTypeImage       SourceType = "docker-image"
+	SourceTypeOCIArtifact SourceType = "oci-artifact"
+	SourceTypeGit         SourceType = "git"
+	SourceTypeHTTP        SourceType = "http"
+	SourceTypeS3          SourceType = "s3"
+)
+
+// Lockfile lists the sources that a build resolved.
+type Lockfile struct {
+	Version int      `json:"version"`
+	Sources []Source `json:"sources"`
+}
+
+// Source is a resolved source. Ref is the reference as it was requested by
+// the build. Pin is the manifest digest for images and OCI artifacts, the
+// commit for git repositories and the checksum of the content for HTTP and S3
+// sources.
+type Source struct {
+	Type SourceType `json:"type"`
+	Ref  string     `json:"ref"`
+	Pin  string     `json:"pin"`
+}
+
+// New returns an empty lockfile of the current version.
+func New() *Lockfile {
+	return &Lockfile{Version: Version}
+}
+
+// Parse reads a lockfile written by a build.
+func Parse(dt []byte) (*Lockfile, error) {
+	var l Lockfile
+	if err := json.Unmarshal(dt, &l); err != nil {
+		return nil, errors.Wrap(err, "failed to parse source lockfile")
+	}
+	if l.Version != Version {
+		return nil, errors.Errorf("unsupported source lockfile version %d", l.Version)
+	}
+	return &l, nil
+}
+
+// Add adds a source to the lockfile. A source with the same type and ref as
+// one that is already in the lockfile is ignored.
+func (l *Lockfile) Add(src Source) {
+	for _, s := range l.Sources {
+		if s.Type == src.Type && s.Ref == src.Ref {
+			return
+		}
+	}
+	l.Sources = append(l.Sources, src)
+}
+
+// Sort orders the sources by type and ref.
+func (l *Lockfile) Sort() {
+	slices.SortFunc(l.Sources, func(a, b Source) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Ref, b.Ref))
+	})
+}
+
+// Policy returns a source policy with a CONVERT rule for every source that
+// pins the source to the version recorded in the lockfile. Sources that are
+// already pinned by their reference are skipped.
+func (l *Lockfile) Policy() (*spb.Policy, error) {
+	pol := &spb.Policy{}
+	for _, src := range l.Sources {
+		rule, err := src.rule()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s source %s in lockfile", src.Type, src.Ref)
+		}
+		if rule != nil {
+			pol.Rules = append(pol.Rules, rule)
+		}
+	}
+	return pol, nil
+}
+
+func (src Source) rule() (*spb.Rule, error) {
+	switch src.Type {
+	case SourceTypeImage, SourceTypeOCIArtifact:
+		dgst, err := digest.Parse(src.Pin)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(src.Ref, "@") {
+			return nil, nil
+		}
+		scheme := srctypes.DockerImageScheme
+		if src.Type == SourceTypeOCIArtifact {
+			scheme = srctypes.OCIArtifactScheme
+		}
+		id := scheme + "://" + src.Ref
+		return &spb.Rule{
+			Action:   spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{Identifier: id},
+			Updates:  &spb.Update{Identifier: id + "@" + dgst.String()},
+		}, nil
+	case SourceTypeGit:
+		if src.Pin == "" {
+			return nil, errors.New("missing commit")
+		}
+		remote, err := gitutil.ParseURL(src.Ref)
+		if errors.Is(err, gitutil.ErrUnknownProtocol) {
+			remote, err = gitutil.ParseURL("https://" + src.Ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var ref string
+		if remote.Opts != nil {
+			ref = remote.Opts.Ref
+		}
+		if ref == src.Pin {
+			return nil, nil
+		}
+		// git identifiers don't include the scheme of the remote and may
+		// end with a subdirectory that is kept when the ref is replaced
+		id := srctypes.GitScheme + "://" + remote.Host + path.Join("/", remote.Path)
+		pattern := "^" + regexp.QuoteMeta(id) + "#" + regexp.QuoteMeta(ref) + "(:.*)?$"
+		if ref == "" {
+			pattern = "^" + regexp.QuoteMeta(id) + "(?:#(:.*))?$"
+		}
+		return &spb.Rule{
+			Action: spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{
+				Identifier: pattern,
+				MatchType:  spb.MatchType_REGEX,
+			},
+			Updates: &spb.Update{Identifier: id + "#" + src.Pin + "${1}"},
+		}, nil
+	case SourceTypeHTTP:
+		dgst, err := digest.Parse(src.Pin)
+		if err != nil {
+			return nil, err
+		}
+		return &spb.Rule{
+			Action:   spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{Identifier: src.Ref},
+			Updates: &spb.Update{
+				Attrs: map[string]string{pb.AttrHTTPChecksum: dgst.String()},
+			},
+		}, nil
+	case SourceTypeS3:
+		// S3 sources have no checksum attribute so they can't be pinned
+		return nil, nil
+	default:
+		return nil, errors.Errorf("unsupported source type %q", src.Type)
+	}
+}
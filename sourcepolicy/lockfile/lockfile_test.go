@@ -0,0 +1,92 @@
+package lockfile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:3614ca5eacf0a3a1bcc361c939202a974b4902b9334ff36eb29ffe9011aaad83"
+
+func TestPolicy(t *testing.T) {
+	l := New()
+	l.Add(Source{Type: SourceTypeImage, Ref: "docker.io/library/busybox:latest", Pin: testDigest})
+	l.Add(Source{Type: SourceTypeImage, Ref: "docker.io/library/busybox:latest", Pin: "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"})
+	l.Add(Source{Type: SourceTypeImage, Ref: "docker.io/library/alpine:latest@" + testDigest, Pin: testDigest})
+	l.Add(Source{Type: SourceTypeOCIArtifact, Ref: "ghcr.io/example/models:v1", Pin: testDigest})
+	l.Add(Source{Type: SourceTypeGit, Ref: "https://github.com/moby/buildkit.git#v0.11.6", Pin: "2951a28cd7085eb18979b1f710678623d94ed578"})
+	l.Add(Source{Type: SourceTypeGit, Ref: "git@github.com:moby/moby.git", Pin: "f4ffeb8d6d79f0b1e9a48ae0a8c8e8df9a5d5f6d"})
+	l.Add(Source{Type: SourceTypeHTTP, Ref: "https://example.com/foo.tar.gz", Pin: testDigest})
+	l.Add(Source{Type: SourceTypeS3, Ref: "s3://bucket/key", Pin: testDigest})
+	l.Sort()
+	require.Len(t, l.Sources, 7)
+	require.Equal(t, SourceTypeImage, l.Sources[0].Type)
+	require.Equal(t, "docker.io/library/alpine:latest@"+testDigest, l.Sources[0].Ref)
+
+	pol, err := l.Policy()
+	require.NoError(t, err)
+	require.Len(t, pol.Rules, 5)
+
+	e := sourcepolicy.NewEngine([]*spb.Policy{pol})
+	for _, tc := range []struct {
+		op       *pb.SourceOp
+		expected *pb.SourceOp
+	}{
+		{
+			op:       &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"},
+			expected: &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest@" + testDigest},
+		},
+		{
+			op:       &pb.SourceOp{Identifier: "oci-artifact://ghcr.io/example/models:v1"},
+			expected: &pb.SourceOp{Identifier: "oci-artifact://ghcr.io/example/models:v1@" + testDigest},
+		},
+		{
+			op:       &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#v0.11.6"},
+			expected: &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#2951a28cd7085eb18979b1f710678623d94ed578"},
+		},
+		{
+			op:       &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#v0.11.6:frontend"},
+			expected: &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#2951a28cd7085eb18979b1f710678623d94ed578:frontend"},
+		},
+		{
+			op:       &pb.SourceOp{Identifier: "git://github.com/moby/moby.git"},
+			expected: &pb.SourceOp{Identifier: "git://github.com/moby/moby.git#f4ffeb8d6d79f0b1e9a48ae0a8c8e8df9a5d5f6d"},
+		},
+		{
+			op: &pb.SourceOp{Identifier: "https://example.com/foo.tar.gz"},
+			expected: &pb.SourceOp{
+				Identifier: "https://example.com/foo.tar.gz",
+				Attrs:      map[string]string{pb.AttrHTTPChecksum: testDigest},
+			},
+		},
+		{
+			op:       &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#v0.12.0"},
+			expected: &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git#v0.12.0"},
+		},
+	} {
+		t.Run(tc.op.Identifier, func(t *testing.T) {
+			_, err := e.Evaluate(context.TODO(), tc.op)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected.Identifier, tc.op.Identifier)
+			require.Equal(t, tc.expected.Attrs, tc.op.Attrs)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	l, err := Parse([]byte(`{"version":1,"sources":[{"type":"git","ref":"https://github.com/moby/buildkit.git","pin":"2951a28cd7085eb18979b1f710678623d94ed578"}]}`))
+	require.NoError(t, err)
+	require.Len(t, l.Sources, 1)
+
+	_, err = Parse([]byte(`{"version":2}`))
+	require.ErrorContains(t, err, "unsupported source lockfile version 2")
+
+	l = New()
+	l.Add(Source{Type: "foo", Ref: "bar"})
+	_, err = l.Policy()
+	require.ErrorContains(t, err, `unsupported source type "foo"`)
+}
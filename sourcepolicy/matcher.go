@@ -1,13 +1,14 @@
 package sourcepolicy
 
 import (
+	"context"
 	"regexp"
 
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/pkg/errors"
 )
 
-func match(src *selectorCache, ref string, attrs map[string]string) (bool, error) {
+func match(ctx context.Context, src *selectorCache, ref string, attrs map[string]string) (bool, error) {
 	for _, c := range src.Constraints {
 		if c == nil {
 			return false, errors.Errorf("invalid nil constraint for %v", src)
@@ -35,6 +36,18 @@ func match(src *selectorCache, ref string, attrs map[string]string) (bool, error
 		}
 	}
 
+	matched, err := matchIdentifier(src, ref)
+	if err != nil || !matched || src.Condition == "" {
+		return matched, err
+	}
+	cond, err := src.condition()
+	if err != nil {
+		return false, err
+	}
+	return cond.EvalBool(conditionVars(ctx, ref, attrs))
+}
+
+func matchIdentifier(src *selectorCache, ref string) (bool, error) {
 	if src.Identifier == ref {
 		return true, nil
 	}
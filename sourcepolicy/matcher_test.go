@@ -1,8 +1,10 @@
 package sourcepolicy
 
 import (
+	"context"
 	"testing"
 
+	"github.com/moby/buildkit/solver/pb"
 	spb "github.com/moby/buildkit/sourcepolicy/pb"
 	"github.com/stretchr/testify/require"
 )
@@ -315,11 +317,67 @@ func TestMatch(t *testing.T) {
 			attrs:   map[string]string{"bar": "Bar"},
 			matches: false,
 		},
+		{
+			name: "condition on missing attr",
+			src: &spb.Selector{
+				Identifier: "https://*",
+				Condition:  `!("http.checksum" in attrs)`,
+			},
+			ref:     "https://example.com/foo.tar.gz",
+			matches: true,
+		},
+		{
+			name: "condition on set attr",
+			src: &spb.Selector{
+				Identifier: "https://*",
+				Condition:  `!("http.checksum" in attrs)`,
+			},
+			ref:     "https://example.com/foo.tar.gz",
+			attrs:   map[string]string{"http.checksum": "sha256:aaaa"},
+			matches: false,
+		},
+		{
+			name: "condition on identifier",
+			src: &spb.Selector{
+				Identifier: "*",
+				Condition:  `identifier.startsWith("git://") && attrs["git.checksum"].size() == 40`,
+			},
+			ref:     "git://github.com/moby/buildkit.git",
+			attrs:   map[string]string{"git.checksum": "2951a28cd7085eb18979b1f710678623d94ed578"},
+			matches: true,
+		},
+		{
+			name: "condition not evaluated for mismatching identifier",
+			src: &spb.Selector{
+				Identifier: "git://*",
+				Condition:  `attrs["git.checksum"] != ""`,
+			},
+			ref:     "https://example.com/foo.tar.gz",
+			matches: false,
+		},
+		{
+			name: "condition error",
+			src: &spb.Selector{
+				Identifier: "git://*",
+				Condition:  `attrs["git.checksum"] != ""`,
+			},
+			ref:  "git://github.com/moby/buildkit.git",
+			xErr: true,
+		},
+		{
+			name: "invalid condition",
+			src: &spb.Selector{
+				Identifier: "*",
+				Condition:  `identifier ==`,
+			},
+			ref:  "git://github.com/moby/buildkit.git",
+			xErr: true,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			matches, err := match(&selectorCache{Selector: tc.src}, tc.ref, tc.attrs)
+			matches, err := match(context.TODO(), &selectorCache{Selector: tc.src}, tc.ref, tc.attrs)
 			if !tc.xErr {
 				require.NoError(t, err)
 			} else {
@@ -329,3 +387,23 @@ func TestMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchConditionEnv(t *testing.T) {
+	src := &selectorCache{Selector: &spb.Selector{
+		Identifier: "docker-image://*",
+		Condition:  `platform.architecture == "arm64" && frontend["target"] == "release"`,
+	}}
+	ref := "docker-image://docker.io/library/busybox:latest"
+
+	ctx := WithFrontendAttrs(context.TODO(), map[string]string{"target": "release"})
+	matches, err := match(WithPlatform(ctx, &pb.Platform{OS: "linux", Architecture: "arm64"}), src, ref, nil)
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	matches, err = match(WithPlatform(ctx, &pb.Platform{OS: "linux", Architecture: "amd64"}), src, ref, nil)
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	_, err = match(WithPlatform(context.TODO(), &pb.Platform{OS: "linux", Architecture: "arm64"}), src, ref, nil)
+	require.ErrorContains(t, err, `no such key: "target"`)
+}
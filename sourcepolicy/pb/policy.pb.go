@@ -28,6 +28,9 @@ const (
 	PolicyAction_ALLOW   PolicyAction = 0
 	PolicyAction_DENY    PolicyAction = 1
 	PolicyAction_CONVERT PolicyAction = 2
+	// VERIFY requires an image to be signed with one of the keys of the
+	// signature requirement of the rule before it is pulled
+	PolicyAction_VERIFY PolicyAction = 3
 )
 
 // Enum value maps for PolicyAction.
@@ -36,11 +39,13 @@ var (
 		0: "ALLOW",
 		1: "DENY",
 		2: "CONVERT",
+		3: "VERIFY",
 	}
 	PolicyAction_value = map[string]int32{
 		"ALLOW":   0,
 		"DENY":    1,
 		"CONVERT": 2,
+		"VERIFY":  3,
 	}
 )
 
@@ -179,10 +184,12 @@ func (MatchType) EnumDescriptor() ([]byte, []int) {
 
 // Rule defines the action(s) to take when a source is matched
 type Rule struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Action        PolicyAction           `protobuf:"varint,1,opt,name=action,proto3,enum=moby.buildkit.v1.sourcepolicy.PolicyAction" json:"action,omitempty"`
-	Selector      *Selector              `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
-	Updates       *Update                `protobuf:"bytes,3,opt,name=updates,proto3" json:"updates,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Action   PolicyAction           `protobuf:"varint,1,opt,name=action,proto3,enum=moby.buildkit.v1.sourcepolicy.PolicyAction" json:"action,omitempty"`
+	Selector *Selector              `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	Updates  *Update                `protobuf:"bytes,3,opt,name=updates,proto3" json:"updates,omitempty"`
+	// Signature is the signature requirement checked by the VERIFY action
+	Signature     *SignatureRequirement `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -238,6 +245,61 @@ func (x *Rule) GetUpdates() *Update {
 	return nil
 }
 
+func (x *Rule) GetSignature() *SignatureRequirement {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// SignatureRequirement defines the keys that an image must be signed with
+type SignatureRequirement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// PublicKeys are PEM encoded public keys for cosign signatures and PEM
+	// encoded root certificates for notation signatures. The image must carry
+	// a valid signature made by one of them.
+	PublicKeys    []string `protobuf:"bytes,1,rep,name=public_keys,json=publicKeys,proto3" json:"public_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignatureRequirement) Reset() {
+	*x = SignatureRequirement{}
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignatureRequirement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignatureRequirement) ProtoMessage() {}
+
+func (x *SignatureRequirement) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignatureRequirement.ProtoReflect.Descriptor instead.
+func (*SignatureRequirement) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignatureRequirement) GetPublicKeys() []string {
+	if x != nil {
+		return x.PublicKeys
+	}
+	return nil
+}
+
 // Update contains updates to the matched build step after rule is applied
 type Update struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -249,7 +311,7 @@ type Update struct {
 
 func (x *Update) Reset() {
 	*x = Update{}
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[1]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -261,7 +323,7 @@ func (x *Update) String() string {
 func (*Update) ProtoMessage() {}
 
 func (x *Update) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[1]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -274,7 +336,7 @@ func (x *Update) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Update.ProtoReflect.Descriptor instead.
 func (*Update) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{1}
+	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Update) GetIdentifier() string {
@@ -296,15 +358,20 @@ type Selector struct {
 	state      protoimpl.MessageState `protogen:"open.v1"`
 	Identifier string                 `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
 	// MatchType is the type of match to perform on the source identifier
-	MatchType     MatchType         `protobuf:"varint,2,opt,name=match_type,json=matchType,proto3,enum=moby.buildkit.v1.sourcepolicy.MatchType" json:"match_type,omitempty"`
-	Constraints   []*AttrConstraint `protobuf:"bytes,3,rep,name=constraints,proto3" json:"constraints,omitempty"`
+	MatchType   MatchType         `protobuf:"varint,2,opt,name=match_type,json=matchType,proto3,enum=moby.buildkit.v1.sourcepolicy.MatchType" json:"match_type,omitempty"`
+	Constraints []*AttrConstraint `protobuf:"bytes,3,rep,name=constraints,proto3" json:"constraints,omitempty"`
+	// Condition is an expression that must evaluate to true for the selector
+	// to match. It can use the identifier and attrs of the source, the
+	// platform that the source is requested for and the frontend attrs of
+	// the build.
+	Condition     string `protobuf:"bytes,4,opt,name=condition,proto3" json:"condition,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Selector) Reset() {
 	*x = Selector{}
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[2]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -316,7 +383,7 @@ func (x *Selector) String() string {
 func (*Selector) ProtoMessage() {}
 
 func (x *Selector) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[2]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,7 +396,7 @@ func (x *Selector) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Selector.ProtoReflect.Descriptor instead.
 func (*Selector) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{2}
+	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Selector) GetIdentifier() string {
@@ -353,6 +420,13 @@ func (x *Selector) GetConstraints() []*AttrConstraint {
 	return nil
 }
 
+func (x *Selector) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
 // AttrConstraint defines a constraint on a source attribute
 type AttrConstraint struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -365,7 +439,7 @@ type AttrConstraint struct {
 
 func (x *AttrConstraint) Reset() {
 	*x = AttrConstraint{}
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[3]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -377,7 +451,7 @@ func (x *AttrConstraint) String() string {
 func (*AttrConstraint) ProtoMessage() {}
 
 func (x *AttrConstraint) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[3]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -390,7 +464,7 @@ func (x *AttrConstraint) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AttrConstraint.ProtoReflect.Descriptor instead.
 func (*AttrConstraint) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{3}
+	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *AttrConstraint) GetKey() string {
@@ -425,7 +499,7 @@ type Policy struct {
 
 func (x *Policy) Reset() {
 	*x = Policy{}
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[4]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -437,7 +511,7 @@ func (x *Policy) String() string {
 func (*Policy) ProtoMessage() {}
 
 func (x *Policy) ProtoReflect() protoreflect.Message {
-	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[4]
+	mi := &file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -450,7 +524,7 @@ func (x *Policy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Policy.ProtoReflect.Descriptor instead.
 func (*Policy) Descriptor() ([]byte, []int) {
-	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{4}
+	return file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Policy) GetVersion() int64 {
@@ -471,11 +545,15 @@ var File_github_com_moby_buildkit_sourcepolicy_pb_policy_proto protoreflect.File
 
 const file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDesc = "" +
 	"\n" +
-	"5github.com/moby/buildkit/sourcepolicy/pb/policy.proto\x12\x1dmoby.buildkit.v1.sourcepolicy\"\xd1\x01\n" +
+	"5github.com/moby/buildkit/sourcepolicy/pb/policy.proto\x12\x1dmoby.buildkit.v1.sourcepolicy\"\xa4\x02\n" +
 	"\x04Rule\x12C\n" +
 	"\x06action\x18\x01 \x01(\x0e2+.moby.buildkit.v1.sourcepolicy.PolicyActionR\x06action\x12C\n" +
 	"\bselector\x18\x02 \x01(\v2'.moby.buildkit.v1.sourcepolicy.SelectorR\bselector\x12?\n" +
-	"\aupdates\x18\x03 \x01(\v2%.moby.buildkit.v1.sourcepolicy.UpdateR\aupdates\"\xaa\x01\n" +
+	"\aupdates\x18\x03 \x01(\v2%.moby.buildkit.v1.sourcepolicy.UpdateR\aupdates\x12Q\n" +
+	"\tsignature\x18\x04 \x01(\v23.moby.buildkit.v1.sourcepolicy.SignatureRequirementR\tsignature\"7\n" +
+	"\x14SignatureRequirement\x12\x1f\n" +
+	"\vpublic_keys\x18\x01 \x03(\tR\n" +
+	"publicKeys\"\xaa\x01\n" +
 	"\x06Update\x12\x1e\n" +
 	"\n" +
 	"identifier\x18\x01 \x01(\tR\n" +
@@ -484,25 +562,28 @@ const file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDesc = "" +
 	"\n" +
 	"AttrsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc4\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe2\x01\n" +
 	"\bSelector\x12\x1e\n" +
 	"\n" +
 	"identifier\x18\x01 \x01(\tR\n" +
 	"identifier\x12G\n" +
 	"\n" +
 	"match_type\x18\x02 \x01(\x0e2(.moby.buildkit.v1.sourcepolicy.MatchTypeR\tmatchType\x12O\n" +
-	"\vconstraints\x18\x03 \x03(\v2-.moby.buildkit.v1.sourcepolicy.AttrConstraintR\vconstraints\"\x80\x01\n" +
+	"\vconstraints\x18\x03 \x03(\v2-.moby.buildkit.v1.sourcepolicy.AttrConstraintR\vconstraints\x12\x1c\n" +
+	"\tcondition\x18\x04 \x01(\tR\tcondition\"\x80\x01\n" +
 	"\x0eAttrConstraint\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value\x12F\n" +
 	"\tcondition\x18\x03 \x01(\x0e2(.moby.buildkit.v1.sourcepolicy.AttrMatchR\tcondition\"]\n" +
 	"\x06Policy\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x03R\aversion\x129\n" +
-	"\x05rules\x18\x02 \x03(\v2#.moby.buildkit.v1.sourcepolicy.RuleR\x05rules*0\n" +
+	"\x05rules\x18\x02 \x03(\v2#.moby.buildkit.v1.sourcepolicy.RuleR\x05rules*<\n" +
 	"\fPolicyAction\x12\t\n" +
 	"\x05ALLOW\x10\x00\x12\b\n" +
 	"\x04DENY\x10\x01\x12\v\n" +
-	"\aCONVERT\x10\x02*1\n" +
+	"\aCONVERT\x10\x02\x12\n" +
+	"\n" +
+	"\x06VERIFY\x10\x03*1\n" +
 	"\tAttrMatch\x12\t\n" +
 	"\x05EQUAL\x10\x00\x12\f\n" +
 	"\bNOTEQUAL\x10\x01\x12\v\n" +
@@ -525,32 +606,34 @@ func file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDescGZIP() []
 }
 
 var file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_goTypes = []any{
-	(PolicyAction)(0),      // 0: moby.buildkit.v1.sourcepolicy.PolicyAction
-	(AttrMatch)(0),         // 1: moby.buildkit.v1.sourcepolicy.AttrMatch
-	(MatchType)(0),         // 2: moby.buildkit.v1.sourcepolicy.MatchType
-	(*Rule)(nil),           // 3: moby.buildkit.v1.sourcepolicy.Rule
-	(*Update)(nil),         // 4: moby.buildkit.v1.sourcepolicy.Update
-	(*Selector)(nil),       // 5: moby.buildkit.v1.sourcepolicy.Selector
-	(*AttrConstraint)(nil), // 6: moby.buildkit.v1.sourcepolicy.AttrConstraint
-	(*Policy)(nil),         // 7: moby.buildkit.v1.sourcepolicy.Policy
-	nil,                    // 8: moby.buildkit.v1.sourcepolicy.Update.AttrsEntry
+	(PolicyAction)(0),            // 0: moby.buildkit.v1.sourcepolicy.PolicyAction
+	(AttrMatch)(0),               // 1: moby.buildkit.v1.sourcepolicy.AttrMatch
+	(MatchType)(0),               // 2: moby.buildkit.v1.sourcepolicy.MatchType
+	(*Rule)(nil),                 // 3: moby.buildkit.v1.sourcepolicy.Rule
+	(*SignatureRequirement)(nil), // 4: moby.buildkit.v1.sourcepolicy.SignatureRequirement
+	(*Update)(nil),               // 5: moby.buildkit.v1.sourcepolicy.Update
+	(*Selector)(nil),             // 6: moby.buildkit.v1.sourcepolicy.Selector
+	(*AttrConstraint)(nil),       // 7: moby.buildkit.v1.sourcepolicy.AttrConstraint
+	(*Policy)(nil),               // 8: moby.buildkit.v1.sourcepolicy.Policy
+	nil,                          // 9: moby.buildkit.v1.sourcepolicy.Update.AttrsEntry
 }
 var file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_depIdxs = []int32{
 	0, // 0: moby.buildkit.v1.sourcepolicy.Rule.action:type_name -> moby.buildkit.v1.sourcepolicy.PolicyAction
-	5, // 1: moby.buildkit.v1.sourcepolicy.Rule.selector:type_name -> moby.buildkit.v1.sourcepolicy.Selector
-	4, // 2: moby.buildkit.v1.sourcepolicy.Rule.updates:type_name -> moby.buildkit.v1.sourcepolicy.Update
-	8, // 3: moby.buildkit.v1.sourcepolicy.Update.attrs:type_name -> moby.buildkit.v1.sourcepolicy.Update.AttrsEntry
-	2, // 4: moby.buildkit.v1.sourcepolicy.Selector.match_type:type_name -> moby.buildkit.v1.sourcepolicy.MatchType
-	6, // 5: moby.buildkit.v1.sourcepolicy.Selector.constraints:type_name -> moby.buildkit.v1.sourcepolicy.AttrConstraint
-	1, // 6: moby.buildkit.v1.sourcepolicy.AttrConstraint.condition:type_name -> moby.buildkit.v1.sourcepolicy.AttrMatch
-	3, // 7: moby.buildkit.v1.sourcepolicy.Policy.rules:type_name -> moby.buildkit.v1.sourcepolicy.Rule
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	6, // 1: moby.buildkit.v1.sourcepolicy.Rule.selector:type_name -> moby.buildkit.v1.sourcepolicy.Selector
+	5, // 2: moby.buildkit.v1.sourcepolicy.Rule.updates:type_name -> moby.buildkit.v1.sourcepolicy.Update
+	4, // 3: moby.buildkit.v1.sourcepolicy.Rule.signature:type_name -> moby.buildkit.v1.sourcepolicy.SignatureRequirement
+	9, // 4: moby.buildkit.v1.sourcepolicy.Update.attrs:type_name -> moby.buildkit.v1.sourcepolicy.Update.AttrsEntry
+	2, // 5: moby.buildkit.v1.sourcepolicy.Selector.match_type:type_name -> moby.buildkit.v1.sourcepolicy.MatchType
+	7, // 6: moby.buildkit.v1.sourcepolicy.Selector.constraints:type_name -> moby.buildkit.v1.sourcepolicy.AttrConstraint
+	1, // 7: moby.buildkit.v1.sourcepolicy.AttrConstraint.condition:type_name -> moby.buildkit.v1.sourcepolicy.AttrMatch
+	3, // 8: moby.buildkit.v1.sourcepolicy.Policy.rules:type_name -> moby.buildkit.v1.sourcepolicy.Rule
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_init() }
@@ -564,7 +647,7 @@ func file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDesc), len(file_github_com_moby_buildkit_sourcepolicy_pb_policy_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,122 @@
+package sourcepolicy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// maxTraceAttrValue is the length of the longest attribute value that is
+// recorded in a trace. Longer values, such as the public keys of a VERIFY
+// rule, are recorded by their digest.
+const maxTraceAttrValue = 128
+
+// Trace records how a source was evaluated by the engine.
+type Trace struct {
+	// Source is the identifier of the source before it was evaluated.
+	Source string `json:"source"`
+	// Steps are the rules that matched the source, in the order they were
+	// evaluated.
+	Steps []TraceStep `json:"steps,omitempty"`
+	// Result is the identifier of the source after it was evaluated.
+	Result string `json:"result"`
+	// Error is set if the source was denied or could not be evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// TraceStep is a rule that matched a source.
+type TraceStep struct {
+	// Policy is the index of the policy of the rule. PolicyName is the name
+	// that was set for the policy with SetPolicyName.
+	Policy     int    `json:"policy"`
+	PolicyName string `json:"policyName,omitempty"`
+	// Rule is the index of the rule in the policy.
+	Rule      int    `json:"rule"`
+	Action    string `json:"action"`
+	MatchType string `json:"matchType"`
+	Selector  string `json:"selector"`
+	// Constraints are the attribute constraints of the selector that the
+	// source passed.
+	Constraints []string `json:"constraints,omitempty"`
+	// Condition is the condition of the selector that the source passed.
+	Condition string `json:"condition,omitempty"`
+	// Identifier is the identifier of the source that the rule matched.
+	Identifier string `json:"identifier"`
+	// Updated is the identifier of the source after a CONVERT rule.
+	Updated string `json:"updated,omitempty"`
+	// Attrs are the attributes of the source that a CONVERT or VERIFY rule
+	// changed, with their new values.
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+func newTraceStep(policy int, name string, rule int, r *spb.Rule, ident string) TraceStep {
+	s := TraceStep{
+		Policy:     policy,
+		PolicyName: name,
+		Rule:       rule,
+		Action:     r.Action.String(),
+		MatchType:  r.Selector.MatchType.String(),
+		Selector:   r.Selector.Identifier,
+		Condition:  r.Selector.Condition,
+		Identifier: ident,
+	}
+	for _, c := range r.Selector.Constraints {
+		s.Constraints = append(s.Constraints, fmt.Sprintf("%s %s %q", c.Key, c.Condition, c.Value))
+	}
+	return s
+}
+
+// String formats the step as a single line, e.g.
+//
+//	policies[0] rules[1]: CONVERT WILDCARD "docker-image://docker.io/library/golang:*" matched docker-image://docker.io/library/golang:1.22 -> docker-image://docker.io/library/golang:1.22-alpine
+func (s TraceStep) String() string {
+	var sb strings.Builder
+	if s.PolicyName != "" {
+		sb.WriteString(s.PolicyName)
+	} else {
+		fmt.Fprintf(&sb, "policies[%d]", s.Policy)
+	}
+	fmt.Fprintf(&sb, " rules[%d]: %s %s %q", s.Rule, s.Action, s.MatchType, s.Selector)
+	for _, c := range s.Constraints {
+		fmt.Fprintf(&sb, " [%s]", c)
+	}
+	if s.Condition != "" {
+		fmt.Fprintf(&sb, " [if %s]", s.Condition)
+	}
+	fmt.Fprintf(&sb, " matched %s", s.Identifier)
+	if s.Updated != "" && s.Updated != s.Identifier {
+		fmt.Fprintf(&sb, " -> %s", s.Updated)
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.Attrs)) {
+		fmt.Fprintf(&sb, " %s=%s", k, s.Attrs[k])
+	}
+	return sb.String()
+}
+
+// traceUpdate records the source as it is after the last step changed it.
+// prev are the attributes of the source before the step.
+func (t *Trace) traceUpdate(op *pb.SourceOp, prev map[string]string) {
+	s := &t.Steps[len(t.Steps)-1]
+	s.Updated = op.Identifier
+	for k, v := range op.Attrs {
+		if pv, ok := prev[k]; ok && pv == v {
+			continue
+		}
+		if s.Attrs == nil {
+			s.Attrs = map[string]string{}
+		}
+		s.Attrs[k] = traceAttrValue(v)
+	}
+}
+
+func traceAttrValue(v string) string {
+	if len(v) > maxTraceAttrValue {
+		return digest.FromString(v).String()
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package sourcepolicy
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/solver/pb"
+	srctypes "github.com/moby/buildkit/source/types"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/pkg/errors"
+)
+
+// requireSignature adds the keys of a verify rule as a signature requirement
+// to an image source operation. The image source verifies the signature of
+// the resolved manifest before pulling it. If the requirement is already
+// present, then the return value is false. Other sources that a verify rule
+// matches, eg. with a "*" selector, are skipped.
+func requireSignature(ctx context.Context, op *pb.SourceOp, rule *spb.Rule) (bool, error) {
+	if !strings.HasPrefix(op.Identifier, srctypes.DockerImageScheme+"://") {
+		bklog.G(ctx).Debugf("sourcepolicy: skipping verify rule for %s, pattern: %s", op.Identifier, rule.Selector.Identifier)
+		return false, nil
+	}
+	keys := rule.GetSignature().GetPublicKeys()
+	if len(keys) == 0 {
+		return false, errors.Errorf("missing public keys for verify rule")
+	}
+
+	var reqs [][]string
+	if v, ok := op.Attrs[pb.AttrImageSignatureKeys]; ok {
+		if err := json.Unmarshal([]byte(v), &reqs); err != nil {
+			return false, errors.Wrapf(err, "invalid image signature keys %s", v)
+		}
+	}
+	for _, req := range reqs {
+		if slices.Equal(req, keys) {
+			return false, nil
+		}
+	}
+
+	bklog.G(ctx).Debugf("sourcepolicy: requiring signature for %s, pattern: %s", op.Identifier, rule.Selector.Identifier)
+
+	dt, err := json.Marshal(append(reqs, keys))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if op.Attrs == nil {
+		op.Attrs = map[string]string{}
+	}
+	op.Attrs[pb.AttrImageSignatureKeys] = string(dt)
+	return true, nil
+}
@@ -0,0 +1,166 @@
+// Package admission limits the number of builds that run at the same time on
+// a daemon. Builds over the limit wait in a bounded queue that admits them in
+// proportion to their weights.
+package admission
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrQueueFull is returned when a build can't be queued because the queue
+// has reached its maximum length.
+var ErrQueueFull = errors.New("build queue is full")
+
+// Queue admits builds up to a maximum number of concurrent builds.
+type Queue struct {
+	mu        sync.Mutex
+	maxActive int
+	maxQueued int
+	active    int
+	vclock    float64
+	waiting   []*Ticket
+}
+
+// NewQueue returns a queue that runs at most maxActive builds at the same
+// time and keeps at most maxQueued builds waiting. Zero values mean no limit.
+func NewQueue(maxActive, maxQueued int) *Queue {
+	return &Queue{
+		maxActive: maxActive,
+		maxQueued: maxQueued,
+	}
+}
+
+// Ticket is the place of a build in the queue.
+type Ticket struct {
+	q        *Queue
+	finish   float64
+	admitted chan struct{}
+	updates  chan struct{}
+	position int
+	released bool
+}
+
+// Enqueue adds a build with weight to the queue. The queue admits builds like
+// weighted fair queuing: every build gets a virtual finish time of the
+// virtual time of the queue plus 1/weight, and the build with the lowest
+// finish time is admitted next, advancing the virtual time of the queue to
+// it. A build with weight 2 is therefore admitted before a build with weight 1
+// that was queued at the same time, but a waiting build is never passed over
+// indefinitely by builds with higher weight that are queued after it. Weights
+// lower than 1 are treated as 1. The returned ticket must be released when the
+// build completes.
+func (q *Queue) Enqueue(weight int) (*Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	t := &Ticket{
+		q:        q,
+		finish:   q.vclock + 1/float64(max(weight, 1)),
+		admitted: make(chan struct{}),
+		updates:  make(chan struct{}, 1),
+	}
+	if len(q.waiting) == 0 && (q.maxActive <= 0 || q.active < q.maxActive) {
+		q.active++
+		q.vclock = t.finish
+		close(t.admitted)
+		return t, nil
+	}
+	if q.maxQueued > 0 && len(q.waiting) >= q.maxQueued {
+		return nil, grpcerrors.WrapCode(errors.WithStack(ErrQueueFull), codes.ResourceExhausted)
+	}
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		return q.waiting[i].finish > t.finish
+	})
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = t
+	q.update()
+	return t, nil
+}
+
+// Wait blocks until the build is admitted or the context is canceled.
+func (t *Ticket) Wait(ctx context.Context) error {
+	select {
+	case <-t.admitted:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Admitted returns a channel that is closed when the build is admitted.
+func (t *Ticket) Admitted() <-chan struct{} {
+	return t.admitted
+}
+
+// Updates returns a channel that receives a value when the position of the
+// build in the queue changes.
+func (t *Ticket) Updates() <-chan struct{} {
+	return t.updates
+}
+
+// Position returns the 1-based position of the build in the queue and the
+// length of the queue. Position is 0 after the build has been admitted.
+func (t *Ticket) Position() (int, int) {
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+	return t.position, len(t.q.waiting)
+}
+
+// Release removes the build from the queue or, if it has been admitted,
+// frees its slot for the next queued build.
+func (t *Ticket) Release() {
+	q := t.q
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if t.released {
+		return
+	}
+	t.released = true
+
+	select {
+	case <-t.admitted:
+		q.active--
+	default:
+		for i, w := range q.waiting {
+			if w == t {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	q.update()
+}
+
+// update admits the builds that fit under the limit and notifies the rest
+// about their new positions.
+func (q *Queue) update() {
+	for len(q.waiting) > 0 && (q.maxActive <= 0 || q.active < q.maxActive) {
+		t := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.active++
+		q.vclock = max(q.vclock, t.finish)
+		t.position = 0
+		close(t.admitted)
+		t.notify()
+	}
+	for i, t := range q.waiting {
+		if t.position != i+1 {
+			t.position = i + 1
+			t.notify()
+		}
+	}
+}
+
+func (t *Ticket) notify() {
+	select {
+	case t.updates <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,117 @@
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+	q := NewQueue(1, 2)
+	ctx := context.Background()
+
+	t1, err := q.Enqueue(1)
+	require.NoError(t, err)
+	require.NoError(t, t1.Wait(ctx))
+	pos, n := t1.Position()
+	require.Equal(t, 0, pos)
+	require.Equal(t, 0, n)
+
+	t2, err := q.Enqueue(1)
+	require.NoError(t, err)
+	pos, n = t2.Position()
+	require.Equal(t, 1, pos)
+	require.Equal(t, 1, n)
+
+	// higher weight goes before the waiting build
+	t3, err := q.Enqueue(2)
+	require.NoError(t, err)
+	pos, _ = t3.Position()
+	require.Equal(t, 1, pos)
+	pos, n = t2.Position()
+	require.Equal(t, 2, pos)
+	require.Equal(t, 2, n)
+	requireUpdate(t, t2)
+
+	_, err = q.Enqueue(1)
+	require.ErrorIs(t, err, ErrQueueFull)
+	require.Equal(t, codes.ResourceExhausted, grpcerrors.Code(err))
+
+	ctx2, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, t2.Wait(ctx2), context.DeadlineExceeded)
+
+	t1.Release()
+	t1.Release() // releasing twice is a no-op
+	require.NoError(t, t3.Wait(ctx))
+	pos, _ = t2.Position()
+	require.Equal(t, 1, pos)
+	requireUpdate(t, t2)
+
+	// a waiting build leaves the queue
+	t2.Release()
+	t3.Release()
+	t4, err := q.Enqueue(1)
+	require.NoError(t, err)
+	require.NoError(t, t4.Wait(ctx))
+	t4.Release()
+}
+
+func TestQueueWeights(t *testing.T) {
+	t.Parallel()
+	q := NewQueue(1, 0)
+	ctx := context.Background()
+
+	hold, err := q.Enqueue(1)
+	require.NoError(t, err)
+	require.NoError(t, hold.Wait(ctx))
+
+	light, err := q.Enqueue(1)
+	require.NoError(t, err)
+
+	// builds with a higher weight that keep arriving pass a waiting build
+	// only until it has waited for its share
+	active := hold
+	for range 3 {
+		tk, err := q.Enqueue(4)
+		require.NoError(t, err)
+		pos, _ := tk.Position()
+		require.Equal(t, 1, pos)
+		active.Release()
+		require.NoError(t, tk.Wait(ctx))
+		active = tk
+	}
+	tk, err := q.Enqueue(4)
+	require.NoError(t, err)
+	pos, _ := tk.Position()
+	require.Equal(t, 2, pos)
+	active.Release()
+	require.NoError(t, light.Wait(ctx))
+	light.Release()
+	require.NoError(t, tk.Wait(ctx))
+	tk.Release()
+}
+
+func TestQueueUnlimited(t *testing.T) {
+	t.Parallel()
+	q := NewQueue(0, 0)
+	for range 10 {
+		tk, err := q.Enqueue(1)
+		require.NoError(t, err)
+		require.NoError(t, tk.Wait(context.Background()))
+	}
+}
+
+func requireUpdate(t *testing.T, tk *Ticket) {
+	t.Helper()
+	select {
+	case <-tk.Updates():
+	default:
+		t.Fatal("expected position update")
+	}
+}
@@ -7,3 +7,13 @@ const (
 
 	DockerAnnotationReferenceTypeDefault = "attestation-manifest"
 )
+
+const (
+	// ArtifactTypeAttestationManifest is the artifact type of attestation
+	// manifests that refer to their image manifest with the subject field.
+	ArtifactTypeAttestationManifest = "application/vnd.docker.attestation.manifest.v1+json"
+
+	// MediaTypeDSSE is the media type of attestation layers that wrap an
+	// in-toto statement in a signed DSSE envelope.
+	MediaTypeDSSE = "application/vnd.dsse.envelope.v1+json"
+)
@@ -5,11 +5,13 @@ import (
 
 	"github.com/containerd/containerd/v2/core/remotes"
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/util/attestation"
 )
 
 // RegisterContentPayloadTypes registers content types that are not defined by
 // default but that we expect to find in registry images.
 func RegisterContentPayloadTypes(ctx context.Context) context.Context {
 	ctx = remotes.WithMediaTypeKeyPrefix(ctx, intoto.PayloadType, "intoto")
+	ctx = remotes.WithMediaTypeKeyPrefix(ctx, attestation.MediaTypeDSSE, "intoto")
 	return ctx
 }
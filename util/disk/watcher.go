@@ -0,0 +1,65 @@
+package disk
+
+import (
+	"context"
+	"time"
+
+	"github.com/moby/buildkit/util/bklog"
+)
+
+// Watcher calls a function when the free space of the filesystem of a path
+// falls below a threshold.
+type Watcher struct {
+	path     string
+	minFree  func() int64
+	interval time.Duration
+	fn       func(context.Context, DiskStat)
+
+	getDiskStat func(string) (DiskStat, error)
+}
+
+// NewWatcher returns a watcher that checks the free space of the filesystem
+// of path every interval and calls fn when it is below the number of bytes
+// returned by minFree. minFree is called on every check so that the threshold
+// can change, a value lower than 1 disables the check.
+func NewWatcher(path string, minFree func() int64, interval time.Duration, fn func(context.Context, DiskStat)) *Watcher {
+	return &Watcher{
+		path:        path,
+		minFree:     minFree,
+		interval:    interval,
+		fn:          fn,
+		getDiskStat: GetDiskStat,
+	}
+}
+
+// Run checks the free space until ctx is canceled. fn is called from Run, so
+// the next check waits until fn has returned.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		w.check(ctx)
+	}
+}
+
+func (w *Watcher) check(ctx context.Context) bool {
+	minFree := w.minFree()
+	if minFree <= 0 {
+		return false
+	}
+	dstat, err := w.getDiskStat(w.path)
+	if err != nil {
+		bklog.G(ctx).WithError(err).Debugf("failed to check free space of %s", w.path)
+		return false
+	}
+	if dstat.Free >= minFree {
+		return false
+	}
+	w.fn(ctx, dstat)
+	return true
+}
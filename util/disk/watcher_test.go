@@ -0,0 +1,68 @@
+package disk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	var calls []DiskStat
+	minFree := int64(100)
+	w := NewWatcher("/", func() int64 { return minFree }, time.Second, func(_ context.Context, dstat DiskStat) {
+		calls = append(calls, dstat)
+	})
+
+	free := int64(200)
+	w.getDiskStat = func(string) (DiskStat, error) {
+		return DiskStat{Total: 1000, Free: free}, nil
+	}
+	require.False(t, w.check(context.TODO()))
+	require.Empty(t, calls)
+
+	free = 50
+	require.True(t, w.check(context.TODO()))
+	require.Equal(t, []DiskStat{{Total: 1000, Free: 50}}, calls)
+
+	// the threshold is read on every check
+	minFree = 40
+	require.False(t, w.check(context.TODO()))
+	minFree = 0
+	require.False(t, w.check(context.TODO()))
+	minFree = 100
+
+	w.getDiskStat = func(string) (DiskStat, error) {
+		return DiskStat{}, errors.New("stat failed")
+	}
+	require.False(t, w.check(context.TODO()))
+	require.Len(t, calls, 1)
+}
+
+func TestWatcherRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	called := make(chan struct{})
+	w := NewWatcher("/", func() int64 { return 100 }, time.Millisecond, func(context.Context, DiskStat) {
+		cancel()
+		close(called)
+	})
+	w.getDiskStat = func(string) (DiskStat, error) {
+		return DiskStat{Free: 10}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-called:
+	case <-time.After(10 * time.Second):
+		t.Fatal("watcher was not triggered")
+	}
+	<-done
+}
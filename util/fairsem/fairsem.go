@@ -0,0 +1,186 @@
+// Package fairsem implements a semaphore that shares its slots between groups
+// of callers, eg. concurrent builds, in proportion to the weights of the
+// groups.
+package fairsem
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+type contextKeyT string
+
+var contextKey = contextKeyT("buildkit/util/fairsem")
+
+type groupKey struct {
+	id     string
+	weight int
+}
+
+// WithGroup returns a context that acquires the slots of a semaphore for the
+// group id. A group with weight 2 gets twice as many slots as a group with
+// weight 1 when both are waiting for the semaphore. Weights lower than 1 are
+// treated as 1.
+func WithGroup(ctx context.Context, id string, weight int) context.Context {
+	return context.WithValue(ctx, contextKey, groupKey{id: id, weight: weight})
+}
+
+func groupFromContext(ctx context.Context) groupKey {
+	g, _ := ctx.Value(contextKey).(groupKey)
+	if g.weight < 1 {
+		g.weight = 1
+	}
+	return g
+}
+
+// Semaphore limits the number of concurrent holders. When there are more
+// callers than free slots, the slots are passed to the waiting groups in
+// proportion to their weights, like in weighted fair queuing: every slot
+// granted to a group advances the virtual time of the group by 1/weight and
+// the group with the lowest virtual time gets the next slot. Callers of the
+// same group are served in order.
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	seq     uint64
+	vclock  float64
+	groups  map[string]*group
+	waiting int
+}
+
+type group struct {
+	weight  int
+	inUse   int64
+	vtime   float64
+	waiters list.List // of *waiter
+}
+
+type waiter struct {
+	seq   uint64
+	ready chan struct{}
+}
+
+// New returns a semaphore with size slots.
+func New(size int64) *Semaphore {
+	return &Semaphore{
+		size:   size,
+		groups: map[string]*group{},
+	}
+}
+
+// Acquire blocks until a slot is available for the group of the context or
+// the context is canceled. The returned function releases the slot.
+func (s *Semaphore) Acquire(ctx context.Context) (func(), error) {
+	key := groupFromContext(ctx)
+
+	s.mu.Lock()
+	g, ok := s.groups[key.id]
+	if !ok {
+		g = &group{}
+		s.groups[key.id] = g
+	}
+	// the weight of a group may change when builds share vertexes
+	g.weight = key.weight
+
+	if s.cur < s.size && s.waiting == 0 {
+		s.grant(g)
+		s.mu.Unlock()
+		return s.releaseFunc(key.id), nil
+	}
+
+	s.seq++
+	w := &waiter{seq: s.seq, ready: make(chan struct{})}
+	elem := g.waiters.PushBack(w)
+	s.waiting++
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaseFunc(key.id), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// acquired while canceling, pass the slot on
+			s.release(key.id)
+		default:
+			g.waiters.Remove(elem)
+			s.waiting--
+			s.cleanup(key.id, g)
+			s.notify()
+		}
+		s.mu.Unlock()
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (s *Semaphore) releaseFunc(id string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.release(id)
+			s.mu.Unlock()
+		})
+	}
+}
+
+func (s *Semaphore) release(id string) {
+	s.cur--
+	if g, ok := s.groups[id]; ok {
+		g.inUse--
+		s.cleanup(id, g)
+	}
+	s.notify()
+}
+
+func (s *Semaphore) cleanup(id string, g *group) {
+	if g.inUse == 0 && g.waiters.Len() == 0 {
+		delete(s.groups, id)
+	}
+}
+
+// notify passes the free slots to the waiting groups with the lowest virtual
+// time.
+func (s *Semaphore) notify() {
+	for s.cur < s.size && s.waiting > 0 {
+		var next *group
+		for _, g := range s.groups {
+			if g.waiters.Len() == 0 {
+				continue
+			}
+			if next == nil || s.less(g, next) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		w := next.waiters.Remove(next.waiters.Front()).(*waiter)
+		s.waiting--
+		s.grant(next)
+		close(w.ready)
+	}
+}
+
+func (s *Semaphore) grant(g *group) {
+	s.cur++
+	g.inUse++
+	// groups that were idle don't get credit for the time they did not use
+	s.vclock = s.start(g)
+	g.vtime = s.vclock + 1/float64(g.weight)
+}
+
+func (s *Semaphore) start(g *group) float64 {
+	return max(g.vtime, s.vclock)
+}
+
+// less returns true if group a should get the next slot before group b.
+func (s *Semaphore) less(a, b *group) bool {
+	if sa, sb := s.start(a), s.start(b); sa != sb {
+		return sa < sb
+	}
+	return a.waiters.Front().Value.(*waiter).seq < b.waiters.Front().Value.(*waiter).seq
+}
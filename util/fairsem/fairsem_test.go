@@ -0,0 +1,153 @@
+package fairsem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	t.Parallel()
+	s := New(2)
+	ctx := context.Background()
+
+	r1, err := s.Acquire(ctx)
+	require.NoError(t, err)
+	r2, err := s.Acquire(ctx)
+	require.NoError(t, err)
+
+	ctx2, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx2)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	r1()
+	r1() // releasing twice is a no-op
+	r3, err := s.Acquire(ctx)
+	require.NoError(t, err)
+	r2()
+	r3()
+
+	require.Equal(t, int64(0), s.cur)
+	require.Empty(t, s.groups)
+}
+
+func TestWeightedShare(t *testing.T) {
+	t.Parallel()
+	s := New(1)
+
+	hold, err := s.Acquire(context.Background())
+	require.NoError(t, err)
+
+	heavy := WithGroup(context.Background(), "heavy", 3)
+	light := WithGroup(context.Background(), "light", 1)
+
+	order := make(chan string, 8)
+	start := func(ctx context.Context, name string) {
+		go func() {
+			release, err := s.Acquire(ctx)
+			if err != nil {
+				order <- "error"
+				return
+			}
+			order <- name
+			release()
+		}()
+	}
+	// the light group queues first
+	for range 4 {
+		start(light, "light")
+	}
+	waitWaiting(t, s, 4)
+	for range 4 {
+		start(heavy, "heavy")
+	}
+	waitWaiting(t, s, 8)
+
+	// the heavy group gets three slots for every slot of the light group,
+	// ties go to the earliest waiter
+	hold()
+	var got []string
+	for range 8 {
+		got = append(got, <-order)
+	}
+	require.Equal(t, []string{"light", "heavy", "heavy", "heavy", "light", "heavy", "light", "light"}, got)
+}
+
+func TestWeightedShareConcurrent(t *testing.T) {
+	t.Parallel()
+	s := New(4)
+
+	holds := make([]func(), 0, 4)
+	for range 4 {
+		r, err := s.Acquire(context.Background())
+		require.NoError(t, err)
+		holds = append(holds, r)
+	}
+
+	heavy := WithGroup(context.Background(), "heavy", 3)
+	light := WithGroup(context.Background(), "light", 1)
+
+	granted := make(chan string, 16)
+	start := func(ctx context.Context, name string) {
+		go func() {
+			if _, err := s.Acquire(ctx); err == nil {
+				granted <- name
+			}
+		}()
+	}
+	for range 8 {
+		start(light, "light")
+	}
+	waitWaiting(t, s, 8)
+	for range 8 {
+		start(heavy, "heavy")
+	}
+	waitWaiting(t, s, 16)
+
+	// free all the slots at once, the slots are not released again
+	for _, r := range holds {
+		r()
+	}
+	counts := map[string]int{}
+	for range 4 {
+		counts[<-granted]++
+	}
+	require.Equal(t, map[string]int{"heavy": 3, "light": 1}, counts)
+}
+
+func TestCancelWaiter(t *testing.T) {
+	t.Parallel()
+	s := New(1)
+
+	r, err := s.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(WithGroup(context.Background(), "a", 1))
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := s.Acquire(ctx)
+		errCh <- err
+	}()
+	waitWaiting(t, s, 1)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	r()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Equal(t, int64(0), s.cur)
+	require.Equal(t, 0, s.waiting)
+	require.Empty(t, s.groups)
+}
+
+func waitWaiting(t *testing.T, s *Semaphore, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.waiting == n
+	}, 5*time.Second, time.Millisecond)
+}
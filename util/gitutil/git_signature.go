@@ -0,0 +1,226 @@
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/util/sigutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	SignatureTypePGP = "gpg"
+	SignatureTypeSSH = "ssh"
+)
+
+var signatureHeaders = map[string]string{
+	"-----BEGIN PGP SIGNATURE-----": SignatureTypePGP,
+	"-----BEGIN SSH SIGNATURE-----": SignatureTypeSSH,
+}
+
+// ErrNotSigned is returned when a commit or tag object has no signature.
+var ErrNotSigned = errors.New("object is not signed")
+
+// SignedObject is a commit or tag object split into the signed payload and
+// the signature.
+type SignedObject struct {
+	Type      string
+	Payload   []byte
+	Signature []byte
+	SigType   string
+}
+
+// Signer describes the key that made a valid signature.
+type Signer struct {
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ParseSignedObject splits a raw commit or tag object, as printed by
+// `git cat-file <type> <object>`, into the signed payload and the signature.
+func ParseSignedObject(typ string, raw []byte) (*SignedObject, error) {
+	switch typ {
+	case "commit":
+		return parseSignedCommit(raw)
+	case "tag":
+		return parseSignedTag(raw)
+	default:
+		return nil, errors.Errorf("unsupported object type %q for signature verification", typ)
+	}
+}
+
+// parseSignedCommit removes the gpgsig header from the commit headers. The
+// signature continues on the following lines that start with a space.
+func parseSignedCommit(raw []byte) (*SignedObject, error) {
+	headers, body, _ := bytes.Cut(raw, []byte("\n\n"))
+	var payload, sig bytes.Buffer
+	inSig := false
+	for _, line := range bytes.SplitAfter(headers, []byte("\n")) {
+		if inSig && bytes.HasPrefix(line, []byte(" ")) {
+			sig.Write(line[1:])
+			continue
+		}
+		inSig = false
+		if v, ok := bytes.CutPrefix(line, []byte("gpgsig ")); ok {
+			inSig = true
+			sig.Write(v)
+			continue
+		}
+		payload.Write(line)
+	}
+	if sig.Len() == 0 {
+		return nil, ErrNotSigned
+	}
+	if !bytes.HasSuffix(sig.Bytes(), []byte("\n")) {
+		sig.WriteString("\n")
+	}
+	if !bytes.HasSuffix(payload.Bytes(), []byte("\n")) {
+		payload.WriteString("\n")
+	}
+	payload.WriteString("\n")
+	payload.Write(body)
+
+	sigType, err := signatureType(sig.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &SignedObject{
+		Type:      "commit",
+		Payload:   payload.Bytes(),
+		Signature: sig.Bytes(),
+		SigType:   sigType,
+	}, nil
+}
+
+// parseSignedTag splits the signature appended to the message of a tag.
+func parseSignedTag(raw []byte) (*SignedObject, error) {
+	idx := -1
+	for header := range signatureHeaders {
+		if i := bytes.LastIndex(raw, []byte("\n"+header)); i > idx {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return nil, ErrNotSigned
+	}
+	sig := raw[idx+1:]
+	sigType, err := signatureType(sig)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedObject{
+		Type:      "tag",
+		Payload:   raw[:idx+1],
+		Signature: sig,
+		SigType:   sigType,
+	}, nil
+}
+
+func signatureType(sig []byte) (string, error) {
+	for header, typ := range signatureHeaders {
+		if bytes.HasPrefix(sig, []byte(header)) {
+			return typ, nil
+		}
+	}
+	line, _, _ := bytes.Cut(sig, []byte("\n"))
+	return "", errors.Errorf("unsupported signature format %q", line)
+}
+
+// Verify checks the signature of the object against a set of public keys.
+// pubKeys can contain armored GPG public key blocks and SSH public keys in
+// authorized_keys format, one per line. The gpg and ssh-keygen binaries are
+// needed for the respective signature types.
+func (obj *SignedObject) Verify(ctx context.Context, pubKeys []byte) (*Signer, error) {
+	tmpDir, err := os.MkdirTemp("", "buildkit-git-verify")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sigFile := filepath.Join(tmpDir, "signature")
+	if err := os.WriteFile(sigFile, obj.Signature, 0600); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	pgpKeys, sshKeys := splitPublicKeys(pubKeys)
+	switch obj.SigType {
+	case SignatureTypePGP:
+		if len(pgpKeys) == 0 {
+			return nil, errors.Errorf("%s is signed with GPG but no GPG public keys were provided", obj.Type)
+		}
+		return verifyPGP(ctx, obj.Payload, obj.Signature, pgpKeys)
+	case SignatureTypeSSH:
+		if len(sshKeys) == 0 {
+			return nil, errors.Errorf("%s is signed with SSH but no SSH public keys were provided", obj.Type)
+		}
+		return verifySSH(ctx, tmpDir, sigFile, obj.Payload, sshKeys)
+	default:
+		return nil, errors.Errorf("unsupported signature type %q", obj.SigType)
+	}
+}
+
+var sshKeyPrefix = regexp.MustCompile(`^(ssh-|ecdsa-|sk-)`)
+
+// splitPublicKeys separates the armored GPG key blocks from the SSH keys.
+func splitPublicKeys(pubKeys []byte) (pgp []byte, ssh []string) {
+	var pgpBuf bytes.Buffer
+	inBlock := false
+	s := bufio.NewScanner(bytes.NewReader(pubKeys))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "-----BEGIN PGP PUBLIC KEY BLOCK-----":
+			inBlock = true
+		case sshKeyPrefix.MatchString(line) && !inBlock:
+			ssh = append(ssh, line)
+			continue
+		}
+		if inBlock {
+			pgpBuf.WriteString(line + "\n")
+		}
+		if line == "-----END PGP PUBLIC KEY BLOCK-----" {
+			inBlock = false
+		}
+	}
+	return pgpBuf.Bytes(), ssh
+}
+
+func verifyPGP(ctx context.Context, payload, sig, keys []byte) (*Signer, error) {
+	fpr, err := sigutil.VerifyPGP(ctx, bytes.NewReader(payload), sig, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{Type: SignatureTypePGP, Fingerprint: fpr}, nil
+}
+
+var sshGoodSignature = regexp.MustCompile(`^Good "git" signature for \S+ with \S+ key (\S+)`)
+
+func verifySSH(ctx context.Context, tmpDir, sigFile string, payload []byte, keys []string) (*Signer, error) {
+	const principal = "buildkit"
+	var allowed strings.Builder
+	for _, k := range keys {
+		allowed.WriteString(principal + " " + k + "\n")
+	}
+	allowedFile := filepath.Join(tmpDir, "allowed_signers")
+	if err := os.WriteFile(allowedFile, []byte(allowed.String()), 0600); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-Y", "verify", "-f", allowedFile, "-I", principal, "-n", "git", "-s", sigFile)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to verify SSH signature: %s", strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+	if m := sshGoodSignature.FindStringSubmatch(strings.TrimSpace(stdout.String())); m != nil {
+		return &Signer{Type: SignatureTypeSSH, Fingerprint: m[1]}, nil
+	}
+	return nil, errors.Errorf("failed to verify SSH signature: unexpected output %q", stdout.String())
+}
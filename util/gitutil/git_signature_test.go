@@ -0,0 +1,66 @@
+package gitutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignedCommit(t *testing.T) {
+	raw := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author test <test> 1700000000 +0000\n" +
+		"committer test <test> 1700000000 +0000\n" +
+		"gpgsig -----BEGIN SSH SIGNATURE-----\n" +
+		" U1NIU0lH\n" +
+		" -----END SSH SIGNATURE-----\n" +
+		"\n" +
+		"message\n"
+
+	obj, err := ParseSignedObject("commit", []byte(raw))
+	require.NoError(t, err)
+	require.Equal(t, SignatureTypeSSH, obj.SigType)
+	require.Equal(t, "-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n", string(obj.Signature))
+	require.Equal(t, "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"+
+		"author test <test> 1700000000 +0000\n"+
+		"committer test <test> 1700000000 +0000\n"+
+		"\n"+
+		"message\n", string(obj.Payload))
+
+	_, err = ParseSignedObject("commit", []byte("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\nmessage\n"))
+	require.ErrorIs(t, err, ErrNotSigned)
+}
+
+func TestParseSignedTag(t *testing.T) {
+	raw := "object 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"type commit\n" +
+		"tag v1\n" +
+		"tagger test <test> 1700000000 +0000\n" +
+		"\n" +
+		"message\n" +
+		"-----BEGIN PGP SIGNATURE-----\n" +
+		"\n" +
+		"iQ==\n" +
+		"-----END PGP SIGNATURE-----\n"
+
+	obj, err := ParseSignedObject("tag", []byte(raw))
+	require.NoError(t, err)
+	require.Equal(t, SignatureTypePGP, obj.SigType)
+	require.Equal(t, "-----BEGIN PGP SIGNATURE-----\n\niQ==\n-----END PGP SIGNATURE-----\n", string(obj.Signature))
+	require.Equal(t, "object 4b825dc642cb6eb9a060e54bf8d69288fbee4904\ntype commit\ntag v1\ntagger test <test> 1700000000 +0000\n\nmessage\n", string(obj.Payload))
+
+	_, err = ParseSignedObject("tree", []byte(raw))
+	require.Error(t, err)
+}
+
+func TestSplitPublicKeys(t *testing.T) {
+	keys := "ssh-ed25519 AAAA test@example\n" +
+		"-----BEGIN PGP PUBLIC KEY BLOCK-----\n" +
+		"\n" +
+		"mQ==\n" +
+		"-----END PGP PUBLIC KEY BLOCK-----\n" +
+		"ecdsa-sha2-nistp256 AAAA\n"
+
+	pgp, ssh := splitPublicKeys([]byte(keys))
+	require.Equal(t, "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nmQ==\n-----END PGP PUBLIC KEY BLOCK-----\n", string(pgp))
+	require.Equal(t, []string{"ssh-ed25519 AAAA test@example", "ecdsa-sha2-nistp256 AAAA"}, ssh)
+}
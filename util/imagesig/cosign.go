@@ -0,0 +1,91 @@
+package imagesig
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/moby/buildkit/util/sigutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Cosign stores signatures in a manifest tagged with CosignTag, or in a
+// manifest with CosignArtifactType that refers to the image with its subject
+// field. Each layer is a simple signing payload and the signature of the
+// payload is stored in an annotation of the layer.
+const (
+	CosignSimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	CosignArtifactType           = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	CosignSignatureAnnotation    = "dev.cosignproject.cosign/signature"
+	CosignSignatureType          = "cosign container image signature"
+)
+
+// CosignTag returns the tag that cosign stores the signatures of dgst in.
+func CosignTag(dgst digest.Digest) string {
+	return dgst.Algorithm().String() + "-" + dgst.Encoded() + ".sig"
+}
+
+// SimpleSigning is the payload of a cosign signature.
+type SimpleSigning struct {
+	Critical SimpleSigningCritical `json:"critical"`
+	Optional map[string]any        `json:"optional"`
+}
+
+type SimpleSigningCritical struct {
+	Identity SimpleSigningIdentity `json:"identity"`
+	Image    SimpleSigningImage    `json:"image"`
+	Type     string                `json:"type"`
+}
+
+type SimpleSigningIdentity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+type SimpleSigningImage struct {
+	DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+}
+
+// verifyCosign checks that sig is a valid signature of the simple signing
+// payload for dgst made by one of the keys.
+func verifyCosign(payload []byte, sig string, dgst digest.Digest, keys []crypto.PublicKey) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("image is signed with cosign but no public keys were provided")
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cosign signature")
+	}
+	h := sha256.Sum256(payload)
+	var signer crypto.PublicKey
+	for _, k := range keys {
+		if sigutil.VerifyPublicKey(k, payload, h[:], raw) {
+			signer = k
+			break
+		}
+	}
+	if signer == nil {
+		return nil, errors.New("failed to verify cosign signature")
+	}
+
+	var ss SimpleSigning
+	if err := json.Unmarshal(payload, &ss); err != nil {
+		return nil, errors.Wrap(err, "failed to parse cosign signature payload")
+	}
+	if ss.Critical.Type != CosignSignatureType {
+		return nil, errors.Errorf("invalid cosign signature type %q", ss.Critical.Type)
+	}
+	if ss.Critical.Image.DockerManifestDigest != dgst {
+		return nil, errors.Errorf("cosign signature is for %s, not %s", ss.Critical.Image.DockerManifestDigest, dgst)
+	}
+	dt, err := x509.MarshalPKIXPublicKey(signer)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Signer{
+		Type:     SignatureTypeCosign,
+		Identity: digest.FromBytes(dt).String(),
+	}, nil
+}
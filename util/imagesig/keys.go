@@ -0,0 +1,57 @@
+package imagesig
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+)
+
+// Keys are the keys that image signatures are verified against.
+type Keys struct {
+	// publicKeys verify cosign signatures
+	publicKeys []crypto.PublicKey
+	// roots are the trusted root certificates of notation signatures
+	roots  *x509.CertPool
+	nroots int
+}
+
+// ParseKeys reads PEM encoded public keys and root certificates. Each value
+// may contain multiple PEM blocks.
+func ParseKeys(values ...string) (*Keys, error) {
+	keys := &Keys{
+		roots: x509.NewCertPool(),
+	}
+	for _, v := range values {
+		rest := []byte(v)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			switch block.Type {
+			case "PUBLIC KEY":
+				pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to parse public key")
+				}
+				keys.publicKeys = append(keys.publicKeys, pub)
+			case "CERTIFICATE":
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to parse certificate")
+				}
+				keys.roots.AddCert(cert)
+				keys.nroots++
+			default:
+				return nil, errors.Errorf("unsupported PEM block type %q", block.Type)
+			}
+		}
+	}
+	if len(keys.publicKeys) == 0 && keys.nroots == 0 {
+		return nil, errors.New("no PEM encoded public keys or certificates found")
+	}
+	return keys, nil
+}
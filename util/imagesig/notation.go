@@ -0,0 +1,189 @@
+package imagesig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// Notation stores signatures in a manifest with NotationArtifactType that
+// refers to the image with its subject field. The single layer of the
+// manifest is the signature envelope.
+const (
+	NotationArtifactType        = "application/vnd.cncf.notary.signature"
+	NotationJWSMediaType        = "application/jose+json"
+	NotationCOSEMediaType       = "application/cose"
+	NotationPayloadContentType  = "application/vnd.cncf.notary.payload.v1+json"
+	notationSigningTimeHeader   = "io.cncf.notary.signingTime"
+	notationExpiryHeader        = "io.cncf.notary.expiry"
+	notationSigningSchemeHeader = "io.cncf.notary.signingScheme"
+	notationSchemeX509          = "notary.x509"
+)
+
+type jwsEnvelope struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Header    struct {
+		CertChain [][]byte `json:"x5c"`
+	} `json:"header"`
+	Signature string `json:"signature"`
+}
+
+type jwsProtectedHeader struct {
+	Algorithm     string     `json:"alg"`
+	ContentType   string     `json:"cty"`
+	SigningScheme string     `json:"io.cncf.notary.signingScheme"`
+	Expiry        *time.Time `json:"io.cncf.notary.expiry"`
+	Critical      []string   `json:"crit"`
+}
+
+type notationPayload struct {
+	TargetArtifact ocispecs.Descriptor `json:"targetArtifact"`
+}
+
+// verifyNotation checks that dt is a notation JWS envelope for dgst signed
+// with a certificate chain that leads to one of the roots.
+func verifyNotation(mediaType string, dt []byte, dgst digest.Digest, keys *Keys, now time.Time) (*Signer, error) {
+	if mediaType == NotationCOSEMediaType {
+		return nil, errors.New("COSE notation signatures are not supported")
+	}
+	if mediaType != NotationJWSMediaType {
+		return nil, errors.Errorf("unsupported notation signature envelope %s", mediaType)
+	}
+	if keys.nroots == 0 {
+		return nil, errors.New("image is signed with notation but no root certificates were provided")
+	}
+
+	var env jwsEnvelope
+	if err := json.Unmarshal(dt, &env); err != nil {
+		return nil, errors.Wrap(err, "failed to parse notation signature envelope")
+	}
+	protected, err := base64.RawURLEncoding.DecodeString(env.Protected)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid notation protected header")
+	}
+	var hdr jwsProtectedHeader
+	if err := json.Unmarshal(protected, &hdr); err != nil {
+		return nil, errors.Wrap(err, "failed to parse notation protected header")
+	}
+	if hdr.ContentType != NotationPayloadContentType {
+		return nil, errors.Errorf("unsupported notation payload content type %q", hdr.ContentType)
+	}
+	for _, c := range hdr.Critical {
+		switch c {
+		case notationSigningSchemeHeader, notationSigningTimeHeader, notationExpiryHeader:
+		default:
+			return nil, errors.Errorf("unsupported critical notation header %q", c)
+		}
+	}
+	// the notary.x509.signingAuthority scheme needs a timestamp from a
+	// trusted authority, which isn't verified
+	if hdr.SigningScheme != notationSchemeX509 {
+		return nil, errors.Errorf("unsupported notation signing scheme %q", hdr.SigningScheme)
+	}
+	if hdr.Expiry != nil && now.After(*hdr.Expiry) {
+		return nil, errors.Errorf("notation signature expired at %s", hdr.Expiry)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid notation signature")
+	}
+	if len(env.Header.CertChain) == 0 {
+		return nil, errors.New("notation signature has no certificate chain")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(env.Header.CertChain))
+	for _, dt := range env.Header.CertChain {
+		cert, err := x509.ParseCertificate(dt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse notation certificate")
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	// the signing time of the notary.x509 scheme is set by the signer, so the
+	// certificates have to be valid now
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         keys.roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to verify notation certificate chain")
+	}
+
+	if err := verifyJWS(hdr.Algorithm, certs[0].PublicKey, []byte(env.Protected+"."+env.Payload), sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid notation payload")
+	}
+	var p notationPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to parse notation payload")
+	}
+	if p.TargetArtifact.Digest != dgst {
+		return nil, errors.Errorf("notation signature is for %s, not %s", p.TargetArtifact.Digest, dgst)
+	}
+	return &Signer{
+		Type:     SignatureTypeNotation,
+		Identity: certs[0].Subject.String(),
+	}, nil
+}
+
+func verifyJWS(alg string, pub crypto.PublicKey, signed, sig []byte) error {
+	var h crypto.Hash
+	switch alg {
+	case "PS256", "ES256":
+		h = crypto.SHA256
+	case "PS384", "ES384":
+		h = crypto.SHA384
+	case "PS512", "ES512":
+		h = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported notation signature algorithm %q", alg)
+	}
+	hh := h.New()
+	hh.Write(signed)
+	sum := hh.Sum(nil)
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'P' {
+			return errors.Errorf("invalid algorithm %s for RSA key", alg)
+		}
+		if err := rsa.VerifyPSS(pub, h, sum, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return errors.Wrap(err, "failed to verify notation signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			return errors.Errorf("invalid algorithm %s for ECDSA key", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid notation ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, sum, r, s) {
+			return errors.New("failed to verify notation signature")
+		}
+		return nil
+	}
+	return errors.Errorf("unsupported notation certificate key type %T", pub)
+}
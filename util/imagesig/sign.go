@@ -0,0 +1,181 @@
+package imagesig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/moby/buildkit/util/sigutil"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// SigningKey signs images and attestations the same way as cosign does with a
+// local key.
+type SigningKey struct {
+	key crypto.Signer
+	id  string
+}
+
+// ParseSigningKey reads a PEM encoded private key. PKCS #8, SEC 1 EC and
+// PKCS #1 RSA keys are supported, but encrypted keys are not.
+func ParseSigningKey(dt []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(dt)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "ENCRYPTED PRIVATE KEY", "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		return nil, errors.Errorf("encrypted private keys are not supported")
+	default:
+		return nil, errors.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	var signer crypto.Signer
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		signer = k
+	case *rsa.PrivateKey:
+		signer = k
+	case ed25519.PrivateKey:
+		signer = k
+	default:
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &SigningKey{
+		key: signer,
+		id:  digest.FromBytes(pub).String(),
+	}, nil
+}
+
+// Public returns the public key that verifies the signatures.
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.key.Public()
+}
+
+// Sign signs msg with the SHA-256 digest of msg for ECDSA and RSA keys and
+// with msg itself for Ed25519 keys, matching sigutil.VerifyPublicKey.
+func (k *SigningKey) Sign(msg []byte) ([]byte, error) {
+	switch key := k.key.(type) {
+	case *ecdsa.PrivateKey:
+		h := sha256.Sum256(msg)
+		return ecdsa.SignASN1(rand.Reader, key, h[:])
+	case *rsa.PrivateKey:
+		h := sha256.Sum256(msg)
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, msg), nil
+	}
+	return nil, errors.Errorf("unsupported private key type %T", k.key)
+}
+
+// SignCosign returns the simple signing payload of the manifest dgst in the
+// repository ref and its base64 encoded signature, as stored in the layers of
+// a cosign signature manifest.
+func (k *SigningKey) SignCosign(ref string, dgst digest.Digest) ([]byte, string, error) {
+	var ss SimpleSigning
+	ss.Critical.Identity.DockerReference = ref
+	ss.Critical.Image.DockerManifestDigest = dgst
+	ss.Critical.Type = CosignSignatureType
+	payload, err := json.Marshal(ss)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	sig, err := k.Sign(payload)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to sign cosign payload")
+	}
+	return payload, base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// SignedCosign returns true if sig is a base64 encoded signature of the
+// cosign payload made by the key.
+func (k *SigningKey) SignedCosign(payload []byte, sig string) bool {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	h := sha256.Sum256(payload)
+	return sigutil.VerifyPublicKey(k.Public(), payload, h[:], raw)
+}
+
+// DSSEEnvelope is a signed payload in the Dead Simple Signing Envelope
+// format, see https://github.com/secure-systems-lab/dsse.
+type DSSEEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     []byte          `json:"payload"`
+	Signatures  []DSSESignature `json:"signatures"`
+}
+
+type DSSESignature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// SignDSSE wraps payload in an envelope with a signature of the key.
+func (k *SigningKey) SignDSSE(payloadType string, payload []byte) (*DSSEEnvelope, error) {
+	sig, err := k.Sign(dssePAE(payloadType, payload))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign DSSE envelope")
+	}
+	return &DSSEEnvelope{
+		PayloadType: payloadType,
+		Payload:     payload,
+		Signatures:  []DSSESignature{{KeyID: k.id, Sig: sig}},
+	}, nil
+}
+
+// VerifyDSSE checks that the envelope has a signature made by one of the
+// public keys and returns its payload.
+func VerifyDSSE(env *DSSEEnvelope, keys *Keys) ([]byte, *Signer, error) {
+	if len(env.Signatures) == 0 {
+		return nil, nil, errors.New("DSSE envelope has no signatures")
+	}
+	msg := dssePAE(env.PayloadType, env.Payload)
+	h := sha256.Sum256(msg)
+	for _, sig := range env.Signatures {
+		for _, pub := range keys.publicKeys {
+			if !sigutil.VerifyPublicKey(pub, msg, h[:], sig.Sig) {
+				continue
+			}
+			dt, err := x509.MarshalPKIXPublicKey(pub)
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			return env.Payload, &Signer{
+				Type:     SignatureTypeCosign,
+				Identity: digest.FromBytes(dt).String(),
+			}, nil
+		}
+	}
+	return nil, nil, errors.New("failed to verify DSSE envelope")
+}
+
+// dssePAE returns the pre-authentication encoding of the payload that DSSE
+// signatures are made over.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
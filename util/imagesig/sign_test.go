@@ -0,0 +1,120 @@
+package imagesig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignCosign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherDER, err := x509.MarshalECPrivateKey(otherKey)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		key   crypto.Signer
+		block *pem.Block
+	}{
+		"pkcs8-ecdsa":   {key: ecKey},
+		"sec1-ecdsa":    {key: ecKey, block: &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}},
+		"pkcs8-rsa":     {key: rsaKey},
+		"pkcs1-rsa":     {key: rsaKey, block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}},
+		"pkcs8-ed25519": {key: edKey},
+	} {
+		t.Run(name, func(t *testing.T) {
+			block := tc.block
+			if block == nil {
+				dt, err := x509.MarshalPKCS8PrivateKey(tc.key)
+				require.NoError(t, err)
+				block = &pem.Block{Type: "PRIVATE KEY", Bytes: dt}
+			}
+			key, err := ParseSigningKey(pem.EncodeToMemory(block))
+			require.NoError(t, err)
+
+			reg := newTestRegistry(t, true)
+			img := reg.putImage()
+			payload, sig, err := key.SignCosign(reg.ref(), img)
+			require.NoError(t, err)
+			reg.putReferrer(img, CosignArtifactType, func(reg *testRegistry) ocispecs.Descriptor {
+				desc := reg.PutBlob(CosignSimpleSigningMediaType, payload)
+				desc.Annotations = map[string]string{CosignSignatureAnnotation: sig}
+				return desc
+			})
+
+			s, err := Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, publicKeyPEM(t, key.Public())))
+			require.NoError(t, err)
+			require.Equal(t, SignatureTypeCosign, s.Type)
+
+			require.True(t, key.SignedCosign(payload, sig))
+			other, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: otherDER}))
+			require.NoError(t, err)
+			require.False(t, other.SignedCosign(payload, sig))
+			require.False(t, key.SignedCosign(payload, "invalid"))
+		})
+	}
+}
+
+func TestSignDSSE(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	key, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: dt}))
+	require.NoError(t, err)
+
+	env, err := key.SignDSSE(intoto.PayloadType, []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`))
+	require.NoError(t, err)
+	require.Len(t, env.Signatures, 1)
+
+	_, pemKey := newECDSAKey(t)
+	payload, s, err := VerifyDSSE(env, mustParseKeys(t, pemKey, publicKeyPEM(t, key.Public())))
+	require.NoError(t, err)
+	require.Equal(t, env.Payload, payload)
+	require.Equal(t, env.Signatures[0].KeyID, s.Identity)
+
+	_, _, err = VerifyDSSE(env, mustParseKeys(t, pemKey))
+	require.ErrorContains(t, err, "failed to verify DSSE envelope")
+
+	// the signature covers the payload type
+	env.PayloadType = "application/json"
+	_, _, err = VerifyDSSE(env, mustParseKeys(t, publicKeyPEM(t, key.Public())))
+	require.ErrorContains(t, err, "failed to verify DSSE envelope")
+}
+
+func TestParseSigningKey(t *testing.T) {
+	_, err := ParseSigningKey([]byte("foo"))
+	require.ErrorContains(t, err, "no PEM encoded private key found")
+
+	_, err = ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: []byte("foo")}))
+	require.ErrorContains(t, err, "encrypted private keys are not supported")
+
+	_, pemKey := newECDSAKey(t)
+	_, err = ParseSigningKey([]byte(pemKey))
+	require.ErrorContains(t, err, `unsupported PEM block type "PUBLIC KEY"`)
+}
+
+func publicKeyPEM(t *testing.T, pub crypto.PublicKey) string {
+	dt, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+}
@@ -0,0 +1,175 @@
+// Package imagesig verifies cosign and notation signatures of images stored
+// in a registry and creates cosign signatures with a local key.
+package imagesig
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/reference"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/hashicorp/go-multierror"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/resolver"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxSignatureBlobSize limits the size of the signature manifests and
+// payloads read from a registry.
+const maxSignatureBlobSize = 4 << 20
+
+// ErrNotSigned is returned if no signatures were found for an image.
+var ErrNotSigned = errors.New("image is not signed")
+
+type SignatureType string
+
+const (
+	SignatureTypeCosign   SignatureType = "cosign"
+	SignatureTypeNotation SignatureType = "notation"
+)
+
+// Signer describes a verified signature.
+type Signer struct {
+	Type SignatureType
+	// Identity is the digest of the public key for cosign signatures and the
+	// subject of the signing certificate for notation signatures.
+	Identity string
+}
+
+// Verify checks that the manifest dgst in the repository of ref has a
+// signature that can be verified with keys. Cosign signatures are looked up
+// with the cosign tag and as referrers, notation signatures as referrers.
+// ErrNotSigned is returned if the image has no signatures at all.
+func Verify(ctx context.Context, r *resolver.Resolver, ref string, dgst digest.Digest, keys *Keys) (*Signer, error) {
+	refspec, err := reference.Parse(ref)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fetcher, err := r.Fetcher(ctx, refspec.Locator+"@"+dgst.String())
+	if err != nil {
+		return nil, err
+	}
+	provider := contentutil.FromFetcher(fetcher)
+
+	var errs *multierror.Error
+	var found bool
+
+	_, desc, err := r.Resolve(ctx, refspec.Locator+":"+CosignTag(dgst))
+	switch {
+	case err == nil:
+		found = true
+		s, err := verifyCosignManifest(ctx, provider, desc, dgst, keys)
+		if err == nil {
+			return s, nil
+		}
+		errs = multierror.Append(errs, err)
+	case !cerrdefs.IsNotFound(err):
+		errs = multierror.Append(errs, err)
+	}
+
+	referrers, err := r.Referrers(ctx, ref, dgst, "")
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	for _, desc := range referrers {
+		var s *Signer
+		var err error
+		switch desc.ArtifactType {
+		case CosignArtifactType:
+			found = true
+			s, err = verifyCosignManifest(ctx, provider, desc, dgst, keys)
+		case NotationArtifactType:
+			found = true
+			s, err = verifyNotationManifest(ctx, provider, desc, dgst, keys)
+		default:
+			continue
+		}
+		if err == nil {
+			return s, nil
+		}
+		errs = multierror.Append(errs, err)
+	}
+
+	if !found {
+		if err := errs.ErrorOrNil(); err != nil {
+			return nil, errors.Wrapf(err, "failed to look up signatures of %s", dgst)
+		}
+		return nil, errors.Wrapf(ErrNotSigned, "no signatures found for %s", dgst)
+	}
+	return nil, errors.Wrapf(errs.ErrorOrNil(), "no valid signature found for %s", dgst)
+}
+
+func verifyCosignManifest(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, dgst digest.Digest, keys *Keys) (*Signer, error) {
+	var mfst ocispecs.Manifest
+	if err := readJSON(ctx, provider, desc, &mfst); err != nil {
+		return nil, err
+	}
+	var errs *multierror.Error
+	for _, l := range mfst.Layers {
+		if l.MediaType != CosignSimpleSigningMediaType {
+			continue
+		}
+		sig, ok := l.Annotations[CosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := readBlob(ctx, provider, l)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		s, err := verifyCosign(payload, sig, dgst, keys.publicKeys)
+		if err == nil {
+			return s, nil
+		}
+		errs = multierror.Append(errs, err)
+	}
+	if err := errs.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return nil, errors.Errorf("cosign signature manifest %s has no signatures", desc.Digest)
+}
+
+func verifyNotationManifest(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, dgst digest.Digest, keys *Keys) (*Signer, error) {
+	var mfst ocispecs.Manifest
+	if err := readJSON(ctx, provider, desc, &mfst); err != nil {
+		return nil, err
+	}
+	if len(mfst.Layers) != 1 {
+		return nil, errors.Errorf("notation signature manifest %s must have one layer, got %d", desc.Digest, len(mfst.Layers))
+	}
+	dt, err := readBlob(ctx, provider, mfst.Layers[0])
+	if err != nil {
+		return nil, err
+	}
+	return verifyNotation(mfst.Layers[0].MediaType, dt, dgst, keys, time.Now())
+}
+
+func readJSON(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, v any) error {
+	dt, err := readBlob(ctx, provider, desc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(dt, v); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", desc.Digest)
+	}
+	return nil
+}
+
+func readBlob(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor) ([]byte, error) {
+	if desc.Size > maxSignatureBlobSize {
+		return nil, errors.Errorf("blob %s is too large: %d bytes", desc.Digest, desc.Size)
+	}
+	dt, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", desc.Digest)
+	}
+	if dgst := desc.Digest.Algorithm().FromBytes(dt); dgst != desc.Digest {
+		return nil, errors.Errorf("digest mismatch %s: %s", dgst, desc.Digest)
+	}
+	return dt, nil
+}
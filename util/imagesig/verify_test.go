@@ -0,0 +1,337 @@
+package imagesig
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/resolver"
+	"github.com/moby/buildkit/util/testutil/fakeregistry"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCosign(t *testing.T) {
+	for _, referrersAPI := range []bool{false, true} {
+		reg := newTestRegistry(t, referrersAPI)
+		img := reg.putImage()
+
+		key, pemKey := newECDSAKey(t)
+		_, otherKey := newECDSAKey(t)
+
+		_, err := Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, pemKey))
+		require.ErrorIs(t, err, ErrNotSigned)
+
+		reg.putCosignTag(img, cosignLayer(t, key, img))
+
+		s, err := Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, otherKey, pemKey))
+		require.NoError(t, err)
+		require.Equal(t, SignatureTypeCosign, s.Type)
+
+		_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, otherKey))
+		require.ErrorContains(t, err, "failed to verify cosign signature")
+		require.NotErrorIs(t, err, ErrNotSigned)
+
+		// a signature of another image does not verify this one
+		img2 := reg.putImage()
+		reg.putCosignTag(img2, cosignLayer(t, key, img))
+		_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img2, mustParseKeys(t, pemKey))
+		require.ErrorContains(t, err, "cosign signature is for "+img.String())
+	}
+}
+
+func TestVerifyCosignReferrer(t *testing.T) {
+	for _, referrersAPI := range []bool{false, true} {
+		reg := newTestRegistry(t, referrersAPI)
+		img := reg.putImage()
+
+		key, pemKey := newECDSAKey(t)
+		reg.putReferrer(img, CosignArtifactType, cosignLayer(t, key, img))
+
+		s, err := Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, pemKey))
+		require.NoError(t, err)
+		require.Equal(t, SignatureTypeCosign, s.Type)
+	}
+}
+
+func TestVerifyNotation(t *testing.T) {
+	reg := newTestRegistry(t, true)
+	img := reg.putImage()
+
+	rootKey, rootCert, rootPEM := newCA(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecLeaf := newLeaf(t, rootKey, rootCert, &ecKey.PublicKey, x509.ExtKeyUsageCodeSigning)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaLeaf := newLeaf(t, rootKey, rootCert, &rsaKey.PublicKey, x509.ExtKeyUsageCodeSigning)
+	_, _, otherRootPEM := newCA(t)
+
+	reg.putReferrer(img, NotationArtifactType, reg.PutBlob(NotationJWSMediaType, notationEnvelope(t, "ES256", ecKey, ecLeaf, img)))
+
+	s, err := Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, rootPEM))
+	require.NoError(t, err)
+	require.Equal(t, SignatureTypeNotation, s.Type)
+	require.Equal(t, "CN=leaf", s.Identity)
+
+	_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, otherRootPEM))
+	require.ErrorContains(t, err, "failed to verify notation certificate chain")
+
+	img2 := reg.putImage()
+	reg.putReferrer(img2, NotationArtifactType, reg.PutBlob(NotationJWSMediaType, notationEnvelope(t, "PS256", rsaKey, rsaLeaf, img2)))
+	s, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img2, mustParseKeys(t, rootPEM))
+	require.NoError(t, err)
+	require.Equal(t, SignatureTypeNotation, s.Type)
+
+	// certificates without the code signing usage are rejected
+	img3 := reg.putImage()
+	serverLeaf := newLeaf(t, rootKey, rootCert, &ecKey.PublicKey, x509.ExtKeyUsageServerAuth)
+	reg.putReferrer(img3, NotationArtifactType, reg.PutBlob(NotationJWSMediaType, notationEnvelope(t, "ES256", ecKey, serverLeaf, img3)))
+	_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img3, mustParseKeys(t, rootPEM))
+	require.ErrorContains(t, err, "failed to verify notation certificate chain")
+
+	// signatures with a scheme that needs a trusted timestamp are rejected
+	img4 := reg.putImage()
+	reg.putReferrer(img4, NotationArtifactType, reg.PutBlob(NotationJWSMediaType, notationEnvelope(t, "ES256", ecKey, ecLeaf, img4, func(h map[string]any) {
+		h[notationSigningSchemeHeader] = "notary.x509.signingAuthority"
+	})))
+	_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img4, mustParseKeys(t, rootPEM))
+	require.ErrorContains(t, err, `unsupported notation signing scheme "notary.x509.signingAuthority"`)
+
+	// an expired certificate is rejected even if the signing time claimed
+	// by the signer is within its validity
+	img5 := reg.putImage()
+	expiredTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "expired"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(-time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	expiredLeaf, err := x509.CreateCertificate(rand.Reader, expiredTmpl, rootCert, &ecKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	reg.putReferrer(img5, NotationArtifactType, reg.PutBlob(NotationJWSMediaType, notationEnvelope(t, "ES256", ecKey, expiredLeaf, img5, func(h map[string]any) {
+		h[notationSigningTimeHeader] = time.Now().Add(-30 * time.Minute).UTC()
+	})))
+	_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img5, mustParseKeys(t, rootPEM))
+	require.ErrorContains(t, err, "failed to verify notation certificate chain")
+
+	// a public key does not verify notation signatures
+	_, pemKey := newECDSAKey(t)
+	_, err = Verify(context.TODO(), reg.resolver(), reg.ref(), img, mustParseKeys(t, pemKey))
+	require.ErrorContains(t, err, "no root certificates were provided")
+}
+
+func TestParseKeys(t *testing.T) {
+	_, pemKey := newECDSAKey(t)
+	_, _, rootPEM := newCA(t)
+
+	keys, err := ParseKeys(pemKey + rootPEM)
+	require.NoError(t, err)
+	require.Len(t, keys.publicKeys, 1)
+	require.Equal(t, 1, keys.nroots)
+
+	_, err = ParseKeys("")
+	require.ErrorContains(t, err, "no PEM encoded public keys or certificates found")
+
+	_, err = ParseKeys(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("foo")})))
+	require.ErrorContains(t, err, `unsupported PEM block type "PRIVATE KEY"`)
+}
+
+func mustParseKeys(t *testing.T, values ...string) *Keys {
+	keys, err := ParseKeys(values...)
+	require.NoError(t, err)
+	return keys
+}
+
+func newECDSAKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	dt, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}))
+}
+
+func newCA(t *testing.T) (crypto.Signer, *x509.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	dt, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(dt)
+	require.NoError(t, err)
+	return key, cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: dt}))
+}
+
+func newLeaf(t *testing.T, caKey crypto.Signer, ca *x509.Certificate, pub crypto.PublicKey, usage x509.ExtKeyUsage) []byte {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	dt, err := x509.CreateCertificate(rand.Reader, tmpl, ca, pub, caKey)
+	require.NoError(t, err)
+	return dt
+}
+
+func cosignLayer(t *testing.T, key *ecdsa.PrivateKey, dgst digest.Digest) func(*testRegistry) ocispecs.Descriptor {
+	return func(reg *testRegistry) ocispecs.Descriptor {
+		var ss SimpleSigning
+		ss.Critical.Identity.DockerReference = reg.ref()
+		ss.Critical.Image.DockerManifestDigest = dgst
+		ss.Critical.Type = CosignSignatureType
+		payload, err := json.Marshal(ss)
+		require.NoError(t, err)
+		h := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+		require.NoError(t, err)
+		desc := reg.PutBlob(CosignSimpleSigningMediaType, payload)
+		desc.Annotations = map[string]string{
+			CosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		}
+		return desc
+	}
+}
+
+func notationEnvelope(t *testing.T, alg string, key crypto.Signer, leaf []byte, dgst digest.Digest, headers ...func(map[string]any)) []byte {
+	h := map[string]any{
+		"alg":                       alg,
+		"cty":                       NotationPayloadContentType,
+		"crit":                      []string{notationSigningSchemeHeader},
+		notationSigningSchemeHeader: notationSchemeX509,
+		notationSigningTimeHeader:   time.Now().UTC(),
+	}
+	for _, f := range headers {
+		f(h)
+	}
+	hdr, err := json.Marshal(h)
+	require.NoError(t, err)
+	payload, err := json.Marshal(notationPayload{
+		TargetArtifact: ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageManifest, Digest: dgst},
+	})
+	require.NoError(t, err)
+	protected := base64.RawURLEncoding.EncodeToString(hdr)
+	encPayload := base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(protected + "." + encPayload))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, sum[:])
+		require.NoError(t, err)
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, sum[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		require.NoError(t, err)
+	}
+
+	var env jwsEnvelope
+	env.Payload = encPayload
+	env.Protected = protected
+	env.Header.CertChain = [][]byte{leaf}
+	env.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	dt, err := json.Marshal(env)
+	require.NoError(t, err)
+	return dt
+}
+
+// testRegistry adds helpers for signed images to the fake registry.
+type testRegistry struct {
+	*fakeregistry.Registry
+	t *testing.T
+	n int
+}
+
+func newTestRegistry(t *testing.T, referrersAPI bool) *testRegistry {
+	return &testRegistry{
+		Registry: fakeregistry.New(t, referrersAPI),
+		t:        t,
+	}
+}
+
+func (reg *testRegistry) ref() string {
+	return reg.Repo() + ":latest"
+}
+
+func (reg *testRegistry) resolver() *resolver.Resolver {
+	hosts := docker.ConfigureDefaultRegistries(docker.WithPlainHTTP(docker.MatchAllHosts))
+	sm, err := session.NewManager()
+	require.NoError(reg.t, err)
+	return resolver.DefaultPool.GetResolver(hosts, reg.ref(), "pull", sm, nil)
+}
+
+func (reg *testRegistry) putImage() digest.Digest {
+	reg.n++
+	config := reg.PutBlob(ocispecs.MediaTypeImageConfig, []byte(`{"n":`+strconv.Itoa(reg.n)+`}`))
+	return reg.PutManifest(ocispecs.Manifest{Config: config}).Digest
+}
+
+func (reg *testRegistry) putCosignTag(dgst digest.Digest, layer func(*testRegistry) ocispecs.Descriptor) {
+	config := reg.PutBlob(ocispecs.MediaTypeImageConfig, []byte("{}"))
+	desc := reg.PutManifest(ocispecs.Manifest{
+		Config: config,
+		Layers: []ocispecs.Descriptor{layer(reg)},
+	})
+	reg.SetTag(CosignTag(dgst), desc.Digest)
+}
+
+func (reg *testRegistry) putReferrer(dgst digest.Digest, artifactType string, layer any) {
+	var l ocispecs.Descriptor
+	switch layer := layer.(type) {
+	case func(*testRegistry) ocispecs.Descriptor:
+		l = layer(reg)
+	case ocispecs.Descriptor:
+		l = layer
+	default:
+		reg.t.Fatal(errors.Errorf("invalid layer %T", layer))
+	}
+	dt, _ := reg.Blob(dgst)
+	subject := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    dgst,
+		Size:      int64(len(dt)),
+	}
+	reg.PutBlob(ocispecs.DescriptorEmptyJSON.MediaType, ocispecs.DescriptorEmptyJSON.Data)
+	desc := reg.PutManifest(ocispecs.Manifest{
+		ArtifactType: artifactType,
+		Config:       ocispecs.DescriptorEmptyJSON,
+		Layers:       []ocispecs.Descriptor{l},
+		Subject:      &subject,
+	})
+	reg.AddReferrers(dgst, desc)
+	if !reg.ReferrersAPI() {
+		idx := reg.PutIndex(reg.Index(dgst))
+		reg.SetTag(resolver.ReferrersTag(dgst), idx.Digest)
+	}
+}
@@ -17,8 +17,10 @@ import (
 	"github.com/containerd/platforms"
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/resolver/limited"
 	"github.com/moby/buildkit/util/resolver/retryhandler"
 	digest "github.com/opencontainers/go-digest"
@@ -104,6 +106,9 @@ func Config(ctx context.Context, str string, resolver remotes.Resolver, cache Co
 	}
 	// use resolver if desc is incomplete
 	if desc.MediaType == "" {
+		if offline.IsOffline(ctx) {
+			return "", nil, errors.Wrapf(offline.ErrNetworkAccess, "image %s could not be resolved from the local content store", ref.String())
+		}
 		_, desc, err = resolver.Resolve(ctx, ref.String())
 		if err != nil {
 			return "", nil, err
@@ -129,11 +134,12 @@ func Config(ctx context.Context, str string, resolver remotes.Resolver, cache Co
 		return "", nil, err
 	}
 
-	handlers := []images.Handler{
-		retryhandler.New(limited.FetchHandler(cache, fetcher, str), func(_ []byte) {}),
-		dslHandler,
-		children,
+	var handlers []images.Handler
+	// in offline mode the manifest and config need to be in the content store
+	if !offline.IsOffline(ctx) {
+		handlers = append(handlers, retryhandler.New(limited.FetchHandler(cache, fetcher, str), func(_ []byte) {}))
 	}
+	handlers = append(handlers, dslHandler, children)
 	if err := images.Dispatch(ctx, images.Handlers(handlers...), nil, desc); err != nil {
 		return "", nil, err
 	}
@@ -189,7 +195,7 @@ func childrenConfigHandler(provider content.Provider, platform platforms.MatchCo
 				descs = append(descs, index.Manifests...)
 			}
 		case images.MediaTypeDockerSchema2Config, ocispecs.MediaTypeImageConfig, docker.LegacyConfigMediaType,
-			intoto.PayloadType:
+			intoto.PayloadType, attestation.MediaTypeDSSE:
 			// childless data types.
 			return nil, nil
 		default:
@@ -0,0 +1,29 @@
+// Package offline marks contexts in which sources must be resolved without
+// accessing the network, eg. for builds in cache-only mode.
+package offline
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type contextKeyT string
+
+var contextKey = contextKeyT("buildkit/util/offline")
+
+// ErrNetworkAccess is returned by sources that would need to access the
+// network in an offline context.
+var ErrNetworkAccess = errors.New("network access is not allowed in cache-only mode")
+
+// WithOffline returns a context that disallows network access for resolving
+// sources.
+func WithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey, true)
+}
+
+// IsOffline returns true if network access is disallowed for the context.
+func IsOffline(ctx context.Context) bool {
+	v, _ := ctx.Value(contextKey).(bool)
+	return v
+}
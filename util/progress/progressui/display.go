@@ -742,7 +742,17 @@ func (t *trace) update(s *client.SolveStatus, termWidth int) {
 		v.warnings = append(v.warnings, *w)
 		v.update(1)
 	}
-	for _, l := range s.Logs {
+	logs := s.Logs
+	for _, e := range s.Events {
+		// events are shown in the logs of the vertex
+		logs = append(logs, &client.VertexLog{
+			Vertex:    e.Vertex,
+			Stream:    2,
+			Data:      fmt.Appendf(nil, "[%s] %s\n", e.Type, e.Message),
+			Timestamp: e.Timestamp,
+		})
+	}
+	for _, l := range logs {
 		v, ok := t.byDigest[l.Vertex]
 		if !ok {
 			continue // shouldn't happen
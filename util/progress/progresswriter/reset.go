@@ -63,11 +63,19 @@ func ResetTime(in Writer) Writer {
 						logs = append(logs, &v)
 					}
 
+					events := make([]*client.VertexEvent, 0, len(st.Events))
+					for _, v := range st.Events {
+						v := *v
+						v.Timestamp = v.Timestamp.Add(-*w.diff)
+						events = append(events, &v)
+					}
+
 					st = &client.SolveStatus{
 						Vertexes: vertexes,
 						Statuses: statuses,
 						Logs:     logs,
 						Warnings: st.Warnings,
+						Events:   events,
 					}
 				}
 				in.Status() <- st
@@ -16,6 +16,7 @@ import (
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/offline"
 	"github.com/moby/buildkit/util/progress/logs"
 	"github.com/moby/buildkit/util/resolver/limited"
 	"github.com/moby/buildkit/util/resolver/retryhandler"
@@ -63,9 +64,13 @@ func (p *Puller) resolve(ctx context.Context, resolver remotes.Resolver) error {
 				p.resolveErr = err
 			}
 		}()
-		if p.tryLocalResolve(ctx) == nil {
+		localErr := p.tryLocalResolve(ctx)
+		if localErr == nil {
 			return
 		}
+		if offline.IsOffline(ctx) {
+			return struct{}{}, errors.Wrapf(offline.ErrNetworkAccess, "image %s could not be resolved from the local content store: %v", p.Src.String(), localErr)
+		}
 		ref, desc, err := resolver.Resolve(ctx, p.Src.String())
 		if err != nil {
 			return struct{}{}, err
@@ -148,12 +153,12 @@ func (p *Puller) PullManifests(ctx context.Context, getResolver SessionResolver)
 	if err != nil {
 		return nil, err
 	}
-	handlers = append(handlers,
-		filterLayerBlobs(metadata, &mu),
-		retryhandler.New(limited.FetchHandler(p.ContentStore, fetcher, p.ref), logs.LoggerFromContext(ctx)),
-		childrenHandler,
-		dslHandler,
-	)
+	handlers = append(handlers, filterLayerBlobs(metadata, &mu))
+	// in offline mode all the metadata blobs need to be in the content store
+	if !offline.IsOffline(ctx) {
+		handlers = append(handlers, retryhandler.New(limited.FetchHandler(p.ContentStore, fetcher, p.ref), logs.LoggerFromContext(ctx)))
+	}
+	handlers = append(handlers, childrenHandler, dslHandler)
 
 	if err := images.Dispatch(ctx, images.Handlers(handlers...), nil, p.desc); err != nil {
 		return nil, err
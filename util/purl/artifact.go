@@ -0,0 +1,42 @@
+package purl
+
+import (
+	"path"
+
+	"github.com/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	packageurl "github.com/package-url/packageurl-go"
+	"github.com/pkg/errors"
+)
+
+// ArtifactToPURL converts the reference and manifest digest of an OCI artifact
+// to a package URL of the oci type. The artifact type is recorded in the
+// artifact_type qualifier if it is known.
+// See https://github.com/package-url/purl-spec/blob/main/PURL-TYPES.rst#oci
+func ArtifactToPURL(ref string, dgst digest.Digest, artifactType string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse ref %q", ref)
+	}
+
+	var qualifiers []packageurl.Qualifier
+	if artifactType != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{
+			Key:   "artifact_type",
+			Value: artifactType,
+		})
+	}
+	qualifiers = append(qualifiers, packageurl.Qualifier{
+		Key:   "repository_url",
+		Value: named.Name(),
+	})
+	if tagged, ok := named.(reference.Tagged); ok {
+		qualifiers = append(qualifiers, packageurl.Qualifier{
+			Key:   "tag",
+			Value: tagged.Tag(),
+		})
+	}
+
+	p := packageurl.NewPackageURL(packageurl.TypeOCI, "", path.Base(named.Name()), dgst.String(), qualifiers, "")
+	return p.ToString(), nil
+}
@@ -0,0 +1,49 @@
+package purl
+
+import (
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactToPURL(t *testing.T) {
+	testDgst := digest.FromBytes([]byte("test"))
+
+	tcases := []struct {
+		ref          string
+		artifactType string
+		expected     string
+		err          bool
+	}{
+		{
+			ref:      "ghcr.io/foo/models:v1",
+			expected: "pkg:oci/models@" + testDgst.String() + "?repository_url=ghcr.io%2Ffoo%2Fmodels&tag=v1",
+		},
+		{
+			ref:          "ghcr.io/foo/models@" + testDgst.String(),
+			artifactType: "application/vnd.example.model.v1",
+			expected:     "pkg:oci/models@" + testDgst.String() + "?artifact_type=application%2Fvnd.example.model.v1&repository_url=ghcr.io%2Ffoo%2Fmodels",
+		},
+		{
+			ref:      "alpine",
+			expected: "pkg:oci/alpine@" + testDgst.String() + "?repository_url=docker.io%2Flibrary%2Falpine",
+		},
+		{
+			ref: "UPPERCASE",
+			err: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.ref, func(t *testing.T) {
+			purl, err := ArtifactToPURL(tc.ref, testDgst, tc.artifactType)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, purl)
+		})
+	}
+}
@@ -15,9 +15,11 @@ import (
 	"github.com/distribution/reference"
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/flightcontrol"
+	"github.com/moby/buildkit/util/imagesig"
 	"github.com/moby/buildkit/util/imageutil"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/logs"
@@ -69,19 +71,7 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 		ref = r.String()
 	}
 
-	scope := "push"
-	if insecure {
-		insecureTrue := true
-		httpTrue := true
-		hosts = resolver.NewRegistryConfig(map[string]resolverconfig.RegistryConfig{
-			reference.Domain(parsed): {
-				Insecure:  &insecureTrue,
-				PlainHTTP: &httpTrue,
-			},
-		})
-		scope += ":insecure"
-	}
-
+	hosts, scope := pushHosts(parsed, insecure, hosts)
 	resolver := resolver.DefaultPool.GetResolver(hosts, ref, scope, sm, session.NewGroup(sid))
 
 	pusher, err := Pusher(ctx, resolver, ref)
@@ -158,6 +148,85 @@ func skipNonDistributableBlobs(f images.HandlerFunc) images.HandlerFunc {
 	}
 }
 
+// Referrers pushes the manifests descs, that refer to the manifest subject
+// with their subject field, to the repository of ref. If the registry does
+// not support the referrers API, they are added to the referrers tag of
+// subject.
+func Referrers(ctx context.Context, sm *session.Manager, sid string, provider content.Provider, manager content.Manager, subject digest.Digest, descs []ocispecs.Descriptor, ref string, insecure bool, hosts docker.RegistryHosts) error {
+	parsed, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	repo := parsed.Name()
+	for _, desc := range descs {
+		if err := Push(ctx, sm, sid, provider, manager, desc.Digest, repo, insecure, hosts, true, nil); err != nil {
+			return err
+		}
+	}
+
+	hosts, scope := pushHosts(parsed, insecure, hosts)
+	r := resolver.DefaultPool.GetResolver(hosts, repo, scope, sm, session.NewGroup(sid))
+	return r.AddReferrers(ctx, repo, subject, descs)
+}
+
+// FetchManifest fetches the manifest that ref points to and copies its
+// layers to store, eg. to push a manifest that extends it. It returns nil if
+// ref does not exist.
+func FetchManifest(ctx context.Context, sm *session.Manager, sid string, store content.Ingester, ref string, insecure bool, hosts docker.RegistryHosts) (*ocispecs.Manifest, error) {
+	parsed, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.TagNameOnly(parsed).String()
+
+	hosts, scope := pushHosts(parsed, insecure, hosts)
+	r := resolver.DefaultPool.GetResolver(hosts, ref, scope, sm, session.NewGroup(sid))
+	_, desc, err := r.Resolve(ctx, ref)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fetcher, err := r.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	provider := contentutil.FromFetcher(fetcher)
+	dt, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest of %s", ref)
+	}
+	var mfst ocispecs.Manifest
+	if err := json.Unmarshal(dt, &mfst); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest of %s", ref)
+	}
+	for _, layer := range mfst.Layers {
+		if err := contentutil.Copy(ctx, store, provider, layer, ref, logs.LoggerFromContext(ctx)); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch layer %s of %s", layer.Digest, ref)
+		}
+	}
+	return &mfst, nil
+}
+
+// pushHosts returns the registry hosts and the resolver scope for pushing to
+// the repository of ref.
+func pushHosts(ref reference.Named, insecure bool, hosts docker.RegistryHosts) (docker.RegistryHosts, string) {
+	scope := "push"
+	if insecure {
+		insecureTrue := true
+		httpTrue := true
+		hosts = resolver.NewRegistryConfig(map[string]resolverconfig.RegistryConfig{
+			reference.Domain(ref): {
+				Insecure:  &insecureTrue,
+				PlainHTTP: &httpTrue,
+			},
+		})
+		scope += ":insecure"
+	}
+	return hosts, scope
+}
+
 func annotateDistributionSourceHandler(manager content.Manager, annotations map[digest.Digest]map[string]string, f images.HandlerFunc) func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
 	return func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
 		children, err := f(ctx, desc)
@@ -250,8 +319,8 @@ func childrenHandler(provider content.Provider) images.HandlerFunc {
 			}
 		case images.MediaTypeDockerSchema2Layer, images.MediaTypeDockerSchema2LayerGzip,
 			images.MediaTypeDockerSchema2Config, ocispecs.MediaTypeImageConfig,
-			ocispecs.MediaTypeImageLayer, ocispecs.MediaTypeImageLayerGzip,
-			intoto.PayloadType:
+			ocispecs.MediaTypeImageLayer, ocispecs.MediaTypeImageLayerGzip, ocispecs.MediaTypeEmptyJSON,
+			intoto.PayloadType, attestation.MediaTypeDSSE, imagesig.CosignSimpleSigningMediaType:
 			// childless data types.
 			return nil, nil
 		default:
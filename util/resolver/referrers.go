@@ -0,0 +1,256 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/containerd/v2/pkg/reference"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxReferrersIndexSize limits the size of the index of referrers returned by
+// a registry.
+const maxReferrersIndexSize = 4 << 20
+
+// ReferrersTag returns the tag that registries without support for the
+// referrers API store the index of referrers of dgst in.
+func ReferrersTag(dgst digest.Digest) string {
+	return dgst.Algorithm().String() + "-" + dgst.Encoded()
+}
+
+// Referrers returns the descriptors of the manifests in the repository of ref
+// that refer to the manifest dgst with their subject field. The referrers API
+// of the registry is used if it is supported, otherwise the index tagged with
+// the referrers tag schema. If artifactType is set, only referrers of that
+// type are returned.
+func (r *Resolver) Referrers(ctx context.Context, ref string, dgst digest.Digest, artifactType string) ([]ocispecs.Descriptor, error) {
+	refspec, err := reference.Parse(ref)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ctx, err = docker.ContextWithRepositoryScope(ctx, refspec, false)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := r.referrersAPI(ctx, refspec, dgst, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil {
+		idx, err = r.referrersTagIndex(ctx, refspec.Locator+":"+ReferrersTag(dgst))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filterReferrers(idx.Manifests, artifactType), nil
+}
+
+// AddReferrers makes the manifests descs, that were pushed to the repository
+// of ref and refer to the manifest dgst with their subject field,
+// discoverable with Referrers. Registries with the referrers API index them
+// when they are pushed, for other registries they are added to the index in
+// the referrers tag of dgst. Updating the tag is not atomic, so concurrent
+// updates for the same manifest may lose referrers.
+func (r *Resolver) AddReferrers(ctx context.Context, ref string, dgst digest.Digest, descs []ocispecs.Descriptor) error {
+	refspec, err := reference.Parse(ref)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ctx, err = docker.ContextWithRepositoryScope(ctx, refspec, true)
+	if err != nil {
+		return err
+	}
+	idx, err := r.referrersAPI(ctx, refspec, dgst, "")
+	if err != nil {
+		return err
+	}
+	if idx != nil {
+		return nil
+	}
+
+	tagRef := refspec.Locator + ":" + ReferrersTag(dgst)
+	idx, err = r.referrersTagIndex(ctx, tagRef)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, desc := range descs {
+		if slices.ContainsFunc(idx.Manifests, func(d ocispecs.Descriptor) bool { return d.Digest == desc.Digest }) {
+			continue
+		}
+		idx.Manifests = append(idx.Manifests, ocispecs.Descriptor{
+			MediaType:    desc.MediaType,
+			ArtifactType: desc.ArtifactType,
+			Digest:       desc.Digest,
+			Size:         desc.Size,
+		})
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	idx.SchemaVersion = 2
+	idx.MediaType = ocispecs.MediaTypeImageIndex
+
+	dt, err := json.Marshal(idx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	desc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	pusher, err := r.Pusher(ctx, tagRef)
+	if err != nil {
+		return err
+	}
+	w, err := pusher.Push(ctx, desc)
+	if err != nil {
+		if cerrdefs.IsAlreadyExists(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to push referrers index %s", tagRef)
+	}
+	defer w.Close()
+	if err := content.Copy(ctx, w, bytes.NewReader(dt), desc.Size, desc.Digest); err != nil {
+		return errors.Wrapf(err, "failed to push referrers index %s", tagRef)
+	}
+	return nil
+}
+
+// referrersAPI calls the referrers API of the registry. A nil index is
+// returned if the registry does not support the API.
+func (r *Resolver) referrersAPI(ctx context.Context, refspec reference.Spec, dgst digest.Digest, artifactType string) (*ocispecs.Index, error) {
+	hosts, err := r.HostsFunc(refspec.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	repo := strings.TrimPrefix(refspec.Locator, refspec.Hostname()+"/")
+
+	var lastErr error
+	for _, host := range hosts {
+		if !host.Capabilities.Has(docker.HostCapabilityPull) {
+			continue
+		}
+		u := url.URL{
+			Scheme: host.Scheme,
+			Host:   host.Host,
+			Path:   path.Join(host.Path, repo, "referrers", dgst.String()),
+		}
+		if artifactType != "" {
+			u.RawQuery = url.Values{"artifactType": []string{artifactType}}.Encode()
+		}
+		idx, err := fetchReferrers(ctx, host, u.String())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		// a nil index means that the referrers API is not supported
+		return idx, nil
+	}
+	return nil, lastErr
+}
+
+// referrersTagIndex reads the index of referrers in tagRef. An empty index is
+// returned if the tag does not exist.
+func (r *Resolver) referrersTagIndex(ctx context.Context, tagRef string) (*ocispecs.Index, error) {
+	_, desc, err := r.Resolve(ctx, tagRef)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return &ocispecs.Index{}, nil
+		}
+		return nil, err
+	}
+	fetcher, err := r.Fetcher(ctx, tagRef)
+	if err != nil {
+		return nil, err
+	}
+	if desc.Size > maxReferrersIndexSize {
+		return nil, errors.Errorf("referrers index %s is too large: %d bytes", tagRef, desc.Size)
+	}
+	dt, err := content.ReadBlob(ctx, contentutil.FromFetcher(fetcher), desc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read referrers index %s", tagRef)
+	}
+	var idx ocispecs.Index
+	if err := json.Unmarshal(dt, &idx); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse referrers index %s", tagRef)
+	}
+	return &idx, nil
+}
+
+// fetchReferrers calls the referrers API. A nil index is returned if the
+// registry does not support the API.
+func fetchReferrers(ctx context.Context, host docker.RegistryHost, u string) (*ocispecs.Index, error) {
+	client := host.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	for retried := false; ; retried = true {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for k, v := range host.Header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", ocispecs.MediaTypeImageIndex)
+		if host.Authorizer != nil {
+			if err := host.Authorizer.Authorize(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && !retried && host.Authorizer != nil:
+			if err := host.Authorizer.AddResponses(ctx, []*http.Response{resp}); err != nil {
+				return nil, err
+			}
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, nil
+		case resp.StatusCode != http.StatusOK:
+			return nil, errors.Errorf("unexpected status %d from referrers API %s", resp.StatusCode, u)
+		}
+		if mt, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";"); mt != ocispecs.MediaTypeImageIndex {
+			return nil, nil
+		}
+		var idx ocispecs.Index
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxReferrersIndexSize)).Decode(&idx); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse response of referrers API %s", u)
+		}
+		return &idx, nil
+	}
+}
+
+func filterReferrers(descs []ocispecs.Descriptor, artifactType string) []ocispecs.Descriptor {
+	if artifactType == "" {
+		return descs
+	}
+	var out []ocispecs.Descriptor
+	for _, desc := range descs {
+		if desc.ArtifactType == artifactType {
+			out = append(out, desc)
+		}
+	}
+	return out
+}
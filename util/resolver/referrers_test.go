@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/testutil/fakeregistry"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddReferrers(t *testing.T) {
+	for _, referrersAPI := range []bool{false, true} {
+		t.Run("referrersAPI="+strconv.FormatBool(referrersAPI), func(t *testing.T) {
+			reg := newTestRegistry(t, referrersAPI)
+			subject := digest.FromString("subject")
+			foo := ocispecs.Descriptor{
+				MediaType:    ocispecs.MediaTypeImageManifest,
+				ArtifactType: "application/vnd.example.foo",
+				Digest:       digest.FromString("foo"),
+				Size:         3,
+				Annotations:  map[string]string{"foo": "bar"},
+			}
+			bar := ocispecs.Descriptor{
+				MediaType:    ocispecs.MediaTypeImageManifest,
+				ArtifactType: "application/vnd.example.bar",
+				Digest:       digest.FromString("bar"),
+				Size:         3,
+			}
+			if referrersAPI {
+				// the registry indexes referrers when they are pushed
+				reg.AddReferrers(subject, foo, bar)
+			}
+
+			r := reg.resolver()
+			ctx := context.TODO()
+			require.NoError(t, r.AddReferrers(ctx, reg.Repo(), subject, []ocispecs.Descriptor{foo}))
+			require.NoError(t, r.AddReferrers(ctx, reg.Repo(), subject, []ocispecs.Descriptor{foo, bar}))
+			require.NoError(t, r.AddReferrers(ctx, reg.Repo(), subject, []ocispecs.Descriptor{bar}))
+
+			descs, err := r.Referrers(ctx, reg.Repo(), subject, "")
+			require.NoError(t, err)
+			require.Len(t, descs, 2)
+			require.Equal(t, foo.Digest, descs[0].Digest)
+			require.Equal(t, bar.Digest, descs[1].Digest)
+
+			descs, err = r.Referrers(ctx, reg.Repo(), subject, "application/vnd.example.bar")
+			require.NoError(t, err)
+			require.Len(t, descs, 1)
+			require.Equal(t, bar.Digest, descs[0].Digest)
+
+			if referrersAPI {
+				require.Equal(t, 0, reg.Puts())
+			} else {
+				// the referrers tag is not updated if all referrers are in it
+				require.Equal(t, 2, reg.Puts())
+				_, ok := reg.Tag(ReferrersTag(subject))
+				require.True(t, ok)
+			}
+		})
+	}
+}
+
+type testRegistry struct {
+	*fakeregistry.Registry
+	t *testing.T
+}
+
+func newTestRegistry(t *testing.T, referrersAPI bool) *testRegistry {
+	return &testRegistry{Registry: fakeregistry.New(t, referrersAPI), t: t}
+}
+
+func (reg *testRegistry) resolver() *Resolver {
+	hosts := docker.ConfigureDefaultRegistries(docker.WithPlainHTTP(docker.MatchAllHosts))
+	sm, err := session.NewManager()
+	require.NoError(reg.t, err)
+	return DefaultPool.GetResolver(hosts, reg.Repo(), "push", sm, nil)
+}
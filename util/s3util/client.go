@@ -0,0 +1,46 @@
+package s3util
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+)
+
+// Config configures the connection to an S3 compatible storage.
+type Config struct {
+	Region          string
+	EndpointURL     string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// UsePathStyle is only used together with EndpointURL
+	UsePathStyle bool
+}
+
+// NewClient returns an S3 client for the config. Static credentials are used
+// if both the access key ID and the secret access key are set, otherwise the
+// credentials are loaded from the environment of the daemon like in the AWS
+// CLI.
+func NewClient(ctx context.Context, config Config) (*s3.Client, error) {
+	var opts []func(*aws_config.LoadOptions) error
+	if config.Region != "" {
+		opts = append(opts, aws_config.WithRegion(config.Region))
+	}
+	cfg, err := aws_config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.Errorf("Unable to load AWS SDK config, %v", err)
+	}
+	return s3.NewFromConfig(cfg, func(options *s3.Options) {
+		if config.AccessKeyID != "" && config.SecretAccessKey != "" {
+			options.Credentials = credentials.NewStaticCredentialsProvider(config.AccessKeyID, config.SecretAccessKey, config.SessionToken)
+		}
+		if config.EndpointURL != "" {
+			options.UsePathStyle = config.UsePathStyle
+			options.BaseEndpoint = aws.String(config.EndpointURL)
+		}
+	}), nil
+}
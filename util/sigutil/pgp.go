@@ -0,0 +1,60 @@
+// Package sigutil verifies the signature formats that are shared by the git,
+// HTTP and image sources.
+package sigutil
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VerifyPGP checks that sig is a valid GPG signature of the data read from r
+// made by one of the armored public key blocks in keys, and returns the
+// fingerprint of the signing key. The gpg binary is needed.
+func VerifyPGP(ctx context.Context, r io.Reader, sig, keys []byte) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "buildkit-pgp-verify")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sigFile := filepath.Join(tmpDir, "signature")
+	if err := os.WriteFile(sigFile, sig, 0600); err != nil {
+		return "", errors.WithStack(err)
+	}
+	home := filepath.Join(tmpDir, "gnupg")
+	if err := os.Mkdir(home, 0700); err != nil {
+		return "", errors.WithStack(err)
+	}
+	gpg := func(stdin io.Reader, args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "gpg", append([]string{"--batch", "--no-tty", "--homedir", home}, args...)...)
+		cmd.Stdin = stdin
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return stdout.Bytes(), errors.Wrapf(err, "gpg stderr:\n%s", stderr.String())
+		}
+		return stdout.Bytes(), nil
+	}
+	if _, err := gpg(bytes.NewReader(keys), "--import"); err != nil {
+		return "", errors.Wrap(err, "failed to import GPG public keys")
+	}
+	out, err := gpg(r, "--status-fd", "1", "--verify", sigFile, "-")
+	for _, line := range strings.Split(string(out), "\n") {
+		if v, ok := strings.CutPrefix(line, "[GNUPG:] VALIDSIG "); ok && err == nil {
+			fpr, _, _ := strings.Cut(v, " ")
+			return fpr, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no valid signature")
+	}
+	return "", errors.Wrap(err, "failed to verify GPG signature")
+}
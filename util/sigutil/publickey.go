@@ -0,0 +1,23 @@
+package sigutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+)
+
+// VerifyPublicKey checks a signature of msg, with the SHA-256 digest h, as
+// created by cosign for the type of the key. ECDSA and RSA keys sign h and
+// Ed25519 keys sign msg itself, so msg is only needed for Ed25519 keys.
+func VerifyPublicKey(k crypto.PublicKey, msg, h, sig []byte) bool {
+	switch k := k.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, h, sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h, sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+// Package fakeregistry provides a minimal OCI registry for tests. It serves
+// the manifests and blobs of a single repository named "test", accepts
+// manifest pushes and optionally implements the referrers API.
+package fakeregistry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type Registry struct {
+	*httptest.Server
+	t            testing.TB
+	referrersAPI bool
+
+	mu         sync.Mutex
+	blobs      map[digest.Digest][]byte
+	mediaTypes map[digest.Digest]string
+	tags       map[string]digest.Digest
+	referrers  map[digest.Digest][]ocispecs.Descriptor
+	puts       int
+}
+
+// New starts a registry that is closed when the test ends. If referrersAPI
+// is false, requests to the referrers API fail with 404.
+func New(t testing.TB, referrersAPI bool) *Registry {
+	reg := &Registry{
+		t:            t,
+		referrersAPI: referrersAPI,
+		blobs:        map[digest.Digest][]byte{},
+		mediaTypes:   map[digest.Digest]string{},
+		tags:         map[string]digest.Digest{},
+		referrers:    map[digest.Digest][]ocispecs.Descriptor{},
+	}
+	reg.Server = httptest.NewServer(reg)
+	t.Cleanup(reg.Close)
+	return reg
+}
+
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	p := strings.TrimPrefix(r.URL.Path, "/v2/test/")
+	kind, name, ok := strings.Cut(p, "/")
+	if !ok {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	switch kind {
+	case "manifests", "blobs":
+		if kind == "manifests" && r.Method == http.MethodPut {
+			dt, err := io.ReadAll(r.Body)
+			require.NoError(reg.t, err)
+			dgst := digest.FromBytes(dt)
+			reg.blobs[dgst] = dt
+			reg.mediaTypes[dgst] = r.Header.Get("Content-Type")
+			if _, err := digest.Parse(name); err != nil {
+				reg.tags[name] = dgst
+			}
+			reg.puts++
+			w.Header().Set("Docker-Content-Digest", dgst.String())
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		dgst, ok := reg.tags[name]
+		if !ok {
+			dgst = digest.Digest(name)
+		}
+		dt, ok := reg.blobs[dgst]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", reg.mediaTypes[dgst])
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(dt)))
+		if r.Method == http.MethodHead {
+			return
+		}
+		w.Write(dt)
+	case "referrers":
+		if !reg.referrersAPI {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ocispecs.MediaTypeImageIndex)
+		json.NewEncoder(w).Encode(reg.index(digest.Digest(name)))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Repo returns the name of the repository of the registry.
+func (reg *Registry) Repo() string {
+	u, err := url.Parse(reg.URL)
+	require.NoError(reg.t, err)
+	return u.Host + "/test"
+}
+
+// ReferrersAPI returns true if the registry implements the referrers API.
+func (reg *Registry) ReferrersAPI() bool {
+	return reg.referrersAPI
+}
+
+// Puts returns the number of manifests that were pushed to the registry.
+func (reg *Registry) Puts() int {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.puts
+}
+
+// Blob returns the content of a blob or manifest.
+func (reg *Registry) Blob(dgst digest.Digest) ([]byte, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	dt, ok := reg.blobs[dgst]
+	return dt, ok
+}
+
+// Tag returns the digest of the manifest that tag points to.
+func (reg *Registry) Tag(tag string) (digest.Digest, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	dgst, ok := reg.tags[tag]
+	return dgst, ok
+}
+
+// SetTag points tag to the manifest with dgst.
+func (reg *Registry) SetTag(tag string, dgst digest.Digest) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.tags[tag] = dgst
+}
+
+func (reg *Registry) PutBlob(mediaType string, dt []byte) ocispecs.Descriptor {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	dgst := digest.FromBytes(dt)
+	reg.blobs[dgst] = dt
+	reg.mediaTypes[dgst] = mediaType
+	return ocispecs.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(dt))}
+}
+
+func (reg *Registry) PutManifest(mfst ocispecs.Manifest) ocispecs.Descriptor {
+	mfst.Versioned = specs.Versioned{SchemaVersion: 2}
+	mfst.MediaType = ocispecs.MediaTypeImageManifest
+	dt, err := json.Marshal(mfst)
+	require.NoError(reg.t, err)
+	desc := reg.PutBlob(ocispecs.MediaTypeImageManifest, dt)
+	desc.ArtifactType = mfst.ArtifactType
+	return desc
+}
+
+func (reg *Registry) PutIndex(idx ocispecs.Index) ocispecs.Descriptor {
+	dt, err := json.Marshal(idx)
+	require.NoError(reg.t, err)
+	return reg.PutBlob(ocispecs.MediaTypeImageIndex, dt)
+}
+
+// AddReferrers adds descs to the referrers of subject that the referrers API
+// returns, like a registry that indexes the referrers pushed to it.
+func (reg *Registry) AddReferrers(subject digest.Digest, descs ...ocispecs.Descriptor) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.referrers[subject] = append(reg.referrers[subject], descs...)
+}
+
+// Index returns the index of the referrers of subject.
+func (reg *Registry) Index(subject digest.Digest) ocispecs.Index {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.index(subject)
+}
+
+func (reg *Registry) index(subject digest.Digest) ocispecs.Index {
+	return ocispecs.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Manifests: append([]ocispecs.Descriptor{}, reg.referrers[subject]...),
+	}
+}
@@ -33,6 +33,8 @@ func InitDockerdWorker() {
 			FeatureCacheBackendS3,
 			FeatureDirectPush,
 			FeatureImageExporter,
+			FeatureImageSigning,
+			FeatureAttestationReferrers,
 			FeatureMultiCacheExport,
 			FeatureMultiPlatform,
 			FeatureOCIExporter,
@@ -40,6 +42,9 @@ func InitDockerdWorker() {
 			FeatureProvenance,
 			FeatureSBOM,
 			FeatureSecurityMode,
+			FeatureSourceS3,
+			FeatureSourceOCIArtifact,
+			FeatureSourceImageSignature,
 			FeatureCNINetwork,
 			FeatureCDI,
 		},
@@ -50,6 +55,10 @@ func InitDockerdWorker() {
 		ContainerdSnapshotter: true,
 		Unsupported: []string{
 			FeatureSecurityMode,
+			FeatureSourceS3,
+			FeatureSourceImageSignature,
+			FeatureImageSigning,
+			FeatureAttestationReferrers,
 			FeatureCNINetwork,
 			FeatureContentCheck,
 			FeatureCDI,
@@ -7,6 +7,7 @@ import (
 )
 
 const (
+	FeatureAttestationReferrers = "attestation_referrers"
 	FeatureCacheExport          = "cache_export"
 	FeatureCacheImport          = "cache_import"
 	FeatureCacheBackendAzblob   = "cache_backend_azblob"
@@ -19,6 +20,7 @@ const (
 	FeatureFrontendOutline      = "frontend_outline"
 	FeatureFrontendTargets      = "frontend_targets"
 	FeatureImageExporter        = "image_exporter"
+	FeatureImageSigning         = "image_signing"
 	FeatureInfo                 = "info"
 	FeatureMergeDiff            = "merge_diff"
 	FeatureMultiCacheExport     = "multi_cache_export"
@@ -29,12 +31,16 @@ const (
 	FeatureSBOM                 = "sbom"
 	FeatureSecurityMode         = "security_mode"
 	FeatureSourceDateEpoch      = "source_date_epoch"
+	FeatureSourceS3             = "source_s3"
+	FeatureSourceOCIArtifact    = "source_oci_artifact"
+	FeatureSourceImageSignature = "source_image_signature"
 	FeatureCNINetwork           = "cni_network"
 	FeatureContentCheck         = "content_check"
 	FeatureCDI                  = "cdi"
 )
 
 var features = map[string]struct{}{
+	FeatureAttestationReferrers: {},
 	FeatureCacheExport:          {},
 	FeatureCacheImport:          {},
 	FeatureCacheBackendAzblob:   {},
@@ -47,6 +53,7 @@ var features = map[string]struct{}{
 	FeatureFrontendOutline:      {},
 	FeatureFrontendTargets:      {},
 	FeatureImageExporter:        {},
+	FeatureImageSigning:         {},
 	FeatureInfo:                 {},
 	FeatureMergeDiff:            {},
 	FeatureMultiCacheExport:     {},
@@ -57,6 +64,9 @@ var features = map[string]struct{}{
 	FeatureSBOM:                 {},
 	FeatureSecurityMode:         {},
 	FeatureSourceDateEpoch:      {},
+	FeatureSourceS3:             {},
+	FeatureSourceOCIArtifact:    {},
+	FeatureSourceImageSignature: {},
 	FeatureCNINetwork:           {},
 	FeatureContentCheck:         {},
 	FeatureCDI:                  {},
@@ -41,8 +41,10 @@ import (
 	"github.com/moby/buildkit/source/git"
 	"github.com/moby/buildkit/source/http"
 	"github.com/moby/buildkit/source/local"
+	"github.com/moby/buildkit/source/s3"
 	"github.com/moby/buildkit/util/archutil"
 	"github.com/moby/buildkit/util/bklog"
+	"github.com/moby/buildkit/util/fairsem"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network"
 	"github.com/moby/buildkit/util/progress"
@@ -84,6 +86,19 @@ type WorkerOpt struct {
 	MountPoolRoot    string
 	ResourceMonitor  *resources.Monitor
 	CDIManager       *cdidevices.Manager
+	// GitObjectStoreAliases maps aliases to patterns of git remote URLs
+	// that share a git object store.
+	GitObjectStoreAliases map[string][]string
+	// FairParallelism limits the number of ops running at the same time
+	// and shares the slots between concurrent builds in proportion to their
+	// scheduler weights. If set, it is used instead of ParallelismSem.
+	FairParallelism *fairsem.Semaphore
+	// S3AllowDaemonCredentials makes S3 sources without credentials from the
+	// client use the credentials of the daemon environment.
+	S3AllowDaemonCredentials bool
+	// S3DaemonCredentialsEndpoints are the custom endpoints that S3 sources
+	// may use with the credentials of the daemon environment.
+	S3DaemonCredentialsEndpoints []string
 }
 
 // Worker is a local worker instance with dedicated snapshotter, cache, and so on.
@@ -141,9 +156,20 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(is)
 
+	as, err := containerimage.NewArtifactSource(containerimage.SourceOpt{
+		CacheAccessor: cm,
+		RegistryHosts: opt.RegistryHosts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Register(as)
+
 	if err := git.Supported(); err == nil {
 		gs, err := git.NewSource(git.Opt{
-			CacheAccessor: cm,
+			CacheAccessor:      cm,
+			ObjectStoreAliases: opt.GitObjectStoreAliases,
 		})
 		if err != nil {
 			return nil, err
@@ -162,6 +188,17 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(hs)
 
+	s3s, err := s3.NewSource(s3.Opt{
+		CacheAccessor:              cm,
+		AllowDaemonCredentials:     opt.S3AllowDaemonCredentials,
+		DaemonCredentialsEndpoints: opt.S3DaemonCredentialsEndpoints,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Register(s3s)
+
 	ss, err := local.NewSource(local.Opt{
 		CacheAccessor: cm,
 	})
@@ -339,11 +376,11 @@ func (w *Worker) ResolveOp(v solver.Vertex, s frontend.FrontendLLBBridge, sm *se
 	if baseOp, ok := v.Sys().(*pb.Op); ok {
 		switch op := baseOp.Op.(type) {
 		case *pb.Op_Source:
-			return ops.NewSourceOp(v, op, baseOp.Platform, w.SourceManager, w.ParallelismSem, sm, w)
+			return ops.NewSourceOp(v, op, baseOp.Platform, w.SourceManager, w.ParallelismSem, sm, w, ops.WithFairParallelism(w.FairParallelism))
 		case *pb.Op_Exec:
-			return ops.NewExecOp(v, op, baseOp.Platform, w.CacheMgr, w.ParallelismSem, sm, w.WorkerOpt.Executor, w)
+			return ops.NewExecOp(v, op, baseOp.Platform, w.CacheMgr, w.ParallelismSem, sm, w.WorkerOpt.Executor, w, ops.WithFairParallelism(w.FairParallelism))
 		case *pb.Op_File:
-			return ops.NewFileOp(v, op, w.CacheMgr, w.ParallelismSem, w)
+			return ops.NewFileOp(v, op, w.CacheMgr, w.ParallelismSem, w, ops.WithFairParallelism(w.FairParallelism))
 		case *pb.Op_Build:
 			return ops.NewBuildOp(v, op, s, w)
 		case *pb.Op_Merge:
@@ -414,12 +451,19 @@ func (w *Worker) ResolveSourceMetadata(ctx context.Context, op *pb.SourceOp, opt
 		if err != nil {
 			return nil, err
 		}
+		res := &sourceresolver.ResolveImageResponse{
+			Digest: dgst,
+			Config: config,
+		}
+		if opt.ImageOpt != nil && opt.ImageOpt.ResolveAttestations {
+			res.Attestations, err = w.ImageSource.ResolveAttestations(ctx, idt.Reference.String(), dgst, opt, sm, g)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return &sourceresolver.MetaResponse{
-			Op: op,
-			Image: &sourceresolver.ResolveImageResponse{
-				Digest: dgst,
-				Config: config,
-			},
+			Op:    op,
+			Image: res,
 		}, nil
 	case *containerimage.OCIIdentifier:
 		opt.OCILayoutOpt = &sourceresolver.ResolveOCILayoutOpt{
@@ -432,12 +476,19 @@ func (w *Worker) ResolveSourceMetadata(ctx context.Context, op *pb.SourceOp, opt
 		if err != nil {
 			return nil, err
 		}
+		res := &sourceresolver.ResolveImageResponse{
+			Digest: dgst,
+			Config: config,
+		}
+		if opt.ImageOpt != nil && opt.ImageOpt.ResolveAttestations {
+			res.Attestations, err = w.OCILayoutSource.ResolveAttestations(ctx, idt.Reference.String(), dgst, opt, sm, g)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return &sourceresolver.MetaResponse{
-			Op: op,
-			Image: &sourceresolver.ResolveImageResponse{
-				Digest: dgst,
-				Config: config,
-			},
+			Op:    op,
+			Image: res,
 		}, nil
 	}
 
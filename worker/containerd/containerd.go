@@ -18,6 +18,7 @@ import (
 	"github.com/moby/buildkit/executor/oci"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
+	"github.com/moby/buildkit/util/fairsem"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/winlayers"
@@ -43,6 +44,7 @@ type WorkerOptions struct {
 	ApparmorProfile string
 	Selinux         bool
 	ParallelismSem  *semaphore.Weighted
+	FairParallelism *fairsem.Semaphore
 	TraceSocket     string
 	Runtime         *RuntimeInfo
 	CDIManager      *cdidevices.Manager
@@ -183,6 +185,7 @@ func newContainerd(client *ctd.Client, workerOpts WorkerOptions) (base.WorkerOpt
 		LeaseManager:     lm,
 		GarbageCollect:   gc,
 		ParallelismSem:   workerOpts.ParallelismSem,
+		FairParallelism:  workerOpts.FairParallelism,
 		MountPoolRoot:    filepath.Join(root, "cachemounts"),
 		CDIManager:       workerOpts.CDIManager,
 	}